@@ -3,12 +3,16 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+
+	"xyz-multifinance/pkg/money"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
 	AppName    string
+	AppEnv     string
 	AppPort    string
 	DBHost     string
 	DBPort     string
@@ -16,6 +20,120 @@ type Config struct {
 	DBPassword string
 	DBName     string
 	JWTSecret  string
+
+	// Maximum allowed difference, in percent, between a transaction's OTR
+	// and the catalog price of its asset.
+	OTRTolerancePercent int
+
+	// Rounding applied to the fraction of a rupiah left when interest is
+	// computed: down, up, half_up or half_even.
+	InterestRounding money.RoundingMode
+
+	// Pricing of contracts made without a partner: flat admin fee and flat
+	// monthly interest rate in basis points of the financed principal.
+	DirectAdminFee        int64
+	DirectInterestRateBps int
+
+	// Late fee accrued per day on an overdue installment, in basis points of
+	// the installment amount, after a grace period and up to a cap.
+	LateFeeDailyBps   int
+	LateFeeGraceDays  int
+	LateFeeCapPercent int
+
+	// Early settlement pricing: share of not-yet-due interest waived, penalty
+	// on the outstanding principal and how long a quote stays valid.
+	SettlementInterestRebatePercent int
+	SettlementPenaltyPercent        int
+	SettlementQuoteValidityHours    int
+
+	// Interest accrual granularity: daily or monthly.
+	InterestAccrualMode string
+
+	// Number of days after creation during which a contract can still be
+	// cancelled. Zero allows cancellation at any time.
+	CancellationCoolingOffDays int
+
+	// Payout provider used to disburse principal to dealers (file or mock),
+	// the directory the file provider writes instructions to and the shared
+	// token the provider's callbacks must carry.
+	PayoutProvider      string
+	PayoutFileDir       string
+	PayoutCallbackToken string
+
+	// Disbursement retries: attempts before giving up, the delay before the
+	// first retry (doubled on each further one, capped at the maximum), how
+	// often the queue is polled and how long a payout may stay processing
+	// before it is sent again.
+	DisbursementMaxAttempts      int
+	DisbursementRetryBaseSeconds int
+	DisbursementRetryMaxSeconds  int
+	DisbursementPollSeconds      int
+	DisbursementStaleSeconds     int
+
+	// Virtual accounts: the bank's company prefix for generated numbers, the
+	// bank code stored with them and the secret and clock tolerance used to
+	// verify the bank's signed payment notifications.
+	VAPrefix                  string
+	VABankCode                string
+	VAWebhookSecret           string
+	VAWebhookToleranceSeconds int
+
+	// Days before its due date that a bank statement credit may arrive and
+	// still be matched automatically to an installment.
+	ReconciliationMatchWindowDays int
+
+	// Branding printed on PDF statements of account.
+	StatementCompanyName    string
+	StatementCompanyAddress string
+	StatementCompanyContact string
+	StatementLogoPath       string
+
+	// Upload limits for KYC photos: maximum file size and the longest side,
+	// in pixels, of the re-encoded image.
+	MaxUploadSizeMB         int
+	UploadMaxImageDimension int
+
+	// Blob store for KYC images: filesystem (below StorageDir, served through
+	// signed links under StoragePublicURL) or s3 (any S3-compatible server).
+	// StorageURLSecret signs filesystem links and defaults to the JWT secret.
+	StorageBackend   string
+	StorageDir       string
+	StoragePublicURL string
+	StorageURLSecret string
+	S3Endpoint       string
+	S3Region         string
+	S3Bucket         string
+	S3AccessKey      string
+	S3SecretKey      string
+	S3PathStyle      bool
+
+	// KYC document retrieval: the public base URL of the application that
+	// signed document links point at, their default and maximum lifetime and
+	// the longest side, in pixels, of generated thumbnails.
+	AppBaseURL                string
+	KYCSignedURLTTLSeconds    int
+	KYCSignedURLMaxTTLSeconds int
+	KYCThumbnailSize          int
+
+	// Encryption at rest: master keys as comma-separated "id:base64key"
+	// pairs, the id of the key new data is sealed with, and the HMAC key of
+	// the NIK blind index. Without keys data is stored unencrypted.
+	EncryptionKeys      string
+	EncryptionActiveKey string
+	BlindIndexKey       string
+
+	// Data subject requests: how often queued export and erasure requests
+	// are processed and how long export archives are kept for download.
+	PrivacyPollSeconds          int
+	PrivacyExportRetentionHours int
+
+	// Local time of day at which the daily overdue job runs.
+	OverdueJobHour   int
+	OverdueJobMinute int
+
+	// Local time of day at which the daily interest accrual job runs.
+	AccrualJobHour   int
+	AccrualJobMinute int
 }
 
 var AppConfig Config
@@ -27,6 +145,7 @@ func Load() Config {
 
 	cfg := Config{
 		AppName:    os.Getenv("APP_NAME"),
+		AppEnv:     getEnvString("APP_ENV", "local"),
 		AppPort:    os.Getenv("APP_PORT"),
 		DBHost:     os.Getenv("DB_HOST"),
 		DBPort:     os.Getenv("DB_PORT"),
@@ -34,9 +153,141 @@ func Load() Config {
 		DBPassword: os.Getenv("DB_PASSWORD"),
 		DBName:     os.Getenv("DB_NAME"),
 		JWTSecret:  os.Getenv("JWT_SECRET"),
+
+		OTRTolerancePercent: getEnvInt("ASSET_OTR_TOLERANCE_PERCENT", 10),
+		InterestRounding:    getEnvRounding("INTEREST_ROUNDING", money.RoundHalfUp),
+
+		DirectAdminFee:        int64(getEnvInt("DIRECT_ADMIN_FEE", 50000)),
+		DirectInterestRateBps: getEnvInt("DIRECT_INTEREST_RATE_BPS", 150),
+
+		LateFeeDailyBps:   getEnvInt("LATE_FEE_DAILY_BPS", 10),
+		LateFeeGraceDays:  getEnvInt("LATE_FEE_GRACE_DAYS", 3),
+		LateFeeCapPercent: getEnvInt("LATE_FEE_CAP_PERCENT", 25),
+
+		SettlementInterestRebatePercent: getEnvInt("SETTLEMENT_INTEREST_REBATE_PERCENT", 100),
+		SettlementPenaltyPercent:        getEnvInt("SETTLEMENT_PENALTY_PERCENT", 2),
+		SettlementQuoteValidityHours:    getEnvInt("SETTLEMENT_QUOTE_VALIDITY_HOURS", 24),
+
+		InterestAccrualMode: getEnvChoice("INTEREST_ACCRUAL_MODE", "daily", "daily", "monthly"),
+
+		CancellationCoolingOffDays: getEnvInt("CANCELLATION_COOLING_OFF_DAYS", 14),
+
+		PayoutProvider:      getEnvChoice("PAYOUT_PROVIDER", "file", "file", "mock"),
+		PayoutFileDir:       getEnvString("PAYOUT_FILE_DIR", "payouts"),
+		PayoutCallbackToken: os.Getenv("PAYOUT_CALLBACK_TOKEN"),
+
+		DisbursementMaxAttempts:      getEnvInt("DISBURSEMENT_MAX_ATTEMPTS", 5),
+		DisbursementRetryBaseSeconds: getEnvInt("DISBURSEMENT_RETRY_BASE_SECONDS", 60),
+		DisbursementRetryMaxSeconds:  getEnvInt("DISBURSEMENT_RETRY_MAX_SECONDS", 3600),
+		DisbursementPollSeconds:      getEnvInt("DISBURSEMENT_POLL_SECONDS", 30),
+		DisbursementStaleSeconds:     getEnvInt("DISBURSEMENT_STALE_SECONDS", 1800),
+
+		VAPrefix:                  getEnvString("VA_PREFIX", "88908"),
+		VABankCode:                getEnvString("VA_BANK_CODE", "BCA"),
+		VAWebhookSecret:           os.Getenv("VA_WEBHOOK_SECRET"),
+		VAWebhookToleranceSeconds: getEnvInt("VA_WEBHOOK_TOLERANCE_SECONDS", 300),
+
+		ReconciliationMatchWindowDays: getEnvInt("RECONCILIATION_MATCH_WINDOW_DAYS", 7),
+
+		StatementCompanyName:    getEnvString("STATEMENT_COMPANY_NAME", "XYZ Multifinance"),
+		StatementCompanyAddress: os.Getenv("STATEMENT_COMPANY_ADDRESS"),
+		StatementCompanyContact: os.Getenv("STATEMENT_COMPANY_CONTACT"),
+		StatementLogoPath:       os.Getenv("STATEMENT_LOGO_PATH"),
+
+		MaxUploadSizeMB:         getEnvInt("MAX_UPLOAD_SIZE_MB", 5),
+		UploadMaxImageDimension: getEnvInt("UPLOAD_MAX_IMAGE_DIMENSION", 2560),
+
+		StorageBackend:   getEnvChoice("STORAGE_BACKEND", "filesystem", "filesystem", "s3"),
+		StorageDir:       getEnvString("STORAGE_DIR", "assets"),
+		StoragePublicURL: getEnvString("STORAGE_PUBLIC_URL", "http://localhost:"+os.Getenv("APP_PORT")+"/api/v1/files"),
+		StorageURLSecret: getEnvString("STORAGE_URL_SECRET", os.Getenv("JWT_SECRET")),
+		S3Endpoint:       os.Getenv("S3_ENDPOINT"),
+		S3Region:         getEnvString("S3_REGION", "us-east-1"),
+		S3Bucket:         os.Getenv("S3_BUCKET"),
+		S3AccessKey:      os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:      os.Getenv("S3_SECRET_KEY"),
+		S3PathStyle:      getEnvBool("S3_PATH_STYLE", true),
+
+		AppBaseURL:                getEnvString("APP_BASE_URL", "http://localhost:"+os.Getenv("APP_PORT")),
+		KYCSignedURLTTLSeconds:    getEnvInt("KYC_SIGNED_URL_TTL_SECONDS", 300),
+		KYCSignedURLMaxTTLSeconds: getEnvInt("KYC_SIGNED_URL_MAX_TTL_SECONDS", 3600),
+		KYCThumbnailSize:          getEnvInt("KYC_THUMBNAIL_SIZE", 320),
+
+		EncryptionKeys:      os.Getenv("ENCRYPTION_KEYS"),
+		EncryptionActiveKey: os.Getenv("ENCRYPTION_ACTIVE_KEY"),
+		BlindIndexKey:       os.Getenv("BLIND_INDEX_KEY"),
+
+		PrivacyPollSeconds:          getEnvInt("PRIVACY_POLL_SECONDS", 60),
+		PrivacyExportRetentionHours: getEnvInt("PRIVACY_EXPORT_RETENTION_HOURS", 72),
+
+		OverdueJobHour:   getEnvInt("OVERDUE_JOB_HOUR", 1),
+		OverdueJobMinute: getEnvInt("OVERDUE_JOB_MINUTE", 0),
+
+		AccrualJobHour:   getEnvInt("ACCRUAL_JOB_HOUR", 1),
+		AccrualJobMinute: getEnvInt("ACCRUAL_JOB_MINUTE", 0),
 	}
 
 	AppConfig = cfg
 
 	return cfg
 }
+
+func getEnvRounding(key string, fallback money.RoundingMode) money.RoundingMode {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	mode, err := money.ParseRoundingMode(value)
+	if err != nil {
+		log.Printf("invalid value for %s, using default %s", key, fallback)
+		return fallback
+	}
+	return mode
+}
+
+func getEnvChoice(key, fallback string, choices ...string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	for _, choice := range choices {
+		if value == choice {
+			return value
+		}
+	}
+	log.Printf("invalid value for %s, using default %s", key, fallback)
+	return fallback
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("invalid value for %s, using default %t", key, fallback)
+		return fallback
+	}
+	return b
+}
+
+func getEnvString(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("invalid value for %s, using default %d", key, fallback)
+		return fallback
+	}
+	return n
+}
@@ -24,7 +24,10 @@ func Connect(cfg config.Config) (*gorm.DB, error) {
 	)
 
 	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
-		Logger: gormLogger.Default.LogMode(gormLogger.Info),
+		Logger: logger.NewGormLogger(gormLogger.Info),
+		// Duplicate-key errors come back as gorm.ErrDuplicatedKey, so
+		// callers can rely on unique indexes without parsing MySQL errors.
+		TranslateError: true,
 	})
 	if err != nil {
 		logger.Log.Errorf("failed to connect to database: %v", err)
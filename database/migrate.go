@@ -0,0 +1,425 @@
+package database
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+// Migrations returns the migrations built into the binary.
+func Migrations() fs.FS {
+	sub, err := fs.Sub(embeddedMigrations, "migrations")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+var (
+	ErrSchemaOutOfDate = errors.New("database schema is out of date")
+	ErrSchemaDirty     = errors.New("database schema is dirty")
+	ErrSchemaUntracked = errors.New("database schema has changes not recorded in schema_migrations")
+)
+
+// Migration is one schema version. Files are named
+// <version>_<name>.up.sql and <version>_<name>.down.sql; every version needs
+// both.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus is a migration together with its state in the database.
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	Dirty     bool
+	AppliedAt *time.Time
+}
+
+// schemaMigration is a row of schema_migrations. A row is dirty while its
+// migration runs; MySQL cannot roll back DDL, so a row left dirty means the
+// schema is half-migrated and has to be repaired by hand.
+type schemaMigration struct {
+	Version   int64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	Dirty     bool
+	AppliedAt *time.Time
+}
+
+func (schemaMigration) TableName() string { return "schema_migrations" }
+
+var (
+	migrationFilePattern = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+	createTablePattern   = regexp.MustCompile(`(?i)^CREATE\s+TABLE\s+` + "`?" + `(\w+)`)
+)
+
+// LoadMigrations reads the migrations in fsys, ordered by version.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != ".sql" {
+			continue
+		}
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: name must be <version>_<name>.up.sql or .down.sql", entry.Name())
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: invalid version", entry.Name())
+		}
+		body, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(body)
+		} else {
+			migration.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs both an up and a down file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator applies and reverts migrations and records them in
+// schema_migrations. Only one migrator may run against a database at a time.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+func NewMigrator(db *gorm.DB, fsys fs.FS) (*Migrator, error) {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+func (m *Migrator) ensureTable() error {
+	return m.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT NOT NULL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    dirty BOOLEAN NOT NULL DEFAULT FALSE,
+    applied_at TIMESTAMP NULL DEFAULT NULL
+)`).Error
+}
+
+func (m *Migrator) applied() (map[int64]schemaMigration, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+	var rows []schemaMigration
+	if err := m.db.Order("version ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]schemaMigration, len(rows))
+	for _, row := range rows {
+		if row.Dirty {
+			return nil, fmt.Errorf("%w: migration %d_%s did not finish; repair the schema by hand, then record the last finished version with `migrate force <version>`",
+				ErrSchemaDirty, row.Version, row.Name)
+		}
+		applied[row.Version] = row
+	}
+	return applied, nil
+}
+
+// Up applies every pending migration in version order and returns the ones
+// it applied.
+func (m *Migrator) Up() ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkUntracked(applied); err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		row := schemaMigration{Version: migration.Version, Name: migration.Name, Dirty: true}
+		if err := m.db.Create(&row).Error; err != nil {
+			return done, err
+		}
+		if err := m.exec(migration.Up); err != nil {
+			return done, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		now := time.Now()
+		if err := m.db.Model(&row).Updates(map[string]interface{}{"dirty": false, "applied_at": now}).Error; err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down reverts the given number of most recently applied migrations and
+// returns the ones it reverted.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		row := schemaMigration{Version: migration.Version}
+		if err := m.db.Model(&row).Update("dirty", true).Error; err != nil {
+			return done, err
+		}
+		if err := m.exec(migration.Down); err != nil {
+			return done, fmt.Errorf("migration %d_%s down: %w", migration.Version, migration.Name, err)
+		}
+		if err := m.db.Delete(&row).Error; err != nil {
+			return done, err
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// checkUntracked refuses to start when a pending migration creates a table
+// that already exists, which happens with databases set up by hand from an
+// older copy of the schema. Running it would fail half-way and leave the
+// schema dirty.
+func (m *Migrator) checkUntracked(applied map[int64]schemaMigration) error {
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		for _, statement := range SplitStatements(migration.Up) {
+			match := createTablePattern.FindStringSubmatch(statement)
+			if match == nil || !m.db.Migrator().HasTable(match[1]) {
+				continue
+			}
+			return fmt.Errorf("%w: table %s of migration %d_%s already exists; record the migrations this database already has with `migrate force <version>`",
+				ErrSchemaUntracked, match[1], migration.Version, migration.Name)
+		}
+	}
+	return nil
+}
+
+// Force records every migration up to and including version as applied and
+// forgets the later ones, without running any SQL. It is for databases whose
+// schema was changed outside the migrator and clears a dirty state once the
+// schema has been repaired by hand.
+func (m *Migrator) Force(version int64) error {
+	known := false
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			known = true
+		}
+	}
+	if !known && version != 0 {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+	if err := m.ensureTable(); err != nil {
+		return err
+	}
+
+	return m.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("version > ?", version).Delete(&schemaMigration{}).Error; err != nil {
+			return err
+		}
+		var rows []schemaMigration
+		if err := tx.Find(&rows).Error; err != nil {
+			return err
+		}
+		recorded := make(map[int64]schemaMigration, len(rows))
+		for _, row := range rows {
+			recorded[row.Version] = row
+		}
+
+		now := time.Now()
+		for _, migration := range m.migrations {
+			if migration.Version > version {
+				break
+			}
+			if row, ok := recorded[migration.Version]; ok && !row.Dirty {
+				continue
+			}
+			row := schemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: &now}
+			if err := tx.Save(&row).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Status lists every known migration with its state, followed by versions
+// recorded in the database that this binary does not know about.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+	var rows []schemaMigration
+	if err := m.db.Order("version ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	recorded := make(map[int64]schemaMigration, len(rows))
+	for _, row := range rows {
+		recorded[row.Version] = row
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	known := make(map[int64]bool, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if row, ok := recorded[migration.Version]; ok {
+			status.Applied = !row.Dirty
+			status.Dirty = row.Dirty
+			status.AppliedAt = row.AppliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	for _, row := range rows {
+		if !known[row.Version] {
+			statuses = append(statuses, MigrationStatus{
+				Version: row.Version, Name: row.Name, Applied: !row.Dirty, Dirty: row.Dirty, AppliedAt: row.AppliedAt,
+			})
+		}
+	}
+	return statuses, nil
+}
+
+// Check returns ErrSchemaOutOfDate when migrations are pending and
+// ErrSchemaDirty when one did not finish. Versions newer than the binary are
+// allowed, so an older build keeps running while a newer one rolls out.
+func (m *Migrator) Check() error {
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	var pending []string
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, fmt.Sprintf("%d_%s", migration.Version, migration.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: pending migrations %s; run `migrate up`", ErrSchemaOutOfDate, strings.Join(pending, ", "))
+	}
+	return nil
+}
+
+// CheckSchema checks the database against the migrations built into the
+// binary.
+func CheckSchema(db *gorm.DB) error {
+	migrator, err := NewMigrator(db, Migrations())
+	if err != nil {
+		return err
+	}
+	return migrator.Check()
+}
+
+// exec runs the statements of a migration one by one, since the driver
+// does not accept several statements in one call.
+func (m *Migrator) exec(script string) error {
+	for i, statement := range SplitStatements(script) {
+		if err := m.db.Exec(statement).Error; err != nil {
+			return fmt.Errorf("statement %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// SplitStatements splits a SQL script on the semicolons that end its
+// statements, skipping semicolons inside quotes and comments. Comments and
+// empty statements are dropped.
+func SplitStatements(script string) []string {
+	var (
+		statements []string
+		current    strings.Builder
+		quote      byte
+	)
+	flush := func() {
+		if statement := strings.TrimSpace(current.String()); statement != "" {
+			statements = append(statements, statement)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case quote != 0:
+			current.WriteByte(c)
+			if c == '\\' && quote != '`' && i+1 < len(script) {
+				i++
+				current.WriteByte(script[i])
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+			current.WriteByte(c)
+		case c == '-' && strings.HasPrefix(script[i:], "-- "), c == '#':
+			for i < len(script) && script[i] != '\n' {
+				i++
+			}
+			current.WriteByte('\n')
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				i = len(script)
+			} else {
+				i += end + 3
+			}
+			current.WriteByte(' ')
+		case c == ';':
+			flush()
+		default:
+			current.WriteByte(c)
+		}
+	}
+	flush()
+	return statements
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestEmbeddedMigrationsLoad(t *testing.T) {
+	migrations, err := LoadMigrations(Migrations())
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	if len(migrations) == 0 || migrations[0].Version != 1 {
+		t.Fatalf("migrations = %+v, want version 1 first", migrations)
+	}
+	for i, migration := range migrations {
+		if i > 0 && migration.Version <= migrations[i-1].Version {
+			t.Errorf("migration %d is out of order", migration.Version)
+		}
+		if len(SplitStatements(migration.Up)) == 0 || len(SplitStatements(migration.Down)) == 0 {
+			t.Errorf("migration %d_%s has an empty up or down script", migration.Version, migration.Name)
+		}
+	}
+}
+
+func TestLoadMigrationsRejectsMissingDown(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0001_users.up.sql":   {Data: []byte("CREATE TABLE users (id INT);")},
+		"0001_users.down.sql": {Data: []byte("DROP TABLE users;")},
+		"0002_loans.up.sql":   {Data: []byte("CREATE TABLE loans (id INT);")},
+	}
+	_, err := LoadMigrations(fsys)
+	if err == nil || !strings.Contains(err.Error(), "2_loans") {
+		t.Fatalf("err = %v, want missing down for 2_loans", err)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- header; not a statement
+CREATE TABLE a (
+    id INT, -- trailing; comment
+    note VARCHAR(10) DEFAULT 'x;y'
+);
+/* block; comment */
+INSERT INTO a (note) VALUES ('it\'s; fine');
+
+`
+	got := SplitStatements(script)
+	want := []string{
+		"CREATE TABLE a (\n    id INT, \n    note VARCHAR(10) DEFAULT 'x;y'\n)",
+		"INSERT INTO a (note) VALUES ('it\\'s; fine')",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("SplitStatements = %q, want %q", got, want)
+	}
+}
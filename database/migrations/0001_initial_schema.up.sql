@@ -1,6 +1,6 @@
--- Buat database
-CREATE DATABASE IF NOT EXISTS xyz_db;
-USE xyz_db;
+-- Skema awal, sama persis dengan database/migration.sql versi pertama.
+-- Database yang dibuat dari file tersebut dicatat sebagai versi ini tanpa
+-- perubahan (IF NOT EXISTS) dan dinaikkan oleh migrasi 0002 dan seterusnya.
 
 -- Tabel Users
 CREATE TABLE IF NOT EXISTS users (
@@ -60,60 +60,3 @@ CREATE TABLE IF NOT EXISTS transactions (
     deleted_at TIMESTAMP NULL DEFAULT NULL,
     FOREIGN KEY (customer_id) REFERENCES customers(id) ON DELETE CASCADE
 );
-
--- INSERT dummy customer for development
-
--- Dummy Admin
-INSERT INTO users (username, password_hash, role, created_at)
-VALUES (
-    'dian',
-    '$2a$10$7uRtHKrklb8BPzmGWAVtJuWPfKebl.eoCveYKAH4elXQpniGAA8IW', //password123
-    'admin',
-    NOW()
-);
-
--- Ambil ID dari user 'cust001' untuk referensi ke customers
-SET @cust_user_id = (SELECT id FROM users WHERE username = 'cust001' LIMIT 1);
-
-INSERT INTO customers (
-    user_id, nik, full_name, legal_name, birth_place, birth_date, salary,
-    photo_ktp, photo_selfie, created_at, updated_at
-) VALUES (
-    1,
-    '12345',
-    'Dian Erwansyah',
-    'Dian Erwansyah',
-    'Tarakan',
-    '1996-05-03',
-    8000000,
-    '',
-    '',
-    NOW(),
-    NOW()
-);
-
--- customer_id = 1 (Dian Erwansyah)
-INSERT INTO limits (customer_id, tenor_month, limit_amount, created_at, updated_at)
-VALUES
-(1, 1, 5000000, NOW(), NOW()),
-(1, 3, 15000000, NOW(), NOW());
-
-
--- Misal customer_id = 1
-INSERT INTO transactions (
-    customer_id, contract_number, tenor, otr, admin_fee,
-    installment_amount, interest_amount, asset_name,
-    created_at, updated_at
-) VALUES (
-    1,
-    'CNTR202507001',
-    3,
-    20000000,
-    1000000,
-    5000000,
-    2000000,
-    'Yamaha NMAX 2024',
-    'disbursed',
-    NOW(),
-    NOW()
-);
\ No newline at end of file
@@ -0,0 +1,19 @@
+-- Tabel Installments
+CREATE TABLE installments (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    transaction_id INT NOT NULL,
+    sequence INT NOT NULL,
+    due_date DATE NOT NULL,
+    principal BIGINT NOT NULL,
+    interest BIGINT NOT NULL,
+    amount BIGINT NOT NULL,
+    paid_amount BIGINT NOT NULL DEFAULT 0,
+    paid_at TIMESTAMP NULL DEFAULT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'unpaid',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    INDEX idx_installments_transaction_id (transaction_id),
+    INDEX idx_installments_due_date (due_date),
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE CASCADE
+);
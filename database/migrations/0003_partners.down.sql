@@ -0,0 +1,10 @@
+ALTER TABLE transactions
+    DROP FOREIGN KEY fk_transactions_partner_id,
+    DROP FOREIGN KEY fk_transactions_outlet_id;
+
+ALTER TABLE transactions
+    DROP COLUMN outlet_id,
+    DROP COLUMN partner_id;
+
+DROP TABLE outlets;
+DROP TABLE partners;
@@ -0,0 +1,36 @@
+-- Tabel Partners (dealer)
+CREATE TABLE partners (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(32) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    api_key_id VARCHAR(32) UNIQUE,
+    api_key_hash VARCHAR(64),
+    admin_fee BIGINT NOT NULL DEFAULT 0,
+    interest_rate_bps INT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL
+);
+
+-- Tabel Outlets
+CREATE TABLE outlets (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    partner_id INT NOT NULL,
+    code VARCHAR(32) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    address VARCHAR(255),
+    city VARCHAR(100),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    UNIQUE KEY uq_outlets_partner_code (partner_id, code),
+    FOREIGN KEY (partner_id) REFERENCES partners(id) ON DELETE CASCADE
+);
+
+ALTER TABLE transactions
+    ADD COLUMN partner_id INT NULL AFTER asset_name,
+    ADD COLUMN outlet_id INT NULL AFTER partner_id,
+    ADD CONSTRAINT fk_transactions_partner_id FOREIGN KEY (partner_id) REFERENCES partners(id),
+    ADD CONSTRAINT fk_transactions_outlet_id FOREIGN KEY (outlet_id) REFERENCES outlets(id);
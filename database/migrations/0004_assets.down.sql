@@ -0,0 +1,4 @@
+ALTER TABLE transactions DROP FOREIGN KEY fk_transactions_asset_id;
+ALTER TABLE transactions DROP COLUMN asset_id;
+
+DROP TABLE assets;
@@ -0,0 +1,17 @@
+-- Tabel Assets (katalog barang yang dibiayai)
+CREATE TABLE assets (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    category VARCHAR(20) NOT NULL,
+    brand VARCHAR(100) NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    year INT NOT NULL,
+    otr_price BIGINT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    UNIQUE KEY uq_assets_catalog (category, brand, model, year)
+);
+
+ALTER TABLE transactions
+    ADD COLUMN asset_id INT NULL AFTER interest_amount,
+    ADD CONSTRAINT fk_transactions_asset_id FOREIGN KEY (asset_id) REFERENCES assets(id);
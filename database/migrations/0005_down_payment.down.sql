@@ -0,0 +1,5 @@
+ALTER TABLE transactions
+    DROP COLUMN principal,
+    DROP COLUMN down_payment;
+
+DROP TABLE down_payment_rules;
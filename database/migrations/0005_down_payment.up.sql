@@ -0,0 +1,18 @@
+-- Tabel Down Payment Rules
+-- category kosong / tenor_month 0 berarti berlaku untuk semua kategori / tenor
+CREATE TABLE down_payment_rules (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    category VARCHAR(20) NOT NULL DEFAULT '',
+    tenor_month INT NOT NULL DEFAULT 0,
+    min_percent INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_down_payment_rules (category, tenor_month)
+);
+
+ALTER TABLE transactions
+    ADD COLUMN down_payment BIGINT NOT NULL DEFAULT 0 AFTER otr,
+    ADD COLUMN principal BIGINT NOT NULL DEFAULT 0 AFTER down_payment;
+
+-- Kontrak lama dibiayai penuh: pokok = OTR - DP.
+UPDATE transactions SET principal = otr - down_payment;
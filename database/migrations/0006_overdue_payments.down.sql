@@ -0,0 +1,11 @@
+DROP TABLE payment_allocations;
+DROP TABLE payments;
+
+ALTER TABLE installments
+    DROP COLUMN late_fee_paid,
+    DROP COLUMN late_fee;
+
+ALTER TABLE transactions
+    DROP COLUMN aging_bucket,
+    DROP COLUMN collectibility,
+    DROP COLUMN days_past_due;
@@ -0,0 +1,38 @@
+ALTER TABLE transactions
+    ADD COLUMN days_past_due INT NOT NULL DEFAULT 0 AFTER `status`,
+    ADD COLUMN collectibility INT NOT NULL DEFAULT 1 AFTER days_past_due,
+    ADD COLUMN aging_bucket VARCHAR(10) NOT NULL DEFAULT 'current' AFTER collectibility;
+
+ALTER TABLE installments
+    ADD COLUMN late_fee BIGINT NOT NULL DEFAULT 0 AFTER paid_amount,
+    ADD COLUMN late_fee_paid BIGINT NOT NULL DEFAULT 0 AFTER late_fee;
+
+-- Tabel Payments
+CREATE TABLE payments (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    transaction_id INT NOT NULL,
+    amount BIGINT NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    reference VARCHAR(100) NOT NULL UNIQUE,
+    paid_at TIMESTAMP NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_payments_transaction_id (transaction_id),
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+);
+
+-- Tabel Payment Allocations
+CREATE TABLE payment_allocations (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    payment_id INT NOT NULL,
+    installment_id INT NOT NULL,
+    late_fee BIGINT NOT NULL DEFAULT 0,
+    interest BIGINT NOT NULL DEFAULT 0,
+    principal BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_payment_allocations_payment_id (payment_id),
+    INDEX idx_payment_allocations_installment_id (installment_id),
+    FOREIGN KEY (payment_id) REFERENCES payments(id) ON DELETE CASCADE,
+    FOREIGN KEY (installment_id) REFERENCES installments(id)
+);
@@ -0,0 +1,20 @@
+-- Tabel Settlement Quotes
+CREATE TABLE settlement_quotes (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    transaction_id INT NOT NULL,
+    as_of DATE NOT NULL,
+    outstanding_principal BIGINT NOT NULL,
+    outstanding_interest BIGINT NOT NULL,
+    interest_rebate BIGINT NOT NULL,
+    outstanding_late_fee BIGINT NOT NULL,
+    penalty_fee BIGINT NOT NULL,
+    total_amount BIGINT NOT NULL,
+    valid_until TIMESTAMP NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    payment_id INT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_settlement_quotes_transaction_id (transaction_id),
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id),
+    FOREIGN KEY (payment_id) REFERENCES payments(id)
+);
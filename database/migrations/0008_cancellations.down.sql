@@ -0,0 +1,3 @@
+DROP TABLE transaction_cancellations;
+
+ALTER TABLE payments DROP COLUMN reversed_at;
@@ -0,0 +1,16 @@
+ALTER TABLE payments ADD COLUMN reversed_at TIMESTAMP NULL AFTER `status`;
+
+-- Tabel Transaction Cancellations
+CREATE TABLE transaction_cancellations (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    transaction_id INT NOT NULL UNIQUE,
+    previous_status VARCHAR(50) NOT NULL,
+    reason_code VARCHAR(30) NOT NULL,
+    note TEXT,
+    cancelled_by INT NOT NULL,
+    reversed_payments INT NOT NULL DEFAULT 0,
+    reversed_amount BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id),
+    FOREIGN KEY (cancelled_by) REFERENCES users(id)
+);
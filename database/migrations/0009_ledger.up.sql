@@ -0,0 +1,49 @@
+-- Tabel Accounts (chart of accounts)
+CREATE TABLE accounts (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    code VARCHAR(10) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO accounts (code, name, type) VALUES
+('1100', 'Cash and bank', 'asset'),
+('1200', 'Loan receivable', 'asset'),
+('1210', 'Interest receivable', 'asset'),
+('1220', 'Late fee receivable', 'asset'),
+('2100', 'Unearned interest', 'liability'),
+('4100', 'Interest income', 'income'),
+('4200', 'Admin fee income', 'income'),
+('4300', 'Late fee income', 'income'),
+('4400', 'Early settlement fee income', 'income'),
+('5100', 'Loan write-off expense', 'expense');
+
+-- Tabel Journal Entries
+CREATE TABLE journal_entries (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    entry_date DATE NOT NULL,
+    type VARCHAR(30) NOT NULL,
+    reference VARCHAR(100) NOT NULL,
+    transaction_id INT NULL,
+    description VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_journal_entries_entry_date (entry_date),
+    INDEX idx_journal_entries_reference (reference),
+    INDEX idx_journal_entries_transaction_id (transaction_id),
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+);
+
+-- Tabel Journal Lines
+CREATE TABLE journal_lines (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    journal_entry_id INT NOT NULL,
+    account_code VARCHAR(10) NOT NULL,
+    debit BIGINT NOT NULL DEFAULT 0,
+    credit BIGINT NOT NULL DEFAULT 0,
+    INDEX idx_journal_lines_journal_entry_id (journal_entry_id),
+    INDEX idx_journal_lines_account_code (account_code),
+    FOREIGN KEY (journal_entry_id) REFERENCES journal_entries(id),
+    FOREIGN KEY (account_code) REFERENCES accounts(code),
+    CHECK (debit >= 0 AND credit >= 0 AND (debit = 0) <> (credit = 0))
+);
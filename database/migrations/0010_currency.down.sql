@@ -0,0 +1,2 @@
+ALTER TABLE transactions DROP COLUMN currency;
+ALTER TABLE limits DROP COLUMN currency;
@@ -0,0 +1,2 @@
+ALTER TABLE limits ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'IDR' AFTER limit_amount;
+ALTER TABLE transactions ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'IDR' AFTER tenor;
@@ -0,0 +1,13 @@
+-- Tabel Interest Accruals
+CREATE TABLE interest_accruals (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    transaction_id INT NOT NULL,
+    accrual_date DATE NOT NULL,
+    amount BIGINT NOT NULL,
+    journal_entry_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_interest_accruals_tx_date (transaction_id, accrual_date),
+    INDEX idx_interest_accruals_accrual_date (accrual_date),
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id),
+    FOREIGN KEY (journal_entry_id) REFERENCES journal_entries(id)
+);
@@ -0,0 +1,8 @@
+DROP TABLE disbursements;
+
+ALTER TABLE transactions DROP COLUMN disbursed_at;
+
+ALTER TABLE outlets
+    DROP COLUMN bank_account_name,
+    DROP COLUMN bank_account_number,
+    DROP COLUMN bank_code;
@@ -0,0 +1,29 @@
+ALTER TABLE outlets
+    ADD COLUMN bank_code VARCHAR(20) AFTER city,
+    ADD COLUMN bank_account_number VARCHAR(34) AFTER bank_code,
+    ADD COLUMN bank_account_name VARCHAR(100) AFTER bank_account_number;
+
+ALTER TABLE transactions ADD COLUMN disbursed_at TIMESTAMP NULL DEFAULT NULL AFTER aging_bucket;
+
+-- Tabel Disbursements (pencairan dana ke dealer)
+CREATE TABLE disbursements (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    transaction_id INT NOT NULL UNIQUE,
+    reference VARCHAR(50) NOT NULL UNIQUE,
+    amount BIGINT NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'IDR',
+    bank_code VARCHAR(20),
+    bank_account_number VARCHAR(34),
+    bank_account_name VARCHAR(100),
+    provider VARCHAR(30),
+    provider_reference VARCHAR(100),
+    status VARCHAR(20) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NULL DEFAULT NULL,
+    last_error VARCHAR(255),
+    disbursed_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_disbursements_status_next_attempt (status, next_attempt_at),
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+);
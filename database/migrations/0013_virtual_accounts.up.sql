@@ -0,0 +1,15 @@
+-- Tabel Virtual Accounts (nomor VA per customer atau per kontrak)
+CREATE TABLE virtual_accounts (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    number VARCHAR(20) NOT NULL UNIQUE,
+    bank_code VARCHAR(20) NOT NULL,
+    customer_id INT NOT NULL,
+    transaction_id INT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_virtual_accounts_customer_id (customer_id),
+    INDEX idx_virtual_accounts_transaction_id (transaction_id),
+    FOREIGN KEY (customer_id) REFERENCES customers(id),
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id)
+);
@@ -0,0 +1,36 @@
+-- Tabel Statement Imports (file mutasi bank untuk rekonsiliasi)
+CREATE TABLE statement_imports (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    file_name VARCHAR(255) NOT NULL,
+    format VARCHAR(10) NOT NULL,
+    imported_by INT NOT NULL,
+    line_count INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Tabel Statement Lines
+CREATE TABLE statement_lines (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    import_id INT NOT NULL,
+    line_number INT NOT NULL,
+    value_date DATE NOT NULL,
+    amount BIGINT NOT NULL,
+    reference VARCHAR(100),
+    description VARCHAR(255),
+    va_number VARCHAR(20),
+    status VARCHAR(20) NOT NULL,
+    note VARCHAR(255),
+    transaction_id INT NULL,
+    payment_id INT NULL,
+    resolved_by INT NULL,
+    resolved_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_statement_lines_import_id (import_id),
+    INDEX idx_statement_lines_status (status),
+    INDEX idx_statement_lines_va_number (va_number),
+    INDEX idx_statement_lines_transaction_id (transaction_id),
+    FOREIGN KEY (import_id) REFERENCES statement_imports(id),
+    FOREIGN KEY (transaction_id) REFERENCES transactions(id),
+    FOREIGN KEY (payment_id) REFERENCES payments(id)
+);
@@ -0,0 +1,12 @@
+-- Hanya bisa dijalankan selama kolom masih berisi plaintext (sebelum
+-- `reencrypt`): nilai terenkripsi tidak muat di tipe kolom lama.
+ALTER TABLE customers
+    DROP COLUMN nik_hash,
+    MODIFY COLUMN nik VARCHAR(20) NOT NULL,
+    MODIFY COLUMN full_name VARCHAR(100) NOT NULL,
+    MODIFY COLUMN legal_name VARCHAR(100) NOT NULL,
+    MODIFY COLUMN birth_place VARCHAR(100),
+    MODIFY COLUMN birth_date DATE,
+    MODIFY COLUMN salary BIGINT;
+
+ALTER TABLE customers ADD UNIQUE INDEX nik (nik);
@@ -0,0 +1,14 @@
+-- nik s.d. salary disimpan terenkripsi, pencarian NIK lewat nik_hash.
+-- Nilai lama tetap terbaca sebagai plaintext; jalankan `reencrypt` setelah
+-- migrasi ini untuk mengenkripsinya dan mengisi nik_hash, karena pencarian
+-- customer berdasarkan NIK hanya memakai nik_hash.
+ALTER TABLE customers DROP INDEX nik;
+
+ALTER TABLE customers
+    MODIFY COLUMN nik VARCHAR(255) NOT NULL,
+    ADD COLUMN nik_hash CHAR(64) NULL UNIQUE AFTER nik,
+    MODIFY COLUMN full_name VARCHAR(255) NOT NULL,
+    MODIFY COLUMN legal_name VARCHAR(255) NOT NULL,
+    MODIFY COLUMN birth_place VARCHAR(255),
+    MODIFY COLUMN birth_date VARCHAR(128),
+    MODIFY COLUMN salary VARCHAR(128);
@@ -0,0 +1 @@
+DROP TABLE kyc_document_accesses;
@@ -0,0 +1,15 @@
+-- Tabel KYC Document Accesses (audit setiap akses foto KTP/selfie)
+CREATE TABLE kyc_document_accesses (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    customer_id INT NOT NULL,
+    document_type VARCHAR(10) NOT NULL,
+    variant VARCHAR(10) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    user_id INT NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_kyc_document_accesses_customer_id (customer_id),
+    FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
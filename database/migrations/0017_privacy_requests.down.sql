@@ -0,0 +1,3 @@
+DROP TABLE privacy_requests;
+
+ALTER TABLE customers DROP COLUMN anonymized_at;
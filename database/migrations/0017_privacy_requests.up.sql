@@ -0,0 +1,20 @@
+ALTER TABLE customers ADD COLUMN anonymized_at TIMESTAMP NULL DEFAULT NULL AFTER photo_selfie;
+
+-- Tabel Privacy Requests (permintaan ekspor dan penghapusan data pribadi, UU PDP)
+CREATE TABLE privacy_requests (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    customer_id INT NOT NULL,
+    type VARCHAR(10) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    reason VARCHAR(255),
+    requested_by INT NOT NULL,
+    result_key VARCHAR(255),
+    last_error VARCHAR(255),
+    completed_at TIMESTAMP NULL DEFAULT NULL,
+    expires_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_privacy_requests_customer_id (customer_id),
+    INDEX idx_privacy_requests_status (status),
+    FOREIGN KEY (customer_id) REFERENCES customers(id)
+);
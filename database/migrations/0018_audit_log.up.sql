@@ -0,0 +1,29 @@
+-- Tabel Audit Logs (jejak perubahan data, berantai hash)
+CREATE TABLE audit_logs (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    request_id VARCHAR(64),
+    user_id INT,
+    role VARCHAR(20),
+    partner_id INT,
+    ip_address VARCHAR(45),
+    entity VARCHAR(20) NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    changes TEXT,
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_audit_logs_entity (entity, entity_id),
+    INDEX idx_audit_logs_user_id (user_id),
+    INDEX idx_audit_logs_request_id (request_id)
+);
+
+-- Tabel Audit Chain Heads (ujung rantai audit log, hanya satu baris)
+CREATE TABLE audit_chain_heads (
+    id INT PRIMARY KEY,
+    last_log_id INT NOT NULL DEFAULT 0,
+    last_hash CHAR(64) NOT NULL DEFAULT '',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+);
+
+INSERT INTO audit_chain_heads (id, last_log_id, last_hash) VALUES (1, 0, '');
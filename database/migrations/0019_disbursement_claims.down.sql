@@ -0,0 +1,3 @@
+ALTER TABLE disbursements
+    DROP INDEX idx_disbursements_status_claimed_at,
+    DROP COLUMN claimed_at;
@@ -0,0 +1,5 @@
+-- Waktu disbursement diklaim worker, untuk mengantrekan ulang pencairan yang
+-- tertahan di status processing.
+ALTER TABLE disbursements
+    ADD COLUMN claimed_at TIMESTAMP NULL DEFAULT NULL AFTER next_attempt_at,
+    ADD INDEX idx_disbursements_status_claimed_at (status, claimed_at);
@@ -0,0 +1,19 @@
+package database
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed seeds/*.yaml
+var embeddedSeeds embed.FS
+
+// Seeds returns the seed fixtures built into the binary, one
+// <environment>.yaml file per environment.
+func Seeds() fs.FS {
+	sub, err := fs.Sub(embeddedSeeds, "seeds")
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccrualHandler struct {
+	accrualUsecase usecase.AccrualUsecase
+}
+
+func NewAccrualHandler(uc usecase.AccrualUsecase) *AccrualHandler {
+	return &AccrualHandler{accrualUsecase: uc}
+}
+
+func (h *AccrualHandler) RunAccrual(c *gin.Context) {
+	asOf, ok := dateQuery(c, "as_of", time.Now())
+	if !ok {
+		return
+	}
+
+	result, err := h.accrualUsecase.Run(asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *AccrualHandler) GetAccrualReport(c *gin.Context) {
+	now := time.Now()
+	from, ok := dateQuery(c, "from", time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local))
+	if !ok {
+		return
+	}
+	to, ok := dateQuery(c, "to", now)
+	if !ok {
+		return
+	}
+
+	report, err := h.accrualUsecase.GetReport(from, to, c.Query("group_by"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
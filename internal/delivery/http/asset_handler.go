@@ -0,0 +1,149 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AssetHandler struct {
+	assetUsecase usecase.AssetUsecase
+}
+
+func NewAssetHandler(uc usecase.AssetUsecase) *AssetHandler {
+	return &AssetHandler{assetUsecase: uc}
+}
+
+type assetRequest struct {
+	Category string `json:"category" binding:"required"`
+	Brand    string `json:"brand" binding:"required"`
+	Model    string `json:"model" binding:"required"`
+	Year     int    `json:"year" binding:"required"`
+	OTRPrice int64  `json:"otr_price" binding:"required"`
+}
+
+func (h *AssetHandler) CreateAsset(c *gin.Context) {
+	var req assetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	asset := model.Asset{
+		Category: req.Category,
+		Brand:    req.Brand,
+		Model:    req.Model,
+		Year:     req.Year,
+		OTRPrice: req.OTRPrice,
+	}
+
+	if err := h.assetUsecase.CreateAsset(&asset); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Asset created", "asset": asset})
+}
+
+func (h *AssetHandler) GetAssets(c *gin.Context) {
+	category := c.Query("category")
+	if category != "" && !model.IsValidAssetCategory(category) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category"})
+		return
+	}
+
+	assets, err := h.assetUsecase.GetAssets(category)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get assets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, assets)
+}
+
+func (h *AssetHandler) GetAssetByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid asset id"})
+		return
+	}
+
+	asset, err := h.assetUsecase.GetAssetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Asset not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, asset)
+}
+
+func (h *AssetHandler) UpdateAsset(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid asset id"})
+		return
+	}
+
+	var updateData map[string]interface{}
+	if err := c.ShouldBindJSON(&updateData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	allowedFields := map[string]bool{"category": true, "brand": true, "model": true, "year": true, "otr_price": true}
+	for key := range updateData {
+		if !allowedFields[key] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid field: " + key})
+			return
+		}
+	}
+
+	if err := h.assetUsecase.UpdateAsset(uint(id), updateData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Asset updated"})
+}
+
+func (h *AssetHandler) DeleteAsset(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid asset id"})
+		return
+	}
+
+	if err := h.assetUsecase.DeleteAsset(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Asset deleted"})
+}
+
+func (h *AssetHandler) ImportAssets(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CSV file is required"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open file"})
+		return
+	}
+	defer file.Close()
+
+	result, err := h.assetUsecase.ImportCSV(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditUsecase usecase.AuditUsecase
+}
+
+func NewAuditHandler(uc usecase.AuditUsecase) *AuditHandler {
+	return &AuditHandler{auditUsecase: uc}
+}
+
+// GetAuditLogs lists audit entries, newest first. The optional to date is
+// inclusive; before_id pages through older entries.
+func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
+	from, ok := dateQuery(c, "from", time.Time{})
+	if !ok {
+		return
+	}
+	to, ok := dateQuery(c, "to", time.Time{})
+	if !ok {
+		return
+	}
+	if !to.IsZero() {
+		to = to.AddDate(0, 0, 1)
+	}
+
+	filter := repository.AuditLogFilter{
+		Entity:    c.Query("entity"),
+		EntityID:  c.Query("entity_id"),
+		Action:    c.Query("action"),
+		RequestID: c.Query("request_id"),
+		From:      from,
+		To:        to,
+	}
+	for key, target := range map[string]*uint{"user_id": &filter.UserID, "before_id": &filter.BeforeID} {
+		value := c.Query(key)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + key})
+			return
+		}
+		*target = uint(parsed)
+	}
+	filter.Limit, _ = strconv.Atoi(c.Query("limit"))
+
+	entries, err := h.auditUsecase.Query(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+func (h *AuditHandler) VerifyChain(c *gin.Context) {
+	result, err := h.auditUsecase.Verify(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
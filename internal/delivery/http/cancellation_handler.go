@@ -0,0 +1,71 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CancellationHandler struct {
+	cancellationUsecase usecase.CancellationUsecase
+}
+
+func NewCancellationHandler(uc usecase.CancellationUsecase) *CancellationHandler {
+	return &CancellationHandler{cancellationUsecase: uc}
+}
+
+type cancelTransactionRequest struct {
+	ReasonCode string `json:"reason_code" binding:"required"`
+	Note       string `json:"note"`
+}
+
+func (h *CancellationHandler) CancelTransaction(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction id"})
+		return
+	}
+
+	var req cancelTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	cancellation, err := h.cancellationUsecase.Cancel(usecase.CancellationRequest{
+		TransactionID: uint(id),
+		ReasonCode:    req.ReasonCode,
+		Note:          req.Note,
+		CancelledBy:   c.GetUint("user_id"),
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Transaction cancelled", "cancellation": cancellation})
+}
+
+func (h *CancellationHandler) GetCancellation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction id"})
+		return
+	}
+
+	cancellation, err := h.cancellationUsecase.GetCancellation(uint(id), viewerOf(c))
+	if errors.Is(err, usecase.ErrCustomerForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, cancellation)
+}
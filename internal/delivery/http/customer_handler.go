@@ -1,29 +1,50 @@
 package http
 
 import (
+	"context"
+	"errors"
 	"net/http"
-	"os"
 	"strconv"
 	"time"
 
 	"xyz-multifinance/internal/model"
 	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/pkg/imageupload"
 	"xyz-multifinance/storage"
 
 	"github.com/gin-gonic/gin"
 )
 
 type CustomerHandler struct {
-	usecase usecase.CustomerUsecase
+	usecase      usecase.CustomerUsecase
+	store        storage.BlobStore
+	uploadPolicy imageupload.Policy
 }
 
-func NewCustomerHandler(uc usecase.CustomerUsecase) *CustomerHandler {
+func NewCustomerHandler(uc usecase.CustomerUsecase, store storage.BlobStore, uploadPolicy imageupload.Policy) *CustomerHandler {
 	return &CustomerHandler{
-		usecase: uc,
+		usecase:      uc,
+		store:        store,
+		uploadPolicy: uploadPolicy,
 	}
 }
 
+// kycPhotoFields are the multipart fields that carry KYC photos.
+var kycPhotoFields = []string{"ktp_photo", "selfie_photo"}
+
+// kycPhotoTypes maps each photo field to the document type its object key
+// is named after.
+var kycPhotoTypes = map[string]string{
+	"ktp_photo":    model.KYCDocumentKTP,
+	"selfie_photo": model.KYCDocumentSelfie,
+}
+
 func (h *CustomerHandler) CreateCustomer(c *gin.Context) {
+	photos, ok := h.readPhotos(c)
+	if !ok {
+		return
+	}
+
 	userIDStr := c.PostForm("user_id")
 	nik := c.PostForm("nik")
 	fullName := c.PostForm("full_name")
@@ -55,36 +76,10 @@ func (h *CustomerHandler) CreateCustomer(c *gin.Context) {
 		return
 	}
 
-	var ktpPath, selfiePath string
-
-	if ktpFileHeader, err := c.FormFile("ktp_photo"); err == nil {
-		ktpFile, err := ktpFileHeader.Open()
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open ktp_photo"})
-			return
-		}
-		defer ktpFile.Close()
-
-		ktpPath, err = storage.SaveImage(ktpFile, ktpFileHeader, "ktp_"+nik)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-	}
-
-	if selfieFileHeader, err := c.FormFile("selfie_photo"); err == nil {
-		selfieFile, err := selfieFileHeader.Open()
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open selfie_photo"})
-			return
-		}
-		defer selfieFile.Close()
-
-		selfiePath, err = storage.SaveImage(selfieFile, selfieFileHeader, "selfie_"+nik)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
+	keys, err := h.savePhotos(c.Request.Context(), photos)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
 	customer := model.Customer{
@@ -95,17 +90,20 @@ func (h *CustomerHandler) CreateCustomer(c *gin.Context) {
 		PlaceBirth:  placeBirth,
 		DateBirth:   dateBirth,
 		Salary:      salary,
-		KTPPhoto:    ktpPath,
-		SelfiePhoto: selfiePath,
+		KTPPhoto:    keys["ktp_photo"],
+		SelfiePhoto: keys["selfie_photo"],
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 
-	if err := h.usecase.CreateCustomer(&customer); err != nil {
+	if err := h.usecase.CreateCustomer(c.Request.Context(), &customer); err != nil {
+		h.deleteObjects(c.Request.Context(), keys)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	customer.UnmaskPII()
+	setDocumentLinks(&customer)
 	c.JSON(http.StatusCreated, gin.H{
 		"message":  "Customer created",
 		"customer": customer,
@@ -119,6 +117,10 @@ func (h *CustomerHandler) GetCustomerByNIK(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
 		return
 	}
+	if customer.AccessibleBy(c.GetUint("user_id"), c.GetString("role")) {
+		customer.UnmaskPII()
+		setDocumentLinks(customer)
+	}
 	c.JSON(http.StatusOK, customer)
 }
 
@@ -129,6 +131,11 @@ func (h *CustomerHandler) UpdateCustomer(c *gin.Context) {
 		return
 	}
 
+	photos, ok := h.readPhotos(c)
+	if !ok {
+		return
+	}
+
 	oldCustomer, err := h.usecase.GetCustomerByNIK(nik)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Customer not found"})
@@ -169,55 +176,155 @@ func (h *CustomerHandler) UpdateCustomer(c *gin.Context) {
 		updatedFields["salary"] = salary
 	}
 
-	if ktpFileHeader, err := c.FormFile("ktp_photo"); err == nil {
-		ktpFile, _ := ktpFileHeader.Open()
-		defer ktpFile.Close()
-
-		if oldCustomer.KTPPhoto != "" {
-			_ = os.Remove(oldCustomer.KTPPhoto)
-		}
-
-		ktpPath, err := storage.SaveImage(ktpFile, ktpFileHeader, "ktp_"+nik)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		updatedFields["photo_ktp"] = ktpPath
+	// Write-ahead: the new photos are stored first and the old ones are only
+	// deleted once the row points at their replacements, so a failure at any
+	// step never leaves the customer referencing a missing object.
+	keys, err := h.savePhotos(c.Request.Context(), photos)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
-
-	if selfieFileHeader, err := c.FormFile("selfie_photo"); err == nil {
-		selfieFile, _ := selfieFileHeader.Open()
-		defer selfieFile.Close()
-
-		if oldCustomer.SelfiePhoto != "" {
-			_ = os.Remove(oldCustomer.SelfiePhoto)
-		}
-
-		selfiePath, err := storage.SaveImage(selfieFile, selfieFileHeader, "selfie_"+nik)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-		updatedFields["photo_selfie"] = selfiePath
+	if key, ok := keys["ktp_photo"]; ok {
+		updatedFields["photo_ktp"] = key
+	}
+	if key, ok := keys["selfie_photo"]; ok {
+		updatedFields["photo_selfie"] = key
 	}
 
 	updatedFields["updated_at"] = time.Now()
 
-	err = h.usecase.UpdateCustomer(nik, updatedFields)
+	err = h.usecase.UpdateCustomer(c.Request.Context(), nik, updatedFields)
 	if err != nil {
+		h.deleteObjects(c.Request.Context(), keys)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	replaced := make(map[string]string)
+	if _, ok := keys["ktp_photo"]; ok && oldCustomer.KTPPhoto != "" {
+		replaced["ktp_photo"] = oldCustomer.KTPPhoto
+	}
+	if _, ok := keys["selfie_photo"]; ok && oldCustomer.SelfiePhoto != "" {
+		replaced["selfie_photo"] = oldCustomer.SelfiePhoto
+	}
+	h.deleteObjects(c.Request.Context(), replaced)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Customer updated"})
 }
 
 func (h *CustomerHandler) DeleteCustomer(c *gin.Context) {
 	nik := c.Param("nik")
-	err := h.usecase.DeleteCustomer(nik)
+	err := h.usecase.DeleteCustomer(c.Request.Context(), nik)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Customer deleted"})
 }
+
+// viewerOf is the authenticated user of the request.
+func viewerOf(c *gin.Context) usecase.Viewer {
+	return usecase.Viewer{UserID: c.GetUint("user_id"), Role: c.GetString("role")}
+}
+
+// setDocumentLinks points the customer's Documents at the authenticated
+// document route for each photo on file.
+func setDocumentLinks(customer *model.Customer) {
+	customer.Documents = map[string]string{}
+	if customer.KTPPhoto != "" {
+		customer.Documents[model.KYCDocumentKTP] = "/api/v1/customers/" + customer.NIK + "/documents/" + model.KYCDocumentKTP
+	}
+	if customer.SelfiePhoto != "" {
+		customer.Documents[model.KYCDocumentSelfie] = "/api/v1/customers/" + customer.NIK + "/documents/" + model.KYCDocumentSelfie
+	}
+}
+
+// savePhotos stores the processed photos in a new KYC directory and returns
+// their object keys by form field. If one of them cannot be stored, the ones
+// already written are removed again.
+func (h *CustomerHandler) savePhotos(ctx context.Context, photos map[string]*imageupload.Image) (map[string]string, error) {
+	keys := make(map[string]string)
+	dir := storage.NewKYCDir()
+	for _, field := range kycPhotoFields {
+		img := photos[field]
+		if img == nil {
+			continue
+		}
+		key, err := storage.SaveImage(ctx, h.store, storage.KYCKeyPrefix(dir, kycPhotoTypes[field]), img)
+		if err != nil {
+			h.deleteObjects(ctx, keys)
+			return nil, err
+		}
+		keys[field] = key
+	}
+	return keys, nil
+}
+
+// deleteObjects removes objects on a best-effort basis, even when the
+// request has been cancelled. Anything left behind is found by the gc
+// command.
+func (h *CustomerHandler) deleteObjects(ctx context.Context, keys map[string]string) {
+	ctx = context.WithoutCancel(ctx)
+	for _, key := range keys {
+		_ = h.store.Delete(ctx, key)
+	}
+}
+
+// readPhotos caps the request body, then validates and re-encodes every KYC
+// photo in the form before anything is stored. When it returns false the
+// error response has already been written.
+func (h *CustomerHandler) readPhotos(c *gin.Context) (map[string]*imageupload.Image, bool) {
+	if h.uploadPolicy.MaxBytes > 0 {
+		// Room for every photo at the maximum size plus the text fields.
+		limit := int64(len(kycPhotoFields))*h.uploadPolicy.MaxBytes + 1<<20
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+	}
+	if err := c.Request.ParseMultipartForm(32 << 20); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": "Request body is too large",
+				"code":  imageupload.CodeFileTooLarge,
+			})
+			return nil, false
+		}
+	}
+
+	photos := make(map[string]*imageupload.Image)
+	for _, field := range kycPhotoFields {
+		header, err := c.FormFile(field)
+		if err != nil {
+			continue
+		}
+		img, err := imageupload.ProcessFile(header, h.uploadPolicy)
+		if err != nil {
+			uploadError(c, field, err)
+			return nil, false
+		}
+		photos[field] = img
+	}
+	return photos, true
+}
+
+// uploadError reports a rejected upload with the offending field and a
+// machine-readable code.
+func uploadError(c *gin.Context, field string, err error) {
+	var uploadErr *imageupload.Error
+	if !errors.As(err, &uploadErr) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusUnprocessableEntity
+	switch uploadErr.Code {
+	case imageupload.CodeFileTooLarge:
+		status = http.StatusRequestEntityTooLarge
+	case imageupload.CodeUnsupportedType:
+		status = http.StatusUnsupportedMediaType
+	}
+	c.JSON(status, gin.H{
+		"error": field + ": " + uploadErr.Message,
+		"code":  uploadErr.Code,
+		"field": field,
+	})
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/payout"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DisbursementHandler struct {
+	disbursementUsecase usecase.DisbursementUsecase
+}
+
+func NewDisbursementHandler(uc usecase.DisbursementUsecase) *DisbursementHandler {
+	return &DisbursementHandler{disbursementUsecase: uc}
+}
+
+func (h *DisbursementHandler) GetDisbursement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction id"})
+		return
+	}
+
+	disbursement, err := h.disbursementUsecase.GetDisbursementByTransaction(uint(id), viewerOf(c))
+	if errors.Is(err, usecase.ErrCustomerForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, disbursement)
+}
+
+func (h *DisbursementHandler) UpdateDisbursement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid disbursement id"})
+		return
+	}
+
+	var updateData map[string]interface{}
+	if err := c.ShouldBindJSON(&updateData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	allowedFields := map[string]bool{"bank_code": true, "bank_account_number": true, "bank_account_name": true}
+	for key := range updateData {
+		if !allowedFields[key] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid field: " + key})
+			return
+		}
+	}
+
+	if err := h.disbursementUsecase.UpdateDisbursement(uint(id), updateData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Disbursement updated"})
+}
+
+func (h *DisbursementHandler) RetryDisbursement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid disbursement id"})
+		return
+	}
+
+	if err := h.disbursementUsecase.RetryDisbursement(uint(id)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Disbursement queued for retry"})
+}
+
+type completeDisbursementRequest struct {
+	ProviderReference string `json:"provider_reference" binding:"required"`
+	PaidAt            string `json:"paid_at"`
+}
+
+// CompleteDisbursement records a payout that an admin confirmed with the bank
+// outside the payout provider, such as one stuck in processing.
+func (h *DisbursementHandler) CompleteDisbursement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction id"})
+		return
+	}
+
+	var req completeDisbursementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	paidAt := time.Now()
+	if req.PaidAt != "" {
+		parsed, err := time.Parse(time.RFC3339, req.PaidAt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paid_at format. Use RFC3339"})
+			return
+		}
+		paidAt = parsed
+	}
+
+	disbursement, err := h.disbursementUsecase.CompleteManually(c.Request.Context(), uint(id), usecase.DisbursementProviderManual, req.ProviderReference, paidAt)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, disbursement)
+}
+
+func (h *DisbursementHandler) ProcessDisbursements(c *gin.Context) {
+	result, err := h.disbursementUsecase.ProcessDue(c.Request.Context(), time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// PayoutCallback receives the outcome of a payout from the provider. It is
+// authenticated by the provider, not by a user token.
+func (h *DisbursementHandler) PayoutCallback(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	disbursement, err := h.disbursementUsecase.HandleCallback(c.Request.Context(), c.Request.Header, body)
+	if errors.Is(err, payout.ErrInvalidCallback) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reference": disbursement.Reference, "status": disbursement.Status})
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DownPaymentRuleHandler struct {
+	ruleUsecase usecase.DownPaymentRuleUsecase
+}
+
+func NewDownPaymentRuleHandler(uc usecase.DownPaymentRuleUsecase) *DownPaymentRuleHandler {
+	return &DownPaymentRuleHandler{ruleUsecase: uc}
+}
+
+// MinPercent is a pointer so that a 0% rule passes the required check.
+type downPaymentRuleRequest struct {
+	Category   string `json:"category"`
+	Tenor      int    `json:"tenor_month"`
+	MinPercent *int   `json:"min_percent" binding:"required,min=0,max=99"`
+}
+
+func (h *DownPaymentRuleHandler) CreateRule(c *gin.Context) {
+	var req downPaymentRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	rule := model.DownPaymentRule{
+		Category:   req.Category,
+		Tenor:      req.Tenor,
+		MinPercent: *req.MinPercent,
+	}
+
+	if err := h.ruleUsecase.CreateRule(&rule); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Down payment rule created", "rule": rule})
+}
+
+func (h *DownPaymentRuleHandler) GetRules(c *gin.Context) {
+	rules, err := h.ruleUsecase.GetRules()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get down payment rules"})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+func (h *DownPaymentRuleHandler) DeleteRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule id"})
+		return
+	}
+
+	if err := h.ruleUsecase.DeleteRule(uint(id)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Down payment rule deleted"})
+}
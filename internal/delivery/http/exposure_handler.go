@@ -0,0 +1,38 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExposureHandler struct {
+	exposureUsecase usecase.ExposureUsecase
+}
+
+func NewExposureHandler(uc usecase.ExposureUsecase) *ExposureHandler {
+	return &ExposureHandler{exposureUsecase: uc}
+}
+
+func (h *ExposureHandler) GetCustomerExposure(c *gin.Context) {
+	nik := c.Param("nik")
+	if nik == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "NIK is required"})
+		return
+	}
+
+	exposure, err := h.exposureUsecase.GetExposureByNIK(nik, viewerOf(c))
+	if errors.Is(err, usecase.ErrCustomerForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, exposure)
+}
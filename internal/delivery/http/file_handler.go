@@ -0,0 +1,44 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"xyz-multifinance/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FileHandler serves blob store objects to holders of a signed URL issued
+// by the application. Plain S3-compatible stores serve their signed URLs
+// themselves.
+type FileHandler struct {
+	store storage.ServedStore
+}
+
+func NewFileHandler(store storage.ServedStore) *FileHandler {
+	return &FileHandler{store: store}
+}
+
+func (h *FileHandler) Download(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	if err := h.store.Verify(key, c.Request.URL.Query()); err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Invalid or expired link"})
+		return
+	}
+
+	body, info, err := h.store.Get(c.Request.Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "File not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer body.Close()
+
+	c.Header("Cache-Control", "private, no-store")
+	c.DataFromReader(http.StatusOK, info.Size, info.ContentType, body, nil)
+}
@@ -0,0 +1,117 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type KYCDocumentHandler struct {
+	kycDocumentUsecase usecase.KYCDocumentUsecase
+}
+
+func NewKYCDocumentHandler(uc usecase.KYCDocumentUsecase) *KYCDocumentHandler {
+	return &KYCDocumentHandler{kycDocumentUsecase: uc}
+}
+
+func (h *KYCDocumentHandler) GetDocument(c *gin.Context) {
+	doc, err := h.kycDocumentUsecase.GetDocument(c.Request.Context(), c.Param("nik"), c.Param("type"), c.Query("variant"), documentViewer(c))
+	if err != nil {
+		documentError(c, err)
+		return
+	}
+	streamDocument(c, doc)
+}
+
+type signedDocumentURLRequest struct {
+	Variant   string `json:"variant"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
+func (h *KYCDocumentHandler) IssueSignedURL(c *gin.Context) {
+	var req signedDocumentURLRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	signed, err := h.kycDocumentUsecase.IssueSignedURL(
+		c.Param("nik"), c.Param("type"), req.Variant,
+		time.Duration(req.ExpiresIn)*time.Second, documentViewer(c),
+	)
+	if err != nil {
+		documentError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, signed)
+}
+
+// OpenSignedDocument serves a document to anyone holding a valid signed
+// link; the link itself is the credential.
+func (h *KYCDocumentHandler) OpenSignedDocument(c *gin.Context) {
+	customerID, err := strconv.ParseUint(c.Param("customer_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": usecase.ErrInvalidDocumentLink.Error()})
+		return
+	}
+
+	viewer := usecase.DocumentViewer{IPAddress: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+	doc, err := h.kycDocumentUsecase.OpenSignedDocument(c.Request.Context(), uint(customerID), c.Param("type"), c.Request.URL.Query(), viewer)
+	if err != nil {
+		documentError(c, err)
+		return
+	}
+	streamDocument(c, doc)
+}
+
+func (h *KYCDocumentHandler) GetAccessLog(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	accesses, err := h.kycDocumentUsecase.GetAccessLog(c.Param("nik"), limit)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, accesses)
+}
+
+func documentViewer(c *gin.Context) usecase.DocumentViewer {
+	return usecase.DocumentViewer{
+		UserID:    c.GetUint("user_id"),
+		Role:      c.GetString("role"),
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
+
+func documentError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrInvalidDocumentRequest):
+		status = http.StatusBadRequest
+	case errors.Is(err, usecase.ErrDocumentNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, usecase.ErrDocumentForbidden), errors.Is(err, usecase.ErrInvalidDocumentLink):
+		status = http.StatusForbidden
+	case errors.Is(err, usecase.ErrThumbnailUnavailable):
+		status = http.StatusUnprocessableEntity
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+func streamDocument(c *gin.Context, doc *usecase.KYCDocument) {
+	defer doc.Body.Close()
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.DataFromReader(http.StatusOK, doc.Size, doc.ContentType, doc.Body, map[string]string{
+		"Content-Disposition": fmt.Sprintf("inline; filename=%q", doc.Filename),
+	})
+}
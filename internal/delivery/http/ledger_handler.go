@@ -0,0 +1,113 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LedgerHandler struct {
+	ledgerUsecase   usecase.LedgerUsecase
+	writeOffUsecase usecase.WriteOffUsecase
+}
+
+func NewLedgerHandler(ledgerUC usecase.LedgerUsecase, writeOffUC usecase.WriteOffUsecase) *LedgerHandler {
+	return &LedgerHandler{ledgerUsecase: ledgerUC, writeOffUsecase: writeOffUC}
+}
+
+type writeOffRequest struct {
+	Note string `json:"note"`
+}
+
+func (h *LedgerHandler) GetAccounts(c *gin.Context) {
+	accounts, err := h.ledgerUsecase.GetAccounts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get accounts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+func (h *LedgerHandler) GetTrialBalance(c *gin.Context) {
+	asOf, ok := dateQuery(c, "as_of", time.Now())
+	if !ok {
+		return
+	}
+
+	tb, err := h.ledgerUsecase.GetTrialBalance(asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tb)
+}
+
+func (h *LedgerHandler) GetAccountStatement(c *gin.Context) {
+	now := time.Now()
+	from, ok := dateQuery(c, "from", time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local))
+	if !ok {
+		return
+	}
+	to, ok := dateQuery(c, "to", now)
+	if !ok {
+		return
+	}
+
+	statement, err := h.ledgerUsecase.GetAccountStatement(c.Param("code"), from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, statement)
+}
+
+func (h *LedgerHandler) WriteOffTransaction(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction id"})
+		return
+	}
+
+	var req writeOffRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+			return
+		}
+	}
+
+	entry, err := h.writeOffUsecase.WriteOff(usecase.WriteOffRequest{
+		TransactionID: uint(id),
+		Note:          req.Note,
+		WrittenOffBy:  c.GetUint("user_id"),
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Transaction written off", "journal_entry": entry})
+}
+
+// dateQuery reads an optional YYYY-MM-DD query parameter. It writes a 400
+// response and returns false when the value is malformed.
+func dateQuery(c *gin.Context, key string, fallback time.Time) (time.Time, bool) {
+	value := c.Query(key)
+	if value == "" {
+		return fallback, true
+	}
+
+	parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + key + " format. Use YYYY-MM-DD"})
+		return time.Time{}, false
+	}
+	return parsed, true
+}
@@ -25,7 +25,7 @@ func (h *LimitHandler) CreateLimit(c *gin.Context) {
 		return
 	}
 
-	if err := h.limitUsecase.CreateLimit(&limit); err != nil {
+	if err := h.limitUsecase.CreateLimit(c.Request.Context(), &limit); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -41,7 +41,7 @@ func (h *LimitHandler) DeleteLimit(c *gin.Context) {
 		return
 	}
 
-	if err := h.limitUsecase.DeleteLimit(uint(id)); err != nil {
+	if err := h.limitUsecase.DeleteLimit(c.Request.Context(), uint(id)); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -103,21 +103,8 @@ func (h *LimitHandler) GetLimitByCustomerAndTenor(c *gin.Context) {
 		return
 	}
 
-	limits, err := h.limitUsecase.GetLimitsByCustomer(uint(customerID))
+	limitFound, err := h.limitUsecase.GetLimitByCustomerAndTenor(uint(customerID), tenor)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Limit not found"})
-		return
-	}
-
-	var limitFound *usecase.LimitWithRemaining
-	for i, l := range limits {
-		if l.Tenor == tenor {
-			limitFound = &limits[i]
-			break
-		}
-	}
-
-	if limitFound == nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Limit for tenor not found"})
 		return
 	}
@@ -147,7 +134,7 @@ func (h *LimitHandler) UpdateLimit(c *gin.Context) {
 		}
 	}
 
-	err = h.limitUsecase.UpdateLimit(uint(id), updateData)
+	err = h.limitUsecase.UpdateLimit(c.Request.Context(), uint(id), updateData)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,53 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OverdueHandler struct {
+	overdueUsecase usecase.OverdueUsecase
+}
+
+func NewOverdueHandler(uc usecase.OverdueUsecase) *OverdueHandler {
+	return &OverdueHandler{overdueUsecase: uc}
+}
+
+func (h *OverdueHandler) GetCustomerDelinquency(c *gin.Context) {
+	delinquency, err := h.overdueUsecase.GetCustomerDelinquency(c.Param("nik"), viewerOf(c))
+	if errors.Is(err, usecase.ErrCustomerForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, delinquency)
+}
+
+func (h *OverdueHandler) Recalculate(c *gin.Context) {
+	asOf := time.Now()
+	if asOfStr := c.Query("as_of"); asOfStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", asOfStr, time.Local)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid as_of format. Use YYYY-MM-DD"})
+			return
+		}
+		asOf = parsed
+	}
+
+	result, err := h.overdueUsecase.Recalculate(asOf)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,176 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PartnerHandler struct {
+	partnerUsecase usecase.PartnerUsecase
+}
+
+func NewPartnerHandler(uc usecase.PartnerUsecase) *PartnerHandler {
+	return &PartnerHandler{partnerUsecase: uc}
+}
+
+type createPartnerRequest struct {
+	Code            string `json:"code" binding:"required"`
+	Name            string `json:"name" binding:"required"`
+	AdminFee        int64  `json:"admin_fee"`
+	InterestRateBps int    `json:"interest_rate_bps"`
+}
+
+type createOutletRequest struct {
+	Code    string `json:"code" binding:"required"`
+	Name    string `json:"name" binding:"required"`
+	Address string `json:"address"`
+	City    string `json:"city"`
+}
+
+func (h *PartnerHandler) CreatePartner(c *gin.Context) {
+	var req createPartnerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	partner := model.Partner{
+		Code:            req.Code,
+		Name:            req.Name,
+		AdminFee:        req.AdminFee,
+		InterestRateBps: req.InterestRateBps,
+	}
+
+	apiKey, err := h.partnerUsecase.CreatePartner(&partner)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Partner created",
+		"partner": partner,
+		"api_key": apiKey,
+	})
+}
+
+func (h *PartnerHandler) GetPartners(c *gin.Context) {
+	partners, err := h.partnerUsecase.GetAllPartners()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get partners"})
+		return
+	}
+
+	c.JSON(http.StatusOK, partners)
+}
+
+func (h *PartnerHandler) GetPartnerByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid partner id"})
+		return
+	}
+
+	partner, err := h.partnerUsecase.GetPartnerByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Partner not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, partner)
+}
+
+func (h *PartnerHandler) UpdatePartner(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid partner id"})
+		return
+	}
+
+	var updateData map[string]interface{}
+	if err := c.ShouldBindJSON(&updateData); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	allowedFields := map[string]bool{"name": true, "admin_fee": true, "interest_rate_bps": true, "active": true}
+	for key := range updateData {
+		if !allowedFields[key] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid field: " + key})
+			return
+		}
+	}
+
+	if err := h.partnerUsecase.UpdatePartner(uint(id), updateData); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Partner updated"})
+}
+
+func (h *PartnerHandler) RotateAPIKey(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid partner id"})
+		return
+	}
+
+	apiKey, err := h.partnerUsecase.RotateAPIKey(uint(id))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_key": apiKey})
+}
+
+func (h *PartnerHandler) CreateOutlet(c *gin.Context) {
+	partnerID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid partner id"})
+		return
+	}
+
+	var req createOutletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	outlet := model.Outlet{
+		PartnerID: uint(partnerID),
+		Code:      req.Code,
+		Name:      req.Name,
+		Address:   req.Address,
+		City:      req.City,
+	}
+
+	if err := h.partnerUsecase.CreateOutlet(&outlet); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Outlet created", "outlet": outlet})
+}
+
+func (h *PartnerHandler) GetOutlets(c *gin.Context) {
+	partnerID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid partner id"})
+		return
+	}
+
+	outlets, err := h.partnerUsecase.GetOutletsByPartner(uint(partnerID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get outlets"})
+		return
+	}
+
+	c.JSON(http.StatusOK, outlets)
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentHandler struct {
+	paymentUsecase usecase.PaymentUsecase
+}
+
+func NewPaymentHandler(uc usecase.PaymentUsecase) *PaymentHandler {
+	return &PaymentHandler{paymentUsecase: uc}
+}
+
+type createPaymentRequest struct {
+	TransactionID uint   `json:"transaction_id" binding:"required"`
+	Amount        int64  `json:"amount" binding:"required"`
+	Channel       string `json:"channel"`
+	Reference     string `json:"reference"`
+	PaidAt        string `json:"paid_at"`
+}
+
+func (h *PaymentHandler) CreatePayment(c *gin.Context) {
+	var req createPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	var paidAt time.Time
+	if req.PaidAt != "" {
+		parsed, err := time.Parse(time.RFC3339, req.PaidAt)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid paid_at format. Use RFC3339"})
+			return
+		}
+		paidAt = parsed
+	}
+
+	payment, created, err := h.paymentUsecase.Pay(usecase.PaymentRequest{
+		TransactionID: req.TransactionID,
+		Amount:        req.Amount,
+		Channel:       req.Channel,
+		Reference:     req.Reference,
+		PaidAt:        paidAt,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !created {
+		c.JSON(http.StatusOK, gin.H{"message": "Payment already posted", "payment": payment})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Payment posted", "payment": payment})
+}
+
+func (h *PaymentHandler) GetPaymentsByTransaction(c *gin.Context) {
+	transactionID, err := strconv.ParseUint(c.Param("transaction_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction_id"})
+		return
+	}
+
+	payments, err := h.paymentUsecase.GetPaymentsByTransaction(uint(transactionID), viewerOf(c))
+	if errors.Is(err, usecase.ErrCustomerForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, payments)
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PrivacyHandler struct {
+	privacyUsecase usecase.PrivacyUsecase
+}
+
+func NewPrivacyHandler(uc usecase.PrivacyUsecase) *PrivacyHandler {
+	return &PrivacyHandler{privacyUsecase: uc}
+}
+
+type privacyRequestInput struct {
+	Type   string `json:"type" binding:"required"`
+	Reason string `json:"reason"`
+}
+
+func (h *PrivacyHandler) CreateRequest(c *gin.Context) {
+	var input privacyRequestInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var (
+		request *model.PrivacyRequest
+		err     error
+	)
+	switch input.Type {
+	case model.PrivacyRequestExport:
+		request, err = h.privacyUsecase.RequestExport(c.Param("nik"), c.GetUint("user_id"), input.Reason)
+	case model.PrivacyRequestErasure:
+		request, err = h.privacyUsecase.RequestErasure(c.Param("nik"), c.GetUint("user_id"), input.Reason)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "type must be export or erasure"})
+		return
+	}
+	if errors.Is(err, usecase.ErrOpenContracts) || errors.Is(err, usecase.ErrCustomerAnonymized) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, request)
+}
+
+func (h *PrivacyHandler) GetRequestsByCustomer(c *gin.Context) {
+	requests, err := h.privacyUsecase.GetRequestsByCustomer(c.Param("nik"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+func (h *PrivacyHandler) GetRequest(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid privacy request id"})
+		return
+	}
+
+	request, err := h.privacyUsecase.GetRequest(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, request)
+}
+
+func (h *PrivacyHandler) DownloadExport(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid privacy request id"})
+		return
+	}
+
+	export, err := h.privacyUsecase.DownloadExport(c.Request.Context(), uint(id))
+	if errors.Is(err, usecase.ErrExportUnavailable) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	defer export.Body.Close()
+
+	c.Header("Cache-Control", "private, no-store")
+	c.DataFromReader(http.StatusOK, export.Size, "application/zip", export.Body, map[string]string{
+		"Content-Disposition": fmt.Sprintf("attachment; filename=%q", export.Filename),
+	})
+}
+
+func (h *PrivacyHandler) ProcessRequests(c *gin.Context) {
+	result, err := h.privacyUsecase.ProcessPending(c.Request.Context(), time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,130 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReconciliationHandler struct {
+	reconciliationUsecase usecase.ReconciliationUsecase
+}
+
+func NewReconciliationHandler(uc usecase.ReconciliationUsecase) *ReconciliationHandler {
+	return &ReconciliationHandler{reconciliationUsecase: uc}
+}
+
+func (h *ReconciliationHandler) ImportStatement(c *gin.Context) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Statement file is required"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open file"})
+		return
+	}
+	defer file.Close()
+
+	report, err := h.reconciliationUsecase.Import(fileHeader.Filename, c.PostForm("format"), file, c.GetUint("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, report)
+}
+
+func (h *ReconciliationHandler) GetImports(c *gin.Context) {
+	imports, err := h.reconciliationUsecase.GetImports()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get imports"})
+		return
+	}
+
+	c.JSON(http.StatusOK, imports)
+}
+
+func (h *ReconciliationHandler) GetReport(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid import id"})
+		return
+	}
+
+	report, err := h.reconciliationUsecase.GetReport(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *ReconciliationHandler) GetQueue(c *gin.Context) {
+	lines, err := h.reconciliationUsecase.GetQueue()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get queue"})
+		return
+	}
+
+	c.JSON(http.StatusOK, lines)
+}
+
+type resolveLineRequest struct {
+	TransactionID uint   `json:"transaction_id" binding:"required"`
+	Note          string `json:"note"`
+}
+
+func (h *ReconciliationHandler) ResolveLine(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid line id"})
+		return
+	}
+
+	var req resolveLineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	line, err := h.reconciliationUsecase.ResolveLine(uint(id), req.TransactionID, c.GetUint("user_id"), req.Note)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Line resolved", "line": line})
+}
+
+type ignoreLineRequest struct {
+	Note string `json:"note" binding:"required"`
+}
+
+func (h *ReconciliationHandler) IgnoreLine(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid line id"})
+		return
+	}
+
+	var req ignoreLineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	line, err := h.reconciliationUsecase.IgnoreLine(uint(id), c.GetUint("user_id"), req.Note)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Line ignored", "line": line})
+}
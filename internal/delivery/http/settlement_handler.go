@@ -0,0 +1,72 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SettlementHandler struct {
+	settlementUsecase usecase.SettlementUsecase
+}
+
+func NewSettlementHandler(uc usecase.SettlementUsecase) *SettlementHandler {
+	return &SettlementHandler{settlementUsecase: uc}
+}
+
+type settleRequest struct {
+	QuoteID   uint   `json:"quote_id" binding:"required"`
+	Channel   string `json:"channel"`
+	Reference string `json:"reference"`
+}
+
+func (h *SettlementHandler) GetSettlementQuote(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction id"})
+		return
+	}
+
+	quote, err := h.settlementUsecase.Quote(uint(id), viewerOf(c))
+	if errors.Is(err, usecase.ErrCustomerForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+func (h *SettlementHandler) Settle(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction id"})
+		return
+	}
+
+	var req settleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	quote, payment, err := h.settlementUsecase.Settle(usecase.SettlementRequest{
+		TransactionID: uint(id),
+		QuoteID:       req.QuoteID,
+		Channel:       req.Channel,
+		Reference:     req.Reference,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Transaction settled", "quote": quote, "payment": payment})
+}
@@ -0,0 +1,41 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StatementHandler struct {
+	statementUsecase usecase.StatementUsecase
+}
+
+func NewStatementHandler(uc usecase.StatementUsecase) *StatementHandler {
+	return &StatementHandler{statementUsecase: uc}
+}
+
+func (h *StatementHandler) GetStatement(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction id"})
+		return
+	}
+
+	viewer := usecase.StatementViewer{UserID: c.GetUint("user_id"), Role: c.GetString("role")}
+	statement, err := h.statementUsecase.RenderStatement(uint(id), c.DefaultQuery("format", usecase.StatementFormatPDF), viewer)
+	if errors.Is(err, usecase.ErrStatementForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+statement.Filename+`"`)
+	c.Data(http.StatusOK, statement.ContentType, statement.Data)
+}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -25,7 +26,7 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 		return
 	}
 
-	err := h.transactionUsecase.CreateTransaction(&tx)
+	err := h.transactionUsecase.CreateTransaction(c.Request.Context(), &tx)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -35,20 +36,35 @@ func (h *TransactionHandler) CreateTransaction(c *gin.Context) {
 }
 
 func (h *TransactionHandler) GetTransactionsByCustomer(c *gin.Context) {
-	customerIDStr := c.Param("customer_id")
-	customerID, err := strconv.ParseUint(customerIDStr, 10, 64)
+	nik := c.Param("nik")
+
+	txs, err := h.transactionUsecase.GetTransactionsByNIK(nik, viewerOf(c))
+	if errors.Is(err, usecase.ErrCustomerForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid customer_id"})
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
 
-	txs, err := h.transactionUsecase.GetTransactionsByCustomer(uint(customerID))
+	c.JSON(http.StatusOK, txs)
+}
+
+func (h *TransactionHandler) GetTransactionByID(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Transactions not found"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction id"})
 		return
 	}
 
-	c.JSON(http.StatusOK, txs)
+	tx, err := h.transactionUsecase.GetTransactionByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tx)
 }
 
 func (h *TransactionHandler) UpdateTransaction(c *gin.Context) {
@@ -65,7 +81,7 @@ func (h *TransactionHandler) UpdateTransaction(c *gin.Context) {
 		return
 	}
 
-	err = h.transactionUsecase.UpdateTransaction(uint(id), &updatedTx)
+	err = h.transactionUsecase.UpdateTransaction(c.Request.Context(), uint(id), &updatedTx)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -74,19 +90,50 @@ func (h *TransactionHandler) UpdateTransaction(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Transaction updated"})
 }
 
-func (h *TransactionHandler) DeleteTransaction(c *gin.Context) {
-	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 64)
+func (h *TransactionHandler) CreatePartnerTransaction(c *gin.Context) {
+	partnerID := c.GetUint("partner_id")
+
+	var tx model.Transaction
+	if err := c.ShouldBindJSON(&tx); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	err := h.transactionUsecase.CreatePartnerTransaction(c.Request.Context(), partnerID, &tx)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Transaction created", "transaction": tx})
+}
+
+func (h *TransactionHandler) GetPartnerTransactions(c *gin.Context) {
+	partnerID := c.GetUint("partner_id")
+
+	txs, err := h.transactionUsecase.GetTransactionsByPartner(partnerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get transactions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, txs)
+}
+
+func (h *TransactionHandler) GetPartnerTransactionByID(c *gin.Context) {
+	partnerID := c.GetUint("partner_id")
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction id"})
 		return
 	}
 
-	err = h.transactionUsecase.DeleteTransaction(uint(id))
+	tx, err := h.transactionUsecase.GetPartnerTransactionByID(partnerID, uint(id))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusNotFound, gin.H{"error": "Transaction not found"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Transaction deleted"})
+	c.JSON(http.StatusOK, tx)
 }
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"xyz-multifinance/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VirtualAccountHandler struct {
+	vaUsecase usecase.VirtualAccountUsecase
+}
+
+func NewVirtualAccountHandler(uc usecase.VirtualAccountUsecase) *VirtualAccountHandler {
+	return &VirtualAccountHandler{vaUsecase: uc}
+}
+
+func (h *VirtualAccountHandler) CreateCustomerAccount(c *gin.Context) {
+	account, err := h.vaUsecase.CreateCustomerAccount(c.Param("nik"), viewerOf(c))
+	if errors.Is(err, usecase.ErrCustomerForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+func (h *VirtualAccountHandler) CreateContractAccount(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid transaction id"})
+		return
+	}
+
+	account, err := h.vaUsecase.CreateContractAccount(uint(id), viewerOf(c))
+	if errors.Is(err, usecase.ErrCustomerForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+func (h *VirtualAccountHandler) GetCustomerAccounts(c *gin.Context) {
+	accounts, err := h.vaUsecase.GetCustomerAccounts(c.Param("nik"), viewerOf(c))
+	if errors.Is(err, usecase.ErrCustomerForbidden) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, accounts)
+}
+
+// maxNotificationBytes caps the body of a payment notification. The route is
+// public, so the body is read before its signature can be checked.
+const maxNotificationBytes = 64 << 10
+
+// PaymentNotification receives a signed payment notification from the bank.
+// It is authenticated by its signature, not by a user token.
+func (h *VirtualAccountHandler) PaymentNotification(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxNotificationBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Request body is too large"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	payment, created, err := h.vaUsecase.HandleNotification(c.Request.Header, body)
+	if errors.Is(err, usecase.ErrInvalidWebhookSignature) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !created {
+		c.JSON(http.StatusOK, gin.H{"message": "Payment already posted", "payment": payment})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Payment posted", "payment": payment})
+}
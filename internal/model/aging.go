@@ -0,0 +1,35 @@
+package model
+
+// Collectibility levels follow the OJK kol 1-5 classification for
+// multifinance receivables.
+const (
+	CollectibilityCurrent     = 1
+	CollectibilitySpecial     = 2
+	CollectibilitySubstandard = 3
+	CollectibilityDoubtful    = 4
+	CollectibilityLoss        = 5
+)
+
+const (
+	AgingBucketCurrent = "current"
+	AgingBucket1To30   = "1-30"
+	AgingBucket31To60  = "31-60"
+	AgingBucket61To90  = "61-90"
+	AgingBucketOver90  = "90+"
+)
+
+// ClassifyDPD maps days past due to its collectibility level and aging bucket.
+func ClassifyDPD(dpd int) (int, string) {
+	switch {
+	case dpd <= 0:
+		return CollectibilityCurrent, AgingBucketCurrent
+	case dpd <= 30:
+		return CollectibilitySpecial, AgingBucket1To30
+	case dpd <= 60:
+		return CollectibilitySubstandard, AgingBucket31To60
+	case dpd <= 90:
+		return CollectibilityDoubtful, AgingBucket61To90
+	default:
+		return CollectibilityLoss, AgingBucketOver90
+	}
+}
@@ -0,0 +1,39 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	AssetCategoryWhiteGoods = "white_goods"
+	AssetCategoryMotorcycle = "motorcycle"
+	AssetCategoryCar        = "car"
+)
+
+func IsValidAssetCategory(category string) bool {
+	switch category {
+	case AssetCategoryWhiteGoods, AssetCategoryMotorcycle, AssetCategoryCar:
+		return true
+	}
+	return false
+}
+
+type Asset struct {
+	ID        uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	Category  string         `gorm:"type:varchar(20);not null;uniqueIndex:uq_assets_catalog" json:"category"`
+	Brand     string         `gorm:"size:100;not null;uniqueIndex:uq_assets_catalog" json:"brand"`
+	Model     string         `gorm:"size:100;not null;uniqueIndex:uq_assets_catalog" json:"model"`
+	Year      int            `gorm:"not null;uniqueIndex:uq_assets_catalog" json:"year"`
+	OTRPrice  int64          `gorm:"column:otr_price;not null" json:"otr_price"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// DisplayName is the label used as a transaction's asset_name.
+func (a Asset) DisplayName() string {
+	return fmt.Sprintf("%s %s %d", a.Brand, a.Model, a.Year)
+}
@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+const (
+	CancellationReasonCustomerRequest = "customer_request"
+	CancellationReasonDuplicate       = "duplicate"
+	CancellationReasonDataError       = "data_error"
+	CancellationReasonFraud           = "fraud"
+)
+
+func IsValidCancellationReason(code string) bool {
+	switch code {
+	case CancellationReasonCustomerRequest, CancellationReasonDuplicate, CancellationReasonDataError, CancellationReasonFraud:
+		return true
+	}
+	return false
+}
+
+// TransactionCancellation is the audit record of a cancelled contract: who
+// cancelled it, why, and how much in payments was reversed.
+type TransactionCancellation struct {
+	ID               uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TransactionID    uint      `gorm:"uniqueIndex;not null" json:"transaction_id"`
+	PreviousStatus   string    `gorm:"type:varchar(50);not null" json:"previous_status"`
+	ReasonCode       string    `gorm:"type:varchar(30);not null" json:"reason_code"`
+	Note             string    `gorm:"type:text" json:"note"`
+	CancelledBy      uint      `gorm:"not null" json:"cancelled_by"`
+	ReversedPayments int       `gorm:"not null;default:0" json:"reversed_payments"`
+	ReversedAmount   int64     `gorm:"not null;default:0" json:"reversed_amount"`
+	CreatedAt        time.Time `json:"created_at"`
+}
@@ -1,23 +1,70 @@
 package model
 
 import (
+	"encoding/json"
 	"time"
 
+	"xyz-multifinance/pkg/redact"
+
 	"gorm.io/gorm"
 )
 
+// Customer personal data is encrypted at rest through the "encrypted"
+// serializer; NIKHash is the blind index used to look customers up by NIK.
+// KTPPhoto and SelfiePhoto hold object keys in the blob store, not paths,
+// and are never serialized; responses carry Documents, the API paths the
+// photos are served from, instead. AnonymizedAt is set once the customer's
+// personal data has been erased.
 type Customer struct {
-	ID          uint           `gorm:"primaryKey;autoIncrement" json:"id"`
-	UserID      uint           `gorm:"column:user_id" json:"user_id"`
-	NIK         string         `gorm:"uniqueIndex;size:16;not null" json:"nik"`
-	FullName    string         `gorm:"not null" json:"full_name"`
-	LegalName   string         `json:"legal_name"`
-	PlaceBirth  string         `gorm:"column:birth_place" json:"place_of_birth"`
-	DateBirth   time.Time      `gorm:"column:birth_date" json:"date_of_birth"`
-	Salary      int64          `json:"salary"`
-	KTPPhoto    string         `gorm:"column:photo_ktp" json:"ktp_photo"`
-	SelfiePhoto string         `gorm:"column:photo_selfie" json:"selfie_photo"`
-	CreatedAt   time.Time      `json:"created_at"`
-	UpdatedAt   time.Time      `json:"updated_at"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+	ID           uint              `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID       uint              `gorm:"column:user_id" json:"user_id"`
+	NIK          string            `gorm:"serializer:encrypted;not null" json:"nik"`
+	NIKHash      string            `gorm:"column:nik_hash;uniqueIndex;size:64" json:"-"`
+	FullName     string            `gorm:"serializer:encrypted;not null" json:"full_name"`
+	LegalName    string            `gorm:"serializer:encrypted" json:"legal_name"`
+	PlaceBirth   string            `gorm:"column:birth_place;serializer:encrypted" json:"place_of_birth"`
+	DateBirth    time.Time         `gorm:"column:birth_date;serializer:encrypted" json:"date_of_birth"`
+	Salary       int64             `gorm:"serializer:encrypted" json:"salary"`
+	KTPPhoto     string            `gorm:"column:photo_ktp" json:"-"`
+	SelfiePhoto  string            `gorm:"column:photo_selfie" json:"-"`
+	Documents    map[string]string `gorm:"-" json:"documents,omitempty"`
+	AnonymizedAt *time.Time        `gorm:"column:anonymized_at" json:"anonymized_at,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+	DeletedAt    gorm.DeletedAt    `gorm:"index" json:"-"`
+
+	unmasked bool
+}
+
+// AccessibleBy reports whether a user may see the customer's personal data
+// and KYC documents: admins and the user the customer record belongs to.
+func (c *Customer) AccessibleBy(userID uint, role string) bool {
+	return role == "admin" || (userID != 0 && c.UserID == userID)
+}
+
+// UnmaskPII makes the customer serialize with its personal data in full.
+// Customers serialize with their NIK masked and their date of birth, salary
+// and document links hidden unless this is called, so callers only unmask
+// once they have checked AccessibleBy or are acting for the customer.
+func (c *Customer) UnmaskPII() {
+	c.unmasked = true
+}
+
+func (c Customer) MarshalJSON() ([]byte, error) {
+	type customerJSON Customer
+	if c.unmasked {
+		return json.Marshal(customerJSON(c))
+	}
+	c.Documents = nil
+	return json.Marshal(struct {
+		customerJSON
+		NIK       string `json:"nik"`
+		DateBirth string `json:"date_of_birth"`
+		Salary    string `json:"salary"`
+	}{
+		customerJSON: customerJSON(c),
+		NIK:          redact.MaskNIK(c.NIK),
+		DateBirth:    "****-**-**",
+		Salary:       "********",
+	})
 }
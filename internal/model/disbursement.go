@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+const (
+	DisbursementStatusPending    = "pending"
+	DisbursementStatusProcessing = "processing"
+	DisbursementStatusSucceeded  = "succeeded"
+	DisbursementStatusFailed     = "failed"
+	DisbursementStatusCancelled  = "cancelled"
+)
+
+// Disbursement is the payout of a contract's principal to the dealer. The
+// contract becomes active only once its disbursement has succeeded.
+type Disbursement struct {
+	ID                uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	TransactionID     uint       `gorm:"uniqueIndex;not null" json:"transaction_id"`
+	Reference         string     `gorm:"uniqueIndex;size:50;not null" json:"reference"`
+	Amount            int64      `gorm:"not null" json:"amount"`
+	Currency          string     `gorm:"type:char(3);not null;default:'IDR'" json:"currency"`
+	BankCode          string     `gorm:"size:20" json:"bank_code"`
+	BankAccountNumber string     `gorm:"size:34" json:"bank_account_number"`
+	BankAccountName   string     `gorm:"size:100" json:"bank_account_name"`
+	Provider          string     `gorm:"size:30" json:"provider"`
+	ProviderReference string     `gorm:"size:100" json:"provider_reference"`
+	Status            string     `gorm:"type:varchar(20);not null" json:"status"`
+	Attempts          int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt     *time.Time `gorm:"index" json:"next_attempt_at"`
+	ClaimedAt         *time.Time `json:"claimed_at"`
+	LastError         string     `gorm:"size:255" json:"last_error"`
+	DisbursedAt       *time.Time `json:"disbursed_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+// HasBeneficiary reports whether the bank account to pay is known.
+func (d Disbursement) HasBeneficiary() bool {
+	return d.BankCode != "" && d.BankAccountNumber != "" && d.BankAccountName != ""
+}
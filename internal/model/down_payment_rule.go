@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// DownPaymentRule sets the minimum down payment, as a percentage of OTR, for
+// an asset category and tenor. An empty category or a zero tenor matches any
+// value; the most specific matching rule wins.
+type DownPaymentRule struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Category   string    `gorm:"type:varchar(20);not null;default:'';uniqueIndex:uq_down_payment_rules" json:"category"`
+	Tenor      int       `gorm:"column:tenor_month;not null;default:0;uniqueIndex:uq_down_payment_rules" json:"tenor_month"`
+	MinPercent int       `gorm:"column:min_percent;not null" json:"min_percent"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
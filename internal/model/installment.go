@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	InstallmentStatusUnpaid  = "unpaid"
+	InstallmentStatusPartial = "partial"
+	InstallmentStatusPaid    = "paid"
+)
+
+type Installment struct {
+	ID            uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	TransactionID uint           `gorm:"index;not null" json:"transaction_id"`
+	Sequence      int            `gorm:"not null" json:"sequence"`
+	DueDate       time.Time      `gorm:"type:date;not null" json:"due_date"`
+	Principal     int64          `gorm:"not null" json:"principal"`
+	Interest      int64          `gorm:"not null" json:"interest"`
+	Amount        int64          `gorm:"not null" json:"amount"`
+	PaidAmount    int64          `gorm:"not null;default:0" json:"paid_amount"`
+	LateFee       int64          `gorm:"not null;default:0" json:"late_fee"`
+	LateFeePaid   int64          `gorm:"not null;default:0" json:"late_fee_paid"`
+	PaidAt        *time.Time     `json:"paid_at"`
+	Status        string         `gorm:"type:varchar(20);not null" json:"status"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// Outstanding is what is still owed on the installment, late fee included.
+func (i Installment) Outstanding() int64 {
+	return i.Amount - i.PaidAmount + i.LateFee - i.LateFeePaid
+}
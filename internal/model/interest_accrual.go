@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+const (
+	AccrualModeDaily   = "daily"
+	AccrualModeMonthly = "monthly"
+)
+
+// InterestAccrual is the interest of a contract recognised as income for one
+// accrual date. There is at most one row per contract and date.
+type InterestAccrual struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TransactionID  uint      `gorm:"uniqueIndex:uq_interest_accruals_tx_date;not null" json:"transaction_id"`
+	AccrualDate    time.Time `gorm:"type:date;uniqueIndex:uq_interest_accruals_tx_date;not null" json:"accrual_date"`
+	Amount         int64     `gorm:"not null" json:"amount"`
+	JournalEntryID uint      `gorm:"not null" json:"journal_entry_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// KYC document types, named after the customer photo they refer to.
+const (
+	KYCDocumentKTP    = "ktp"
+	KYCDocumentSelfie = "selfie"
+)
+
+// Renditions a KYC document can be served in.
+const (
+	KYCVariantOriginal  = "original"
+	KYCVariantThumbnail = "thumbnail"
+)
+
+const (
+	// KYCAccessView records that a document was served.
+	KYCAccessView = "view"
+	// KYCAccessURLIssued records that a signed link to a document was handed
+	// out; opening the link is recorded as a view of its own.
+	KYCAccessURLIssued = "url_issued"
+)
+
+// How a document was reached: an authenticated API call or a signed link.
+const (
+	KYCChannelAPI       = "api"
+	KYCChannelSignedURL = "signed_url"
+)
+
+// KYCDocumentAccess is the audit entry written for every view of a KYC
+// document and every signed link issued for one.
+type KYCDocumentAccess struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	CustomerID   uint      `gorm:"index;not null" json:"customer_id"`
+	DocumentType string    `gorm:"type:varchar(10);not null" json:"document_type"`
+	Variant      string    `gorm:"type:varchar(10);not null" json:"variant"`
+	Action       string    `gorm:"type:varchar(20);not null" json:"action"`
+	Channel      string    `gorm:"type:varchar(20);not null" json:"channel"`
+	UserID       uint      `gorm:"not null" json:"user_id"`
+	IPAddress    string    `gorm:"size:45" json:"ip_address"`
+	UserAgent    string    `gorm:"size:255" json:"user_agent"`
+	CreatedAt    time.Time `json:"created_at"`
+}
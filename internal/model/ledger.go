@@ -0,0 +1,113 @@
+package model
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	AccountTypeAsset     = "asset"
+	AccountTypeLiability = "liability"
+	AccountTypeEquity    = "equity"
+	AccountTypeIncome    = "income"
+	AccountTypeExpense   = "expense"
+)
+
+// Chart of accounts used by the automatic postings.
+const (
+	AccountCash                = "1100"
+	AccountLoanReceivable      = "1200"
+	AccountInterestReceivable  = "1210"
+	AccountLateFeeReceivable   = "1220"
+	AccountUnearnedInterest    = "2100"
+	AccountInterestIncome      = "4100"
+	AccountAdminFeeIncome      = "4200"
+	AccountLateFeeIncome       = "4300"
+	AccountSettlementFeeIncome = "4400"
+	AccountWriteOffExpense     = "5100"
+)
+
+const (
+	JournalTypeDisbursement    = "disbursement"
+	JournalTypeRepayment       = "repayment"
+	JournalTypeLateFee         = "late_fee"
+	JournalTypeInterestAccrual = "interest_accrual"
+	JournalTypeSettlement      = "settlement"
+	JournalTypeWriteOff        = "write_off"
+	JournalTypeCancellation    = "cancellation"
+)
+
+type Account struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code      string    `gorm:"uniqueIndex;size:10;not null" json:"code"`
+	Name      string    `gorm:"size:100;not null" json:"name"`
+	Type      string    `gorm:"type:varchar(20);not null" json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DebitNormal reports whether the account's balance grows with debits.
+func (a Account) DebitNormal() bool {
+	return a.Type == AccountTypeAsset || a.Type == AccountTypeExpense
+}
+
+// Balance turns debit and credit totals into a balance on the account's
+// normal side.
+func (a Account) Balance(debit, credit int64) int64 {
+	if a.DebitNormal() {
+		return debit - credit
+	}
+	return credit - debit
+}
+
+type JournalEntry struct {
+	ID            uint          `gorm:"primaryKey;autoIncrement" json:"id"`
+	EntryDate     time.Time     `gorm:"type:date;not null" json:"entry_date"`
+	Type          string        `gorm:"type:varchar(30);not null" json:"type"`
+	Reference     string        `gorm:"index;size:100;not null" json:"reference"`
+	TransactionID *uint         `gorm:"index" json:"transaction_id"`
+	Description   string        `gorm:"size:255" json:"description"`
+	Lines         []JournalLine `gorm:"foreignKey:JournalEntryID" json:"lines"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+// JournalLine is one side of a posting. Exactly one of Debit and Credit is
+// non-zero.
+type JournalLine struct {
+	ID             uint   `gorm:"primaryKey;autoIncrement" json:"id"`
+	JournalEntryID uint   `gorm:"index;not null" json:"journal_entry_id"`
+	AccountCode    string `gorm:"index;size:10;not null" json:"account_code"`
+	Debit          int64  `gorm:"not null;default:0" json:"debit"`
+	Credit         int64  `gorm:"not null;default:0" json:"credit"`
+}
+
+var ErrUnbalancedEntry = errors.New("journal entry debits and credits do not balance")
+
+// Validate checks the double-entry invariant: at least two lines, each one
+// a positive debit or a positive credit, and total debits equal to total
+// credits.
+func (e *JournalEntry) Validate() error {
+	if len(e.Lines) < 2 {
+		return errors.New("journal entry needs at least two lines")
+	}
+
+	var debit, credit int64
+	for _, line := range e.Lines {
+		if line.Debit < 0 || line.Credit < 0 || (line.Debit == 0) == (line.Credit == 0) {
+			return errors.New("journal line must have either a debit or a credit")
+		}
+		debit += line.Debit
+		credit += line.Credit
+	}
+	if debit != credit {
+		return ErrUnbalancedEntry
+	}
+	return nil
+}
+
+// BeforeCreate refuses to store an entry that breaks the invariant, whoever
+// creates it.
+func (e *JournalEntry) BeforeCreate(tx *gorm.DB) error {
+	return e.Validate()
+}
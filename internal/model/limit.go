@@ -11,6 +11,7 @@ type Limit struct {
 	CustomerID uint           `gorm:"index;not null" json:"customer_id"`
 	Tenor      int            `gorm:"column:tenor_month;not null" json:"tenor_month"`
 	Limit      int64          `gorm:"column:limit_amount;not null" json:"limit_amount"`
+	Currency   string         `gorm:"type:char(3);not null;default:'IDR'" json:"currency"`
 	CreatedAt  time.Time      `json:"created_at"`
 	UpdatedAt  time.Time      `json:"updated_at"`
 	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Partner struct {
+	ID              uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	Code            string         `gorm:"uniqueIndex;size:32;not null" json:"code"`
+	Name            string         `gorm:"not null" json:"name"`
+	APIKeyID        string         `gorm:"column:api_key_id;uniqueIndex;size:32" json:"-"`
+	APIKeyHash      string         `gorm:"column:api_key_hash;size:64" json:"-"`
+	AdminFee        int64          `gorm:"column:admin_fee;not null" json:"admin_fee"`
+	InterestRateBps int            `gorm:"column:interest_rate_bps;not null" json:"interest_rate_bps"`
+	Active          bool           `gorm:"not null;default:true" json:"active"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+type Outlet struct {
+	ID                uint           `gorm:"primaryKey;autoIncrement" json:"id"`
+	PartnerID         uint           `gorm:"index;not null" json:"partner_id"`
+	Code              string         `gorm:"size:32;not null" json:"code"`
+	Name              string         `gorm:"not null" json:"name"`
+	Address           string         `json:"address"`
+	City              string         `json:"city"`
+	BankCode          string         `gorm:"size:20" json:"bank_code"`
+	BankAccountNumber string         `gorm:"size:34" json:"bank_account_number"`
+	BankAccountName   string         `gorm:"size:100" json:"bank_account_name"`
+	Active            bool           `gorm:"not null;default:true" json:"active"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+const (
+	PaymentStatusPosted   = "posted"
+	PaymentStatusReversed = "reversed"
+)
+
+type Payment struct {
+	ID            uint                `gorm:"primaryKey;autoIncrement" json:"id"`
+	TransactionID uint                `gorm:"index;not null" json:"transaction_id"`
+	Amount        int64               `gorm:"not null" json:"amount"`
+	Channel       string              `gorm:"type:varchar(20);not null" json:"channel"`
+	Reference     string              `gorm:"uniqueIndex;size:100;not null" json:"reference"`
+	PaidAt        time.Time           `gorm:"not null" json:"paid_at"`
+	Status        string              `gorm:"type:varchar(20);not null" json:"status"`
+	ReversedAt    *time.Time          `json:"reversed_at"`
+	Allocations   []PaymentAllocation `gorm:"foreignKey:PaymentID" json:"allocations,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+}
+
+// PaymentAllocation records how much of a payment went to each component of
+// an installment, so the payment can be traced or reversed later.
+type PaymentAllocation struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	PaymentID     uint      `gorm:"index;not null" json:"payment_id"`
+	InstallmentID uint      `gorm:"index;not null" json:"installment_id"`
+	LateFee       int64     `gorm:"not null;default:0" json:"late_fee"`
+	Interest      int64     `gorm:"not null;default:0" json:"interest"`
+	Principal     int64     `gorm:"not null;default:0" json:"principal"`
+	CreatedAt     time.Time `json:"created_at"`
+}
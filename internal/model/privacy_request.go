@@ -0,0 +1,37 @@
+package model
+
+import "time"
+
+// Data subject requests under the personal data protection law (UU PDP).
+const (
+	PrivacyRequestExport  = "export"
+	PrivacyRequestErasure = "erasure"
+)
+
+const (
+	PrivacyStatusPending    = "pending"
+	PrivacyStatusProcessing = "processing"
+	PrivacyStatusCompleted  = "completed"
+	PrivacyStatusFailed     = "failed"
+	// PrivacyStatusExpired marks a completed export whose archive has been
+	// deleted after its retention period.
+	PrivacyStatusExpired = "expired"
+)
+
+// PrivacyRequest tracks an admin-triggered export or erasure of a
+// customer's personal data. ResultKey is the blob store key of a finished
+// export archive.
+type PrivacyRequest struct {
+	ID          uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	CustomerID  uint       `gorm:"index;not null" json:"customer_id"`
+	Type        string     `gorm:"type:varchar(10);not null" json:"type"`
+	Status      string     `gorm:"type:varchar(20);index;not null" json:"status"`
+	Reason      string     `gorm:"size:255" json:"reason"`
+	RequestedBy uint       `gorm:"not null" json:"requested_by"`
+	ResultKey   string     `gorm:"size:255" json:"-"`
+	LastError   string     `gorm:"size:255" json:"last_error"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+const (
+	// StatementLineMatched lines were posted automatically.
+	StatementLineMatched = "matched"
+	// StatementLineAlreadyPosted lines correspond to a payment that was
+	// already posted, typically by the virtual account webhook.
+	StatementLineAlreadyPosted = "already_posted"
+	// StatementLinePartial lines paid into a known account but do not cover
+	// the installment due.
+	StatementLinePartial = "partial"
+	// StatementLineAmbiguous lines fit more than one contract.
+	StatementLineAmbiguous = "ambiguous"
+	// StatementLineUnmatched lines could not be tied to any contract.
+	StatementLineUnmatched = "unmatched"
+	// StatementLineResolved lines were matched by hand.
+	StatementLineResolved = "resolved"
+	// StatementLineIgnored lines are debits or were dismissed by hand.
+	StatementLineIgnored = "ignored"
+)
+
+// StatementLineNeedsReview lists the statuses that wait in the manual
+// matching queue.
+var StatementLineNeedsReview = []string{StatementLinePartial, StatementLineAmbiguous, StatementLineUnmatched}
+
+// StatementImport is one uploaded bank mutation file.
+type StatementImport struct {
+	ID         uint            `gorm:"primaryKey;autoIncrement" json:"id"`
+	FileName   string          `gorm:"size:255;not null" json:"file_name"`
+	Format     string          `gorm:"type:varchar(10);not null" json:"format"`
+	ImportedBy uint            `gorm:"not null" json:"imported_by"`
+	LineCount  int             `gorm:"not null" json:"line_count"`
+	Lines      []StatementLine `gorm:"foreignKey:ImportID" json:"lines,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// StatementLine is one mutation of an import and the outcome of matching it.
+type StatementLine struct {
+	ID            uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	ImportID      uint       `gorm:"index;not null" json:"import_id"`
+	LineNumber    int        `gorm:"not null" json:"line_number"`
+	ValueDate     time.Time  `gorm:"type:date;not null" json:"value_date"`
+	Amount        int64      `gorm:"not null" json:"amount"`
+	Reference     string     `gorm:"size:100" json:"reference"`
+	Description   string     `gorm:"size:255" json:"description"`
+	VANumber      string     `gorm:"size:20;index" json:"va_number"`
+	Status        string     `gorm:"type:varchar(20);not null;index" json:"status"`
+	Note          string     `gorm:"size:255" json:"note"`
+	TransactionID *uint      `gorm:"index" json:"transaction_id"`
+	PaymentID     *uint      `json:"payment_id"`
+	ResolvedBy    *uint      `json:"resolved_by"`
+	ResolvedAt    *time.Time `json:"resolved_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+const (
+	SettlementQuoteStatusOpen     = "open"
+	SettlementQuoteStatusExecuted = "executed"
+)
+
+// SettlementQuote is the price offered to a customer for paying off a
+// contract early. It is fixed as of AsOf and can be executed until
+// ValidUntil, as long as nothing was paid on the contract in between.
+type SettlementQuote struct {
+	ID                   uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	TransactionID        uint      `gorm:"index;not null" json:"transaction_id"`
+	AsOf                 time.Time `gorm:"type:date;not null" json:"as_of"`
+	OutstandingPrincipal int64     `gorm:"not null" json:"outstanding_principal"`
+	OutstandingInterest  int64     `gorm:"not null" json:"outstanding_interest"`
+	InterestRebate       int64     `gorm:"not null" json:"interest_rebate"`
+	OutstandingLateFee   int64     `gorm:"column:outstanding_late_fee;not null" json:"outstanding_late_fee"`
+	PenaltyFee           int64     `gorm:"not null" json:"penalty_fee"`
+	TotalAmount          int64     `gorm:"not null" json:"total_amount"`
+	ValidUntil           time.Time `gorm:"not null" json:"valid_until"`
+	Status               string    `gorm:"type:varchar(20);not null" json:"status"`
+	PaymentID            *uint     `json:"payment_id"`
+	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
+}
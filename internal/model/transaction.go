@@ -6,16 +6,37 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	TransactionStatusApproved   = "approved"
+	TransactionStatusOngoing    = "ongoing"
+	TransactionStatusSuccess    = "success"
+	TransactionStatusClosed     = "closed"
+	TransactionStatusSettled    = "settled"
+	TransactionStatusCancelled  = "cancelled"
+	TransactionStatusWrittenOff = "written_off"
+)
+
 type Transaction struct {
 	ID                uint           `gorm:"primaryKey;autoIncrement" json:"id"`
 	ContractNumber    string         `gorm:"uniqueIndex;not null" json:"contract_number"`
 	CustomerID        uint           `gorm:"index;not null" json:"customer_id"`
 	Tenor             int            `gorm:"not null" json:"tenor"`
 	InstallmentAmount int64          `gorm:"column:installment_amount;not null" json:"installment_amount"`
+	Currency          string         `gorm:"type:char(3);not null;default:'IDR'" json:"currency"`
 	OTR               int64          `json:"otr"`
+	DownPayment       int64          `gorm:"column:down_payment;not null;default:0" json:"down_payment"`
+	Principal         int64          `gorm:"column:principal;not null;default:0" json:"principal"`
 	AdminFee          int64          `gorm:"column:admin_fee" json:"admin_fee"`
 	InterestAmount    int64          `gorm:"column:interest_amount" json:"interest_amount"`
+	AssetID           *uint          `gorm:"index" json:"asset_id"`
 	AssetName         string         `json:"asset_name"`
+	PartnerID         *uint          `gorm:"index" json:"partner_id"`
+	OutletID          *uint          `gorm:"index" json:"outlet_id"`
+	Status            string         `gorm:"type:varchar(50);not null" json:"status"`
+	DisbursedAt       *time.Time     `json:"disbursed_at"`
+	DaysPastDue       int            `gorm:"column:days_past_due;not null;default:0" json:"days_past_due"`
+	Collectibility    int            `gorm:"not null;default:1" json:"collectibility"`
+	AgingBucket       string         `gorm:"column:aging_bucket;type:varchar(10);not null;default:'current'" json:"aging_bucket"`
 	CreatedAt         time.Time      `json:"created_at"`
 	UpdatedAt         time.Time      `json:"updated_at"`
 	DeletedAt         gorm.DeletedAt `gorm:"index" json:"-"`
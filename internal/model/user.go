@@ -9,7 +9,7 @@ import (
 type User struct {
 	ID        uint           `gorm:"primaryKey;autoIncrement" json:"id"`
 	Username  string         `gorm:"unique;not null" json:"username"`
-	Password  string         `gorm:"not null" json:"-"`
+	Password  string         `gorm:"column:password_hash;not null" json:"-"`
 	Role      string         `gorm:"type:varchar(20);not null" json:"role"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
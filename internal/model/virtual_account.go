@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// VirtualAccount is a bank account number assigned to a customer, or to a
+// single contract, into which repayments are transferred. Payments into a
+// customer account go to the customer's oldest active contract.
+type VirtualAccount struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Number        string    `gorm:"uniqueIndex;size:20;not null" json:"number"`
+	BankCode      string    `gorm:"size:20;not null" json:"bank_code"`
+	CustomerID    uint      `gorm:"index;not null" json:"customer_id"`
+	TransactionID *uint     `gorm:"index" json:"transaction_id"`
+	Active        bool      `gorm:"not null;default:true" json:"active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
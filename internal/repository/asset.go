@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"time"
+
+	"xyz-multifinance/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AssetRepository interface {
+	Create(asset *model.Asset) error
+	Update(id uint, fields map[string]interface{}) error
+	Delete(id uint) error
+	FindByID(id uint) (*model.Asset, error)
+	FindAll(category string) ([]model.Asset, error)
+	FindDeleted() ([]model.Asset, error)
+	Restore(id uint, otrPrice int64) error
+	Upsert(assets []model.Asset) error
+}
+
+type assetRepository struct {
+	db *gorm.DB
+}
+
+func NewAssetRepository(db *gorm.DB) AssetRepository {
+	return &assetRepository{db: db}
+}
+
+func (r *assetRepository) Create(asset *model.Asset) error {
+	return r.db.Create(asset).Error
+}
+
+func (r *assetRepository) Update(id uint, fields map[string]interface{}) error {
+	return r.db.Model(&model.Asset{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *assetRepository) Delete(id uint) error {
+	return r.db.Delete(&model.Asset{}, id).Error
+}
+
+func (r *assetRepository) FindByID(id uint) (*model.Asset, error) {
+	var asset model.Asset
+	if err := r.db.First(&asset, id).Error; err != nil {
+		return nil, err
+	}
+	return &asset, nil
+}
+
+func (r *assetRepository) FindAll(category string) ([]model.Asset, error) {
+	var assets []model.Asset
+	query := r.db.Order("category, brand, model, year")
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+	if err := query.Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// FindDeleted returns the soft-deleted catalog entries. They still hold their
+// (category, brand, model, year) in the unique index.
+func (r *assetRepository) FindDeleted() ([]model.Asset, error) {
+	var assets []model.Asset
+	if err := r.db.Unscoped().Where("deleted_at IS NOT NULL").Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	return assets, nil
+}
+
+// Restore brings a soft-deleted entry back with a new OTR price.
+func (r *assetRepository) Restore(id uint, otrPrice int64) error {
+	return r.db.Unscoped().Model(&model.Asset{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"otr_price":  otrPrice,
+		"updated_at": time.Now(),
+		"deleted_at": nil,
+	}).Error
+}
+
+// Upsert inserts catalog entries or refreshes the OTR price of entries that
+// already exist for the same category, brand, model and year. A soft-deleted
+// entry only gets its price refreshed and stays deleted.
+func (r *assetRepository) Upsert(assets []model.Asset) error {
+	if len(assets) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "category"}, {Name: "brand"}, {Name: "model"}, {Name: "year"}},
+		DoUpdates: clause.AssignmentColumns([]string{"otr_price", "updated_at"}),
+	}).CreateInBatches(&assets, 200).Error
+}
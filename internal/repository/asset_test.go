@@ -0,0 +1,51 @@
+package repository_test
+
+import (
+	"testing"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+)
+
+func TestUpsert_KeepsDeletedAssetsDeleted(t *testing.T) {
+	db := openTestDB(t, &model.Asset{})
+	repo := repository.NewAssetRepository(db)
+
+	if err := repo.Create(&model.Asset{Category: "car", Brand: "Toyota", Model: "Avanza", Year: 2023, OTRPrice: 240_000_000}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := repo.Delete(1); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	err := repo.Upsert([]model.Asset{
+		{Category: "car", Brand: "Toyota", Model: "Avanza", Year: 2023, OTRPrice: 250_000_000},
+		{Category: "motorcycle", Brand: "Yamaha", Model: "NMAX", Year: 2024, OTRPrice: 32_000_000},
+	})
+	if err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+
+	live, err := repo.FindAll("")
+	if err != nil {
+		t.Fatalf("find all: %v", err)
+	}
+	if len(live) != 1 || live[0].Brand != "Yamaha" {
+		t.Errorf("live = %+v, want only the new entry", live)
+	}
+	deleted, err := repo.FindDeleted()
+	if err != nil {
+		t.Fatalf("find deleted: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].ID != 1 {
+		t.Fatalf("deleted = %+v, want entry 1 still deleted", deleted)
+	}
+
+	if err := repo.Restore(1, 250_000_000); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	restored, err := repo.FindByID(1)
+	if err != nil || restored.OTRPrice != 250_000_000 {
+		t.Errorf("restored = %+v, err = %v, want entry 1 back at the new price", restored, err)
+	}
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"time"
+
+	"xyz-multifinance/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AuditLogFilter narrows an audit log query. Zero fields are ignored.
+// Results are returned newest first; BeforeID pages through older entries.
+type AuditLogFilter struct {
+	Entity    string
+	EntityID  string
+	Action    string
+	UserID    uint
+	RequestID string
+	From      time.Time
+	To        time.Time
+	BeforeID  uint
+	Limit     int
+}
+
+type AuditLogRepository interface {
+	// Append chains the entry to the current head of the log and stores it.
+	// The entry's PrevHash and Hash are filled in.
+	Append(entry *model.AuditLog) error
+	Find(filter AuditLogFilter) ([]model.AuditLog, error)
+	FindBatch(afterID uint, limit int) ([]model.AuditLog, error)
+	FindHead() (*model.AuditChainHead, error)
+}
+
+// auditChainHeadID is the id of the only AuditChainHead row.
+const auditChainHeadID = 1
+
+type auditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+func (r *auditLogRepository) Append(entry *model.AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		head := model.AuditChainHead{ID: auditChainHeadID}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Attrs(model.AuditChainHead{LastHash: ""}).
+			FirstOrCreate(&head, model.AuditChainHead{ID: auditChainHeadID}).Error
+		if err != nil {
+			return err
+		}
+
+		entry.PrevHash = head.LastHash
+		entry.Hash = entry.ComputeHash()
+		if err := tx.Create(entry).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&head).Updates(map[string]interface{}{
+			"last_log_id": entry.ID,
+			"last_hash":   entry.Hash,
+		}).Error
+	})
+}
+
+func (r *auditLogRepository) Find(filter AuditLogFilter) ([]model.AuditLog, error) {
+	query := r.db.Model(&model.AuditLog{})
+	if filter.Entity != "" {
+		query = query.Where("entity = ?", filter.Entity)
+	}
+	if filter.EntityID != "" {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	if filter.BeforeID != 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+
+	var entries []model.AuditLog
+	if err := query.Order("id DESC").Limit(filter.Limit).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// FindBatch returns entries in chain order, starting after afterID.
+func (r *auditLogRepository) FindBatch(afterID uint, limit int) ([]model.AuditLog, error) {
+	var entries []model.AuditLog
+	if err := r.db.Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&entries).Error; err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// FindHead returns the end of the chain, or an empty head when nothing has
+// been logged yet.
+func (r *auditLogRepository) FindHead() (*model.AuditChainHead, error) {
+	var heads []model.AuditChainHead
+	if err := r.db.Where("id = ?", auditChainHeadID).Limit(1).Find(&heads).Error; err != nil {
+		return nil, err
+	}
+	if len(heads) == 0 {
+		return &model.AuditChainHead{ID: auditChainHeadID}, nil
+	}
+	return &heads[0], nil
+}
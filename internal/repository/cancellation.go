@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"xyz-multifinance/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type CancellationRepository interface {
+	Create(tx *gorm.DB, cancellation *model.TransactionCancellation) error
+	FindByTransactionID(transactionID uint) (*model.TransactionCancellation, error)
+}
+
+type cancellationRepository struct {
+	db *gorm.DB
+}
+
+func NewCancellationRepository(db *gorm.DB) CancellationRepository {
+	return &cancellationRepository{db: db}
+}
+
+func (r *cancellationRepository) Create(tx *gorm.DB, cancellation *model.TransactionCancellation) error {
+	return tx.Create(cancellation).Error
+}
+
+func (r *cancellationRepository) FindByTransactionID(transactionID uint) (*model.TransactionCancellation, error) {
+	var cancellation model.TransactionCancellation
+	if err := r.db.Where("transaction_id = ?", transactionID).First(&cancellation).Error; err != nil {
+		return nil, err
+	}
+	return &cancellation, nil
+}
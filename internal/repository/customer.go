@@ -1,7 +1,10 @@
 package repository
 
 import (
+	"time"
+
 	"xyz-multifinance/internal/model"
+	"xyz-multifinance/pkg/fieldcrypt"
 
 	"gorm.io/gorm"
 )
@@ -9,11 +12,19 @@ import (
 type CustomerRepository interface {
 	FindByNIK(nik string) (*model.Customer, error)
 	FindByID(id uint) (*model.Customer, error)
+	FindBatch(afterID uint, limit int) ([]model.Customer, error)
 	Create(customer *model.Customer) error
 	Update(nik string, fields map[string]interface{}) error
+	SaveEncrypted(customer *model.Customer) error
+	UpdatePhotos(id uint, ktpPhoto, selfiePhoto string) error
+	Anonymize(tx *gorm.DB, id uint, anonymizedAt time.Time) error
 	Delete(id uint) error
 }
 
+// encryptedCustomerColumns are the customer columns stored through the
+// encrypted serializer.
+var encryptedCustomerColumns = []string{"nik", "full_name", "legal_name", "birth_place", "birth_date", "salary"}
+
 type customerRepository struct {
 	db *gorm.DB
 }
@@ -22,9 +33,17 @@ func NewCustomerRepository(db *gorm.DB) CustomerRepository {
 	return &customerRepository{db: db}
 }
 
+// byNIK matches a customer through the NIK blind index only. Rows written
+// before the index existed get it from the reencrypt command.
+func byNIK(nik string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("nik_hash = ?", fieldcrypt.BlindIndex(nik))
+	}
+}
+
 func (r *customerRepository) FindByNIK(nik string) (*model.Customer, error) {
 	var customer model.Customer
-	if err := r.db.Where("nik = ?", nik).First(&customer).Error; err != nil {
+	if err := r.db.Scopes(byNIK(nik)).First(&customer).Error; err != nil {
 		return nil, err
 	}
 	return &customer, nil
@@ -38,13 +57,75 @@ func (r *customerRepository) FindByID(id uint) (*model.Customer, error) {
 	return &customer, nil
 }
 
+// FindBatch returns up to limit customers with an id above afterID, deleted
+// ones included, in id order.
+func (r *customerRepository) FindBatch(afterID uint, limit int) ([]model.Customer, error) {
+	var customers []model.Customer
+	err := r.db.Unscoped().Where("id > ?", afterID).Order("id ASC").Limit(limit).Find(&customers).Error
+	if err != nil {
+		return nil, err
+	}
+	return customers, nil
+}
+
 func (r *customerRepository) Create(customer *model.Customer) error {
+	customer.NIKHash = fieldcrypt.BlindIndex(customer.NIK)
 	return r.db.Create(customer).Error
 }
 
+// Update applies a partial update. GORM does not run serializers on map
+// updates, so values of encrypted columns are wrapped to be encrypted here.
 func (r *customerRepository) Update(nik string, fields map[string]interface{}) error {
-	return r.db.Model(&model.Customer{}).Where("nik = ?", nik).Updates(fields).Error
+	if newNIK, ok := fields["nik"].(string); ok {
+		fields["nik_hash"] = fieldcrypt.BlindIndex(newNIK)
+	}
+	for _, column := range encryptedCustomerColumns {
+		if value, ok := fields[column]; ok {
+			fields[column] = fieldcrypt.Value(column, value)
+		}
+	}
+	return r.db.Model(&model.Customer{}).Scopes(byNIK(nik)).Updates(fields).Error
 }
+
+// SaveEncrypted writes the encrypted columns and the blind index of a
+// customer again, sealing them with the active key.
+func (r *customerRepository) SaveEncrypted(customer *model.Customer) error {
+	customer.NIKHash = fieldcrypt.BlindIndex(customer.NIK)
+	columns := append([]string{"nik_hash"}, encryptedCustomerColumns...)
+	return r.db.Unscoped().Model(customer).Select(columns).UpdateColumns(customer).Error
+}
+
+// UpdatePhotos points a customer's KYC photo columns at new object keys,
+// deleted customers included.
+func (r *customerRepository) UpdatePhotos(id uint, ktpPhoto, selfiePhoto string) error {
+	return r.db.Unscoped().Model(&model.Customer{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"photo_ktp": ktpPhoto, "photo_selfie": selfiePhoto}).Error
+}
+
+// Anonymize blanks a customer's personal data and KYC photo keys, deleted
+// customers included. The row itself is kept for the contracts that refer to
+// it.
+func (r *customerRepository) Anonymize(tx *gorm.DB, id uint, anonymizedAt time.Time) error {
+	fields := map[string]interface{}{
+		"nik_hash":      nil,
+		"photo_ktp":     "",
+		"photo_selfie":  "",
+		"anonymized_at": anonymizedAt,
+	}
+	blank := map[string]interface{}{
+		"nik":         "",
+		"full_name":   "",
+		"legal_name":  "",
+		"birth_place": "",
+		"birth_date":  time.Time{},
+		"salary":      int64(0),
+	}
+	for _, column := range encryptedCustomerColumns {
+		fields[column] = fieldcrypt.Value(column, blank[column])
+	}
+	return tx.Unscoped().Model(&model.Customer{}).Where("id = ?", id).Updates(fields).Error
+}
+
 func (r *customerRepository) Delete(id uint) error {
 	return r.db.Delete(&model.Customer{}, id).Error
 }
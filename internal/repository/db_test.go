@@ -0,0 +1,34 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// openTestDB opens an in-memory SQLite database with tables for models, so
+// repository queries run against a real engine.
+func openTestDB(tb testing.TB, models ...interface{}) *gorm.DB {
+	tb.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger:                                   logger.Default.LogMode(logger.Silent),
+		DisableForeignKeyConstraintWhenMigrating: true,
+	})
+	if err != nil {
+		tb.Fatalf("open sqlite: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		tb.Fatalf("sql db: %v", err)
+	}
+	// Every connection to :memory: is a separate database.
+	sqlDB.SetMaxOpenConns(1)
+	tb.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.AutoMigrate(models...); err != nil {
+		tb.Fatalf("migrate: %v", err)
+	}
+	return db
+}
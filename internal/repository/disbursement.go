@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"time"
+
+	"xyz-multifinance/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DisbursementRepository interface {
+	Create(tx *gorm.DB, disbursement *model.Disbursement) error
+	Save(tx *gorm.DB, disbursement *model.Disbursement) error
+	Update(id uint, fields map[string]interface{}) error
+	FindByID(id uint) (*model.Disbursement, error)
+	FindByIDForUpdate(tx *gorm.DB, id uint) (*model.Disbursement, error)
+	FindByTransactionID(transactionID uint) (*model.Disbursement, error)
+	FindByTransactionIDForUpdate(tx *gorm.DB, transactionID uint) (*model.Disbursement, error)
+	FindByReference(reference string) (*model.Disbursement, error)
+	FindDue(now time.Time, limit int) ([]model.Disbursement, error)
+	Claim(id uint, now time.Time) (bool, error)
+	FindStale(claimedBefore time.Time, limit int) ([]model.Disbursement, error)
+}
+
+type disbursementRepository struct {
+	db *gorm.DB
+}
+
+func NewDisbursementRepository(db *gorm.DB) DisbursementRepository {
+	return &disbursementRepository{db: db}
+}
+
+func (r *disbursementRepository) Create(tx *gorm.DB, disbursement *model.Disbursement) error {
+	return tx.Create(disbursement).Error
+}
+
+func (r *disbursementRepository) Save(tx *gorm.DB, disbursement *model.Disbursement) error {
+	return tx.Save(disbursement).Error
+}
+
+func (r *disbursementRepository) Update(id uint, fields map[string]interface{}) error {
+	return r.db.Model(&model.Disbursement{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *disbursementRepository) FindByID(id uint) (*model.Disbursement, error) {
+	var disbursement model.Disbursement
+	if err := r.db.First(&disbursement, id).Error; err != nil {
+		return nil, err
+	}
+	return &disbursement, nil
+}
+
+func (r *disbursementRepository) FindByIDForUpdate(tx *gorm.DB, id uint) (*model.Disbursement, error) {
+	var disbursement model.Disbursement
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&disbursement, id).Error; err != nil {
+		return nil, err
+	}
+	return &disbursement, nil
+}
+
+func (r *disbursementRepository) FindByTransactionID(transactionID uint) (*model.Disbursement, error) {
+	var disbursement model.Disbursement
+	if err := r.db.Where("transaction_id = ?", transactionID).First(&disbursement).Error; err != nil {
+		return nil, err
+	}
+	return &disbursement, nil
+}
+
+func (r *disbursementRepository) FindByTransactionIDForUpdate(tx *gorm.DB, transactionID uint) (*model.Disbursement, error) {
+	var disbursement model.Disbursement
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("transaction_id = ?", transactionID).
+		First(&disbursement).Error
+	if err != nil {
+		return nil, err
+	}
+	return &disbursement, nil
+}
+
+func (r *disbursementRepository) FindByReference(reference string) (*model.Disbursement, error) {
+	var disbursement model.Disbursement
+	if err := r.db.Where("reference = ?", reference).First(&disbursement).Error; err != nil {
+		return nil, err
+	}
+	return &disbursement, nil
+}
+
+// FindDue returns pending disbursements whose next attempt is due and whose
+// beneficiary is known, oldest first.
+func (r *disbursementRepository) FindDue(now time.Time, limit int) ([]model.Disbursement, error) {
+	var disbursements []model.Disbursement
+	err := r.db.
+		Where("status = ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)", model.DisbursementStatusPending, now).
+		Where("bank_code <> '' AND bank_account_number <> '' AND bank_account_name <> ''").
+		Order("id ASC").
+		Limit(limit).
+		Find(&disbursements).Error
+	if err != nil {
+		return nil, err
+	}
+	return disbursements, nil
+}
+
+// Claim moves a due pending disbursement to processing, counts the attempt
+// and records when it was claimed. It reports false when another worker got
+// there first.
+func (r *disbursementRepository) Claim(id uint, now time.Time) (bool, error) {
+	result := r.db.Model(&model.Disbursement{}).
+		Where("id = ? AND status = ? AND (next_attempt_at IS NULL OR next_attempt_at <= ?)", id, model.DisbursementStatusPending, now).
+		Updates(map[string]interface{}{
+			"status":     model.DisbursementStatusProcessing,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"claimed_at": now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// FindStale returns disbursements that were claimed before claimedBefore and
+// are still processing, oldest claim first. Rows claimed before claimed_at
+// was recorded count as stale.
+func (r *disbursementRepository) FindStale(claimedBefore time.Time, limit int) ([]model.Disbursement, error) {
+	var disbursements []model.Disbursement
+	err := r.db.
+		Where("status = ? AND (claimed_at IS NULL OR claimed_at <= ?)", model.DisbursementStatusProcessing, claimedBefore).
+		Order("claimed_at ASC").
+		Limit(limit).
+		Find(&disbursements).Error
+	if err != nil {
+		return nil, err
+	}
+	return disbursements, nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"xyz-multifinance/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type DownPaymentRuleRepository interface {
+	Create(rule *model.DownPaymentRule) error
+	Delete(id uint) error
+	FindByID(id uint) (*model.DownPaymentRule, error)
+	FindAll() ([]model.DownPaymentRule, error)
+	FindApplicable(category string, tenor int) ([]model.DownPaymentRule, error)
+}
+
+type downPaymentRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewDownPaymentRuleRepository(db *gorm.DB) DownPaymentRuleRepository {
+	return &downPaymentRuleRepository{db: db}
+}
+
+func (r *downPaymentRuleRepository) Create(rule *model.DownPaymentRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *downPaymentRuleRepository) Delete(id uint) error {
+	return r.db.Delete(&model.DownPaymentRule{}, id).Error
+}
+
+func (r *downPaymentRuleRepository) FindByID(id uint) (*model.DownPaymentRule, error) {
+	var rule model.DownPaymentRule
+	if err := r.db.First(&rule, id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *downPaymentRuleRepository) FindAll() ([]model.DownPaymentRule, error) {
+	var rules []model.DownPaymentRule
+	if err := r.db.Order("category, tenor_month").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *downPaymentRuleRepository) FindApplicable(category string, tenor int) ([]model.DownPaymentRule, error) {
+	var rules []model.DownPaymentRule
+	err := r.db.
+		Where("category IN ? AND tenor_month IN ?", []string{category, ""}, []int{tenor, 0}).
+		Find(&rules).Error
+	if err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
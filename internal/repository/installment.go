@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"time"
+
+	"xyz-multifinance/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InstallmentRepository interface {
+	CreateBatch(tx *gorm.DB, installments []model.Installment) error
+	FindByTransactionID(transactionID uint) ([]model.Installment, error)
+	FindOpenForUpdate(tx *gorm.DB, transactionID uint) ([]model.Installment, error)
+	FindAllForUpdate(tx *gorm.DB, transactionID uint) ([]model.Installment, error)
+	FindOverdue(asOf time.Time) ([]model.Installment, error)
+	FindOverdueByCustomerID(customerID uint, asOf time.Time) ([]model.Installment, error)
+	Save(tx *gorm.DB, installment *model.Installment) error
+	UpdateLateFee(tx *gorm.DB, id uint, lateFee int64) error
+	FindByTransactionIDs(transactionIDs []uint) ([]model.Installment, error)
+}
+
+type installmentRepository struct {
+	db *gorm.DB
+}
+
+func NewInstallmentRepository(db *gorm.DB) InstallmentRepository {
+	return &installmentRepository{db: db}
+}
+
+func (r *installmentRepository) CreateBatch(tx *gorm.DB, installments []model.Installment) error {
+	if len(installments) == 0 {
+		return nil
+	}
+	return tx.Create(&installments).Error
+}
+
+func (r *installmentRepository) FindByTransactionID(transactionID uint) ([]model.Installment, error) {
+	var installments []model.Installment
+	if err := r.db.Where("transaction_id = ?", transactionID).Order("sequence ASC").Find(&installments).Error; err != nil {
+		return nil, err
+	}
+	return installments, nil
+}
+
+// FindOpenForUpdate locks the installments of a contract that are not fully
+// paid yet, oldest first.
+func (r *installmentRepository) FindOpenForUpdate(tx *gorm.DB, transactionID uint) ([]model.Installment, error) {
+	var installments []model.Installment
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("transaction_id = ? AND status <> ?", transactionID, model.InstallmentStatusPaid).
+		Order("sequence ASC").
+		Find(&installments).Error
+	if err != nil {
+		return nil, err
+	}
+	return installments, nil
+}
+
+func (r *installmentRepository) FindAllForUpdate(tx *gorm.DB, transactionID uint) ([]model.Installment, error) {
+	var installments []model.Installment
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("transaction_id = ?", transactionID).
+		Order("sequence ASC").
+		Find(&installments).Error
+	if err != nil {
+		return nil, err
+	}
+	return installments, nil
+}
+
+// FindOverdue returns unpaid installments of active contracts that fell due
+// before asOf.
+func (r *installmentRepository) FindOverdue(asOf time.Time) ([]model.Installment, error) {
+	var installments []model.Installment
+	err := r.db.
+		Joins("JOIN transactions t ON t.id = installments.transaction_id AND t.deleted_at IS NULL").
+		Where("installments.status <> ? AND installments.due_date < ? AND t.status IN ?", model.InstallmentStatusPaid, asOf, activeStatuses).
+		Order("installments.transaction_id, installments.sequence").
+		Find(&installments).Error
+	if err != nil {
+		return nil, err
+	}
+	return installments, nil
+}
+
+func (r *installmentRepository) FindOverdueByCustomerID(customerID uint, asOf time.Time) ([]model.Installment, error) {
+	var installments []model.Installment
+	err := r.db.
+		Joins("JOIN transactions t ON t.id = installments.transaction_id AND t.deleted_at IS NULL").
+		Where("installments.status <> ? AND installments.due_date < ? AND t.customer_id = ? AND t.status IN ?",
+			model.InstallmentStatusPaid, asOf, customerID, activeStatuses).
+		Order("installments.transaction_id, installments.sequence").
+		Find(&installments).Error
+	if err != nil {
+		return nil, err
+	}
+	return installments, nil
+}
+
+func (r *installmentRepository) Save(tx *gorm.DB, installment *model.Installment) error {
+	return tx.Save(installment).Error
+}
+
+func (r *installmentRepository) UpdateLateFee(tx *gorm.DB, id uint, lateFee int64) error {
+	return tx.Model(&model.Installment{}).Where("id = ?", id).Update("late_fee", lateFee).Error
+}
+
+func (r *installmentRepository) FindByTransactionIDs(transactionIDs []uint) ([]model.Installment, error) {
+	var installments []model.Installment
+	if len(transactionIDs) == 0 {
+		return installments, nil
+	}
+	err := r.db.Where("transaction_id IN ?", transactionIDs).
+		Order("transaction_id ASC, sequence ASC").
+		Find(&installments).Error
+	if err != nil {
+		return nil, err
+	}
+	return installments, nil
+}
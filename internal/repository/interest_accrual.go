@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"time"
+
+	"xyz-multifinance/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type InterestAccrualRepository interface {
+	Create(tx *gorm.DB, accrual *model.InterestAccrual) error
+	SummarizeByTransaction(transactionIDs []uint) (map[uint]AccrualSummary, error)
+	Report(from, to time.Time, groupBy string) ([]AccrualReportRow, error)
+}
+
+// AccrualSummary is how much interest has been accrued for a contract so far
+// and up to which date.
+type AccrualSummary struct {
+	TransactionID   uint
+	LastAccrualDate time.Time
+	Total           int64
+}
+
+type AccrualReportRow struct {
+	Period    string `json:"period"`
+	Contracts int    `json:"contracts"`
+	Amount    int64  `json:"amount"`
+}
+
+type interestAccrualRepository struct {
+	db *gorm.DB
+}
+
+func NewInterestAccrualRepository(db *gorm.DB) InterestAccrualRepository {
+	return &interestAccrualRepository{db: db}
+}
+
+func (r *interestAccrualRepository) Create(tx *gorm.DB, accrual *model.InterestAccrual) error {
+	return tx.Create(accrual).Error
+}
+
+func (r *interestAccrualRepository) SummarizeByTransaction(transactionIDs []uint) (map[uint]AccrualSummary, error) {
+	summaries := make(map[uint]AccrualSummary)
+	if len(transactionIDs) == 0 {
+		return summaries, nil
+	}
+
+	var rows []AccrualSummary
+	err := r.db.Model(&model.InterestAccrual{}).
+		Select("transaction_id, MAX(accrual_date) AS last_accrual_date, SUM(amount) AS total").
+		Where("transaction_id IN ?", transactionIDs).
+		Group("transaction_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		summaries[row.TransactionID] = row
+	}
+	return summaries, nil
+}
+
+// Report totals accruals between two dates, inclusive, per day or per month.
+func (r *interestAccrualRepository) Report(from, to time.Time, groupBy string) ([]AccrualReportRow, error) {
+	period := "DATE_FORMAT(accrual_date, '%Y-%m-%d')"
+	if groupBy == "month" {
+		period = "DATE_FORMAT(accrual_date, '%Y-%m')"
+	}
+
+	var rows []AccrualReportRow
+	err := r.db.Model(&model.InterestAccrual{}).
+		Select(period+" AS period, COUNT(DISTINCT transaction_id) AS contracts, SUM(amount) AS amount").
+		Where("accrual_date >= ? AND accrual_date <= ?", from, to).
+		Group("period").
+		Order("period ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
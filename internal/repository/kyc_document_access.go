@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"xyz-multifinance/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type KYCDocumentAccessRepository interface {
+	Create(access *model.KYCDocumentAccess) error
+	FindByCustomerID(customerID uint, limit int) ([]model.KYCDocumentAccess, error)
+}
+
+type kycDocumentAccessRepository struct {
+	db *gorm.DB
+}
+
+func NewKYCDocumentAccessRepository(db *gorm.DB) KYCDocumentAccessRepository {
+	return &kycDocumentAccessRepository{db: db}
+}
+
+func (r *kycDocumentAccessRepository) Create(access *model.KYCDocumentAccess) error {
+	return r.db.Create(access).Error
+}
+
+// FindByCustomerID returns the most recent accesses to a customer's
+// documents, newest first.
+func (r *kycDocumentAccessRepository) FindByCustomerID(customerID uint, limit int) ([]model.KYCDocumentAccess, error) {
+	var accesses []model.KYCDocumentAccess
+	err := r.db.Where("customer_id = ?", customerID).Order("id DESC").Limit(limit).Find(&accesses).Error
+	if err != nil {
+		return nil, err
+	}
+	return accesses, nil
+}
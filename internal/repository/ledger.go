@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"time"
+
+	"xyz-multifinance/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type LedgerRepository interface {
+	CreateEntry(tx *gorm.DB, entry *model.JournalEntry) error
+	FindAccounts() ([]model.Account, error)
+	FindAccountByCode(code string) (*model.Account, error)
+	SumByAccount(asOf time.Time) ([]AccountTotal, error)
+	SumAccountBefore(code string, before time.Time) (*AccountTotal, error)
+	FindStatementLines(code string, from, to time.Time) ([]StatementLine, error)
+	ContractBalances(tx *gorm.DB, transactionID uint) (map[string]int64, error)
+}
+
+// AccountTotal is the sum of all postings to an account.
+type AccountTotal struct {
+	AccountCode string `json:"account_code"`
+	Debit       int64  `json:"debit"`
+	Credit      int64  `json:"credit"`
+}
+
+// StatementLine is a journal line together with the entry it belongs to.
+type StatementLine struct {
+	JournalEntryID uint      `json:"journal_entry_id"`
+	EntryDate      time.Time `json:"entry_date"`
+	Type           string    `json:"type"`
+	Reference      string    `json:"reference"`
+	Description    string    `json:"description"`
+	TransactionID  *uint     `json:"transaction_id"`
+	Debit          int64     `json:"debit"`
+	Credit         int64     `json:"credit"`
+}
+
+type ledgerRepository struct {
+	db *gorm.DB
+}
+
+func NewLedgerRepository(db *gorm.DB) LedgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+// CreateEntry stores the entry together with its lines. The entry is
+// validated by its BeforeCreate hook.
+func (r *ledgerRepository) CreateEntry(tx *gorm.DB, entry *model.JournalEntry) error {
+	return tx.Create(entry).Error
+}
+
+func (r *ledgerRepository) FindAccounts() ([]model.Account, error) {
+	var accounts []model.Account
+	if err := r.db.Order("code ASC").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (r *ledgerRepository) FindAccountByCode(code string) (*model.Account, error) {
+	var account model.Account
+	if err := r.db.Where("code = ?", code).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// SumByAccount totals debits and credits per account for entries dated on or
+// before asOf.
+func (r *ledgerRepository) SumByAccount(asOf time.Time) ([]AccountTotal, error) {
+	var totals []AccountTotal
+	err := r.db.Table("journal_lines l").
+		Select("l.account_code, COALESCE(SUM(l.debit), 0) AS debit, COALESCE(SUM(l.credit), 0) AS credit").
+		Joins("JOIN journal_entries e ON e.id = l.journal_entry_id").
+		Where("e.entry_date <= ?", asOf).
+		Group("l.account_code").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
+
+func (r *ledgerRepository) SumAccountBefore(code string, before time.Time) (*AccountTotal, error) {
+	total := AccountTotal{AccountCode: code}
+	err := r.db.Table("journal_lines l").
+		Select("COALESCE(SUM(l.debit), 0) AS debit, COALESCE(SUM(l.credit), 0) AS credit").
+		Joins("JOIN journal_entries e ON e.id = l.journal_entry_id").
+		Where("l.account_code = ? AND e.entry_date < ?", code, before).
+		Scan(&total).Error
+	if err != nil {
+		return nil, err
+	}
+	return &total, nil
+}
+
+func (r *ledgerRepository) FindStatementLines(code string, from, to time.Time) ([]StatementLine, error) {
+	var lines []StatementLine
+	err := r.db.Table("journal_lines l").
+		Select("e.id AS journal_entry_id, e.entry_date, e.type, e.reference, e.description, e.transaction_id, l.debit, l.credit").
+		Joins("JOIN journal_entries e ON e.id = l.journal_entry_id").
+		Where("l.account_code = ? AND e.entry_date >= ? AND e.entry_date <= ?", code, from, to).
+		Order("e.entry_date ASC, e.id ASC, l.id ASC").
+		Scan(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// ContractBalances returns debit minus credit per account over every entry
+// posted for a contract.
+func (r *ledgerRepository) ContractBalances(tx *gorm.DB, transactionID uint) (map[string]int64, error) {
+	var rows []struct {
+		AccountCode string
+		Balance     int64
+	}
+	err := tx.Table("journal_lines l").
+		Select("l.account_code, COALESCE(SUM(l.debit - l.credit), 0) AS balance").
+		Joins("JOIN journal_entries e ON e.id = l.journal_entry_id").
+		Where("e.transaction_id = ?", transactionID).
+		Group("l.account_code").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		balances[row.AccountCode] = row.Balance
+	}
+	return balances, nil
+}
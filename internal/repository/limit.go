@@ -12,6 +12,16 @@ type LimitRepository interface {
 	Delete(id uint) error
 	FindByID(id uint) (*model.Limit, error)
 	FindByCustomerID(customerID uint) ([]model.Limit, error)
+	FindUsageByID(id uint) (*LimitUsage, error)
+	FindUsageByCustomerID(customerID uint) ([]LimitUsage, error)
+	FindUsageByCustomerAndTenor(customerID uint, tenor int) (*LimitUsage, error)
+}
+
+// LimitUsage is a limit together with the amount already consumed by the
+// customer's active transactions on the same tenor.
+type LimitUsage struct {
+	model.Limit
+	UsedAmount int64 `gorm:"column:used_amount"`
 }
 
 type limitRepository struct {
@@ -45,3 +55,47 @@ func (r *limitRepository) FindByCustomerID(customerID uint) ([]model.Limit, erro
 	err := r.db.Where("customer_id = ?", customerID).Find(&limits).Error
 	return limits, err
 }
+
+func (r *limitRepository) FindUsageByID(id uint) (*LimitUsage, error) {
+	var usages []LimitUsage
+	if err := r.withUsage().Where("limits.id = ?", id).Scan(&usages).Error; err != nil {
+		return nil, err
+	}
+	if len(usages) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &usages[0], nil
+}
+
+func (r *limitRepository) FindUsageByCustomerID(customerID uint) ([]LimitUsage, error) {
+	var usages []LimitUsage
+	err := r.withUsage().
+		Where("limits.customer_id = ?", customerID).
+		Order("limits.tenor_month ASC").
+		Scan(&usages).Error
+	return usages, err
+}
+
+func (r *limitRepository) FindUsageByCustomerAndTenor(customerID uint, tenor int) (*LimitUsage, error) {
+	var usages []LimitUsage
+	err := r.withUsage().
+		Where("limits.customer_id = ? AND limits.tenor_month = ?", customerID, tenor).
+		Scan(&usages).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(usages) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &usages[0], nil
+}
+
+// withUsage joins limits with the financed principal of the customer's active
+// transactions on the same tenor, so the used amount comes back in the same
+// round-trip as the limit itself.
+func (r *limitRepository) withUsage() *gorm.DB {
+	return r.db.Model(&model.Limit{}).
+		Select("limits.*, COALESCE(SUM(t.principal), 0) AS used_amount").
+		Joins("LEFT JOIN transactions t ON t.customer_id = limits.customer_id AND t.tenor = limits.tenor_month AND t.status IN ? AND t.deleted_at IS NULL", usedLimitStatuses).
+		Group("limits.id")
+}
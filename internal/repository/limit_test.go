@@ -0,0 +1,112 @@
+package repository_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// seedLimits gives each customer one limit per tenor and txPerTenor
+// transactions on every tenor, cycling through statuses that do and do not
+// consume the limit. Every fourth transaction is soft-deleted.
+func seedLimits(tb testing.TB, db *gorm.DB, customers, tenors, txPerTenor int) {
+	tb.Helper()
+	statuses := []string{
+		model.TransactionStatusApproved, model.TransactionStatusOngoing, model.TransactionStatusSuccess,
+		model.TransactionStatusClosed, model.TransactionStatusCancelled,
+	}
+
+	var limits []model.Limit
+	var transactions []model.Transaction
+	for c := 1; c <= customers; c++ {
+		for tenor := 1; tenor <= tenors; tenor++ {
+			limits = append(limits, model.Limit{CustomerID: uint(c), Tenor: tenor, Limit: int64(tenor) * 10_000_000, Currency: "IDR"})
+			for i := 0; i < txPerTenor; i++ {
+				tx := model.Transaction{
+					ContractNumber: fmt.Sprintf("CN-%d-%d-%d", c, tenor, i),
+					CustomerID:     uint(c),
+					Tenor:          tenor,
+					Currency:       "IDR",
+					Principal:      100_000,
+					Status:         statuses[i%len(statuses)],
+				}
+				if i%4 == 3 {
+					tx.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+				}
+				transactions = append(transactions, tx)
+			}
+		}
+	}
+	if err := db.CreateInBatches(limits, 500).Error; err != nil {
+		tb.Fatalf("seed limits: %v", err)
+	}
+	if err := db.CreateInBatches(transactions, 500).Error; err != nil {
+		tb.Fatalf("seed transactions: %v", err)
+	}
+}
+
+func TestFindUsageByCustomerID_SumsActiveTransactionsPerTenor(t *testing.T) {
+	db := openTestDB(t, &model.Limit{}, &model.Transaction{})
+	seedLimits(t, db, 3, 4, 10)
+	repo := repository.NewLimitRepository(db)
+
+	usages, err := repo.FindUsageByCustomerID(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(usages) != 4 {
+		t.Fatalf("got %d limits, want 4", len(usages))
+	}
+	// Of the ten transactions per tenor, indexes 0, 1, 2, 5 and 6 are in a
+	// status that uses the limit and not soft-deleted.
+	for i, u := range usages {
+		if u.CustomerID != 2 || u.Tenor != i+1 || u.UsedAmount != 500_000 {
+			t.Errorf("usage %d = %+v, want tenor %d of customer 2 using 500000", i, u, i+1)
+		}
+	}
+
+	if _, err := repo.FindUsageByCustomerID(99); err != nil {
+		t.Errorf("unknown customer: unexpected error %v", err)
+	}
+}
+
+func TestFindUsageByCustomerID_CountsLimitWithoutTransactions(t *testing.T) {
+	db := openTestDB(t, &model.Limit{}, &model.Transaction{})
+	seedLimits(t, db, 1, 2, 0)
+
+	usages, err := repository.NewLimitRepository(db).FindUsageByCustomerID(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(usages) != 2 || usages[0].UsedAmount != 0 || usages[1].UsedAmount != 0 {
+		t.Errorf("usages = %+v, want two unused limits", usages)
+	}
+}
+
+func BenchmarkFindUsageByCustomerID(b *testing.B) {
+	for _, tenors := range []int{3, 12, 48} {
+		b.Run(fmt.Sprintf("limits=%d", tenors), func(b *testing.B) {
+			db := openTestDB(b, &model.Limit{}, &model.Transaction{})
+			// 100 customers with 10 transactions per tenor, so the join has to
+			// pick one customer's rows out of thousands.
+			seedLimits(b, db, 100, tenors, 10)
+			repo := repository.NewLimitRepository(db)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				usages, err := repo.FindUsageByCustomerID(uint(i%100 + 1))
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(usages) != tenors {
+					b.Fatalf("got %d limits, want %d", len(usages), tenors)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"xyz-multifinance/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type OutletRepository interface {
+	Create(outlet *model.Outlet) error
+	FindByID(id uint) (*model.Outlet, error)
+	FindByPartnerID(partnerID uint) ([]model.Outlet, error)
+}
+
+type outletRepository struct {
+	db *gorm.DB
+}
+
+func NewOutletRepository(db *gorm.DB) OutletRepository {
+	return &outletRepository{db: db}
+}
+
+func (r *outletRepository) Create(outlet *model.Outlet) error {
+	return r.db.Create(outlet).Error
+}
+
+func (r *outletRepository) FindByID(id uint) (*model.Outlet, error) {
+	var outlet model.Outlet
+	if err := r.db.First(&outlet, id).Error; err != nil {
+		return nil, err
+	}
+	return &outlet, nil
+}
+
+func (r *outletRepository) FindByPartnerID(partnerID uint) ([]model.Outlet, error) {
+	var outlets []model.Outlet
+	if err := r.db.Where("partner_id = ?", partnerID).Order("id ASC").Find(&outlets).Error; err != nil {
+		return nil, err
+	}
+	return outlets, nil
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"xyz-multifinance/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type PartnerRepository interface {
+	Create(partner *model.Partner) error
+	Update(id uint, fields map[string]interface{}) error
+	FindByID(id uint) (*model.Partner, error)
+	FindByAPIKeyID(keyID string) (*model.Partner, error)
+	FindAll() ([]model.Partner, error)
+}
+
+type partnerRepository struct {
+	db *gorm.DB
+}
+
+func NewPartnerRepository(db *gorm.DB) PartnerRepository {
+	return &partnerRepository{db: db}
+}
+
+func (r *partnerRepository) Create(partner *model.Partner) error {
+	return r.db.Create(partner).Error
+}
+
+func (r *partnerRepository) Update(id uint, fields map[string]interface{}) error {
+	return r.db.Model(&model.Partner{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *partnerRepository) FindByID(id uint) (*model.Partner, error) {
+	var partner model.Partner
+	if err := r.db.First(&partner, id).Error; err != nil {
+		return nil, err
+	}
+	return &partner, nil
+}
+
+func (r *partnerRepository) FindByAPIKeyID(keyID string) (*model.Partner, error) {
+	var partner model.Partner
+	if err := r.db.Where("api_key_id = ?", keyID).First(&partner).Error; err != nil {
+		return nil, err
+	}
+	return &partner, nil
+}
+
+func (r *partnerRepository) FindAll() ([]model.Partner, error) {
+	var partners []model.Partner
+	if err := r.db.Order("id ASC").Find(&partners).Error; err != nil {
+		return nil, err
+	}
+	return partners, nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"time"
+
+	"xyz-multifinance/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentRepository interface {
+	Create(tx *gorm.DB, payment *model.Payment) error
+	FindByReference(reference string) (*model.Payment, error)
+	FindByReferenceForUpdate(tx *gorm.DB, reference string) (*model.Payment, error)
+	FindByTransactionID(transactionID uint) ([]model.Payment, error)
+	FindPostedForUpdate(tx *gorm.DB, transactionID uint) ([]model.Payment, error)
+	MarkReversed(tx *gorm.DB, id uint, reversedAt time.Time) error
+}
+
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) PaymentRepository {
+	return &paymentRepository{db: db}
+}
+
+// Create stores the payment together with its allocations.
+func (r *paymentRepository) Create(tx *gorm.DB, payment *model.Payment) error {
+	return tx.Create(payment).Error
+}
+
+func (r *paymentRepository) FindByReference(reference string) (*model.Payment, error) {
+	var payment model.Payment
+	if err := r.db.Preload("Allocations").Where("reference = ?", reference).First(&payment).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *paymentRepository) FindByReferenceForUpdate(tx *gorm.DB, reference string) (*model.Payment, error) {
+	var payment model.Payment
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Allocations").
+		Where("reference = ?", reference).
+		First(&payment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *paymentRepository) FindByTransactionID(transactionID uint) ([]model.Payment, error) {
+	var payments []model.Payment
+	err := r.db.Preload("Allocations").
+		Where("transaction_id = ?", transactionID).
+		Order("paid_at ASC, id ASC").
+		Find(&payments).Error
+	if err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (r *paymentRepository) FindPostedForUpdate(tx *gorm.DB, transactionID uint) ([]model.Payment, error) {
+	var payments []model.Payment
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Preload("Allocations").
+		Where("transaction_id = ? AND status = ?", transactionID, model.PaymentStatusPosted).
+		Order("paid_at ASC, id ASC").
+		Find(&payments).Error
+	if err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (r *paymentRepository) MarkReversed(tx *gorm.DB, id uint, reversedAt time.Time) error {
+	return tx.Model(&model.Payment{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":      model.PaymentStatusReversed,
+		"reversed_at": reversedAt,
+	}).Error
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"time"
+
+	"xyz-multifinance/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type PrivacyRequestRepository interface {
+	Create(request *model.PrivacyRequest) error
+	Update(id uint, fields map[string]interface{}) error
+	FindByID(id uint) (*model.PrivacyRequest, error)
+	FindByCustomerID(customerID uint) ([]model.PrivacyRequest, error)
+	FindPending(limit int) ([]model.PrivacyRequest, error)
+	FindExpiredExports(now time.Time, limit int) ([]model.PrivacyRequest, error)
+	FindResultKeys() ([]string, error)
+	Claim(id uint) (bool, error)
+	ExpireExports(tx *gorm.DB, customerID uint) error
+}
+
+type privacyRequestRepository struct {
+	db *gorm.DB
+}
+
+func NewPrivacyRequestRepository(db *gorm.DB) PrivacyRequestRepository {
+	return &privacyRequestRepository{db: db}
+}
+
+func (r *privacyRequestRepository) Create(request *model.PrivacyRequest) error {
+	return r.db.Create(request).Error
+}
+
+func (r *privacyRequestRepository) Update(id uint, fields map[string]interface{}) error {
+	return r.db.Model(&model.PrivacyRequest{}).Where("id = ?", id).Updates(fields).Error
+}
+
+func (r *privacyRequestRepository) FindByID(id uint) (*model.PrivacyRequest, error) {
+	var request model.PrivacyRequest
+	if err := r.db.First(&request, id).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *privacyRequestRepository) FindByCustomerID(customerID uint) ([]model.PrivacyRequest, error) {
+	var requests []model.PrivacyRequest
+	if err := r.db.Where("customer_id = ?", customerID).Order("id DESC").Find(&requests).Error; err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// FindPending returns requests waiting to be processed, oldest first.
+func (r *privacyRequestRepository) FindPending(limit int) ([]model.PrivacyRequest, error) {
+	var requests []model.PrivacyRequest
+	err := r.db.Where("status = ?", model.PrivacyStatusPending).Order("id ASC").Limit(limit).Find(&requests).Error
+	if err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// FindExpiredExports returns completed exports whose archive is past its
+// retention period.
+func (r *privacyRequestRepository) FindExpiredExports(now time.Time, limit int) ([]model.PrivacyRequest, error) {
+	var requests []model.PrivacyRequest
+	err := r.db.
+		Where("type = ? AND status = ? AND expires_at <= ?", model.PrivacyRequestExport, model.PrivacyStatusCompleted, now).
+		Order("id ASC").
+		Limit(limit).
+		Find(&requests).Error
+	if err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// FindResultKeys returns the blob store keys of every export archive still
+// on file.
+func (r *privacyRequestRepository) FindResultKeys() ([]string, error) {
+	var keys []string
+	err := r.db.Model(&model.PrivacyRequest{}).Where("result_key <> ''").Pluck("result_key", &keys).Error
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// Claim moves a pending request to processing. It reports false when
+// another worker got there first.
+func (r *privacyRequestRepository) Claim(id uint) (bool, error) {
+	result := r.db.Model(&model.PrivacyRequest{}).
+		Where("id = ? AND status = ?", id, model.PrivacyStatusPending).
+		Update("status", model.PrivacyStatusProcessing)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ExpireExports marks every export archive of a customer as gone.
+func (r *privacyRequestRepository) ExpireExports(tx *gorm.DB, customerID uint) error {
+	return tx.Model(&model.PrivacyRequest{}).
+		Where("customer_id = ? AND type = ? AND result_key <> ''", customerID, model.PrivacyRequestExport).
+		Updates(map[string]interface{}{"status": model.PrivacyStatusExpired, "result_key": ""}).Error
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"xyz-multifinance/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReconciliationRepository interface {
+	CreateImport(tx *gorm.DB, statementImport *model.StatementImport) error
+	FindImports() ([]model.StatementImport, error)
+	FindImportByID(id uint) (*model.StatementImport, error)
+	FindLine(id uint) (*model.StatementLine, error)
+	FindLineForUpdate(tx *gorm.DB, id uint) (*model.StatementLine, error)
+	SaveLine(tx *gorm.DB, line *model.StatementLine) error
+	FindQueue() ([]model.StatementLine, error)
+	Summarize(importID uint) ([]StatusTotal, error)
+}
+
+// StatusTotal counts the lines of an import in one status.
+type StatusTotal struct {
+	Status    string `json:"status"`
+	LineCount int    `json:"lines"`
+	Amount    int64  `json:"amount"`
+}
+
+type reconciliationRepository struct {
+	db *gorm.DB
+}
+
+func NewReconciliationRepository(db *gorm.DB) ReconciliationRepository {
+	return &reconciliationRepository{db: db}
+}
+
+// CreateImport stores the import together with its lines.
+func (r *reconciliationRepository) CreateImport(tx *gorm.DB, statementImport *model.StatementImport) error {
+	return tx.Create(statementImport).Error
+}
+
+func (r *reconciliationRepository) FindImports() ([]model.StatementImport, error) {
+	var imports []model.StatementImport
+	if err := r.db.Order("id DESC").Find(&imports).Error; err != nil {
+		return nil, err
+	}
+	return imports, nil
+}
+
+func (r *reconciliationRepository) FindImportByID(id uint) (*model.StatementImport, error) {
+	var statementImport model.StatementImport
+	err := r.db.Preload("Lines", func(db *gorm.DB) *gorm.DB {
+		return db.Order("line_number ASC")
+	}).First(&statementImport, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &statementImport, nil
+}
+
+func (r *reconciliationRepository) FindLine(id uint) (*model.StatementLine, error) {
+	var line model.StatementLine
+	if err := r.db.First(&line, id).Error; err != nil {
+		return nil, err
+	}
+	return &line, nil
+}
+
+func (r *reconciliationRepository) FindLineForUpdate(tx *gorm.DB, id uint) (*model.StatementLine, error) {
+	var line model.StatementLine
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&line, id).Error; err != nil {
+		return nil, err
+	}
+	return &line, nil
+}
+
+func (r *reconciliationRepository) SaveLine(tx *gorm.DB, line *model.StatementLine) error {
+	return tx.Save(line).Error
+}
+
+// FindQueue returns the lines waiting for manual matching, oldest first.
+func (r *reconciliationRepository) FindQueue() ([]model.StatementLine, error) {
+	var lines []model.StatementLine
+	err := r.db.Where("status IN ?", model.StatementLineNeedsReview).
+		Order("value_date ASC, id ASC").
+		Find(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+func (r *reconciliationRepository) Summarize(importID uint) ([]StatusTotal, error) {
+	var totals []StatusTotal
+	err := r.db.Model(&model.StatementLine{}).
+		Select("status, COUNT(*) AS line_count, COALESCE(SUM(amount), 0) AS amount").
+		Where("import_id = ?", importID).
+		Group("status").
+		Order("status").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
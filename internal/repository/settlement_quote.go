@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"time"
+
+	"xyz-multifinance/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SettlementQuoteRepository interface {
+	Create(quote *model.SettlementQuote) error
+	FindOpen(transactionID uint, validAt time.Time) (*model.SettlementQuote, error)
+	FindByIDForUpdate(tx *gorm.DB, id uint) (*model.SettlementQuote, error)
+	Save(tx *gorm.DB, quote *model.SettlementQuote) error
+}
+
+type settlementQuoteRepository struct {
+	db *gorm.DB
+}
+
+func NewSettlementQuoteRepository(db *gorm.DB) SettlementQuoteRepository {
+	return &settlementQuoteRepository{db: db}
+}
+
+func (r *settlementQuoteRepository) Create(quote *model.SettlementQuote) error {
+	return r.db.Create(quote).Error
+}
+
+// FindOpen returns the latest open quote of a contract that is still valid at
+// validAt.
+func (r *settlementQuoteRepository) FindOpen(transactionID uint, validAt time.Time) (*model.SettlementQuote, error) {
+	var quote model.SettlementQuote
+	err := r.db.Where("transaction_id = ? AND status = ? AND valid_until > ?", transactionID, model.SettlementQuoteStatusOpen, validAt).
+		Order("id DESC").First(&quote).Error
+	if err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+func (r *settlementQuoteRepository) FindByIDForUpdate(tx *gorm.DB, id uint) (*model.SettlementQuote, error) {
+	var quote model.SettlementQuote
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&quote, id).Error; err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+func (r *settlementQuoteRepository) Save(tx *gorm.DB, quote *model.SettlementQuote) error {
+	return tx.Save(quote).Error
+}
@@ -1,19 +1,44 @@
 package repository
 
 import (
+	"time"
+
 	"xyz-multifinance/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TransactionRepository interface {
 	Create(tx *gorm.DB, transaction *model.Transaction) error
-	Update(tx *gorm.DB, id uint, transaction *model.Transaction) error
-	Delete(id uint) error
+	UpdatePricing(tx *gorm.DB, transaction *model.Transaction) error
 	FindByID(id uint) (*model.Transaction, error)
+	FindByIDForUpdate(tx *gorm.DB, id uint) (*model.Transaction, error)
+	FindByContractNumber(contractNumber string) (*model.Transaction, error)
+	UpdateFields(tx *gorm.DB, id uint, fields map[string]interface{}) error
+	ResetAging(tx *gorm.DB, excludeIDs []uint) error
 	FindByCustomerID(customerID uint) ([]model.Transaction, error)
+	FindByPartnerID(partnerID uint) ([]model.Transaction, error)
 	FindAll() ([]model.Transaction, error)
+	FindActive() ([]model.Transaction, error)
 	SumUsedAmount(customerID uint, tenor int) (int64, error)
+	AggregateExposure(customerID uint, asOf time.Time) ([]ExposureRow, error)
+}
+
+// usedLimitStatuses are the transaction statuses that still consume a customer's limit.
+var usedLimitStatuses = []string{model.TransactionStatusApproved, model.TransactionStatusSuccess, model.TransactionStatusOngoing}
+
+// activeStatuses are the statuses of disbursed contracts that are still being repaid.
+var activeStatuses = []string{model.TransactionStatusSuccess, model.TransactionStatusOngoing}
+
+// ExposureRow is the per-tenor aggregate of a customer's active contracts.
+type ExposureRow struct {
+	Tenor                int        `json:"tenor"`
+	ActiveContracts      int        `json:"active_contracts"`
+	UsedAmount           int64      `json:"used_amount"`
+	OutstandingPrincipal int64      `json:"outstanding_principal"`
+	OverdueAmount        int64      `json:"overdue_amount"`
+	NextDueDate          *time.Time `json:"next_due_date"`
 }
 
 type transactionRepository struct {
@@ -31,32 +56,57 @@ func (r *transactionRepository) Create(tx *gorm.DB, transaction *model.Transacti
 	return nil
 }
 
-func (r *transactionRepository) Update(tx *gorm.DB, id uint, transaction *model.Transaction) error {
-	if err := tx.First(&model.Transaction{}, id).Error; err != nil {
-		return err
-	}
-	transaction.ID = id
-	if err := tx.Save(transaction).Error; err != nil {
-		return err
+// UpdatePricing writes the terms and amounts of a contract, leaving its
+// status, aging and disbursement date untouched.
+func (r *transactionRepository) UpdatePricing(tx *gorm.DB, transaction *model.Transaction) error {
+	return tx.Model(transaction).
+		Select("tenor", "currency", "otr", "down_payment", "principal", "admin_fee",
+			"interest_amount", "installment_amount", "asset_id", "asset_name").
+		Updates(transaction).Error
+}
+
+func (r *transactionRepository) FindByID(id uint) (*model.Transaction, error) {
+	var transaction model.Transaction
+	if err := r.db.First(&transaction, id).Error; err != nil {
+		return nil, err
 	}
-	return nil
+	return &transaction, nil
 }
 
-func (r *transactionRepository) Delete(id uint) error {
-	if err := r.db.Delete(&model.Transaction{}, id).Error; err != nil {
-		return err
+func (r *transactionRepository) FindByContractNumber(contractNumber string) (*model.Transaction, error) {
+	var transaction model.Transaction
+	if err := r.db.Where("contract_number = ?", contractNumber).First(&transaction).Error; err != nil {
+		return nil, err
 	}
-	return nil
+	return &transaction, nil
 }
 
-func (r *transactionRepository) FindByID(id uint) (*model.Transaction, error) {
+func (r *transactionRepository) FindByIDForUpdate(tx *gorm.DB, id uint) (*model.Transaction, error) {
 	var transaction model.Transaction
-	if err := r.db.First(&transaction, id).Error; err != nil {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, id).Error; err != nil {
 		return nil, err
 	}
 	return &transaction, nil
 }
 
+func (r *transactionRepository) UpdateFields(tx *gorm.DB, id uint, fields map[string]interface{}) error {
+	return tx.Model(&model.Transaction{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// ResetAging marks every active contract that is not in excludeIDs as current.
+func (r *transactionRepository) ResetAging(tx *gorm.DB, excludeIDs []uint) error {
+	query := tx.Model(&model.Transaction{}).
+		Where("status IN ? AND days_past_due <> 0", activeStatuses)
+	if len(excludeIDs) > 0 {
+		query = query.Where("id NOT IN ?", excludeIDs)
+	}
+	return query.Updates(map[string]interface{}{
+		"days_past_due":  0,
+		"collectibility": model.CollectibilityCurrent,
+		"aging_bucket":   model.AgingBucketCurrent,
+	}).Error
+}
+
 func (r *transactionRepository) FindByCustomerID(customerID uint) ([]model.Transaction, error) {
 	var transactions []model.Transaction
 	if err := r.db.Where("customer_id = ?", customerID).Find(&transactions).Error; err != nil {
@@ -65,6 +115,14 @@ func (r *transactionRepository) FindByCustomerID(customerID uint) ([]model.Trans
 	return transactions, nil
 }
 
+func (r *transactionRepository) FindByPartnerID(partnerID uint) ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	if err := r.db.Where("partner_id = ?", partnerID).Order("id DESC").Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
 func (r *transactionRepository) FindAll() ([]model.Transaction, error) {
 	var transactions []model.Transaction
 	if err := r.db.Find(&transactions).Error; err != nil {
@@ -73,11 +131,20 @@ func (r *transactionRepository) FindAll() ([]model.Transaction, error) {
 	return transactions, nil
 }
 
+// FindActive returns the contracts that are still being repaid.
+func (r *transactionRepository) FindActive() ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	if err := r.db.Where("status IN ?", activeStatuses).Order("id ASC").Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
 func (r *transactionRepository) SumUsedAmount(customerID uint, tenor int) (int64, error) {
 	var total int64
 	err := r.db.Model(&model.Transaction{}).
-		Where("customer_id = ? AND tenor = ? AND status IN ?", customerID, tenor, []string{"success", "ongoing"}).
-		Select("COALESCE(SUM(installment_amount), 0)").
+		Where("customer_id = ? AND tenor = ? AND status IN ?", customerID, tenor, usedLimitStatuses).
+		Select("COALESCE(SUM(principal), 0)").
 		Scan(&total).Error
 
 	if err != nil {
@@ -85,3 +152,37 @@ func (r *transactionRepository) SumUsedAmount(customerID uint, tenor int) (int64
 	}
 	return total, nil
 }
+
+// AggregateExposure sums outstanding principal, overdue amount and the nearest
+// upcoming due date per tenor in a single query. Payments are applied to
+// interest first, so the outstanding principal of an installment is whatever
+// remains after its interest portion has been covered.
+func (r *transactionRepository) AggregateExposure(customerID uint, asOf time.Time) ([]ExposureRow, error) {
+	var rows []ExposureRow
+	err := r.db.Raw(`
+		SELECT t.tenor,
+			COUNT(*) AS active_contracts,
+			COALESCE(SUM(t.principal), 0) AS used_amount,
+			COALESCE(SUM(s.outstanding_principal), 0) AS outstanding_principal,
+			COALESCE(SUM(s.overdue_amount), 0) AS overdue_amount,
+			MIN(s.next_due_date) AS next_due_date
+		FROM transactions t
+		LEFT JOIN (
+			SELECT transaction_id,
+				SUM(GREATEST(principal - GREATEST(paid_amount - interest, 0), 0)) AS outstanding_principal,
+				SUM(CASE WHEN due_date < ? THEN amount - paid_amount ELSE 0 END) AS overdue_amount,
+				MIN(CASE WHEN due_date >= ? THEN due_date END) AS next_due_date
+			FROM installments
+			WHERE status <> ? AND deleted_at IS NULL
+			GROUP BY transaction_id
+		) s ON s.transaction_id = t.id
+		WHERE t.customer_id = ? AND t.status IN ? AND t.deleted_at IS NULL
+		GROUP BY t.tenor
+		ORDER BY t.tenor`,
+		asOf, asOf, model.InstallmentStatusPaid, customerID, usedLimitStatuses,
+	).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
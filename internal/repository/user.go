@@ -2,6 +2,7 @@ package repository
 
 import (
 	"errors"
+	"fmt"
 	"xyz-multifinance/internal/model"
 
 	"gorm.io/gorm"
@@ -11,6 +12,7 @@ type UserRepository interface {
 	FindByUsername(username string) (*model.User, error)
 	FindByID(id uint) (*model.User, error)
 	Create(user *model.User) error
+	Anonymize(tx *gorm.DB, id uint) error
 }
 
 type userRepository struct {
@@ -45,3 +47,12 @@ func (r *userRepository) FindByID(id uint) (*model.User, error) {
 func (r *userRepository) Create(user *model.User) error {
 	return r.db.Create(user).Error
 }
+
+// Anonymize replaces a user's username and clears the password hash, which
+// no password matches, so the account can no longer be used or traced.
+func (r *userRepository) Anonymize(tx *gorm.DB, id uint) error {
+	return tx.Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"username":      fmt.Sprintf("erased-%d", id),
+		"password_hash": "",
+	}).Error
+}
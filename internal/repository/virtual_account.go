@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"xyz-multifinance/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type VirtualAccountRepository interface {
+	Create(account *model.VirtualAccount) error
+	FindByNumber(number string) (*model.VirtualAccount, error)
+	FindByCustomerID(customerID uint) ([]model.VirtualAccount, error)
+}
+
+type virtualAccountRepository struct {
+	db *gorm.DB
+}
+
+func NewVirtualAccountRepository(db *gorm.DB) VirtualAccountRepository {
+	return &virtualAccountRepository{db: db}
+}
+
+func (r *virtualAccountRepository) Create(account *model.VirtualAccount) error {
+	return r.db.Create(account).Error
+}
+
+func (r *virtualAccountRepository) FindByNumber(number string) (*model.VirtualAccount, error) {
+	var account model.VirtualAccount
+	if err := r.db.Where("number = ?", number).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *virtualAccountRepository) FindByCustomerID(customerID uint) ([]model.VirtualAccount, error) {
+	var accounts []model.VirtualAccount
+	if err := r.db.Where("customer_id = ?", customerID).Order("id ASC").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
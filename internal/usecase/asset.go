@@ -0,0 +1,255 @@
+package usecase
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type AssetUsecase interface {
+	CreateAsset(asset *model.Asset) error
+	UpdateAsset(id uint, fields map[string]interface{}) error
+	DeleteAsset(id uint) error
+	GetAssetByID(id uint) (*model.Asset, error)
+	GetAssets(category string) ([]model.Asset, error)
+	ImportCSV(r io.Reader) (*AssetImportResult, error)
+}
+
+type assetUsecase struct {
+	assetRepo repository.AssetRepository
+}
+
+func NewAssetUsecase(assetRepo repository.AssetRepository) AssetUsecase {
+	return &assetUsecase{assetRepo: assetRepo}
+}
+
+type AssetImportError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+type AssetImportResult struct {
+	Imported int                `json:"imported"`
+	Errors   []AssetImportError `json:"errors"`
+}
+
+// catalogKey identifies an entry the way the unique catalog index does, which
+// compares brand and model case-insensitively.
+func catalogKey(asset model.Asset) string {
+	return strings.ToLower(fmt.Sprintf("%s|%s|%s|%d", asset.Category, asset.Brand, asset.Model, asset.Year))
+}
+
+// deletedAssets returns the soft-deleted entries by catalog key. They keep
+// their place in the unique index, so a new entry with the same key collides
+// with them.
+func (uc *assetUsecase) deletedAssets() (map[string]model.Asset, error) {
+	assets, err := uc.assetRepo.FindDeleted()
+	if err != nil {
+		return nil, err
+	}
+	deleted := make(map[string]model.Asset, len(assets))
+	for _, a := range assets {
+		deleted[catalogKey(a)] = a
+	}
+	return deleted, nil
+}
+
+func validateAsset(asset *model.Asset) error {
+	if !model.IsValidAssetCategory(asset.Category) {
+		return errors.New("category must be one of white_goods, motorcycle, car")
+	}
+	if asset.Brand == "" || asset.Model == "" {
+		return errors.New("brand and model are required")
+	}
+	if asset.Year < 1900 || asset.Year > time.Now().Year()+1 {
+		return errors.New("year is out of range")
+	}
+	if asset.OTRPrice <= 0 {
+		return errors.New("otr_price must be greater than zero")
+	}
+	return nil
+}
+
+func (uc *assetUsecase) CreateAsset(asset *model.Asset) error {
+	if err := validateAsset(asset); err != nil {
+		return err
+	}
+
+	deleted, err := uc.deletedAssets()
+	if err != nil {
+		return err
+	}
+	// Creating an entry that was deleted brings the original back, so the
+	// transactions that reference it point at a live entry again.
+	if d, ok := deleted[catalogKey(*asset)]; ok {
+		if err := uc.assetRepo.Restore(d.ID, asset.OTRPrice); err != nil {
+			return err
+		}
+		restored, err := uc.assetRepo.FindByID(d.ID)
+		if err != nil {
+			return err
+		}
+		*asset = *restored
+		return nil
+	}
+
+	asset.CreatedAt = time.Now()
+	asset.UpdatedAt = time.Now()
+	err = uc.assetRepo.Create(asset)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return errors.New("asset already exists in the catalog")
+	}
+	return err
+}
+
+func (uc *assetUsecase) UpdateAsset(id uint, fields map[string]interface{}) error {
+	existing, err := uc.assetRepo.FindByID(id)
+	if err != nil {
+		return errors.New("asset not found")
+	}
+
+	updated := *existing
+	if v, ok := fields["category"].(string); ok {
+		updated.Category = v
+	}
+	if v, ok := fields["brand"].(string); ok {
+		updated.Brand = v
+	}
+	if v, ok := fields["model"].(string); ok {
+		updated.Model = v
+	}
+	if v, ok := fields["year"].(float64); ok {
+		updated.Year = int(v)
+	}
+	if v, ok := fields["otr_price"].(float64); ok {
+		updated.OTRPrice = int64(v)
+	}
+	if err := validateAsset(&updated); err != nil {
+		return err
+	}
+	if catalogKey(updated) != catalogKey(*existing) {
+		deleted, err := uc.deletedAssets()
+		if err != nil {
+			return err
+		}
+		if _, ok := deleted[catalogKey(updated)]; ok {
+			return errors.New("a deleted asset has the same category, brand, model and year; create it again to restore it")
+		}
+	}
+
+	fields["updated_at"] = time.Now()
+	err = uc.assetRepo.Update(id, fields)
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return errors.New("asset already exists in the catalog")
+	}
+	return err
+}
+
+func (uc *assetUsecase) DeleteAsset(id uint) error {
+	if _, err := uc.assetRepo.FindByID(id); err != nil {
+		return errors.New("asset not found")
+	}
+	return uc.assetRepo.Delete(id)
+}
+
+func (uc *assetUsecase) GetAssetByID(id uint) (*model.Asset, error) {
+	return uc.assetRepo.FindByID(id)
+}
+
+func (uc *assetUsecase) GetAssets(category string) ([]model.Asset, error) {
+	return uc.assetRepo.FindAll(category)
+}
+
+// ImportCSV loads catalog entries from a CSV with the header
+// category,brand,model,year,otr_price. Valid rows are upserted; invalid rows
+// and rows of deleted entries are reported back with their line number and
+// skipped, so an import never undoes a deletion.
+func (uc *assetUsecase) ImportCSV(r io.Reader) (*AssetImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("csv file is empty")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"category", "brand", "model", "year", "otr_price"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing column: %s", required)
+		}
+	}
+
+	deleted, err := uc.deletedAssets()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AssetImportResult{Errors: []AssetImportError{}}
+	var assets []model.Asset
+	line := 1
+	now := time.Now()
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			result.Errors = append(result.Errors, AssetImportError{Line: line, Message: err.Error()})
+			continue
+		}
+
+		field := func(name string) string {
+			return strings.TrimSpace(record[columns[name]])
+		}
+
+		year, err := strconv.Atoi(field("year"))
+		if err != nil {
+			result.Errors = append(result.Errors, AssetImportError{Line: line, Message: "invalid year"})
+			continue
+		}
+		price, err := strconv.ParseInt(field("otr_price"), 10, 64)
+		if err != nil {
+			result.Errors = append(result.Errors, AssetImportError{Line: line, Message: "invalid otr_price"})
+			continue
+		}
+
+		asset := model.Asset{
+			Category:  strings.ToLower(field("category")),
+			Brand:     field("brand"),
+			Model:     field("model"),
+			Year:      year,
+			OTRPrice:  price,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := validateAsset(&asset); err != nil {
+			result.Errors = append(result.Errors, AssetImportError{Line: line, Message: err.Error()})
+			continue
+		}
+		if _, ok := deleted[catalogKey(asset)]; ok {
+			result.Errors = append(result.Errors, AssetImportError{Line: line, Message: "asset was deleted; create it again to restore it"})
+			continue
+		}
+		assets = append(assets, asset)
+	}
+
+	if err := uc.assetRepo.Upsert(assets); err != nil {
+		return nil, err
+	}
+	result.Imported = len(assets)
+
+	return result, nil
+}
@@ -0,0 +1,123 @@
+package usecase_test
+
+import (
+	"strings"
+	"testing"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/internal/usecase"
+
+	"gorm.io/gorm"
+)
+
+type mockAssetRepo struct {
+	repository.AssetRepository
+	deleted  []model.Asset
+	restored map[uint]int64
+	created  []model.Asset
+	upserted []model.Asset
+}
+
+func (m *mockAssetRepo) FindDeleted() ([]model.Asset, error) {
+	return m.deleted, nil
+}
+
+func (m *mockAssetRepo) FindByID(id uint) (*model.Asset, error) {
+	for _, a := range m.deleted {
+		if a.ID == id {
+			a.OTRPrice = m.restored[id]
+			return &a, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockAssetRepo) Restore(id uint, otrPrice int64) error {
+	if m.restored == nil {
+		m.restored = make(map[uint]int64)
+	}
+	m.restored[id] = otrPrice
+	return nil
+}
+
+func (m *mockAssetRepo) Create(asset *model.Asset) error {
+	m.created = append(m.created, *asset)
+	return nil
+}
+
+func (m *mockAssetRepo) Upsert(assets []model.Asset) error {
+	m.upserted = append(m.upserted, assets...)
+	return nil
+}
+
+func TestImportCSV_ReportsInvalidRows(t *testing.T) {
+	repo := &mockAssetRepo{}
+	uc := usecase.NewAssetUsecase(repo)
+
+	csv := strings.Join([]string{
+		"category,brand,model,year,otr_price",
+		"motorcycle,Yamaha,NMAX,2024,32000000",
+		"car,Toyota,Avanza,2023,250000000",
+		"boat,Yamaha,Jet Ski,2024,150000000",
+		"white_goods,LG,Kulkas 2 Pintu,abc,5000000",
+	}, "\n")
+
+	result, err := uc.ImportCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if result.Imported != 2 || len(repo.upserted) != 2 {
+		t.Errorf("expected 2 imported assets, got %d", result.Imported)
+	}
+	if len(result.Errors) != 2 || result.Errors[0].Line != 4 || result.Errors[1].Line != 5 {
+		t.Errorf("expected errors on lines 4 and 5, got %+v", result.Errors)
+	}
+}
+
+func TestImportCSV_SkipsDeletedAssets(t *testing.T) {
+	repo := &mockAssetRepo{deleted: []model.Asset{{ID: 4, Category: "car", Brand: "Toyota", Model: "Avanza", Year: 2023}}}
+	uc := usecase.NewAssetUsecase(repo)
+
+	csv := strings.Join([]string{
+		"category,brand,model,year,otr_price",
+		"motorcycle,Yamaha,NMAX,2024,32000000",
+		"car,TOYOTA,avanza,2023,250000000",
+	}, "\n")
+
+	result, err := uc.ImportCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if result.Imported != 1 || len(repo.upserted) != 1 || repo.upserted[0].Brand != "Yamaha" {
+		t.Errorf("upserted = %+v, want only the live entry", repo.upserted)
+	}
+	if len(result.Errors) != 1 || result.Errors[0].Line != 3 || !strings.Contains(result.Errors[0].Message, "deleted") {
+		t.Errorf("errors = %+v, want line 3 reported as deleted", result.Errors)
+	}
+}
+
+func TestCreateAsset_RestoresDeletedEntry(t *testing.T) {
+	repo := &mockAssetRepo{deleted: []model.Asset{{ID: 4, Category: "car", Brand: "Toyota", Model: "Avanza", Year: 2023, OTRPrice: 240_000_000}}}
+	uc := usecase.NewAssetUsecase(repo)
+
+	asset := &model.Asset{Category: "car", Brand: "Toyota", Model: "Avanza", Year: 2023, OTRPrice: 250_000_000}
+	if err := uc.CreateAsset(asset); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if asset.ID != 4 || asset.OTRPrice != 250_000_000 || repo.restored[4] != 250_000_000 {
+		t.Errorf("asset = %+v, restored = %v, want entry 4 back at the new price", asset, repo.restored)
+	}
+	if len(repo.created) != 0 {
+		t.Errorf("created = %+v, want no new entry", repo.created)
+	}
+}
+
+func TestImportCSV_MissingColumn(t *testing.T) {
+	uc := usecase.NewAssetUsecase(&mockAssetRepo{})
+
+	_, err := uc.ImportCSV(strings.NewReader("category,brand,model,year\nmotorcycle,Honda,Beat,2024\n"))
+	if err == nil || err.Error() != "missing column: otr_price" {
+		t.Errorf("expected missing column error, got %v", err)
+	}
+}
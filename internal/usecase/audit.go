@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/logger"
+	"xyz-multifinance/pkg/audit"
+)
+
+// AuditEvent describes one state change. Before is nil for creations and
+// After is nil for deletions. RedactFields lists fields whose values must not
+// reach the log; only the fact that they changed is kept.
+type AuditEvent struct {
+	Entity       string
+	EntityID     string
+	Action       string
+	Before       interface{}
+	After        interface{}
+	RedactFields []string
+}
+
+// Auditor records state changes in the audit log, attributed to the actor
+// carried by ctx.
+type Auditor interface {
+	Record(ctx context.Context, event AuditEvent) error
+}
+
+type AuditUsecase interface {
+	Auditor
+	Query(filter repository.AuditLogFilter) ([]model.AuditLog, error)
+	// Verify walks the whole chain and reports the first entry whose hash
+	// does not match its content or its predecessor.
+	Verify(ctx context.Context) (*AuditVerification, error)
+}
+
+type AuditVerification struct {
+	Valid    bool   `json:"valid"`
+	Entries  int    `json:"entries"`
+	BrokenAt uint   `json:"broken_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	LastHash string `json:"last_hash"`
+}
+
+const (
+	auditQueryDefaultLimit = 100
+	auditQueryMaxLimit     = 500
+	auditVerifyBatchSize   = 500
+)
+
+type auditUsecase struct {
+	auditRepo repository.AuditLogRepository
+	now       func() time.Time
+}
+
+func NewAuditUsecase(auditRepo repository.AuditLogRepository) AuditUsecase {
+	return &auditUsecase{
+		auditRepo: auditRepo,
+		now:       time.Now,
+	}
+}
+
+func (uc *auditUsecase) Record(ctx context.Context, event AuditEvent) error {
+	changes, err := audit.Diff(event.Before, event.After, event.RedactFields...)
+	if err != nil {
+		return fmt.Errorf("failed to diff %s %s: %w", event.Entity, event.EntityID, err)
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	// Without an actor the change was made by the application itself, e.g.
+	// a background job.
+	actor, ok := audit.ActorFrom(ctx)
+	if !ok {
+		actor.Role = audit.SystemRole
+	}
+	entry := &model.AuditLog{
+		RequestID: actor.RequestID,
+		UserID:    actor.UserID,
+		Role:      actor.Role,
+		PartnerID: actor.PartnerID,
+		IPAddress: actor.IP,
+		Entity:    event.Entity,
+		EntityID:  event.EntityID,
+		Action:    event.Action,
+		Changes:   data,
+		CreatedAt: uc.now().Truncate(time.Second),
+	}
+	if err := uc.auditRepo.Append(entry); err != nil {
+		return err
+	}
+	audit.MarkRecorded(ctx)
+	return nil
+}
+
+func (uc *auditUsecase) Query(filter repository.AuditLogFilter) ([]model.AuditLog, error) {
+	if filter.Limit <= 0 || filter.Limit > auditQueryMaxLimit {
+		filter.Limit = auditQueryDefaultLimit
+	}
+	return uc.auditRepo.Find(filter)
+}
+
+func (uc *auditUsecase) Verify(ctx context.Context) (*AuditVerification, error) {
+	// Read the head first: entries appended while the walk runs come after
+	// it and are not checked against it.
+	head, err := uc.auditRepo.FindHead()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AuditVerification{}
+	var afterID uint
+	prevHash := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		entries, err := uc.auditRepo.FindBatch(afterID, auditVerifyBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if head.LastLogID != 0 && entry.ID > head.LastLogID {
+				break
+			}
+			result.Entries++
+			switch {
+			case entry.PrevHash != prevHash:
+				return result.broken(entry.ID, "entry does not follow the previous entry"), nil
+			case entry.Hash != entry.ComputeHash():
+				return result.broken(entry.ID, "entry content does not match its hash"), nil
+			}
+			prevHash = entry.Hash
+			afterID = entry.ID
+		}
+		if len(entries) < auditVerifyBatchSize || afterID >= head.LastLogID {
+			break
+		}
+	}
+
+	if afterID != head.LastLogID || prevHash != head.LastHash {
+		return result.broken(afterID, "log ends before the recorded chain head"), nil
+	}
+	result.Valid = true
+	result.LastHash = prevHash
+	return result, nil
+}
+
+func (v *AuditVerification) broken(id uint, reason string) *AuditVerification {
+	v.BrokenAt = id
+	v.Reason = reason
+	return v
+}
+
+// recordAudit writes an audit entry for a change that has already been
+// committed. A failure is logged rather than returned, since the change
+// itself cannot be undone at that point.
+func recordAudit(ctx context.Context, auditor Auditor, event AuditEvent) {
+	if err := auditor.Record(ctx, event); err != nil {
+		logger.Log.WithError(err).
+			WithField("entity", event.Entity).
+			WithField("entity_id", event.EntityID).
+			Errorf("failed to record audit entry for %s", event.Action)
+	}
+}
@@ -0,0 +1,120 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/pkg/audit"
+)
+
+type mockAuditor struct {
+	events []usecase.AuditEvent
+}
+
+func (m *mockAuditor) Record(ctx context.Context, event usecase.AuditEvent) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+// mockAuditLogRepo keeps the chain in memory the way the database
+// repository does.
+type mockAuditLogRepo struct {
+	repository.AuditLogRepository
+	entries []model.AuditLog
+	head    model.AuditChainHead
+}
+
+func (m *mockAuditLogRepo) Append(entry *model.AuditLog) error {
+	entry.ID = uint(len(m.entries) + 1)
+	entry.PrevHash = m.head.LastHash
+	entry.Hash = entry.ComputeHash()
+	m.entries = append(m.entries, *entry)
+	m.head.LastLogID, m.head.LastHash = entry.ID, entry.Hash
+	return nil
+}
+
+func (m *mockAuditLogRepo) FindBatch(afterID uint, limit int) ([]model.AuditLog, error) {
+	var batch []model.AuditLog
+	for _, entry := range m.entries {
+		if entry.ID > afterID && len(batch) < limit {
+			batch = append(batch, entry)
+		}
+	}
+	return batch, nil
+}
+
+func (m *mockAuditLogRepo) FindHead() (*model.AuditChainHead, error) {
+	head := m.head
+	return &head, nil
+}
+
+func TestAuditRecord_ChainsEntriesAndDetectsTampering(t *testing.T) {
+	repo := &mockAuditLogRepo{}
+	uc := usecase.NewAuditUsecase(repo)
+	ctx := audit.NewContext(context.Background(), audit.Actor{UserID: 1, Role: "admin", IP: "10.0.0.1", RequestID: "req-1"})
+
+	before := &model.Customer{ID: 7, NIK: "3201010101010001", FullName: "Budi", Salary: 5000000}
+	after := &model.Customer{ID: 7, NIK: "3201010101010001", FullName: "Budi Santoso", Salary: 5000000}
+	events := []usecase.AuditEvent{
+		{Entity: model.AuditEntityCustomer, EntityID: "7", Action: model.AuditActionUpdate, Before: before, After: after, RedactFields: []string{"full_name"}},
+		{Entity: model.AuditEntityLimit, EntityID: "3", Action: model.AuditActionCreate, After: &model.Limit{ID: 3, Tenor: 6, Limit: 1000000}},
+		{Entity: model.AuditEntityLimit, EntityID: "3", Action: model.AuditActionDelete, Before: &model.Limit{ID: 3, Tenor: 6, Limit: 1000000}},
+	}
+	for _, event := range events {
+		if err := uc.Record(ctx, event); err != nil {
+			t.Fatalf("record: %v", err)
+		}
+	}
+	if !audit.Recorded(ctx) {
+		t.Error("request not marked as audited")
+	}
+
+	first := repo.entries[0]
+	if first.UserID != 1 || first.Role != "admin" || first.RequestID != "req-1" || first.IPAddress != "10.0.0.1" {
+		t.Errorf("actor not recorded: %+v", first)
+	}
+	var changes map[string]audit.Change
+	if err := json.Unmarshal(first.Changes, &changes); err != nil {
+		t.Fatalf("changes: %v", err)
+	}
+	if len(changes) != 1 || changes["full_name"].To != "[REDACTED]" {
+		t.Errorf("changes = %s", first.Changes)
+	}
+	if strings.Contains(string(first.Changes), "Budi") {
+		t.Errorf("personal data leaked into the log: %s", first.Changes)
+	}
+	if repo.entries[1].PrevHash != first.Hash {
+		t.Error("entries are not chained")
+	}
+
+	result, err := uc.Verify(context.Background())
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if !result.Valid || result.Entries != 3 {
+		t.Fatalf("verification = %+v, want a valid chain of 3", result)
+	}
+
+	repo.entries[1].Changes = json.RawMessage(`{"limit_amount":{"from":null,"to":9000000}}`)
+	result, err = uc.Verify(context.Background())
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if result.Valid || result.BrokenAt != 2 {
+		t.Errorf("edited entry not detected: %+v", result)
+	}
+
+	repo.entries = repo.entries[:1]
+	result, err = uc.Verify(context.Background())
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if result.Valid {
+		t.Errorf("truncated log verified: %+v", result)
+	}
+}
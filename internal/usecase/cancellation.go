@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// CancellationPolicy limits cancellation to the first CoolingOffDays days
+// after a contract was created. Zero disables the window.
+type CancellationPolicy struct {
+	CoolingOffDays int
+}
+
+type CancellationRequest struct {
+	TransactionID uint
+	ReasonCode    string
+	Note          string
+	CancelledBy   uint
+}
+
+type CancellationUsecase interface {
+	// Cancel voids an active contract. Posted payments are reversed and the
+	// contract stops counting against the customer's limit; nothing is
+	// deleted.
+	Cancel(req CancellationRequest) (*model.TransactionCancellation, error)
+	// GetCancellation returns ErrCustomerForbidden unless the viewer is an
+	// admin or the contract's customer.
+	GetCancellation(transactionID uint, viewer Viewer) (*model.TransactionCancellation, error)
+}
+
+type cancellationUsecase struct {
+	cancellationRepo repository.CancellationRepository
+	txRepo           repository.TransactionRepository
+	installmentRepo  repository.InstallmentRepository
+	paymentRepo      repository.PaymentRepository
+	ledgerRepo       repository.LedgerRepository
+	disbursementRepo repository.DisbursementRepository
+	customerRepo     repository.CustomerRepository
+	policy           CancellationPolicy
+	db               *gorm.DB
+}
+
+func NewCancellationUsecase(
+	cancellationRepo repository.CancellationRepository,
+	txRepo repository.TransactionRepository,
+	installmentRepo repository.InstallmentRepository,
+	paymentRepo repository.PaymentRepository,
+	ledgerRepo repository.LedgerRepository,
+	disbursementRepo repository.DisbursementRepository,
+	customerRepo repository.CustomerRepository,
+	policy CancellationPolicy,
+	db *gorm.DB,
+) CancellationUsecase {
+	return &cancellationUsecase{
+		cancellationRepo: cancellationRepo,
+		txRepo:           txRepo,
+		installmentRepo:  installmentRepo,
+		paymentRepo:      paymentRepo,
+		ledgerRepo:       ledgerRepo,
+		disbursementRepo: disbursementRepo,
+		customerRepo:     customerRepo,
+		policy:           policy,
+		db:               db,
+	}
+}
+
+func (uc *cancellationUsecase) Cancel(req CancellationRequest) (*model.TransactionCancellation, error) {
+	if !model.IsValidCancellationReason(req.ReasonCode) {
+		return nil, errors.New("invalid reason_code")
+	}
+
+	cancellation := &model.TransactionCancellation{
+		TransactionID: req.TransactionID,
+		ReasonCode:    req.ReasonCode,
+		Note:          req.Note,
+		CancelledBy:   req.CancelledBy,
+	}
+
+	err := uc.db.Transaction(func(txDB *gorm.DB) error {
+		tx, err := uc.txRepo.FindByIDForUpdate(txDB, req.TransactionID)
+		if err != nil {
+			return errors.New("transaction not found")
+		}
+		if tx.Status == model.TransactionStatusCancelled {
+			return errors.New("transaction already cancelled")
+		}
+		if tx.Status == model.TransactionStatusApproved {
+			if err := uc.cancelDisbursement(txDB, tx.ID); err != nil {
+				return err
+			}
+		} else if !isActiveStatus(tx.Status) {
+			return fmt.Errorf("cannot cancel a %s transaction", tx.Status)
+		}
+
+		now := time.Now()
+		if uc.policy.CoolingOffDays > 0 && now.After(tx.CreatedAt.AddDate(0, 0, uc.policy.CoolingOffDays)) {
+			return fmt.Errorf("cooling-off period of %d days has ended", uc.policy.CoolingOffDays)
+		}
+
+		payments, err := uc.paymentRepo.FindPostedForUpdate(txDB, tx.ID)
+		if err != nil {
+			return err
+		}
+		installments, err := uc.installmentRepo.FindAllForUpdate(txDB, tx.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := reverseAllocations(payments, installments); err != nil {
+			return err
+		}
+		for i := range installments {
+			if err := uc.installmentRepo.Save(txDB, &installments[i]); err != nil {
+				return err
+			}
+		}
+		for _, payment := range payments {
+			if err := uc.paymentRepo.MarkReversed(txDB, payment.ID, now); err != nil {
+				return err
+			}
+			cancellation.ReversedPayments++
+			cancellation.ReversedAmount += payment.Amount
+		}
+
+		balances, err := uc.ledgerRepo.ContractBalances(txDB, tx.ID)
+		if err != nil {
+			return err
+		}
+		entry := reversalEntry(tx.ID, balances, now, fmt.Sprintf("Cancellation of contract %s (%s)", tx.ContractNumber, req.ReasonCode))
+		if err := postEntry(txDB, uc.ledgerRepo, entry); err != nil {
+			return err
+		}
+
+		fields := agingFields(0)
+		fields["status"] = model.TransactionStatusCancelled
+		if err := uc.txRepo.UpdateFields(txDB, tx.ID, fields); err != nil {
+			return err
+		}
+
+		cancellation.PreviousStatus = tx.Status
+		return uc.cancellationRepo.Create(txDB, cancellation)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cancellation, nil
+}
+
+// cancelDisbursement stops the payout of a contract that has not been
+// activated yet. A payout already with the provider cannot be recalled.
+func (uc *cancellationUsecase) cancelDisbursement(txDB *gorm.DB, transactionID uint) error {
+	d, err := uc.disbursementRepo.FindByTransactionIDForUpdate(txDB, transactionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if d.Status != model.DisbursementStatusPending && d.Status != model.DisbursementStatusFailed {
+		return fmt.Errorf("cannot cancel a contract whose disbursement is %s", d.Status)
+	}
+
+	d.Status = model.DisbursementStatusCancelled
+	d.NextAttemptAt = nil
+	return uc.disbursementRepo.Save(txDB, d)
+}
+
+func (uc *cancellationUsecase) GetCancellation(transactionID uint, viewer Viewer) (*model.TransactionCancellation, error) {
+	tx, err := uc.txRepo.FindByID(transactionID)
+	if err != nil {
+		return nil, errors.New("transaction not found")
+	}
+	customer, err := uc.customerRepo.FindByID(tx.CustomerID)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+	if err := authorizeCustomer(customer, viewer); err != nil {
+		return nil, err
+	}
+
+	cancellation, err := uc.cancellationRepo.FindByTransactionID(transactionID)
+	if err != nil {
+		return nil, errors.New("cancellation not found")
+	}
+	return cancellation, nil
+}
+
+// reverseAllocations takes every allocation of the given payments back off
+// the installments they were applied to.
+func reverseAllocations(payments []model.Payment, installments []model.Installment) error {
+	byID := make(map[uint]*model.Installment, len(installments))
+	for i := range installments {
+		byID[installments[i].ID] = &installments[i]
+	}
+
+	for _, payment := range payments {
+		for _, allocation := range payment.Allocations {
+			inst, ok := byID[allocation.InstallmentID]
+			if !ok {
+				return fmt.Errorf("payment %d allocated to unknown installment %d", payment.ID, allocation.InstallmentID)
+			}
+			inst.LateFeePaid -= allocation.LateFee
+			inst.PaidAmount -= allocation.Interest + allocation.Principal
+		}
+	}
+
+	for i := range installments {
+		inst := &installments[i]
+		switch {
+		case inst.PaidAmount == 0 && inst.LateFeePaid == 0:
+			inst.Status = model.InstallmentStatusUnpaid
+			inst.PaidAt = nil
+		case inst.Outstanding() > 0:
+			inst.Status = model.InstallmentStatusPartial
+			inst.PaidAt = nil
+		}
+	}
+	return nil
+}
@@ -0,0 +1,263 @@
+package usecase_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/internal/usecase"
+
+	"gorm.io/gorm"
+)
+
+func TestReverseAllocations(t *testing.T) {
+	paidAt := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	installments := func() []model.Installment {
+		return []model.Installment{
+			{ID: 1, Amount: 1000, Interest: 200, PaidAmount: 1000, LateFee: 50, LateFeePaid: 50, PaidAt: &paidAt, Status: model.InstallmentStatusPaid},
+			{ID: 2, Amount: 1000, Interest: 200, PaidAmount: 300, Status: model.InstallmentStatusPartial},
+		}
+	}
+	first := model.Payment{ID: 1, Allocations: []model.PaymentAllocation{{InstallmentID: 1, LateFee: 50, Interest: 200, Principal: 800}}}
+	second := model.Payment{ID: 2, Allocations: []model.PaymentAllocation{{InstallmentID: 2, Interest: 200, Principal: 100}}}
+	topUp := model.Payment{ID: 3, Allocations: []model.PaymentAllocation{{InstallmentID: 1, Principal: 400}}}
+
+	cases := []struct {
+		name       string
+		payments   []model.Payment
+		wantPaid   []int64
+		wantStatus []string
+	}{
+		{"every payment", []model.Payment{first, second}, []int64{0, 0}, []string{model.InstallmentStatusUnpaid, model.InstallmentStatusUnpaid}},
+		{"part of a paid installment", []model.Payment{topUp}, []int64{600, 300}, []string{model.InstallmentStatusPartial, model.InstallmentStatusPartial}},
+		{"nothing", nil, []int64{1000, 300}, []string{model.InstallmentStatusPaid, model.InstallmentStatusPartial}},
+	}
+	for _, tc := range cases {
+		got := installments()
+		if err := usecase.ReverseAllocations(tc.payments, got); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		for i, inst := range got {
+			if inst.PaidAmount != tc.wantPaid[i] || inst.Status != tc.wantStatus[i] {
+				t.Errorf("%s: installment %d = paid %d %s, want paid %d %s", tc.name, inst.ID, inst.PaidAmount, inst.Status, tc.wantPaid[i], tc.wantStatus[i])
+			}
+			if (inst.Status == model.InstallmentStatusPaid) != (inst.PaidAt != nil) {
+				t.Errorf("%s: installment %d is %s with paid_at %v", tc.name, inst.ID, inst.Status, inst.PaidAt)
+			}
+		}
+	}
+
+	stray := model.Payment{ID: 4, Allocations: []model.PaymentAllocation{{InstallmentID: 9, Principal: 100}}}
+	if err := usecase.ReverseAllocations([]model.Payment{stray}, installments()); err == nil || !strings.Contains(err.Error(), "unknown installment 9") {
+		t.Errorf("err = %v, want the unknown installment reported", err)
+	}
+}
+
+type mockCancellationRepo struct {
+	repository.CancellationRepository
+	created *model.TransactionCancellation
+}
+
+func (m *mockCancellationRepo) FindByTransactionID(transactionID uint) (*model.TransactionCancellation, error) {
+	if m.created == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return m.created, nil
+}
+
+func (m *mockCancellationRepo) Create(db *gorm.DB, cancellation *model.TransactionCancellation) error {
+	m.created = cancellation
+	return nil
+}
+
+type mockCancelInstallmentRepo struct {
+	mockPayInstallmentRepo
+}
+
+func (m *mockCancelInstallmentRepo) FindAllForUpdate(db *gorm.DB, transactionID uint) ([]model.Installment, error) {
+	return append([]model.Installment(nil), m.installments...), nil
+}
+
+type mockCancelPaymentRepo struct {
+	repository.PaymentRepository
+	payments []model.Payment
+	reversed []uint
+}
+
+func (m *mockCancelPaymentRepo) FindPostedForUpdate(db *gorm.DB, transactionID uint) ([]model.Payment, error) {
+	return m.payments, nil
+}
+
+func (m *mockCancelPaymentRepo) MarkReversed(db *gorm.DB, id uint, reversedAt time.Time) error {
+	m.reversed = append(m.reversed, id)
+	return nil
+}
+
+type mockCancelLedgerRepo struct {
+	mockJournalRepo
+	balances map[string]int64
+}
+
+func (m *mockCancelLedgerRepo) ContractBalances(db *gorm.DB, transactionID uint) (map[string]int64, error) {
+	return m.balances, nil
+}
+
+type cancellationFixture struct {
+	uc               usecase.CancellationUsecase
+	cancellationRepo *mockCancellationRepo
+	txRepo           *mockPayTxRepo
+	installmentRepo  *mockCancelInstallmentRepo
+	paymentRepo      *mockCancelPaymentRepo
+	ledgerRepo       *mockCancelLedgerRepo
+	disbursementRepo *mockUpdateDisbursementRepo
+}
+
+// newCancellationFixture builds an ongoing contract created createdDaysAgo
+// with one installment fully paid by a single payment.
+func newCancellationFixture(t *testing.T, status string, createdDaysAgo, coolingOffDays int) *cancellationFixture {
+	paidAt := time.Now().AddDate(0, 0, -1)
+	f := &cancellationFixture{
+		cancellationRepo: &mockCancellationRepo{},
+		txRepo: &mockPayTxRepo{tx: model.Transaction{
+			ID: 7, ContractNumber: "CN-7", Status: status, CreatedAt: time.Now().AddDate(0, 0, -createdDaysAgo),
+		}},
+		installmentRepo: &mockCancelInstallmentRepo{mockPayInstallmentRepo{installments: []model.Installment{
+			{ID: 1, TransactionID: 7, Amount: 1000, Interest: 200, PaidAmount: 1000, PaidAt: &paidAt, Status: model.InstallmentStatusPaid},
+			{ID: 2, TransactionID: 7, Amount: 1000, Interest: 200, Status: model.InstallmentStatusUnpaid},
+		}}},
+		paymentRepo: &mockCancelPaymentRepo{payments: []model.Payment{
+			{ID: 5, TransactionID: 7, Amount: 1000, Allocations: []model.PaymentAllocation{{InstallmentID: 1, Interest: 200, Principal: 800}}},
+		}},
+		ledgerRepo: &mockCancelLedgerRepo{balances: map[string]int64{
+			model.AccountLoanReceivable:     800,
+			model.AccountInterestReceivable: 200,
+			model.AccountUnearnedInterest:   -400,
+			model.AccountCash:               -600,
+		}},
+		disbursementRepo: &mockUpdateDisbursementRepo{d: model.Disbursement{ID: 9, TransactionID: 7, Status: model.DisbursementStatusPending}},
+	}
+	f.uc = usecase.NewCancellationUsecase(
+		f.cancellationRepo, f.txRepo, f.installmentRepo, f.paymentRepo, f.ledgerRepo, f.disbursementRepo, customersOwnedBy(11),
+		usecase.CancellationPolicy{CoolingOffDays: coolingOffDays}, newTestDB(t),
+	)
+	return f
+}
+
+func TestCancel_ReversesPaymentsAndVoidsContract(t *testing.T) {
+	f := newCancellationFixture(t, model.TransactionStatusOngoing, 2, 14)
+
+	cancellation, err := f.uc.Cancel(usecase.CancellationRequest{TransactionID: 7, ReasonCode: model.CancellationReasonCustomerRequest, CancelledBy: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cancellation.ReversedPayments != 1 || cancellation.ReversedAmount != 1000 || cancellation.PreviousStatus != model.TransactionStatusOngoing {
+		t.Errorf("cancellation = %+v, want one payment of 1000 reversed from ongoing", cancellation)
+	}
+	if f.cancellationRepo.created != cancellation {
+		t.Error("cancellation was not stored")
+	}
+	if len(f.paymentRepo.reversed) != 1 || f.paymentRepo.reversed[0] != 5 {
+		t.Errorf("reversed payments = %v, want [5]", f.paymentRepo.reversed)
+	}
+	for _, inst := range f.installmentRepo.saved {
+		if inst.PaidAmount != 0 || inst.PaidAt != nil || inst.Status != model.InstallmentStatusUnpaid {
+			t.Errorf("installment %d = %+v, want it unpaid again", inst.ID, inst)
+		}
+	}
+	if len(f.installmentRepo.saved) != 2 {
+		t.Errorf("saved %d installments, want 2", len(f.installmentRepo.saved))
+	}
+
+	if len(f.ledgerRepo.entries) != 1 {
+		t.Fatalf("posted %d entries, want the reversal", len(f.ledgerRepo.entries))
+	}
+	entry := f.ledgerRepo.entries[0]
+	if entry.Type != model.JournalTypeCancellation || entry.Validate() != nil {
+		t.Errorf("entry = %+v, want a balanced cancellation", entry)
+	}
+	net := make(map[string]int64)
+	for _, line := range entry.Lines {
+		net[line.AccountCode] += line.Debit - line.Credit
+	}
+	for code, balance := range f.ledgerRepo.balances {
+		if net[code] != -balance {
+			t.Errorf("account %s moved by %d, want %d", code, net[code], -balance)
+		}
+	}
+
+	if f.txRepo.fields["status"] != model.TransactionStatusCancelled || f.txRepo.fields["days_past_due"] != 0 {
+		t.Errorf("transaction fields = %v, want cancelled and current", f.txRepo.fields)
+	}
+	if f.disbursementRepo.saved != nil {
+		t.Error("the disbursement of an active contract was touched")
+	}
+}
+
+func TestCancel_StopsPendingDisbursement(t *testing.T) {
+	f := newCancellationFixture(t, model.TransactionStatusApproved, 0, 14)
+	f.paymentRepo.payments = nil
+
+	if _, err := f.uc.Cancel(usecase.CancellationRequest{TransactionID: 7, ReasonCode: model.CancellationReasonDuplicate}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if f.disbursementRepo.saved == nil || f.disbursementRepo.saved.Status != model.DisbursementStatusCancelled {
+		t.Errorf("disbursement = %+v, want it cancelled", f.disbursementRepo.saved)
+	}
+}
+
+func TestCancel_Refusals(t *testing.T) {
+	cases := []struct {
+		name               string
+		status             string
+		disbursementStatus string
+		createdDaysAgo     int
+		coolingOffDays     int
+		reason             string
+		wantErr            string
+	}{
+		{"cooling-off ended", model.TransactionStatusOngoing, "", 15, 14, model.CancellationReasonCustomerRequest, "cooling-off period of 14 days has ended"},
+		{"already cancelled", model.TransactionStatusCancelled, "", 0, 14, model.CancellationReasonFraud, "already cancelled"},
+		{"closed contract", model.TransactionStatusClosed, "", 0, 14, model.CancellationReasonFraud, "cannot cancel a closed transaction"},
+		{"payout in flight", model.TransactionStatusApproved, model.DisbursementStatusProcessing, 0, 14, model.CancellationReasonFraud, "disbursement is processing"},
+		{"unknown reason", model.TransactionStatusOngoing, "", 0, 14, "changed_mind", "invalid reason_code"},
+	}
+	for _, tc := range cases {
+		f := newCancellationFixture(t, tc.status, tc.createdDaysAgo, tc.coolingOffDays)
+		if tc.disbursementStatus != "" {
+			f.disbursementRepo.d.Status = tc.disbursementStatus
+		}
+		_, err := f.uc.Cancel(usecase.CancellationRequest{TransactionID: 7, ReasonCode: tc.reason})
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("%s: err = %v, want %q", tc.name, err, tc.wantErr)
+		}
+		if f.cancellationRepo.created != nil || f.paymentRepo.reversed != nil || f.ledgerRepo.entries != nil || f.txRepo.fields != nil {
+			t.Errorf("%s: contract was cancelled", tc.name)
+		}
+	}
+}
+
+func TestCancel_WithoutCoolingOffWindow(t *testing.T) {
+	f := newCancellationFixture(t, model.TransactionStatusOngoing, 400, 0)
+
+	if _, err := f.uc.Cancel(usecase.CancellationRequest{TransactionID: 7, ReasonCode: model.CancellationReasonDataError}); err != nil {
+		t.Errorf("a zero window should allow cancelling old contracts: %v", err)
+	}
+}
+
+func TestGetCancellation_OnlyForOwnerOrAdmin(t *testing.T) {
+	f := newCancellationFixture(t, model.TransactionStatusOngoing, 1, 14)
+	if _, err := f.uc.Cancel(usecase.CancellationRequest{TransactionID: 7, ReasonCode: model.CancellationReasonCustomerRequest}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := f.uc.GetCancellation(7, usecase.Viewer{UserID: 12, Role: "customer"}); !errors.Is(err, usecase.ErrCustomerForbidden) {
+		t.Errorf("err = %v, want another user's cancellation forbidden", err)
+	}
+	if cancellation, err := f.uc.GetCancellation(7, usecase.Viewer{UserID: 11, Role: "customer"}); err != nil || cancellation.TransactionID != 7 {
+		t.Errorf("cancellation = %+v, err = %v, want the customer's own cancellation", cancellation, err)
+	}
+}
@@ -1,34 +1,60 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"xyz-multifinance/internal/model"
 	"xyz-multifinance/internal/repository"
 )
 
+// ErrCustomerForbidden is returned when the viewer may not act for the
+// customer a record belongs to.
+var ErrCustomerForbidden = errors.New("not allowed to access this customer")
+
+// Viewer is the authenticated user a request is made by.
+type Viewer struct {
+	UserID uint
+	Role   string
+}
+
+// authorizeCustomer lets admins and the customer's own user through.
+func authorizeCustomer(customer *model.Customer, viewer Viewer) error {
+	if !customer.AccessibleBy(viewer.UserID, viewer.Role) {
+		return ErrCustomerForbidden
+	}
+	return nil
+}
+
 type CustomerUsecase interface {
-	CreateCustomer(cust *model.Customer) error
+	CreateCustomer(ctx context.Context, cust *model.Customer) error
 	GetCustomerByNIK(nik string) (*model.Customer, error)
-	UpdateCustomer(nik string, updatedFields map[string]interface{}) error
-	DeleteCustomer(nik string) error
+	UpdateCustomer(ctx context.Context, nik string, updatedFields map[string]interface{}) error
+	DeleteCustomer(ctx context.Context, nik string) error
 }
 
+// customerAuditRedactions are the personal data fields whose values are kept
+// out of the audit log, which is never erased.
+var customerAuditRedactions = []string{"nik", "full_name", "legal_name", "place_of_birth", "date_of_birth", "salary"}
+
 type customerUsecase struct {
 	customerRepo repository.CustomerRepository
 	userRepo     repository.UserRepository
+	auditor      Auditor
 }
 
-func NewCustomerUsecase(repo repository.CustomerRepository, uRepo repository.UserRepository) CustomerUsecase {
+func NewCustomerUsecase(repo repository.CustomerRepository, uRepo repository.UserRepository, auditor Auditor) CustomerUsecase {
 	return &customerUsecase{
 		customerRepo: repo,
 		userRepo:     uRepo,
+		auditor:      auditor,
 	}
 }
 
-func (uc *customerUsecase) CreateCustomer(customer *model.Customer) error {
+func (uc *customerUsecase) CreateCustomer(ctx context.Context, customer *model.Customer) error {
 	user, err := uc.userRepo.FindByID(customer.UserID)
 	if err != nil {
 		return fmt.Errorf("failed to check user: %w", err)
@@ -45,29 +71,67 @@ func (uc *customerUsecase) CreateCustomer(customer *model.Customer) error {
 	customer.CreatedAt = time.Now()
 	customer.UpdatedAt = time.Now()
 
-	return uc.customerRepo.Create(customer)
+	if err := uc.customerRepo.Create(customer); err != nil {
+		return err
+	}
+	uc.audit(ctx, model.AuditActionCreate, customer.ID, nil, customer)
+	return nil
 }
 
 func (uc *customerUsecase) GetCustomerByNIK(nik string) (*model.Customer, error) {
 	return uc.customerRepo.FindByNIK(nik)
 }
 
-func (uc *customerUsecase) UpdateCustomer(nik string, updatedFields map[string]interface{}) error {
-	_, err := uc.customerRepo.FindByNIK(nik)
+func (uc *customerUsecase) UpdateCustomer(ctx context.Context, nik string, updatedFields map[string]interface{}) error {
+	before, err := uc.customerRepo.FindByNIK(nik)
 	if err != nil {
 		return err
 	}
 
 	updatedFields["updated_at"] = time.Now()
 
-	return uc.customerRepo.Update(nik, updatedFields)
+	if err := uc.customerRepo.Update(nik, updatedFields); err != nil {
+		return err
+	}
+	after, err := uc.customerRepo.FindByID(before.ID)
+	if err != nil {
+		return err
+	}
+	uc.audit(ctx, model.AuditActionUpdate, before.ID, before, after)
+	return nil
 }
 
-func (uc *customerUsecase) DeleteCustomer(nik string) error {
+func (uc *customerUsecase) DeleteCustomer(ctx context.Context, nik string) error {
 	customer, err := uc.customerRepo.FindByNIK(nik)
 	if err != nil {
 		return errors.New("customer not found")
 	}
 
-	return uc.customerRepo.Delete(customer.ID)
+	if err := uc.customerRepo.Delete(customer.ID); err != nil {
+		return err
+	}
+	uc.audit(ctx, model.AuditActionDelete, customer.ID, customer, nil)
+	return nil
+}
+
+func (uc *customerUsecase) audit(ctx context.Context, action string, id uint, before, after *model.Customer) {
+	recordAudit(ctx, uc.auditor, AuditEvent{
+		Entity:       model.AuditEntityCustomer,
+		EntityID:     strconv.FormatUint(uint64(id), 10),
+		Action:       action,
+		Before:       unmaskedCopy(before),
+		After:        unmaskedCopy(after),
+		RedactFields: customerAuditRedactions,
+	})
+}
+
+// unmaskedCopy lets the audit diff compare the real values of the personal
+// data fields; it redacts them itself.
+func unmaskedCopy(customer *model.Customer) *model.Customer {
+	if customer == nil {
+		return nil
+	}
+	c := *customer
+	c.UnmaskPII()
+	return &c
 }
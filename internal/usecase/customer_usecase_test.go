@@ -1,18 +1,40 @@
 package usecase_test
 
 import (
+	"context"
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
 	"xyz-multifinance/internal/model"
 	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/pkg/audit"
+
+	"gorm.io/gorm"
 )
 
 type mockCustomerRepo struct {
-	FindByNIKFunc func(nik string) (*model.Customer, error)
-	FindByIDFunc  func(id uint) (*model.Customer, error)
-	CreateFunc    func(customer *model.Customer) error
-	UpdateFunc    func(nik string, fields map[string]interface{}) error
-	DeleteFunc    func(id uint) error
+	FindByNIKFunc     func(nik string) (*model.Customer, error)
+	FindByIDFunc      func(id uint) (*model.Customer, error)
+	FindBatchFunc     func(afterID uint, limit int) ([]model.Customer, error)
+	CreateFunc        func(customer *model.Customer) error
+	UpdateFunc        func(nik string, fields map[string]interface{}) error
+	SaveEncryptedFunc func(customer *model.Customer) error
+	UpdatePhotosFunc  func(id uint, ktpPhoto, selfiePhoto string) error
+	AnonymizeFunc     func(id uint, anonymizedAt time.Time) error
+	DeleteFunc        func(id uint) error
+}
+
+// customersOwnedBy is a customer repository in which every customer belongs
+// to the given user.
+func customersOwnedBy(userID uint) *mockCustomerRepo {
+	return &mockCustomerRepo{
+		FindByNIKFunc: func(nik string) (*model.Customer, error) {
+			return &model.Customer{ID: 3, UserID: userID, NIK: nik}, nil
+		},
+		FindByIDFunc: func(id uint) (*model.Customer, error) { return &model.Customer{ID: id, UserID: userID}, nil },
+	}
 }
 
 func (m *mockCustomerRepo) FindByNIK(nik string) (*model.Customer, error) {
@@ -29,6 +51,13 @@ func (m *mockCustomerRepo) FindByID(id uint) (*model.Customer, error) {
 	return nil, nil
 }
 
+func (m *mockCustomerRepo) FindBatch(afterID uint, limit int) ([]model.Customer, error) {
+	if m.FindBatchFunc != nil {
+		return m.FindBatchFunc(afterID, limit)
+	}
+	return nil, nil
+}
+
 func (m *mockCustomerRepo) Create(customer *model.Customer) error {
 	if m.CreateFunc != nil {
 		return m.CreateFunc(customer)
@@ -43,6 +72,27 @@ func (m *mockCustomerRepo) Update(nik string, fields map[string]interface{}) err
 	return nil
 }
 
+func (m *mockCustomerRepo) SaveEncrypted(customer *model.Customer) error {
+	if m.SaveEncryptedFunc != nil {
+		return m.SaveEncryptedFunc(customer)
+	}
+	return nil
+}
+
+func (m *mockCustomerRepo) UpdatePhotos(id uint, ktpPhoto, selfiePhoto string) error {
+	if m.UpdatePhotosFunc != nil {
+		return m.UpdatePhotosFunc(id, ktpPhoto, selfiePhoto)
+	}
+	return nil
+}
+
+func (m *mockCustomerRepo) Anonymize(tx *gorm.DB, id uint, anonymizedAt time.Time) error {
+	if m.AnonymizeFunc != nil {
+		return m.AnonymizeFunc(id, anonymizedAt)
+	}
+	return nil
+}
+
 func (m *mockCustomerRepo) Delete(id uint) error {
 	if m.DeleteFunc != nil {
 		return m.DeleteFunc(id)
@@ -54,6 +104,7 @@ type mockUserRepo struct {
 	FindByUsernameFunc func(username string) (*model.User, error)
 	FindByIDFunc       func(id uint) (*model.User, error)
 	CreateFunc         func(user *model.User) error
+	AnonymizeFunc      func(id uint) error
 }
 
 func (m *mockUserRepo) FindByUsername(username string) (*model.User, error) {
@@ -77,6 +128,13 @@ func (m *mockUserRepo) Create(user *model.User) error {
 	return nil
 }
 
+func (m *mockUserRepo) Anonymize(tx *gorm.DB, id uint) error {
+	if m.AnonymizeFunc != nil {
+		return m.AnonymizeFunc(id)
+	}
+	return nil
+}
+
 func TestCreateCustomer_Success(t *testing.T) {
 	mockUser := &model.User{ID: 1, Username: "Admin"}
 
@@ -91,7 +149,7 @@ func TestCreateCustomer_Success(t *testing.T) {
 		FindByIDFunc: func(id uint) (*model.User, error) {
 			return mockUser, nil
 		},
-	})
+	}, &mockAuditor{})
 
 	customer := &model.Customer{
 		FullName:   "Agustiansyah",
@@ -100,7 +158,7 @@ func TestCreateCustomer_Success(t *testing.T) {
 		PlaceBirth: "Jakarta",
 	}
 
-	err := uc.CreateCustomer(customer)
+	err := uc.CreateCustomer(context.Background(), customer)
 	if err != nil {
 		t.Errorf("expected nil error, got %v", err)
 	}
@@ -120,15 +178,77 @@ func TestCreateCustomer_NIKExists(t *testing.T) {
 		FindByIDFunc: func(id uint) (*model.User, error) {
 			return &model.User{ID: 1, Username: "Admin"}, nil
 		},
-	})
+	}, &mockAuditor{})
 
 	customer := &model.Customer{
 		NIK:    "1234567890",
 		UserID: 1,
 	}
 
-	err := uc.CreateCustomer(customer)
+	err := uc.CreateCustomer(context.Background(), customer)
 	if err == nil || err.Error() != "customer with this NIK already exists" {
 		t.Errorf("expected error about NIK, got %v", err)
 	}
 }
+
+func TestCustomer_SerializesMaskedByDefault(t *testing.T) {
+	customer := model.Customer{NIK: "3171234567890001", Salary: 8000000, DateBirth: time.Date(1990, 5, 1, 0, 0, 0, 0, time.UTC)}
+
+	masked, err := json.Marshal(customer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"nik":"3171********0001"`, `"salary":"********"`, `"date_of_birth":"****-**-**"`} {
+		if !strings.Contains(string(masked), want) {
+			t.Errorf("masked json = %s, want it to contain %s", masked, want)
+		}
+	}
+
+	customer.UnmaskPII()
+	unmasked, err := json.Marshal(customer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(unmasked), `"nik":"3171234567890001"`) || !strings.Contains(string(unmasked), `"salary":8000000`) {
+		t.Errorf("unmasked json = %s, want the personal data in full", unmasked)
+	}
+}
+
+func TestUpdateCustomer_AuditSeesMaskedFieldChanges(t *testing.T) {
+	stored := model.Customer{ID: 4, NIK: "3171234567890001", FullName: "Budi Santoso", Salary: 5000000}
+	repo := &mockCustomerRepo{
+		FindByNIKFunc: func(nik string) (*model.Customer, error) {
+			customer := stored
+			return &customer, nil
+		},
+		UpdateFunc: func(nik string, fields map[string]interface{}) error {
+			stored.Salary = fields["salary"].(int64)
+			return nil
+		},
+		FindByIDFunc: func(id uint) (*model.Customer, error) {
+			customer := stored
+			return &customer, nil
+		},
+	}
+	auditor := &mockAuditor{}
+	uc := usecase.NewCustomerUsecase(repo, &mockUserRepo{}, auditor)
+
+	if err := uc.UpdateCustomer(context.Background(), stored.NIK, map[string]interface{}{"salary": int64(9000000)}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(auditor.events) != 1 {
+		t.Fatalf("recorded %d events, want 1", len(auditor.events))
+	}
+	event := auditor.events[0]
+	changes, err := audit.Diff(event.Before, event.After, event.RedactFields...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := changes["salary"]; !ok {
+		t.Errorf("changes = %v, want the salary change recorded", changes)
+	}
+	if _, ok := changes["nik"]; ok {
+		t.Errorf("changes = %v, want the unchanged NIK left out", changes)
+	}
+}
@@ -0,0 +1,67 @@
+package usecase_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"xyz-multifinance/logger"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+// newTestDB returns a *gorm.DB whose transactions begin and commit but whose
+// statements all fail. Usecases only use it to open transactions and hand
+// them to repositories, which the tests replace with mocks, so any SQL that
+// does reach it is a bug in the test.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	sqlDB := sql.OpenDB(txOnlyConnector{})
+	db, err := gorm.Open(mysql.New(mysql.Config{Conn: sqlDB, SkipInitializeWithVersion: true}), &gorm.Config{
+		DisableAutomaticPing: true,
+		Logger:               gormLogger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open test db: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// discardLogs gives usecases that log their progress a logger for the
+// duration of the test.
+func discardLogs(t *testing.T) {
+	t.Helper()
+	previous := logger.Log
+	logger.Log = logrus.New()
+	logger.Log.SetOutput(io.Discard)
+	t.Cleanup(func() { logger.Log = previous })
+}
+
+var errNoSQL = errors.New("test db does not run SQL")
+
+type txOnlyConnector struct{}
+
+func (txOnlyConnector) Connect(context.Context) (driver.Conn, error) { return txOnlyConn{}, nil }
+func (txOnlyConnector) Driver() driver.Driver                        { return txOnlyDriver{} }
+
+type txOnlyDriver struct{}
+
+func (txOnlyDriver) Open(string) (driver.Conn, error) { return txOnlyConn{}, nil }
+
+type txOnlyConn struct{}
+
+func (txOnlyConn) Prepare(string) (driver.Stmt, error) { return nil, errNoSQL }
+func (txOnlyConn) Close() error                        { return nil }
+func (txOnlyConn) Begin() (driver.Tx, error)           { return txOnlyTx{}, nil }
+
+type txOnlyTx struct{}
+
+func (txOnlyTx) Commit() error   { return nil }
+func (txOnlyTx) Rollback() error { return nil }
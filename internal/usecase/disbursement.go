@@ -0,0 +1,522 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/logger"
+	"xyz-multifinance/payout"
+
+	"gorm.io/gorm"
+)
+
+// DisbursementProviderManual marks payouts an admin completed by hand.
+const DisbursementProviderManual = "manual"
+
+// DisbursementPolicy controls how often a payout is retried. The delay
+// before attempt n+1 is RetryBaseDelay * 2^(n-1), capped at RetryMaxDelay.
+// A payout still processing StaleAfter after it was claimed, because the
+// worker died or the provider's callback never came, counts as a failed
+// attempt; zero never gives up on them.
+type DisbursementPolicy struct {
+	MaxAttempts    int
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	StaleAfter     time.Duration
+	BatchSize      int
+}
+
+type DisbursementRunResult struct {
+	Released  int `json:"released"`
+	Attempted int `json:"attempted"`
+	Succeeded int `json:"succeeded"`
+	Pending   int `json:"pending"`
+	Failed    int `json:"failed"`
+}
+
+type DisbursementUsecase interface {
+	// ProcessDue returns stale processing disbursements to the queue, then
+	// sends every due pending disbursement to the payout provider.
+	ProcessDue(ctx context.Context, now time.Time) (*DisbursementRunResult, error)
+	// HandleCallback authenticates a provider callback and applies its
+	// outcome. Repeated callbacks with the same outcome are ignored.
+	HandleCallback(ctx context.Context, header http.Header, body []byte) (*model.Disbursement, error)
+	// CompleteManually records that a contract was paid out outside the
+	// payout provider and activates it as of paidAt. Completing a
+	// disbursement that already succeeded is a no-op; one that is processing
+	// can only be completed once it is stale.
+	CompleteManually(ctx context.Context, transactionID uint, provider, providerReference string, paidAt time.Time) (*model.Disbursement, error)
+	// GetDisbursementByTransaction returns ErrCustomerForbidden unless the
+	// viewer is an admin or the contract's customer.
+	GetDisbursementByTransaction(transactionID uint, viewer Viewer) (*model.Disbursement, error)
+	UpdateDisbursement(id uint, fields map[string]interface{}) error
+	RetryDisbursement(id uint) error
+}
+
+type disbursementUsecase struct {
+	disbursementRepo repository.DisbursementRepository
+	txRepo           repository.TransactionRepository
+	installmentRepo  repository.InstallmentRepository
+	ledgerRepo       repository.LedgerRepository
+	customerRepo     repository.CustomerRepository
+	provider         payout.Provider
+	policy           DisbursementPolicy
+	auditor          Auditor
+	db               *gorm.DB
+}
+
+func NewDisbursementUsecase(
+	disbursementRepo repository.DisbursementRepository,
+	txRepo repository.TransactionRepository,
+	installmentRepo repository.InstallmentRepository,
+	ledgerRepo repository.LedgerRepository,
+	customerRepo repository.CustomerRepository,
+	provider payout.Provider,
+	policy DisbursementPolicy,
+	auditor Auditor,
+	db *gorm.DB,
+) DisbursementUsecase {
+	return &disbursementUsecase{
+		disbursementRepo: disbursementRepo,
+		txRepo:           txRepo,
+		installmentRepo:  installmentRepo,
+		ledgerRepo:       ledgerRepo,
+		customerRepo:     customerRepo,
+		provider:         provider,
+		policy:           policy,
+		auditor:          auditor,
+		db:               db,
+	}
+}
+
+// newDisbursement prepares the payout of a freshly approved contract, paid to
+// the outlet's bank account when the contract came through one.
+// disbursementAmount is what the dealer is paid for a contract: the financed
+// principal less the admin fee, which the dealer collects from the customer
+// together with the down payment.
+func disbursementAmount(tx *model.Transaction) int64 {
+	return tx.Principal - tx.AdminFee
+}
+
+func newDisbursement(tx *model.Transaction, outlet *model.Outlet) *model.Disbursement {
+	d := &model.Disbursement{
+		TransactionID: tx.ID,
+		Reference:     fmt.Sprintf("DSB-%s", tx.ContractNumber),
+		Amount:        disbursementAmount(tx),
+		Currency:      tx.Currency,
+		Status:        model.DisbursementStatusPending,
+	}
+	if outlet != nil {
+		d.BankCode = outlet.BankCode
+		d.BankAccountNumber = outlet.BankAccountNumber
+		d.BankAccountName = outlet.BankAccountName
+	}
+	return d
+}
+
+func (uc *disbursementUsecase) ProcessDue(ctx context.Context, now time.Time) (*DisbursementRunResult, error) {
+	result := &DisbursementRunResult{}
+	if err := uc.releaseStale(ctx, now, result); err != nil {
+		return result, err
+	}
+
+	due, err := uc.disbursementRepo.FindDue(now, uc.policy.BatchSize)
+	if err != nil {
+		return result, err
+	}
+
+	for _, d := range due {
+		claimed, err := uc.disbursementRepo.Claim(d.ID, now)
+		if err != nil {
+			return result, err
+		}
+		if !claimed {
+			continue
+		}
+		result.Attempted++
+
+		res, sendErr := uc.provider.Send(ctx, payout.Request{
+			Reference:     d.Reference,
+			Amount:        d.Amount,
+			Currency:      d.Currency,
+			BankCode:      d.BankCode,
+			AccountNumber: d.BankAccountNumber,
+			AccountName:   d.BankAccountName,
+		})
+
+		var outcome *model.Disbursement
+		switch {
+		case sendErr != nil:
+			outcome, err = uc.recordFailure(d.ID, sendErr.Error(), time.Now())
+		case res.Status == payout.StatusSucceeded:
+			outcome, err = uc.recordSuccess(d.ID, res.ProviderReference, time.Now())
+		case res.Status == payout.StatusFailed:
+			outcome, err = uc.recordRejection(d.ID, res.ProviderReference, res.Reason)
+		default:
+			err = uc.disbursementRepo.Update(d.ID, map[string]interface{}{
+				"provider":           uc.provider.Name(),
+				"provider_reference": res.ProviderReference,
+			})
+			outcome = &model.Disbursement{
+				ID:                d.ID,
+				Status:            model.DisbursementStatusProcessing,
+				Provider:          uc.provider.Name(),
+				ProviderReference: res.ProviderReference,
+				LastError:         d.LastError,
+			}
+		}
+		if err != nil {
+			return result, err
+		}
+		uc.audit(ctx, &d, outcome)
+
+		switch outcome.Status {
+		case model.DisbursementStatusSucceeded:
+			result.Succeeded++
+		case model.DisbursementStatusFailed:
+			result.Failed++
+		default:
+			result.Pending++
+		}
+	}
+
+	if result.Attempted > 0 || result.Released > 0 {
+		logger.Log.WithField("provider", uc.provider.Name()).
+			Infof("disbursements processed: %d released, %d attempted, %d succeeded, %d pending, %d failed",
+				result.Released, result.Attempted, result.Succeeded, result.Pending, result.Failed)
+	}
+	return result, nil
+}
+
+// releaseStale treats every stale processing payout as a failed attempt, so
+// it is sent again under the same reference, which the provider uses to
+// recognise a payout it already made, or fails once its attempts are used
+// up.
+func (uc *disbursementUsecase) releaseStale(ctx context.Context, now time.Time, result *DisbursementRunResult) error {
+	if uc.policy.StaleAfter <= 0 {
+		return nil
+	}
+	stale, err := uc.disbursementRepo.FindStale(now.Add(-uc.policy.StaleAfter), uc.policy.BatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range stale {
+		var outcome *model.Disbursement
+		err := uc.db.Transaction(func(txDB *gorm.DB) error {
+			locked, err := uc.disbursementRepo.FindByIDForUpdate(txDB, d.ID)
+			if err != nil {
+				return err
+			}
+			if !uc.isStale(locked, now) {
+				return nil
+			}
+			uc.scheduleRetry(locked, "no outcome from the payout provider", now)
+			outcome = locked
+			return uc.disbursementRepo.Save(txDB, locked)
+		})
+		if err != nil {
+			return err
+		}
+		if outcome == nil {
+			continue
+		}
+		uc.audit(ctx, &d, outcome)
+		result.Released++
+		if outcome.Status == model.DisbursementStatusFailed {
+			result.Failed++
+		}
+	}
+	return nil
+}
+
+// isStale reports whether a processing disbursement has waited longer than
+// StaleAfter for its outcome.
+func (uc *disbursementUsecase) isStale(d *model.Disbursement, now time.Time) bool {
+	if d.Status != model.DisbursementStatusProcessing || uc.policy.StaleAfter <= 0 {
+		return false
+	}
+	return d.ClaimedAt == nil || !d.ClaimedAt.After(now.Add(-uc.policy.StaleAfter))
+}
+
+// backoff is the delay before the next attempt after the given number of
+// attempts.
+func (p DisbursementPolicy) backoff(attempts int) time.Duration {
+	delay := p.RetryBaseDelay
+	for i := 1; i < attempts && delay < p.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if p.RetryMaxDelay > 0 && delay > p.RetryMaxDelay {
+		delay = p.RetryMaxDelay
+	}
+	return delay
+}
+
+// recordFailure schedules another attempt, or gives up once the attempts are
+// used up.
+func (uc *disbursementUsecase) recordFailure(id uint, reason string, now time.Time) (*model.Disbursement, error) {
+	var d *model.Disbursement
+	err := uc.db.Transaction(func(txDB *gorm.DB) error {
+		var err error
+		d, err = uc.disbursementRepo.FindByIDForUpdate(txDB, id)
+		if err != nil {
+			return err
+		}
+
+		if d.Status == model.DisbursementStatusSucceeded || d.Status == model.DisbursementStatusCancelled {
+			return nil
+		}
+
+		uc.scheduleRetry(d, reason, now)
+		return uc.disbursementRepo.Save(txDB, d)
+	})
+	return d, err
+}
+
+// scheduleRetry queues the next attempt after a failed one, or fails the
+// disbursement once its attempts are used up.
+func (uc *disbursementUsecase) scheduleRetry(d *model.Disbursement, reason string, now time.Time) {
+	d.Provider = uc.provider.Name()
+	d.LastError = truncate(reason, 255)
+	if d.Attempts >= uc.policy.MaxAttempts {
+		d.Status = model.DisbursementStatusFailed
+		d.NextAttemptAt = nil
+	} else {
+		next := now.Add(uc.policy.backoff(d.Attempts))
+		d.Status = model.DisbursementStatusPending
+		d.NextAttemptAt = &next
+	}
+}
+
+// recordRejection marks a payout the provider refused outright. It is not
+// retried until an admin fixes the beneficiary and retries it.
+func (uc *disbursementUsecase) recordRejection(id uint, providerReference, reason string) (*model.Disbursement, error) {
+	var d *model.Disbursement
+	err := uc.db.Transaction(func(txDB *gorm.DB) error {
+		var err error
+		d, err = uc.disbursementRepo.FindByIDForUpdate(txDB, id)
+		if err != nil {
+			return err
+		}
+		if d.Status == model.DisbursementStatusSucceeded || d.Status == model.DisbursementStatusCancelled {
+			return nil
+		}
+
+		d.Provider = uc.provider.Name()
+		d.ProviderReference = providerReference
+		d.Status = model.DisbursementStatusFailed
+		d.NextAttemptAt = nil
+		d.LastError = truncate(reason, 255)
+		return uc.disbursementRepo.Save(txDB, d)
+	})
+	return d, err
+}
+
+// recordSuccess completes the disbursement and activates its contract: the
+// installment schedule starts from the disbursement date and the loan is
+// booked in the ledger.
+func (uc *disbursementUsecase) recordSuccess(id uint, providerReference string, now time.Time) (*model.Disbursement, error) {
+	return uc.complete(id, uc.provider.Name(), providerReference, now)
+}
+
+// complete marks the disbursement as paid out by provider and activates its
+// contract. Completing it again is a no-op.
+func (uc *disbursementUsecase) complete(id uint, provider, providerReference string, now time.Time) (*model.Disbursement, error) {
+	var d *model.Disbursement
+	err := uc.db.Transaction(func(txDB *gorm.DB) error {
+		var err error
+		d, err = uc.disbursementRepo.FindByIDForUpdate(txDB, id)
+		if err != nil {
+			return err
+		}
+		if d.Status == model.DisbursementStatusSucceeded {
+			return nil
+		}
+		if d.Status == model.DisbursementStatusCancelled {
+			return errors.New("disbursement was cancelled")
+		}
+
+		d.Provider = provider
+		d.ProviderReference = providerReference
+		d.Status = model.DisbursementStatusSucceeded
+		d.NextAttemptAt = nil
+		d.LastError = ""
+		d.DisbursedAt = &now
+		if err := uc.disbursementRepo.Save(txDB, d); err != nil {
+			return err
+		}
+
+		return uc.activate(txDB, d.TransactionID, now)
+	})
+	return d, err
+}
+
+func (uc *disbursementUsecase) activate(txDB *gorm.DB, transactionID uint, now time.Time) error {
+	tx, err := uc.txRepo.FindByIDForUpdate(txDB, transactionID)
+	if err != nil {
+		return err
+	}
+	if tx.Status != model.TransactionStatusApproved {
+		return fmt.Errorf("cannot activate a %s transaction", tx.Status)
+	}
+
+	installments, err := buildSchedule(tx, now)
+	if err != nil {
+		return err
+	}
+	if err := uc.installmentRepo.CreateBatch(txDB, installments); err != nil {
+		return err
+	}
+	if err := postEntry(txDB, uc.ledgerRepo, disbursementEntry(tx, now)); err != nil {
+		return err
+	}
+
+	return uc.txRepo.UpdateFields(txDB, tx.ID, map[string]interface{}{
+		"status":       model.TransactionStatusOngoing,
+		"disbursed_at": now,
+	})
+}
+
+func (uc *disbursementUsecase) HandleCallback(ctx context.Context, header http.Header, body []byte) (*model.Disbursement, error) {
+	cb, err := uc.provider.ParseCallback(header, body)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := uc.disbursementRepo.FindByReference(cb.Reference)
+	if err != nil {
+		return nil, errors.New("disbursement not found")
+	}
+
+	switch d.Status {
+	case model.DisbursementStatusSucceeded:
+		if cb.Status != payout.StatusSucceeded {
+			return nil, errors.New("callback conflicts with a succeeded disbursement")
+		}
+		return d, nil
+	case model.DisbursementStatusFailed:
+		if cb.Status == payout.StatusFailed {
+			return d, nil
+		}
+	case model.DisbursementStatusCancelled:
+		return nil, errors.New("disbursement was cancelled")
+	}
+
+	var outcome *model.Disbursement
+	if cb.Status == payout.StatusSucceeded {
+		outcome, err = uc.recordSuccess(d.ID, cb.ProviderReference, time.Now())
+	} else {
+		outcome, err = uc.recordRejection(d.ID, cb.ProviderReference, cb.Reason)
+	}
+	if err != nil {
+		return nil, err
+	}
+	uc.audit(ctx, d, outcome)
+	return outcome, nil
+}
+
+func (uc *disbursementUsecase) CompleteManually(ctx context.Context, transactionID uint, provider, providerReference string, paidAt time.Time) (*model.Disbursement, error) {
+	d, err := uc.disbursementRepo.FindByTransactionID(transactionID)
+	if err != nil {
+		return nil, errors.New("disbursement not found")
+	}
+	if d.Status == model.DisbursementStatusProcessing && !uc.isStale(d, time.Now()) {
+		return nil, errors.New("disbursement is being sent to the payout provider")
+	}
+
+	outcome, err := uc.complete(d.ID, provider, providerReference, paidAt)
+	if err != nil {
+		return nil, err
+	}
+	uc.audit(ctx, d, outcome)
+	return outcome, nil
+}
+
+// audit records how a payout attempt, a provider callback or a manual
+// completion moved a disbursement.
+func (uc *disbursementUsecase) audit(ctx context.Context, before, after *model.Disbursement) {
+	from, to := payoutState(before), payoutState(after)
+	if reflect.DeepEqual(from, to) {
+		return
+	}
+	recordAudit(ctx, uc.auditor, AuditEvent{
+		Entity:   model.AuditEntityDisbursement,
+		EntityID: strconv.FormatUint(uint64(before.ID), 10),
+		Action:   model.AuditActionUpdate,
+		Before:   from,
+		After:    to,
+	})
+}
+
+func payoutState(d *model.Disbursement) map[string]interface{} {
+	return map[string]interface{}{
+		"status":             d.Status,
+		"provider":           d.Provider,
+		"provider_reference": d.ProviderReference,
+		"last_error":         d.LastError,
+	}
+}
+
+func (uc *disbursementUsecase) GetDisbursementByTransaction(transactionID uint, viewer Viewer) (*model.Disbursement, error) {
+	tx, err := uc.txRepo.FindByID(transactionID)
+	if err != nil {
+		return nil, errors.New("transaction not found")
+	}
+	customer, err := uc.customerRepo.FindByID(tx.CustomerID)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+	if err := authorizeCustomer(customer, viewer); err != nil {
+		return nil, err
+	}
+
+	d, err := uc.disbursementRepo.FindByTransactionID(transactionID)
+	if err != nil {
+		return nil, errors.New("disbursement not found")
+	}
+	return d, nil
+}
+
+// UpdateDisbursement changes the beneficiary of a disbursement that has not
+// been paid out yet.
+func (uc *disbursementUsecase) UpdateDisbursement(id uint, fields map[string]interface{}) error {
+	d, err := uc.disbursementRepo.FindByID(id)
+	if err != nil {
+		return errors.New("disbursement not found")
+	}
+	if d.Status != model.DisbursementStatusPending && d.Status != model.DisbursementStatusFailed {
+		return fmt.Errorf("cannot update a %s disbursement", d.Status)
+	}
+	return uc.disbursementRepo.Update(id, fields)
+}
+
+// RetryDisbursement puts a failed or stale processing disbursement back in
+// the queue with a fresh set of attempts.
+func (uc *disbursementUsecase) RetryDisbursement(id uint) error {
+	d, err := uc.disbursementRepo.FindByID(id)
+	if err != nil {
+		return errors.New("disbursement not found")
+	}
+	if d.Status != model.DisbursementStatusFailed && !uc.isStale(d, time.Now()) {
+		return fmt.Errorf("cannot retry a %s disbursement", d.Status)
+	}
+	return uc.disbursementRepo.Update(id, map[string]interface{}{
+		"status":          model.DisbursementStatusPending,
+		"attempts":        0,
+		"next_attempt_at": nil,
+	})
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
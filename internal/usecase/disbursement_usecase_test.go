@@ -0,0 +1,419 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/payout"
+
+	"gorm.io/gorm"
+)
+
+func TestDisbursementBackoff(t *testing.T) {
+	policy := usecase.DisbursementPolicy{RetryBaseDelay: time.Minute, RetryMaxDelay: 10 * time.Minute}
+
+	cases := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{4, 8 * time.Minute},
+		{5, 10 * time.Minute},
+		{1000, 10 * time.Minute},
+	}
+	for _, tc := range cases {
+		if got := usecase.Backoff(policy, tc.attempts); got != tc.want {
+			t.Errorf("backoff after %d attempts = %s, want %s", tc.attempts, got, tc.want)
+		}
+	}
+}
+
+// mockQueueDisbursementRepo holds a single disbursement and claims it the
+// way the database repository does.
+type mockQueueDisbursementRepo struct {
+	repository.DisbursementRepository
+	d     model.Disbursement
+	saves int
+}
+
+func (m *mockQueueDisbursementRepo) FindDue(now time.Time, limit int) ([]model.Disbursement, error) {
+	if m.d.Status != model.DisbursementStatusPending || (m.d.NextAttemptAt != nil && m.d.NextAttemptAt.After(now)) {
+		return nil, nil
+	}
+	return []model.Disbursement{m.d}, nil
+}
+
+func (m *mockQueueDisbursementRepo) Claim(id uint, now time.Time) (bool, error) {
+	m.d.Status = model.DisbursementStatusProcessing
+	m.d.Attempts++
+	m.d.ClaimedAt = &now
+	return true, nil
+}
+
+func (m *mockQueueDisbursementRepo) FindStale(claimedBefore time.Time, limit int) ([]model.Disbursement, error) {
+	if m.d.Status != model.DisbursementStatusProcessing || (m.d.ClaimedAt != nil && m.d.ClaimedAt.After(claimedBefore)) {
+		return nil, nil
+	}
+	return []model.Disbursement{m.d}, nil
+}
+
+func (m *mockQueueDisbursementRepo) FindByID(id uint) (*model.Disbursement, error) {
+	d := m.d
+	return &d, nil
+}
+
+func (m *mockQueueDisbursementRepo) Update(id uint, fields map[string]interface{}) error {
+	if status, ok := fields["status"].(string); ok {
+		m.d.Status = status
+	}
+	m.saves++
+	return nil
+}
+
+func (m *mockQueueDisbursementRepo) FindByIDForUpdate(db *gorm.DB, id uint) (*model.Disbursement, error) {
+	d := m.d
+	return &d, nil
+}
+
+func (m *mockQueueDisbursementRepo) FindByTransactionID(transactionID uint) (*model.Disbursement, error) {
+	d := m.d
+	return &d, nil
+}
+
+func (m *mockQueueDisbursementRepo) FindByReference(reference string) (*model.Disbursement, error) {
+	if reference != m.d.Reference {
+		return nil, gorm.ErrRecordNotFound
+	}
+	d := m.d
+	return &d, nil
+}
+
+func (m *mockQueueDisbursementRepo) Save(db *gorm.DB, d *model.Disbursement) error {
+	m.d = *d
+	m.saves++
+	return nil
+}
+
+type mockScheduleInstallmentRepo struct {
+	repository.InstallmentRepository
+	created []model.Installment
+}
+
+func (m *mockScheduleInstallmentRepo) CreateBatch(db *gorm.DB, installments []model.Installment) error {
+	m.created = append(m.created, installments...)
+	return nil
+}
+
+type disbursementFixture struct {
+	uc               usecase.DisbursementUsecase
+	provider         *payout.MockProvider
+	disbursementRepo *mockQueueDisbursementRepo
+	txRepo           *mockPayTxRepo
+	installmentRepo  *mockScheduleInstallmentRepo
+	ledgerRepo       *mockJournalRepo
+	auditor          *mockAuditor
+}
+
+func newDisbursementFixture(t *testing.T, status string) *disbursementFixture {
+	discardLogs(t)
+	f := &disbursementFixture{
+		provider: payout.NewMockProvider("secret"),
+		disbursementRepo: &mockQueueDisbursementRepo{d: model.Disbursement{
+			ID: 9, TransactionID: 7, Reference: "DSB-CN-7", Amount: 8_950_000, Currency: "IDR", Status: status,
+			BankCode: "014", BankAccountNumber: "1234567890", BankAccountName: "PT Dealer",
+		}},
+		txRepo: &mockPayTxRepo{tx: model.Transaction{
+			ID: 7, ContractNumber: "CN-7", Tenor: 3, Currency: "IDR", Principal: 9_000_000, AdminFee: 50_000, InterestAmount: 900_001,
+			Status: model.TransactionStatusApproved,
+		}},
+		installmentRepo: &mockScheduleInstallmentRepo{},
+		ledgerRepo:      &mockJournalRepo{},
+		auditor:         &mockAuditor{},
+	}
+	f.uc = usecase.NewDisbursementUsecase(
+		f.disbursementRepo, f.txRepo, f.installmentRepo, f.ledgerRepo, customersOwnedBy(11), f.provider,
+		usecase.DisbursementPolicy{MaxAttempts: 3, RetryBaseDelay: time.Minute, RetryMaxDelay: 10 * time.Minute, StaleAfter: 30 * time.Minute, BatchSize: 10},
+		f.auditor, newTestDB(t),
+	)
+	return f
+}
+
+func TestProcessDue_ActivatesContract(t *testing.T) {
+	f := newDisbursementFixture(t, model.DisbursementStatusPending)
+
+	result, err := f.uc.ProcessDue(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if result.Attempted != 1 || result.Succeeded != 1 {
+		t.Errorf("result = %+v, want one success", result)
+	}
+	if f.disbursementRepo.d.Status != model.DisbursementStatusSucceeded || f.disbursementRepo.d.ProviderReference != "MOCK-1" || f.disbursementRepo.d.DisbursedAt == nil {
+		t.Errorf("disbursement = %+v, want it succeeded with the provider reference", f.disbursementRepo.d)
+	}
+	if requests := f.provider.Requests(); len(requests) != 1 || requests[0].Reference != "DSB-CN-7" || requests[0].AccountNumber != "1234567890" {
+		t.Errorf("payout requests = %+v", requests)
+	}
+
+	var principal, interest int64
+	for i, inst := range f.installmentRepo.created {
+		if inst.Sequence != i+1 || inst.TransactionID != 7 || inst.Status != model.InstallmentStatusUnpaid {
+			t.Errorf("installment %d = %+v", i, inst)
+		}
+		principal += inst.Principal
+		interest += inst.Interest
+	}
+	if len(f.installmentRepo.created) != 3 || principal != 9_000_000 || interest != 900_001 {
+		t.Errorf("schedule = %+v, want 3 installments covering the contract", f.installmentRepo.created)
+	}
+	if len(f.ledgerRepo.entries) != 1 || f.ledgerRepo.entries[0].Type != model.JournalTypeDisbursement {
+		t.Fatalf("entries = %+v, want the disbursement booked", f.ledgerRepo.entries)
+	}
+	// Only the principal less the admin fee leaves cash; the fee is withheld.
+	credits := map[string]int64{}
+	for _, line := range f.ledgerRepo.entries[0].Lines {
+		credits[line.AccountCode] += line.Credit
+	}
+	if credits[model.AccountCash] != 8_950_000 || credits[model.AccountAdminFeeIncome] != 50_000 {
+		t.Errorf("credits = %v, want 8950000 from cash and 50000 admin fee income", credits)
+	}
+	if f.txRepo.fields["status"] != model.TransactionStatusOngoing {
+		t.Errorf("transaction fields = %v, want it ongoing", f.txRepo.fields)
+	}
+	if len(f.auditor.events) != 1 || f.auditor.events[0].Entity != model.AuditEntityDisbursement {
+		t.Errorf("audit = %+v, want the payout recorded", f.auditor.events)
+	}
+}
+
+func TestProcessDue_RetriesWithBackoffThenGivesUp(t *testing.T) {
+	f := newDisbursementFixture(t, model.DisbursementStatusPending)
+	f.provider.FailNext(3)
+
+	for attempt, wantDelay := range []time.Duration{time.Minute, 2 * time.Minute} {
+		before := time.Now()
+		result, err := f.uc.ProcessDue(context.Background(), before.Add(time.Hour*time.Duration(attempt)))
+		if err != nil {
+			t.Fatalf("attempt %d: unexpected error: %v", attempt+1, err)
+		}
+		d := f.disbursementRepo.d
+		if result.Pending != 1 || d.Status != model.DisbursementStatusPending || d.LastError != "mock provider unavailable" {
+			t.Fatalf("attempt %d: result = %+v, disbursement = %+v, want it queued again", attempt+1, result, d)
+		}
+		if delay := d.NextAttemptAt.Sub(before); delay < wantDelay || delay > wantDelay+time.Minute/2 {
+			t.Errorf("attempt %d: next attempt in %s, want %s", attempt+1, delay, wantDelay)
+		}
+	}
+
+	result, err := f.uc.ProcessDue(context.Background(), time.Now().Add(3*time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d := f.disbursementRepo.d
+	if result.Failed != 1 || d.Status != model.DisbursementStatusFailed || d.Attempts != 3 || d.NextAttemptAt != nil {
+		t.Errorf("result = %+v, disbursement = %+v, want it failed after 3 attempts", result, d)
+	}
+	if f.installmentRepo.created != nil || f.txRepo.fields != nil {
+		t.Error("a failed payout activated the contract")
+	}
+
+	if result, _ := f.uc.ProcessDue(context.Background(), time.Now().Add(4*time.Hour)); result.Attempted != 0 {
+		t.Errorf("a failed disbursement was attempted again")
+	}
+}
+
+func callback(status payout.Status) (http.Header, []byte) {
+	header := http.Header{}
+	header.Set(payout.CallbackTokenHeader, "secret")
+	return header, []byte(`{"reference":"DSB-CN-7","provider_reference":"PAY-77","status":"` + string(status) + `","reason":"account closed"}`)
+}
+
+func TestHandleCallback(t *testing.T) {
+	cases := []struct {
+		name       string
+		status     string
+		callback   payout.Status
+		wantStatus string
+		wantErr    string
+		activated  bool
+	}{
+		{"processing succeeds", model.DisbursementStatusProcessing, payout.StatusSucceeded, model.DisbursementStatusSucceeded, "", true},
+		{"processing is rejected", model.DisbursementStatusProcessing, payout.StatusFailed, model.DisbursementStatusFailed, "", false},
+		{"late success of a failed payout", model.DisbursementStatusFailed, payout.StatusSucceeded, model.DisbursementStatusSucceeded, "", true},
+		{"repeated failure", model.DisbursementStatusFailed, payout.StatusFailed, model.DisbursementStatusFailed, "", false},
+		{"repeated success", model.DisbursementStatusSucceeded, payout.StatusSucceeded, model.DisbursementStatusSucceeded, "", false},
+		{"failure after success", model.DisbursementStatusSucceeded, payout.StatusFailed, model.DisbursementStatusSucceeded, "conflicts with a succeeded disbursement", false},
+		{"cancelled contract", model.DisbursementStatusCancelled, payout.StatusSucceeded, model.DisbursementStatusCancelled, "was cancelled", false},
+	}
+	for _, tc := range cases {
+		f := newDisbursementFixture(t, tc.status)
+		header, body := callback(tc.callback)
+
+		d, err := f.uc.HandleCallback(context.Background(), header, body)
+		if tc.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("%s: err = %v, want %q", tc.name, err, tc.wantErr)
+			}
+		} else if err != nil || d.Status != tc.wantStatus {
+			t.Errorf("%s: disbursement = %+v, err = %v, want %s", tc.name, d, err, tc.wantStatus)
+		}
+
+		if f.disbursementRepo.d.Status != tc.wantStatus {
+			t.Errorf("%s: stored status = %s, want %s", tc.name, f.disbursementRepo.d.Status, tc.wantStatus)
+		}
+		if activated := f.txRepo.fields["status"] == model.TransactionStatusOngoing; activated != tc.activated {
+			t.Errorf("%s: contract activated = %v, want %v", tc.name, activated, tc.activated)
+		}
+		if changed := tc.status != tc.wantStatus; changed != (len(f.auditor.events) == 1) {
+			t.Errorf("%s: audit = %+v", tc.name, f.auditor.events)
+		}
+	}
+}
+
+func TestHandleCallback_RejectsBadToken(t *testing.T) {
+	f := newDisbursementFixture(t, model.DisbursementStatusProcessing)
+	header, body := callback(payout.StatusSucceeded)
+	header.Set(payout.CallbackTokenHeader, "guess")
+
+	if _, err := f.uc.HandleCallback(context.Background(), header, body); err == nil {
+		t.Fatal("expected the callback to be refused")
+	}
+	if f.disbursementRepo.saves != 0 {
+		t.Error("an unauthenticated callback changed the disbursement")
+	}
+}
+
+func TestCompleteManually(t *testing.T) {
+	f := newDisbursementFixture(t, model.DisbursementStatusPending)
+	paidAt := time.Now().AddDate(0, 0, -20)
+
+	if _, err := f.uc.CompleteManually(context.Background(), 7, "seed", "CN-7", paidAt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d := f.disbursementRepo.d
+	if d.Status != model.DisbursementStatusSucceeded || d.Provider != "seed" || !d.DisbursedAt.Equal(paidAt) {
+		t.Errorf("disbursement = %+v, want it paid by seed on %s", d, paidAt)
+	}
+	firstDue := paidAt.AddDate(0, 1, 0).Format("2006-01-02")
+	if len(f.installmentRepo.created) != 3 || f.installmentRepo.created[0].DueDate.Format("2006-01-02") != firstDue {
+		t.Errorf("schedule = %+v, want it to start from the payout date", f.installmentRepo.created)
+	}
+	if len(f.provider.Requests()) != 0 {
+		t.Error("a manual completion was sent to the provider")
+	}
+}
+
+// stuck leaves the fixture's disbursement processing since claimedAgo, after
+// one attempt.
+func (f *disbursementFixture) stuck(claimedAgo time.Duration) {
+	claimedAt := time.Now().Add(-claimedAgo)
+	f.disbursementRepo.d.Status = model.DisbursementStatusProcessing
+	f.disbursementRepo.d.Attempts = 1
+	f.disbursementRepo.d.ClaimedAt = &claimedAt
+}
+
+func TestProcessDue_ReleasesStaleProcessing(t *testing.T) {
+	f := newDisbursementFixture(t, model.DisbursementStatusPending)
+	f.stuck(time.Hour)
+	now := time.Now()
+
+	result, err := f.uc.ProcessDue(context.Background(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d := f.disbursementRepo.d
+	if result.Released != 1 || result.Attempted != 0 || d.Status != model.DisbursementStatusPending || d.NextAttemptAt == nil {
+		t.Fatalf("result = %+v, disbursement = %+v, want it queued for a retry", result, d)
+	}
+	if d.LastError != "no outcome from the payout provider" || len(f.auditor.events) != 1 {
+		t.Errorf("last error = %q, audit = %+v", d.LastError, f.auditor.events)
+	}
+
+	result, err = f.uc.ProcessDue(context.Background(), now.Add(2*time.Minute))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Succeeded != 1 || f.disbursementRepo.d.Attempts != 2 || f.txRepo.fields["status"] != model.TransactionStatusOngoing {
+		t.Errorf("result = %+v, disbursement = %+v, want the second attempt to pay out", result, f.disbursementRepo.d)
+	}
+	if requests := f.provider.Requests(); len(requests) != 1 || requests[0].Reference != "DSB-CN-7" {
+		t.Errorf("payout requests = %+v, want the original reference", requests)
+	}
+}
+
+func TestProcessDue_FailsStaleProcessingOutOfAttempts(t *testing.T) {
+	f := newDisbursementFixture(t, model.DisbursementStatusPending)
+	f.stuck(time.Hour)
+	f.disbursementRepo.d.Attempts = 3
+
+	result, err := f.uc.ProcessDue(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Released != 1 || result.Failed != 1 || f.disbursementRepo.d.Status != model.DisbursementStatusFailed {
+		t.Errorf("result = %+v, disbursement = %+v, want it failed", result, f.disbursementRepo.d)
+	}
+}
+
+func TestProcessDue_WaitsForRecentProcessing(t *testing.T) {
+	f := newDisbursementFixture(t, model.DisbursementStatusPending)
+	f.stuck(5 * time.Minute)
+
+	result, err := f.uc.ProcessDue(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Released != 0 || f.disbursementRepo.d.Status != model.DisbursementStatusProcessing || f.disbursementRepo.saves != 0 {
+		t.Errorf("result = %+v, disbursement = %+v, want it left processing", result, f.disbursementRepo.d)
+	}
+}
+
+func TestStaleProcessing_AdminRetryAndCompletion(t *testing.T) {
+	cases := []struct {
+		name       string
+		claimedAgo time.Duration
+		wantErr    bool
+	}{
+		{"recent claim", 5 * time.Minute, true},
+		{"stale claim", time.Hour, false},
+	}
+	for _, tc := range cases {
+		f := newDisbursementFixture(t, model.DisbursementStatusPending)
+		f.stuck(tc.claimedAgo)
+		if err := f.uc.RetryDisbursement(9); (err != nil) != tc.wantErr {
+			t.Errorf("%s: retry err = %v, want error %v", tc.name, err, tc.wantErr)
+		}
+
+		f = newDisbursementFixture(t, model.DisbursementStatusPending)
+		f.stuck(tc.claimedAgo)
+		_, err := f.uc.CompleteManually(context.Background(), 7, usecase.DisbursementProviderManual, "BANK-1", time.Now())
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: complete err = %v, want error %v", tc.name, err, tc.wantErr)
+		}
+		if completed := f.disbursementRepo.d.Status == model.DisbursementStatusSucceeded; completed == tc.wantErr {
+			t.Errorf("%s: disbursement = %+v", tc.name, f.disbursementRepo.d)
+		}
+	}
+}
+
+func TestGetDisbursementByTransaction_OnlyForOwnerOrAdmin(t *testing.T) {
+	f := newDisbursementFixture(t, model.DisbursementStatusPending)
+
+	if _, err := f.uc.GetDisbursementByTransaction(7, usecase.Viewer{UserID: 12, Role: "customer"}); !errors.Is(err, usecase.ErrCustomerForbidden) {
+		t.Errorf("err = %v, want another user's disbursement forbidden", err)
+	}
+	for _, viewer := range []usecase.Viewer{{UserID: 11, Role: "customer"}, {UserID: 1, Role: "admin"}} {
+		if d, err := f.uc.GetDisbursementByTransaction(7, viewer); err != nil || d.ID != 9 {
+			t.Errorf("%+v: disbursement = %+v, err = %v, want the contract's disbursement", viewer, d, err)
+		}
+	}
+}
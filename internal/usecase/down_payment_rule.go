@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+)
+
+type DownPaymentRuleUsecase interface {
+	CreateRule(rule *model.DownPaymentRule) error
+	DeleteRule(id uint) error
+	GetRules() ([]model.DownPaymentRule, error)
+}
+
+type downPaymentRuleUsecase struct {
+	ruleRepo repository.DownPaymentRuleRepository
+}
+
+func NewDownPaymentRuleUsecase(ruleRepo repository.DownPaymentRuleRepository) DownPaymentRuleUsecase {
+	return &downPaymentRuleUsecase{ruleRepo: ruleRepo}
+}
+
+func (uc *downPaymentRuleUsecase) CreateRule(rule *model.DownPaymentRule) error {
+	if rule.Category != "" && !model.IsValidAssetCategory(rule.Category) {
+		return errors.New("category must be empty or one of white_goods, motorcycle, car")
+	}
+	if rule.Tenor < 0 {
+		return errors.New("tenor must not be negative")
+	}
+	if rule.MinPercent < 0 || rule.MinPercent >= 100 {
+		return errors.New("min_percent must be between 0 and 99")
+	}
+
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = time.Now()
+	return uc.ruleRepo.Create(rule)
+}
+
+func (uc *downPaymentRuleUsecase) DeleteRule(id uint) error {
+	if _, err := uc.ruleRepo.FindByID(id); err != nil {
+		return errors.New("rule not found")
+	}
+	return uc.ruleRepo.Delete(id)
+}
+
+func (uc *downPaymentRuleUsecase) GetRules() ([]model.DownPaymentRule, error) {
+	return uc.ruleRepo.FindAll()
+}
+
+// mostSpecificRule picks the rule that matches both category and tenor over
+// one that only matches one of them, and either of those over a catch-all.
+func mostSpecificRule(rules []model.DownPaymentRule) *model.DownPaymentRule {
+	var best *model.DownPaymentRule
+	bestScore := -1
+	for i := range rules {
+		score := 0
+		if rules[i].Category != "" {
+			score += 2
+		}
+		if rules[i].Tenor != 0 {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = &rules[i], score
+		}
+	}
+	return best
+}
@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+)
+
+// Exported for the tests in usecase_test.
+
+var MostSpecificRule = mostSpecificRule
+
+func CheckDownPayment(dpRuleRepo repository.DownPaymentRuleRepository, tx *model.Transaction, category string) error {
+	uc := &transactionUsecase{dpRuleRepo: dpRuleRepo}
+	return uc.checkDownPayment(tx, category)
+}
+
+var MatchLine = matchLine
+
+var AllocatePayment = allocatePayment
+
+var OldestOverdueDPD = oldestOverdueDPD
+
+var ReverseAllocations = reverseAllocations
+
+var EarnedInterest = earnedInterest
+
+var AccrualDates = accrualDates
+
+func Backoff(policy DisbursementPolicy, attempts int) time.Duration {
+	return policy.backoff(attempts)
+}
+
+var ApplyPricing = applyPricing
+
+var SetInstallmentAmount = setInstallmentAmount
@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/pkg/redact"
+)
+
+type ExposureUsecase interface {
+	// GetExposureByNIK returns ErrCustomerForbidden unless the viewer is an
+	// admin or the customer's own user.
+	GetExposureByNIK(nik string, viewer Viewer) (*CustomerExposure, error)
+}
+
+type exposureUsecase struct {
+	customerRepo    repository.CustomerRepository
+	limitRepo       repository.LimitRepository
+	transactionRepo repository.TransactionRepository
+}
+
+func NewExposureUsecase(
+	customerRepo repository.CustomerRepository,
+	limitRepo repository.LimitRepository,
+	transactionRepo repository.TransactionRepository,
+) ExposureUsecase {
+	return &exposureUsecase{
+		customerRepo:    customerRepo,
+		limitRepo:       limitRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+type TenorExposure struct {
+	Tenor                int        `json:"tenor,omitempty"`
+	LimitAmount          int64      `json:"limit_amount"`
+	UsedAmount           int64      `json:"used_amount"`
+	RemainingLimit       int64      `json:"remaining_limit"`
+	ActiveContracts      int        `json:"active_contracts"`
+	OutstandingPrincipal int64      `json:"outstanding_principal"`
+	OverdueAmount        int64      `json:"overdue_amount"`
+	NextDueDate          *time.Time `json:"next_due_date"`
+	UtilizationRatio     float64    `json:"utilization_ratio"`
+}
+
+type CustomerExposure struct {
+	CustomerID uint            `json:"customer_id"`
+	NIK        string          `json:"nik"`
+	AsOf       time.Time       `json:"as_of"`
+	Tenors     []TenorExposure `json:"tenors"`
+	Total      TenorExposure   `json:"total"`
+}
+
+func (uc *exposureUsecase) GetExposureByNIK(nik string, viewer Viewer) (*CustomerExposure, error) {
+	customer, err := uc.customerRepo.FindByNIK(nik)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+	if err := authorizeCustomer(customer, viewer); err != nil {
+		return nil, err
+	}
+
+	asOf := startOfDay(time.Now())
+
+	limits, err := uc.limitRepo.FindByCustomerID(customer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := uc.transactionRepo.AggregateExposure(customer.ID, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	byTenor := make(map[int]*TenorExposure)
+	var tenors []int
+	entry := func(tenor int) *TenorExposure {
+		if e, ok := byTenor[tenor]; ok {
+			return e
+		}
+		e := &TenorExposure{Tenor: tenor}
+		byTenor[tenor] = e
+		tenors = append(tenors, tenor)
+		return e
+	}
+
+	for _, l := range limits {
+		entry(l.Tenor).LimitAmount += l.Limit
+	}
+	for _, row := range rows {
+		e := entry(row.Tenor)
+		e.ActiveContracts = row.ActiveContracts
+		e.UsedAmount = row.UsedAmount
+		e.OutstandingPrincipal = row.OutstandingPrincipal
+		e.OverdueAmount = row.OverdueAmount
+		e.NextDueDate = row.NextDueDate
+	}
+
+	exposure := &CustomerExposure{
+		CustomerID: customer.ID,
+		NIK:        redact.MaskNIK(customer.NIK),
+		AsOf:       asOf,
+		Tenors:     make([]TenorExposure, 0, len(tenors)),
+	}
+
+	sort.Ints(tenors)
+	total := &exposure.Total
+	for _, tenor := range tenors {
+		e := byTenor[tenor]
+		e.RemainingLimit = e.LimitAmount - e.UsedAmount
+		e.UtilizationRatio = utilization(e.UsedAmount, e.LimitAmount)
+		exposure.Tenors = append(exposure.Tenors, *e)
+
+		total.LimitAmount += e.LimitAmount
+		total.UsedAmount += e.UsedAmount
+		total.ActiveContracts += e.ActiveContracts
+		total.OutstandingPrincipal += e.OutstandingPrincipal
+		total.OverdueAmount += e.OverdueAmount
+		if e.NextDueDate != nil && (total.NextDueDate == nil || e.NextDueDate.Before(*total.NextDueDate)) {
+			total.NextDueDate = e.NextDueDate
+		}
+	}
+	total.RemainingLimit = total.LimitAmount - total.UsedAmount
+	total.UtilizationRatio = utilization(total.UsedAmount, total.LimitAmount)
+
+	return exposure, nil
+}
+
+func utilization(used, limit int64) float64 {
+	if limit <= 0 {
+		return 0
+	}
+	return float64(used) / float64(limit)
+}
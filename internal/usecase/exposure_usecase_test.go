@@ -0,0 +1,132 @@
+package usecase_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/internal/usecase"
+)
+
+type mockExposureLimitRepo struct {
+	repository.LimitRepository
+	limits []model.Limit
+}
+
+func (m *mockExposureLimitRepo) FindByCustomerID(customerID uint) ([]model.Limit, error) {
+	return m.limits, nil
+}
+
+type mockExposureTxRepo struct {
+	repository.TransactionRepository
+	rows []repository.ExposureRow
+}
+
+func (m *mockExposureTxRepo) AggregateExposure(customerID uint, asOf time.Time) ([]repository.ExposureRow, error) {
+	return m.rows, nil
+}
+
+func newExposureUsecase(limits []model.Limit, rows []repository.ExposureRow) usecase.ExposureUsecase {
+	customerRepo := &mockCustomerRepo{
+		FindByNIKFunc: func(nik string) (*model.Customer, error) {
+			if nik != "3201010101010001" {
+				return nil, errors.New("record not found")
+			}
+			return &model.Customer{ID: 3, UserID: 11, NIK: nik}, nil
+		},
+	}
+	return usecase.NewExposureUsecase(customerRepo, &mockExposureLimitRepo{limits: limits}, &mockExposureTxRepo{rows: rows})
+}
+
+func TestGetExposureByNIK_MultipleTenors(t *testing.T) {
+	soon := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	later := soon.AddDate(0, 0, 10)
+	uc := newExposureUsecase(
+		[]model.Limit{{Tenor: 12, Limit: 0}, {Tenor: 3, Limit: 5_000_000}, {Tenor: 6, Limit: 10_000_000}},
+		[]repository.ExposureRow{
+			{Tenor: 6, ActiveContracts: 2, UsedAmount: 4_000_000, OutstandingPrincipal: 3_000_000, OverdueAmount: 500_000, NextDueDate: &later},
+			{Tenor: 12, ActiveContracts: 1, UsedAmount: 1_000_000, OutstandingPrincipal: 900_000, NextDueDate: &soon},
+			{Tenor: 24, ActiveContracts: 1, UsedAmount: 2_000_000, OutstandingPrincipal: 2_000_000},
+		},
+	)
+
+	exposure, err := uc.GetExposureByNIK("3201010101010001", usecase.Viewer{Role: "admin"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []usecase.TenorExposure{
+		{Tenor: 3, LimitAmount: 5_000_000, RemainingLimit: 5_000_000},
+		{Tenor: 6, LimitAmount: 10_000_000, UsedAmount: 4_000_000, RemainingLimit: 6_000_000, ActiveContracts: 2,
+			OutstandingPrincipal: 3_000_000, OverdueAmount: 500_000, NextDueDate: &later, UtilizationRatio: 0.4},
+		// A zero limit, or none at all, cannot be utilized: the ratio stays
+		// zero and the remaining limit goes negative.
+		{Tenor: 12, UsedAmount: 1_000_000, RemainingLimit: -1_000_000, ActiveContracts: 1, OutstandingPrincipal: 900_000, NextDueDate: &soon},
+		{Tenor: 24, UsedAmount: 2_000_000, RemainingLimit: -2_000_000, ActiveContracts: 1, OutstandingPrincipal: 2_000_000},
+	}
+	if len(exposure.Tenors) != len(want) {
+		t.Fatalf("tenors = %+v, want %d", exposure.Tenors, len(want))
+	}
+	for i, got := range exposure.Tenors {
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(want[i])
+		if string(gotJSON) != string(wantJSON) {
+			t.Errorf("tenor %d = %s, want %s", want[i].Tenor, gotJSON, wantJSON)
+		}
+	}
+
+	total := exposure.Total
+	if total.LimitAmount != 15_000_000 || total.UsedAmount != 7_000_000 || total.RemainingLimit != 8_000_000 ||
+		total.ActiveContracts != 4 || total.OutstandingPrincipal != 5_900_000 || total.OverdueAmount != 500_000 {
+		t.Errorf("total = %+v", total)
+	}
+	if total.NextDueDate == nil || !total.NextDueDate.Equal(soon) {
+		t.Errorf("total next due date = %v, want the earliest, %s", total.NextDueDate, soon)
+	}
+	if total.UtilizationRatio < 0.466 || total.UtilizationRatio > 0.467 {
+		t.Errorf("total utilization = %f, want 7/15", total.UtilizationRatio)
+	}
+	if exposure.CustomerID != 3 || exposure.Total.Tenor != 0 {
+		t.Errorf("exposure = %+v", exposure)
+	}
+}
+
+func TestGetExposureByNIK_NoLimitsOrContracts(t *testing.T) {
+	uc := newExposureUsecase(nil, nil)
+
+	exposure, err := uc.GetExposureByNIK("3201010101010001", usecase.Viewer{Role: "admin"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body, _ := json.Marshal(exposure)
+	if !strings.Contains(string(body), `"tenors":[]`) {
+		t.Errorf("body = %s, want an empty tenors list", body)
+	}
+	if exposure.Total != (usecase.TenorExposure{}) {
+		t.Errorf("total = %+v, want all zero", exposure.Total)
+	}
+}
+
+func TestGetExposureByNIK_UnknownCustomer(t *testing.T) {
+	uc := newExposureUsecase(nil, nil)
+
+	if _, err := uc.GetExposureByNIK("3201010101010002", usecase.Viewer{Role: "admin"}); err == nil || err.Error() != "customer not found" {
+		t.Errorf("err = %v, want customer not found", err)
+	}
+}
+
+func TestGetExposureByNIK_OnlyForOwnerOrAdmin(t *testing.T) {
+	uc := newExposureUsecase(nil, nil)
+
+	if _, err := uc.GetExposureByNIK("3201010101010001", usecase.Viewer{UserID: 12, Role: "customer"}); !errors.Is(err, usecase.ErrCustomerForbidden) {
+		t.Errorf("err = %v, want another user's exposure forbidden", err)
+	}
+	if _, err := uc.GetExposureByNIK("3201010101010001", usecase.Viewer{UserID: 11, Role: "customer"}); err != nil {
+		t.Errorf("err = %v, want the customer's own exposure", err)
+	}
+}
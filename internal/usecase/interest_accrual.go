@@ -0,0 +1,244 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/logger"
+	"xyz-multifinance/pkg/money"
+
+	"gorm.io/gorm"
+)
+
+type AccrualUsecase interface {
+	// Run recognises the interest earned by every active contract up to
+	// asOf. Dates missed since the last run are caught up and dates already
+	// accrued are skipped, so it can be re-run safely.
+	Run(asOf time.Time) (*AccrualRunResult, error)
+	GetReport(from, to time.Time, groupBy string) (*AccrualReport, error)
+}
+
+type AccrualRunResult struct {
+	AsOf      time.Time `json:"as_of"`
+	Mode      string    `json:"mode"`
+	Contracts int       `json:"contracts"`
+	Accruals  int       `json:"accruals"`
+	Amount    int64     `json:"amount"`
+}
+
+type AccrualReport struct {
+	From    time.Time                     `json:"from"`
+	To      time.Time                     `json:"to"`
+	GroupBy string                        `json:"group_by"`
+	Rows    []repository.AccrualReportRow `json:"rows"`
+	Total   int64                         `json:"total"`
+}
+
+type accrualUsecase struct {
+	accrualRepo     repository.InterestAccrualRepository
+	txRepo          repository.TransactionRepository
+	installmentRepo repository.InstallmentRepository
+	ledgerRepo      repository.LedgerRepository
+	mode            string
+	db              *gorm.DB
+}
+
+func NewAccrualUsecase(
+	accrualRepo repository.InterestAccrualRepository,
+	txRepo repository.TransactionRepository,
+	installmentRepo repository.InstallmentRepository,
+	ledgerRepo repository.LedgerRepository,
+	mode string,
+	db *gorm.DB,
+) AccrualUsecase {
+	return &accrualUsecase{
+		accrualRepo:     accrualRepo,
+		txRepo:          txRepo,
+		installmentRepo: installmentRepo,
+		ledgerRepo:      ledgerRepo,
+		mode:            mode,
+		db:              db,
+	}
+}
+
+func (uc *accrualUsecase) Run(asOf time.Time) (*AccrualRunResult, error) {
+	asOf = startOfDay(asOf)
+	result := &AccrualRunResult{AsOf: asOf, Mode: uc.mode}
+
+	transactions, err := uc.txRepo.FindActive()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, 0, len(transactions))
+	for _, tx := range transactions {
+		ids = append(ids, tx.ID)
+	}
+
+	installments, err := uc.installmentRepo.FindByTransactionIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	schedules := make(map[uint][]model.Installment, len(ids))
+	for _, inst := range installments {
+		schedules[inst.TransactionID] = append(schedules[inst.TransactionID], inst)
+	}
+
+	summaries, err := uc.accrualRepo.SummarizeByTransaction(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range transactions {
+		tx := &transactions[i]
+		activated := tx.CreatedAt
+		if tx.DisbursedAt != nil {
+			activated = *tx.DisbursedAt
+		}
+		start := startOfDay(activated.In(asOf.Location()))
+		from := start.AddDate(0, 0, 1)
+		accrued := int64(0)
+		if summary, ok := summaries[tx.ID]; ok {
+			last := summary.LastAccrualDate
+			from = time.Date(last.Year(), last.Month(), last.Day()+1, 0, 0, 0, 0, asOf.Location())
+			accrued = summary.Total
+		}
+
+		count, amount, err := uc.accrueContract(tx, schedules[tx.ID], start, accrued, accrualDates(from, asOf, uc.mode))
+		if err != nil {
+			return nil, fmt.Errorf("accrue contract %d: %w", tx.ID, err)
+		}
+		if count > 0 {
+			result.Contracts++
+			result.Accruals += count
+			result.Amount += amount
+		}
+	}
+
+	logger.Log.WithField("as_of", asOf.Format("2006-01-02")).
+		Infof("interest accrued (%s): %d contracts, %d accruals, amount %d",
+			uc.mode, result.Contracts, result.Accruals, result.Amount)
+
+	return result, nil
+}
+
+// accrueContract posts one accrual per date for the interest earned since
+// the previous date. Each accrual moves the amount from unearned interest to
+// interest income.
+func (uc *accrualUsecase) accrueContract(tx *model.Transaction, schedule []model.Installment, start time.Time, accrued int64, dates []time.Time) (int, int64, error) {
+	var count int
+	var total int64
+	err := uc.db.Transaction(func(txDB *gorm.DB) error {
+		for _, date := range dates {
+			earned, err := earnedInterest(tx, schedule, start, date)
+			if err != nil {
+				return err
+			}
+			amount := earned - accrued
+			if amount <= 0 {
+				continue
+			}
+
+			entry := journalEntry(model.JournalTypeInterestAccrual,
+				fmt.Sprintf("accrual:%d:%s", tx.ID, date.Format("20060102")), tx.ID, date,
+				fmt.Sprintf("Interest accrual of contract %s", tx.ContractNumber),
+				debit(model.AccountUnearnedInterest, amount),
+				credit(model.AccountInterestIncome, amount),
+			)
+			if err := postEntry(txDB, uc.ledgerRepo, entry); err != nil {
+				return err
+			}
+
+			accrual := &model.InterestAccrual{
+				TransactionID:  tx.ID,
+				AccrualDate:    date,
+				Amount:         amount,
+				JournalEntryID: entry.ID,
+			}
+			if err := uc.accrualRepo.Create(txDB, accrual); err != nil {
+				return err
+			}
+
+			accrued = earned
+			count++
+			total += amount
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return count, total, nil
+}
+
+func (uc *accrualUsecase) GetReport(from, to time.Time, groupBy string) (*AccrualReport, error) {
+	if groupBy == "" {
+		groupBy = "day"
+	}
+	if groupBy != "day" && groupBy != "month" {
+		return nil, errors.New("group_by must be day or month")
+	}
+	if to.Before(from) {
+		return nil, errors.New("to must not be before from")
+	}
+
+	rows, err := uc.accrualRepo.Report(from, to, groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &AccrualReport{From: from, To: to, GroupBy: groupBy, Rows: rows}
+	if report.Rows == nil {
+		report.Rows = []repository.AccrualReportRow{}
+	}
+	for _, row := range rows {
+		report.Total += row.Amount
+	}
+	return report, nil
+}
+
+// earnedInterest is the interest of a contract earned by the end of date.
+// Each installment's interest is earned evenly over the days of its period,
+// from the previous due date (or the contract start) to its own due date.
+// Partial days are rounded down, so the full interest is reached exactly on
+// the due date.
+func earnedInterest(tx *model.Transaction, schedule []model.Installment, start, date time.Time) (int64, error) {
+	var earned int64
+	periodStart := start
+	for _, inst := range schedule {
+		periodDays := daysBetween(periodStart, inst.DueDate)
+		elapsed := daysBetween(periodStart, date)
+
+		switch {
+		case elapsed >= periodDays:
+			earned += inst.Interest
+		case elapsed > 0:
+			part, err := txMoney(tx, inst.Interest).MulRatio(int64(elapsed), int64(periodDays), money.RoundDown)
+			if err != nil {
+				return 0, err
+			}
+			earned += part.Amount()
+		}
+		periodStart = inst.DueDate
+	}
+	return earned, nil
+}
+
+// accrualDates lists the dates to accrue from from to to inclusive: every
+// day in daily mode, or every month end in monthly mode.
+func accrualDates(from, to time.Time, mode string) []time.Time {
+	var dates []time.Time
+	if mode == model.AccrualModeMonthly {
+		for d := time.Date(from.Year(), from.Month()+1, 0, 0, 0, 0, 0, from.Location()); !d.After(to); d = time.Date(d.Year(), d.Month()+2, 0, 0, 0, 0, 0, d.Location()) {
+			dates = append(dates, d)
+		}
+		return dates
+	}
+
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d)
+	}
+	return dates
+}
@@ -0,0 +1,214 @@
+package usecase_test
+
+import (
+	"testing"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/internal/usecase"
+
+	"gorm.io/gorm"
+)
+
+func accrualDay(month time.Month, day int) time.Time {
+	return time.Date(2024, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// accrualSchedule is a two-month contract starting on 1 January 2024 whose
+// installments each carry 100 of interest over a 30-day period.
+func accrualSchedule() []model.Installment {
+	return []model.Installment{
+		{ID: 1, TransactionID: 7, Sequence: 1, DueDate: accrualDay(time.January, 31), Interest: 100},
+		{ID: 2, TransactionID: 7, Sequence: 2, DueDate: accrualDay(time.March, 1), Interest: 100},
+	}
+}
+
+func TestEarnedInterest(t *testing.T) {
+	tx := &model.Transaction{ID: 7, Currency: "IDR"}
+	start := accrualDay(time.January, 1)
+
+	cases := []struct {
+		name string
+		date time.Time
+		want int64
+	}{
+		{"contract start", start, 0},
+		{"partial days round down", accrualDay(time.January, 11), 33},
+		{"first due date", accrualDay(time.January, 31), 100},
+		{"halfway through the second period", accrualDay(time.February, 15), 150},
+		{"day before the last due date", accrualDay(time.February, 29), 196},
+		{"last due date", accrualDay(time.March, 1), 200},
+		{"after the schedule", accrualDay(time.June, 1), 200},
+	}
+	for _, tc := range cases {
+		got, err := usecase.EarnedInterest(tx, accrualSchedule(), start, tc.date)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: earned = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestAccrualDates(t *testing.T) {
+	cases := []struct {
+		name     string
+		from, to time.Time
+		mode     string
+		want     []time.Time
+	}{
+		{"daily", accrualDay(time.January, 30), accrualDay(time.February, 1), model.AccrualModeDaily,
+			[]time.Time{accrualDay(time.January, 30), accrualDay(time.January, 31), accrualDay(time.February, 1)}},
+		{"daily up to date", accrualDay(time.February, 2), accrualDay(time.February, 1), model.AccrualModeDaily, nil},
+		{"monthly", accrualDay(time.January, 15), accrualDay(time.March, 31), model.AccrualModeMonthly,
+			[]time.Time{accrualDay(time.January, 31), accrualDay(time.February, 29), accrualDay(time.March, 31)}},
+		{"monthly before month end", accrualDay(time.January, 15), accrualDay(time.March, 30), model.AccrualModeMonthly,
+			[]time.Time{accrualDay(time.January, 31), accrualDay(time.February, 29)}},
+	}
+	for _, tc := range cases {
+		got := usecase.AccrualDates(tc.from, tc.to, tc.mode)
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: dates = %v, want %v", tc.name, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if !got[i].Equal(tc.want[i]) {
+				t.Errorf("%s: dates = %v, want %v", tc.name, got, tc.want)
+				break
+			}
+		}
+	}
+}
+
+// mockAccrualRepo keeps the accruals it is given and summarizes them like
+// the database would, so consecutive runs see each other's work.
+type mockAccrualRepo struct {
+	repository.InterestAccrualRepository
+	accruals []model.InterestAccrual
+}
+
+func (m *mockAccrualRepo) Create(db *gorm.DB, accrual *model.InterestAccrual) error {
+	m.accruals = append(m.accruals, *accrual)
+	return nil
+}
+
+func (m *mockAccrualRepo) SummarizeByTransaction(transactionIDs []uint) (map[uint]repository.AccrualSummary, error) {
+	summaries := make(map[uint]repository.AccrualSummary)
+	for _, accrual := range m.accruals {
+		summary := summaries[accrual.TransactionID]
+		summary.TransactionID = accrual.TransactionID
+		summary.Total += accrual.Amount
+		if accrual.AccrualDate.After(summary.LastAccrualDate) {
+			summary.LastAccrualDate = accrual.AccrualDate
+		}
+		summaries[accrual.TransactionID] = summary
+	}
+	return summaries, nil
+}
+
+type mockAccrualTxRepo struct {
+	repository.TransactionRepository
+	transactions []model.Transaction
+}
+
+func (m *mockAccrualTxRepo) FindActive() ([]model.Transaction, error) {
+	return m.transactions, nil
+}
+
+type mockAccrualInstallmentRepo struct {
+	repository.InstallmentRepository
+	installments []model.Installment
+}
+
+func (m *mockAccrualInstallmentRepo) FindByTransactionIDs(transactionIDs []uint) ([]model.Installment, error) {
+	return m.installments, nil
+}
+
+func newAccrualUsecase(t *testing.T, mode string) (usecase.AccrualUsecase, *mockAccrualRepo, *mockJournalRepo) {
+	discardLogs(t)
+
+	disbursedAt := accrualDay(time.January, 1).Add(10 * time.Hour)
+	accrualRepo := &mockAccrualRepo{}
+	ledgerRepo := &mockJournalRepo{}
+	uc := usecase.NewAccrualUsecase(
+		accrualRepo,
+		&mockAccrualTxRepo{transactions: []model.Transaction{{ID: 7, ContractNumber: "CN-7", Currency: "IDR", DisbursedAt: &disbursedAt}}},
+		&mockAccrualInstallmentRepo{installments: accrualSchedule()},
+		ledgerRepo, mode, newTestDB(t),
+	)
+	return uc, accrualRepo, ledgerRepo
+}
+
+func TestAccrualRun_CatchesUpMissedDays(t *testing.T) {
+	uc, accrualRepo, ledgerRepo := newAccrualUsecase(t, model.AccrualModeDaily)
+
+	result, err := uc.Run(accrualDay(time.January, 5).Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Earned by the end of 2, 3, 4 and 5 January: 3, 6, 10 and 13.
+	want := []int64{3, 3, 4, 3}
+	if result.Contracts != 1 || result.Accruals != 4 || result.Amount != 13 {
+		t.Errorf("result = %+v, want 4 accruals totalling 13", result)
+	}
+	if len(accrualRepo.accruals) != len(want) {
+		t.Fatalf("accruals = %+v, want %v", accrualRepo.accruals, want)
+	}
+	for i, accrual := range accrualRepo.accruals {
+		if accrual.Amount != want[i] || !accrual.AccrualDate.Equal(accrualDay(time.January, 2+i)) {
+			t.Errorf("accrual %d = %s %d, want %s %d", i, accrual.AccrualDate.Format("2006-01-02"), accrual.Amount,
+				accrualDay(time.January, 2+i).Format("2006-01-02"), want[i])
+		}
+	}
+	if len(ledgerRepo.entries) != 4 || ledgerRepo.entries[0].Reference != "accrual:7:20240102" {
+		t.Errorf("entries = %+v, want one per accrual", ledgerRepo.entries)
+	}
+}
+
+func TestAccrualRun_IsIdempotent(t *testing.T) {
+	uc, accrualRepo, ledgerRepo := newAccrualUsecase(t, model.AccrualModeDaily)
+	asOf := accrualDay(time.January, 5)
+
+	if _, err := uc.Run(asOf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := uc.Run(asOf)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Accruals != 0 || len(accrualRepo.accruals) != 4 || len(ledgerRepo.entries) != 4 {
+		t.Errorf("re-run posted %d accruals, %d stored in total, want none new", result.Accruals, len(accrualRepo.accruals))
+	}
+
+	result, err = uc.Run(accrualDay(time.January, 6))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Accruals != 1 || result.Amount != 3 {
+		t.Errorf("next day = %+v, want only 6 January accrued", result)
+	}
+}
+
+func TestAccrualRun_MonthlyMode(t *testing.T) {
+	uc, accrualRepo, _ := newAccrualUsecase(t, model.AccrualModeMonthly)
+
+	result, err := uc.Run(accrualDay(time.April, 10))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The last 4 of interest is earned on 1 March and booked at the March
+	// month end.
+	if result.Accruals != 3 || result.Amount != 200 {
+		t.Errorf("result = %+v, want 200 over the January, February and March month ends", result)
+	}
+	want := map[string]int64{"2024-01-31": 100, "2024-02-29": 96, "2024-03-31": 4}
+	for _, accrual := range accrualRepo.accruals {
+		if date := accrual.AccrualDate.Format("2006-01-02"); want[date] != accrual.Amount {
+			t.Errorf("accrual on %s = %d, want %d", date, accrual.Amount, want[date])
+		}
+	}
+}
@@ -0,0 +1,277 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/pkg/imageupload"
+	"xyz-multifinance/pkg/urlsign"
+	"xyz-multifinance/storage"
+)
+
+var (
+	ErrInvalidDocumentRequest = errors.New("invalid document request")
+	ErrDocumentNotFound       = errors.New("document not found")
+	ErrDocumentForbidden      = errors.New("not allowed to view this document")
+	ErrInvalidDocumentLink    = errors.New("invalid or expired document link")
+	ErrThumbnailUnavailable   = errors.New("no thumbnail can be made of this document")
+)
+
+// KYCDocumentPolicy configures how KYC documents are served. BaseURL is the
+// public URL of the API (".../api/v1") that signed links point at.
+type KYCDocumentPolicy struct {
+	BaseURL       string
+	URLTTL        time.Duration
+	MaxURLTTL     time.Duration
+	ThumbnailSize int
+}
+
+// DocumentViewer is the user asking for a document, as needed for the
+// permission check and the audit trail.
+type DocumentViewer struct {
+	UserID    uint
+	Role      string
+	IPAddress string
+	UserAgent string
+}
+
+// KYCDocument is a document ready to be streamed. The caller must close
+// Body.
+type KYCDocument struct {
+	Filename    string
+	ContentType string
+	Size        int64
+	Body        io.ReadCloser
+}
+
+type SignedDocumentURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type KYCDocumentUsecase interface {
+	// GetDocument returns a customer's KYC document to its owner or an
+	// admin and records the view.
+	GetDocument(ctx context.Context, nik, docType, variant string, viewer DocumentViewer) (*KYCDocument, error)
+	// IssueSignedURL returns a link that serves the document without
+	// authentication until it expires. Views through the link are recorded
+	// against the user it was issued to.
+	IssueSignedURL(nik, docType, variant string, ttl time.Duration, viewer DocumentViewer) (*SignedDocumentURL, error)
+	OpenSignedDocument(ctx context.Context, customerID uint, docType string, query url.Values, viewer DocumentViewer) (*KYCDocument, error)
+	GetAccessLog(nik string, limit int) ([]model.KYCDocumentAccess, error)
+}
+
+type kycDocumentUsecase struct {
+	customerRepo repository.CustomerRepository
+	accessRepo   repository.KYCDocumentAccessRepository
+	store        storage.BlobStore
+	signer       *urlsign.Signer
+	policy       KYCDocumentPolicy
+}
+
+func NewKYCDocumentUsecase(
+	customerRepo repository.CustomerRepository,
+	accessRepo repository.KYCDocumentAccessRepository,
+	store storage.BlobStore,
+	signer *urlsign.Signer,
+	policy KYCDocumentPolicy,
+) KYCDocumentUsecase {
+	policy.BaseURL = strings.TrimSuffix(policy.BaseURL, "/")
+	return &kycDocumentUsecase{
+		customerRepo: customerRepo,
+		accessRepo:   accessRepo,
+		store:        store,
+		signer:       signer,
+		policy:       policy,
+	}
+}
+
+func (uc *kycDocumentUsecase) GetDocument(ctx context.Context, nik, docType, variant string, viewer DocumentViewer) (*KYCDocument, error) {
+	variant, err := validateDocumentRequest(docType, variant)
+	if err != nil {
+		return nil, err
+	}
+	customer, err := uc.customerRepo.FindByNIK(nik)
+	if err != nil {
+		return nil, ErrDocumentNotFound
+	}
+	if !customer.AccessibleBy(viewer.UserID, viewer.Role) {
+		return nil, ErrDocumentForbidden
+	}
+	return uc.serve(ctx, customer, docType, variant, model.KYCChannelAPI, viewer)
+}
+
+func (uc *kycDocumentUsecase) IssueSignedURL(nik, docType, variant string, ttl time.Duration, viewer DocumentViewer) (*SignedDocumentURL, error) {
+	variant, err := validateDocumentRequest(docType, variant)
+	if err != nil {
+		return nil, err
+	}
+	customer, err := uc.customerRepo.FindByNIK(nik)
+	if err != nil {
+		return nil, ErrDocumentNotFound
+	}
+	if !customer.AccessibleBy(viewer.UserID, viewer.Role) {
+		return nil, ErrDocumentForbidden
+	}
+	if documentKey(customer, docType) == "" {
+		return nil, ErrDocumentNotFound
+	}
+
+	if ttl <= 0 {
+		ttl = uc.policy.URLTTL
+	}
+	if uc.policy.MaxURLTTL > 0 && ttl > uc.policy.MaxURLTTL {
+		ttl = uc.policy.MaxURLTTL
+	}
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+
+	query := uc.signer.Sign(signedDocumentPath(customer.ID, docType, variant, viewer.UserID), expiresAt)
+	query.Set("variant", variant)
+	query.Set("viewer", strconv.FormatUint(uint64(viewer.UserID), 10))
+
+	if err := uc.record(customer.ID, docType, variant, model.KYCAccessURLIssued, model.KYCChannelAPI, viewer); err != nil {
+		return nil, err
+	}
+	return &SignedDocumentURL{
+		URL:       fmt.Sprintf("%s/kyc-documents/%d/%s?%s", uc.policy.BaseURL, customer.ID, docType, query.Encode()),
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// OpenSignedDocument serves a document through a link from IssueSignedURL.
+// The viewer only contributes the request's address and user agent; the
+// user is the one the link was issued to.
+func (uc *kycDocumentUsecase) OpenSignedDocument(ctx context.Context, customerID uint, docType string, query url.Values, viewer DocumentViewer) (*KYCDocument, error) {
+	variant, err := validateDocumentRequest(docType, query.Get("variant"))
+	if err != nil {
+		return nil, ErrInvalidDocumentLink
+	}
+	issuedTo, err := strconv.ParseUint(query.Get("viewer"), 10, 64)
+	if err != nil {
+		return nil, ErrInvalidDocumentLink
+	}
+	if err := uc.signer.Verify(signedDocumentPath(customerID, docType, variant, uint(issuedTo)), query, time.Now()); err != nil {
+		return nil, ErrInvalidDocumentLink
+	}
+
+	customer, err := uc.customerRepo.FindByID(customerID)
+	if err != nil {
+		return nil, ErrDocumentNotFound
+	}
+	viewer.UserID = uint(issuedTo)
+	return uc.serve(ctx, customer, docType, variant, model.KYCChannelSignedURL, viewer)
+}
+
+func (uc *kycDocumentUsecase) GetAccessLog(nik string, limit int) ([]model.KYCDocumentAccess, error) {
+	customer, err := uc.customerRepo.FindByNIK(nik)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return uc.accessRepo.FindByCustomerID(customer.ID, limit)
+}
+
+// serve loads a document and records the view before any of it is
+// returned, so nothing is served without an audit entry.
+func (uc *kycDocumentUsecase) serve(ctx context.Context, customer *model.Customer, docType, variant, channel string, viewer DocumentViewer) (*KYCDocument, error) {
+	key := documentKey(customer, docType)
+	if key == "" {
+		return nil, ErrDocumentNotFound
+	}
+	body, info, err := uc.store.Get(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrDocumentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &KYCDocument{
+		Filename:    fmt.Sprintf("%s-%d%s", docType, customer.ID, path.Ext(key)),
+		ContentType: info.ContentType,
+		Size:        info.Size,
+		Body:        body,
+	}
+	if variant == model.KYCVariantThumbnail {
+		data, err := io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return nil, err
+		}
+		thumb, err := imageupload.Thumbnail(data, uc.policy.ThumbnailSize)
+		var imageErr *imageupload.Error
+		if errors.As(err, &imageErr) {
+			return nil, ErrThumbnailUnavailable
+		}
+		if err != nil {
+			return nil, err
+		}
+		doc = &KYCDocument{
+			Filename:    fmt.Sprintf("%s-%d-thumbnail%s", docType, customer.ID, thumb.Extension),
+			ContentType: thumb.ContentType,
+			Size:        int64(len(thumb.Data)),
+			Body:        io.NopCloser(bytes.NewReader(thumb.Data)),
+		}
+	}
+
+	if err := uc.record(customer.ID, docType, variant, model.KYCAccessView, channel, viewer); err != nil {
+		doc.Body.Close()
+		return nil, err
+	}
+	return doc, nil
+}
+
+func (uc *kycDocumentUsecase) record(customerID uint, docType, variant, action, channel string, viewer DocumentViewer) error {
+	err := uc.accessRepo.Create(&model.KYCDocumentAccess{
+		CustomerID:   customerID,
+		DocumentType: docType,
+		Variant:      variant,
+		Action:       action,
+		Channel:      channel,
+		UserID:       viewer.UserID,
+		IPAddress:    truncate(viewer.IPAddress, 45),
+		UserAgent:    truncate(viewer.UserAgent, 255),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record document access: %w", err)
+	}
+	return nil
+}
+
+// validateDocumentRequest checks the document type and returns the variant,
+// defaulting to the original.
+func validateDocumentRequest(docType, variant string) (string, error) {
+	if docType != model.KYCDocumentKTP && docType != model.KYCDocumentSelfie {
+		return "", fmt.Errorf("%w: document type must be %s or %s", ErrInvalidDocumentRequest, model.KYCDocumentKTP, model.KYCDocumentSelfie)
+	}
+	switch variant {
+	case "", model.KYCVariantOriginal:
+		return model.KYCVariantOriginal, nil
+	case model.KYCVariantThumbnail:
+		return variant, nil
+	}
+	return "", fmt.Errorf("%w: variant must be %s or %s", ErrInvalidDocumentRequest, model.KYCVariantOriginal, model.KYCVariantThumbnail)
+}
+
+func documentKey(customer *model.Customer, docType string) string {
+	if docType == model.KYCDocumentKTP {
+		return customer.KTPPhoto
+	}
+	return customer.SelfiePhoto
+}
+
+func signedDocumentPath(customerID uint, docType, variant string, viewerID uint) string {
+	return fmt.Sprintf("kyc-documents/%d/%s/%s/%d", customerID, docType, variant, viewerID)
+}
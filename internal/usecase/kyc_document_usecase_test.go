@@ -0,0 +1,146 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/pkg/urlsign"
+	"xyz-multifinance/storage"
+)
+
+type mockKYCDocumentAccessRepo struct {
+	accesses []model.KYCDocumentAccess
+}
+
+func (m *mockKYCDocumentAccessRepo) Create(access *model.KYCDocumentAccess) error {
+	m.accesses = append(m.accesses, *access)
+	return nil
+}
+
+func (m *mockKYCDocumentAccessRepo) FindByCustomerID(customerID uint, limit int) ([]model.KYCDocumentAccess, error) {
+	return m.accesses, nil
+}
+
+func newKYCDocumentUsecase(t *testing.T) (usecase.KYCDocumentUsecase, *mockKYCDocumentAccessRepo) {
+	t.Helper()
+	store := storage.NewFileSystemStore(t.TempDir(), "http://localhost/api/v1/files", urlsign.New("files"))
+	ktp := []byte("ktp image bytes")
+	if err := store.Put(context.Background(), "kyc/3201/ktp_1.jpg", bytes.NewReader(ktp), int64(len(ktp)), "image/jpeg"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	customer := &model.Customer{ID: 7, UserID: 42, NIK: "3201", KTPPhoto: "kyc/3201/ktp_1.jpg"}
+	customers := &mockCustomerRepo{
+		FindByNIKFunc: func(nik string) (*model.Customer, error) {
+			if nik != customer.NIK {
+				return nil, errors.New("record not found")
+			}
+			return customer, nil
+		},
+		FindByIDFunc: func(id uint) (*model.Customer, error) {
+			if id != customer.ID {
+				return nil, errors.New("record not found")
+			}
+			return customer, nil
+		},
+	}
+	accesses := &mockKYCDocumentAccessRepo{}
+	uc := usecase.NewKYCDocumentUsecase(customers, accesses, store, urlsign.New("documents"), usecase.KYCDocumentPolicy{
+		BaseURL:       "http://localhost/api/v1",
+		URLTTL:        5 * time.Minute,
+		MaxURLTTL:     time.Hour,
+		ThumbnailSize: 64,
+	})
+	return uc, accesses
+}
+
+func TestGetDocument_ChecksPermissionAndRecordsView(t *testing.T) {
+	uc, accesses := newKYCDocumentUsecase(t)
+	ctx := context.Background()
+
+	if _, err := uc.GetDocument(ctx, "3201", "ktp", "", usecase.DocumentViewer{UserID: 9}); !errors.Is(err, usecase.ErrDocumentForbidden) {
+		t.Errorf("other user: expected ErrDocumentForbidden, got %v", err)
+	}
+	if _, err := uc.GetDocument(ctx, "3201", "selfie", "", usecase.DocumentViewer{Role: "admin"}); !errors.Is(err, usecase.ErrDocumentNotFound) {
+		t.Errorf("missing selfie: expected ErrDocumentNotFound, got %v", err)
+	}
+	if _, err := uc.GetDocument(ctx, "3201", "passport", "", usecase.DocumentViewer{Role: "admin"}); !errors.Is(err, usecase.ErrInvalidDocumentRequest) {
+		t.Errorf("unknown type: expected ErrInvalidDocumentRequest, got %v", err)
+	}
+	if len(accesses.accesses) != 0 {
+		t.Fatalf("refused requests were recorded: %+v", accesses.accesses)
+	}
+
+	doc, err := uc.GetDocument(ctx, "3201", "ktp", "", usecase.DocumentViewer{UserID: 42, IPAddress: "10.0.0.1"})
+	if err != nil {
+		t.Fatalf("owner: unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(doc.Body)
+	doc.Body.Close()
+	if string(body) != "ktp image bytes" || doc.ContentType != "image/jpeg" {
+		t.Errorf("got %q (%s)", body, doc.ContentType)
+	}
+
+	if len(accesses.accesses) != 1 {
+		t.Fatalf("expected 1 audit entry, got %d", len(accesses.accesses))
+	}
+	got := accesses.accesses[0]
+	if got.CustomerID != 7 || got.UserID != 42 || got.Action != model.KYCAccessView ||
+		got.Channel != model.KYCChannelAPI || got.Variant != model.KYCVariantOriginal || got.IPAddress != "10.0.0.1" {
+		t.Errorf("unexpected audit entry: %+v", got)
+	}
+}
+
+func TestSignedURL_ServesUntilTamperedOrExpired(t *testing.T) {
+	uc, accesses := newKYCDocumentUsecase(t)
+	ctx := context.Background()
+
+	signed, err := uc.IssueSignedURL("3201", "ktp", "", 0, usecase.DocumentViewer{Role: "admin", UserID: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(signed.URL, "http://localhost/api/v1/kyc-documents/7/ktp?") {
+		t.Fatalf("unexpected URL %s", signed.URL)
+	}
+	if ttl := time.Until(signed.ExpiresAt); ttl > 5*time.Minute || ttl < 4*time.Minute {
+		t.Errorf("expected the default 5 minute lifetime, got %v", ttl)
+	}
+	link, _ := url.Parse(signed.URL)
+	query := link.Query()
+
+	doc, err := uc.OpenSignedDocument(ctx, 7, "ktp", query, usecase.DocumentViewer{IPAddress: "10.0.0.2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	doc.Body.Close()
+
+	tampered := url.Values{}
+	for k, v := range query {
+		tampered[k] = v
+	}
+	tampered.Set("viewer", "42")
+	if _, err := uc.OpenSignedDocument(ctx, 7, "ktp", tampered, usecase.DocumentViewer{}); !errors.Is(err, usecase.ErrInvalidDocumentLink) {
+		t.Errorf("tampered viewer: expected ErrInvalidDocumentLink, got %v", err)
+	}
+	if _, err := uc.OpenSignedDocument(ctx, 7, "selfie", query, usecase.DocumentViewer{}); !errors.Is(err, usecase.ErrInvalidDocumentLink) {
+		t.Errorf("other document: expected ErrInvalidDocumentLink, got %v", err)
+	}
+
+	if len(accesses.accesses) != 2 {
+		t.Fatalf("expected issue and view to be recorded, got %+v", accesses.accesses)
+	}
+	if a := accesses.accesses[0]; a.Action != model.KYCAccessURLIssued || a.UserID != 1 {
+		t.Errorf("unexpected issue entry: %+v", a)
+	}
+	if a := accesses.accesses[1]; a.Action != model.KYCAccessView || a.Channel != model.KYCChannelSignedURL || a.UserID != 1 || a.IPAddress != "10.0.0.2" {
+		t.Errorf("unexpected view entry: %+v", a)
+	}
+}
@@ -0,0 +1,288 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type LedgerUsecase interface {
+	GetAccounts() ([]model.Account, error)
+	GetTrialBalance(asOf time.Time) (*TrialBalance, error)
+	GetAccountStatement(code string, from, to time.Time) (*AccountStatement, error)
+}
+
+type TrialBalanceLine struct {
+	Code    string `json:"code"`
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Debit   int64  `json:"debit"`
+	Credit  int64  `json:"credit"`
+	Balance int64  `json:"balance"`
+}
+
+type TrialBalance struct {
+	AsOf        time.Time          `json:"as_of"`
+	Accounts    []TrialBalanceLine `json:"accounts"`
+	TotalDebit  int64              `json:"total_debit"`
+	TotalCredit int64              `json:"total_credit"`
+	Balanced    bool               `json:"balanced"`
+}
+
+type AccountStatementLine struct {
+	repository.StatementLine
+	Balance int64 `json:"balance"`
+}
+
+type AccountStatement struct {
+	Account        model.Account          `json:"account"`
+	From           time.Time              `json:"from"`
+	To             time.Time              `json:"to"`
+	OpeningBalance int64                  `json:"opening_balance"`
+	Lines          []AccountStatementLine `json:"lines"`
+	ClosingBalance int64                  `json:"closing_balance"`
+}
+
+type ledgerUsecase struct {
+	ledgerRepo repository.LedgerRepository
+}
+
+func NewLedgerUsecase(ledgerRepo repository.LedgerRepository) LedgerUsecase {
+	return &ledgerUsecase{ledgerRepo: ledgerRepo}
+}
+
+func (uc *ledgerUsecase) GetAccounts() ([]model.Account, error) {
+	return uc.ledgerRepo.FindAccounts()
+}
+
+func (uc *ledgerUsecase) GetTrialBalance(asOf time.Time) (*TrialBalance, error) {
+	accounts, err := uc.ledgerRepo.FindAccounts()
+	if err != nil {
+		return nil, err
+	}
+	totals, err := uc.ledgerRepo.SumByAccount(asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	byCode := make(map[string]repository.AccountTotal, len(totals))
+	for _, total := range totals {
+		byCode[total.AccountCode] = total
+	}
+
+	tb := &TrialBalance{AsOf: asOf, Accounts: []TrialBalanceLine{}}
+	for _, account := range accounts {
+		total := byCode[account.Code]
+		tb.Accounts = append(tb.Accounts, TrialBalanceLine{
+			Code:    account.Code,
+			Name:    account.Name,
+			Type:    account.Type,
+			Debit:   total.Debit,
+			Credit:  total.Credit,
+			Balance: account.Balance(total.Debit, total.Credit),
+		})
+		tb.TotalDebit += total.Debit
+		tb.TotalCredit += total.Credit
+	}
+	tb.Balanced = tb.TotalDebit == tb.TotalCredit
+
+	return tb, nil
+}
+
+func (uc *ledgerUsecase) GetAccountStatement(code string, from, to time.Time) (*AccountStatement, error) {
+	if to.Before(from) {
+		return nil, errors.New("to must not be before from")
+	}
+
+	account, err := uc.ledgerRepo.FindAccountByCode(code)
+	if err != nil {
+		return nil, errors.New("account not found")
+	}
+
+	opening, err := uc.ledgerRepo.SumAccountBefore(code, from)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := uc.ledgerRepo.FindStatementLines(code, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &AccountStatement{
+		Account:        *account,
+		From:           from,
+		To:             to,
+		OpeningBalance: account.Balance(opening.Debit, opening.Credit),
+		Lines:          make([]AccountStatementLine, 0, len(lines)),
+	}
+	balance := statement.OpeningBalance
+	for _, line := range lines {
+		balance += account.Balance(line.Debit, line.Credit)
+		statement.Lines = append(statement.Lines, AccountStatementLine{StatementLine: line, Balance: balance})
+	}
+	statement.ClosingBalance = balance
+
+	return statement, nil
+}
+
+// posting is a signed amount on an account: positive amounts are debits,
+// negative amounts credits.
+type posting struct {
+	account string
+	amount  int64
+}
+
+func debit(account string, amount int64) posting {
+	return posting{account: account, amount: amount}
+}
+
+func credit(account string, amount int64) posting {
+	return posting{account: account, amount: -amount}
+}
+
+// journalEntry builds an entry for a contract, dropping zero postings.
+func journalEntry(entryType, reference string, transactionID uint, date time.Time, description string, postings ...posting) *model.JournalEntry {
+	entry := &model.JournalEntry{
+		EntryDate:     startOfDay(date),
+		Type:          entryType,
+		Reference:     reference,
+		TransactionID: &transactionID,
+		Description:   description,
+	}
+	for _, p := range postings {
+		switch {
+		case p.amount > 0:
+			entry.Lines = append(entry.Lines, model.JournalLine{AccountCode: p.account, Debit: p.amount})
+		case p.amount < 0:
+			entry.Lines = append(entry.Lines, model.JournalLine{AccountCode: p.account, Credit: -p.amount})
+		}
+	}
+	return entry
+}
+
+// postEntry stores the entry, skipping entries whose amounts were all zero.
+func postEntry(txDB *gorm.DB, repo repository.LedgerRepository, entry *model.JournalEntry) error {
+	if len(entry.Lines) == 0 {
+		return nil
+	}
+	return repo.CreateEntry(txDB, entry)
+}
+
+// disbursementEntry books the financed principal and the full contract
+// interest as receivables. The interest is unearned until each installment
+// falls due. The admin fee is kept back from the amount paid to the dealer,
+// so it is earned from the principal rather than from cash received.
+func disbursementEntry(tx *model.Transaction, date time.Time) *model.JournalEntry {
+	return journalEntry(model.JournalTypeDisbursement, fmt.Sprintf("transaction:%d", tx.ID), tx.ID, date,
+		fmt.Sprintf("Disbursement of contract %s", tx.ContractNumber),
+		debit(model.AccountLoanReceivable, tx.Principal),
+		credit(model.AccountCash, disbursementAmount(tx)),
+		credit(model.AccountAdminFeeIncome, tx.AdminFee),
+		debit(model.AccountInterestReceivable, tx.InterestAmount),
+		credit(model.AccountUnearnedInterest, tx.InterestAmount),
+	)
+}
+
+// repaymentPostings credits the receivables a payment was allocated to.
+func repaymentPostings(payment *model.Payment) []posting {
+	var principal, interest, lateFee int64
+	for _, allocation := range payment.Allocations {
+		principal += allocation.Principal
+		interest += allocation.Interest
+		lateFee += allocation.LateFee
+	}
+	return []posting{
+		debit(model.AccountCash, payment.Amount),
+		credit(model.AccountLoanReceivable, principal),
+		credit(model.AccountInterestReceivable, interest),
+		credit(model.AccountLateFeeReceivable, lateFee),
+	}
+}
+
+func repaymentEntry(payment *model.Payment) *model.JournalEntry {
+	return journalEntry(model.JournalTypeRepayment, fmt.Sprintf("payment:%d", payment.ID), payment.TransactionID, payment.PaidAt,
+		fmt.Sprintf("Repayment %s", payment.Reference),
+		repaymentPostings(payment)...,
+	)
+}
+
+// payoffEntry books a repayment that clears the contract before all of its
+// interest has been accrued. Whatever is still unearned becomes income, as
+// in settlementEntry.
+func payoffEntry(payment *model.Payment, balances map[string]int64) *model.JournalEntry {
+	unearned := -balances[model.AccountUnearnedInterest]
+	postings := append(repaymentPostings(payment),
+		debit(model.AccountUnearnedInterest, unearned),
+		credit(model.AccountInterestIncome, unearned),
+	)
+	return journalEntry(model.JournalTypeRepayment, fmt.Sprintf("payment:%d", payment.ID), payment.TransactionID, payment.PaidAt,
+		fmt.Sprintf("Repayment %s", payment.Reference),
+		postings...,
+	)
+}
+
+func lateFeeEntry(transactionID uint, amount int64, asOf time.Time) *model.JournalEntry {
+	return journalEntry(model.JournalTypeLateFee, fmt.Sprintf("late_fee:%d:%s", transactionID, asOf.Format("20060102")), transactionID, asOf,
+		"Late fee",
+		debit(model.AccountLateFeeReceivable, amount),
+		credit(model.AccountLateFeeIncome, amount),
+	)
+}
+
+// settlementEntry books an early settlement payment. The rebate is written
+// off against unearned interest and whatever interest is still unearned
+// after that becomes income.
+func settlementEntry(payment *model.Payment, quote *model.SettlementQuote, balances map[string]int64) *model.JournalEntry {
+	unearned := -balances[model.AccountUnearnedInterest]
+	postings := append(repaymentPostings(payment),
+		credit(model.AccountSettlementFeeIncome, quote.PenaltyFee),
+		debit(model.AccountUnearnedInterest, quote.InterestRebate),
+		credit(model.AccountInterestReceivable, quote.InterestRebate),
+		debit(model.AccountUnearnedInterest, unearned-quote.InterestRebate),
+		credit(model.AccountInterestIncome, unearned-quote.InterestRebate),
+	)
+	return journalEntry(model.JournalTypeSettlement, fmt.Sprintf("payment:%d", payment.ID), payment.TransactionID, payment.PaidAt,
+		fmt.Sprintf("Early settlement %s", payment.Reference),
+		postings...,
+	)
+}
+
+// writeOffEntry clears every receivable of the contract. Interest that was
+// never earned is cancelled against unearned interest; the rest is a loss.
+func writeOffEntry(transactionID uint, balances map[string]int64, date time.Time, note string) *model.JournalEntry {
+	principal := balances[model.AccountLoanReceivable]
+	interest := balances[model.AccountInterestReceivable]
+	lateFee := balances[model.AccountLateFeeReceivable]
+	unearned := -balances[model.AccountUnearnedInterest]
+
+	return journalEntry(model.JournalTypeWriteOff, fmt.Sprintf("write_off:%d", transactionID), transactionID, date,
+		note,
+		credit(model.AccountLoanReceivable, principal),
+		credit(model.AccountInterestReceivable, interest),
+		credit(model.AccountLateFeeReceivable, lateFee),
+		debit(model.AccountUnearnedInterest, unearned),
+		debit(model.AccountWriteOffExpense, principal+interest+lateFee-unearned),
+	)
+}
+
+// reversalEntry brings every account touched by the contract back to zero.
+func reversalEntry(transactionID uint, balances map[string]int64, date time.Time, description string) *model.JournalEntry {
+	codes := make([]string, 0, len(balances))
+	for code := range balances {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	postings := make([]posting, 0, len(codes))
+	for _, code := range codes {
+		postings = append(postings, posting{account: code, amount: -balances[code]})
+	}
+	return journalEntry(model.JournalTypeCancellation, fmt.Sprintf("cancellation:%d", transactionID), transactionID, date,
+		description, postings...)
+}
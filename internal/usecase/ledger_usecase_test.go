@@ -0,0 +1,140 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/internal/usecase"
+)
+
+type mockLedgerRepo struct {
+	repository.LedgerRepository
+	accounts []model.Account
+	totals   []repository.AccountTotal
+	opening  repository.AccountTotal
+	lines    []repository.StatementLine
+}
+
+func (m *mockLedgerRepo) FindAccounts() ([]model.Account, error) {
+	return m.accounts, nil
+}
+
+func (m *mockLedgerRepo) FindAccountByCode(code string) (*model.Account, error) {
+	for _, account := range m.accounts {
+		if account.Code == code {
+			return &account, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (m *mockLedgerRepo) SumByAccount(asOf time.Time) ([]repository.AccountTotal, error) {
+	return m.totals, nil
+}
+
+func (m *mockLedgerRepo) SumAccountBefore(code string, before time.Time) (*repository.AccountTotal, error) {
+	return &m.opening, nil
+}
+
+func (m *mockLedgerRepo) FindStatementLines(code string, from, to time.Time) ([]repository.StatementLine, error) {
+	return m.lines, nil
+}
+
+var chartOfAccounts = []model.Account{
+	{Code: model.AccountCash, Name: "Cash", Type: model.AccountTypeAsset},
+	{Code: model.AccountLoanReceivable, Name: "Loan receivable", Type: model.AccountTypeAsset},
+	{Code: model.AccountUnearnedInterest, Name: "Unearned interest", Type: model.AccountTypeLiability},
+	{Code: model.AccountInterestIncome, Name: "Interest income", Type: model.AccountTypeIncome},
+}
+
+func TestJournalEntryValidate(t *testing.T) {
+	balanced := model.JournalEntry{Lines: []model.JournalLine{
+		{AccountCode: model.AccountLoanReceivable, Debit: 1000},
+		{AccountCode: model.AccountCash, Credit: 1000},
+	}}
+	if err := balanced.Validate(); err != nil {
+		t.Errorf("expected balanced entry to be valid, got %v", err)
+	}
+
+	unbalanced := model.JournalEntry{Lines: []model.JournalLine{
+		{AccountCode: model.AccountLoanReceivable, Debit: 1000},
+		{AccountCode: model.AccountCash, Credit: 999},
+	}}
+	if err := unbalanced.Validate(); !errors.Is(err, model.ErrUnbalancedEntry) {
+		t.Errorf("expected ErrUnbalancedEntry, got %v", err)
+	}
+
+	bothSides := model.JournalEntry{Lines: []model.JournalLine{
+		{AccountCode: model.AccountLoanReceivable, Debit: 1000, Credit: 1000},
+		{AccountCode: model.AccountCash, Debit: 0},
+	}}
+	if err := bothSides.Validate(); err == nil {
+		t.Error("expected error for line with both debit and credit")
+	}
+}
+
+func TestGetTrialBalance(t *testing.T) {
+	repo := &mockLedgerRepo{
+		accounts: chartOfAccounts,
+		totals: []repository.AccountTotal{
+			{AccountCode: model.AccountCash, Debit: 400, Credit: 1000},
+			{AccountCode: model.AccountLoanReceivable, Debit: 1000, Credit: 300},
+			{AccountCode: model.AccountInterestIncome, Credit: 100},
+		},
+	}
+	uc := usecase.NewLedgerUsecase(repo)
+
+	tb, err := uc.GetTrialBalance(time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !tb.Balanced || tb.TotalDebit != 1400 || tb.TotalCredit != 1400 {
+		t.Errorf("expected balanced totals of 1400, got debit %d credit %d", tb.TotalDebit, tb.TotalCredit)
+	}
+	if len(tb.Accounts) != len(chartOfAccounts) {
+		t.Fatalf("expected every account listed, got %d", len(tb.Accounts))
+	}
+
+	want := map[string]int64{
+		model.AccountCash:             -600,
+		model.AccountLoanReceivable:   700,
+		model.AccountUnearnedInterest: 0,
+		model.AccountInterestIncome:   100,
+	}
+	for _, line := range tb.Accounts {
+		if line.Balance != want[line.Code] {
+			t.Errorf("account %s: expected balance %d, got %d", line.Code, want[line.Code], line.Balance)
+		}
+	}
+}
+
+func TestGetAccountStatement_RunningBalance(t *testing.T) {
+	repo := &mockLedgerRepo{
+		accounts: chartOfAccounts,
+		opening:  repository.AccountTotal{Credit: 50},
+		lines: []repository.StatementLine{
+			{JournalEntryID: 1, Credit: 100},
+			{JournalEntryID: 2, Debit: 30},
+		},
+	}
+	uc := usecase.NewLedgerUsecase(repo)
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.Local)
+	statement, err := uc.GetAccountStatement(model.AccountInterestIncome, from, from.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if statement.OpeningBalance != 50 {
+		t.Errorf("expected opening balance 50, got %d", statement.OpeningBalance)
+	}
+	if statement.Lines[0].Balance != 150 || statement.Lines[1].Balance != 120 {
+		t.Errorf("unexpected running balances %d, %d", statement.Lines[0].Balance, statement.Lines[1].Balance)
+	}
+	if statement.ClosingBalance != 120 {
+		t.Errorf("expected closing balance 120, got %d", statement.ClosingBalance)
+	}
+}
@@ -1,57 +1,93 @@
 package usecase
 
 import (
+	"context"
 	"errors"
+	"strconv"
+
 	"xyz-multifinance/internal/model"
 	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/pkg/money"
+
+	"gorm.io/gorm"
 )
 
 type LimitUsecase interface {
-	CreateLimit(limit *model.Limit) error
-	UpdateLimit(id uint, fields map[string]interface{}) error
-	DeleteLimit(id uint) error
+	CreateLimit(ctx context.Context, limit *model.Limit) error
+	UpdateLimit(ctx context.Context, id uint, fields map[string]interface{}) error
+	DeleteLimit(ctx context.Context, id uint) error
 	GetLimitByID(id uint) (*LimitWithRemaining, error)
 	GetLimitsByCustomer(customerID uint) ([]LimitWithRemaining, error)
+	GetLimitByCustomerAndTenor(customerID uint, tenor int) (*LimitWithRemaining, error)
 }
 
 type limitUsecase struct {
-	limitRepo       repository.LimitRepository
-	transactionRepo repository.TransactionRepository
+	limitRepo repository.LimitRepository
+	auditor   Auditor
 }
 
-func NewLimitUsecase(limitRepo repository.LimitRepository, transactionRepo repository.TransactionRepository) LimitUsecase {
+func NewLimitUsecase(limitRepo repository.LimitRepository, auditor Auditor) LimitUsecase {
 	return &limitUsecase{
-		limitRepo:       limitRepo,
-		transactionRepo: transactionRepo,
+		limitRepo: limitRepo,
+		auditor:   auditor,
 	}
 }
 
-func (uc *limitUsecase) CreateLimit(limit *model.Limit) error {
+func (uc *limitUsecase) CreateLimit(ctx context.Context, limit *model.Limit) error {
 	if limit.Tenor <= 0 {
 		return errors.New("tenor must be greater than zero")
 	}
 	if limit.Limit <= 0 {
 		return errors.New("limit must be greater than zero")
 	}
-	return uc.limitRepo.Create(limit)
+	if err := normalizeCurrency(&limit.Currency); err != nil {
+		return err
+	}
+	if err := uc.limitRepo.Create(limit); err != nil {
+		return err
+	}
+	uc.audit(ctx, model.AuditActionCreate, limit.ID, nil, limit)
+	return nil
 }
 
-func (uc *limitUsecase) UpdateLimit(id uint, updatedFields map[string]interface{}) error {
-	_, err := uc.limitRepo.FindByID(id)
+func (uc *limitUsecase) UpdateLimit(ctx context.Context, id uint, updatedFields map[string]interface{}) error {
+	before, err := uc.limitRepo.FindByID(id)
 	if err != nil {
 		return errors.New("limit not found")
 	}
 
-	return uc.limitRepo.Update(id, updatedFields)
+	if err := uc.limitRepo.Update(id, updatedFields); err != nil {
+		return err
+	}
+	after, err := uc.limitRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	uc.audit(ctx, model.AuditActionUpdate, id, before, after)
+	return nil
 }
 
-func (uc *limitUsecase) DeleteLimit(id uint) error {
-	_, err := uc.limitRepo.FindByID(id)
+func (uc *limitUsecase) DeleteLimit(ctx context.Context, id uint) error {
+	before, err := uc.limitRepo.FindByID(id)
 	if err != nil {
 		return errors.New("limit not found")
 	}
 
-	return uc.limitRepo.Delete(id)
+	if err := uc.limitRepo.Delete(id); err != nil {
+		return err
+	}
+	uc.audit(ctx, model.AuditActionDelete, id, before, nil)
+	return nil
+}
+
+func (uc *limitUsecase) audit(ctx context.Context, action string, id uint, before, after *model.Limit) {
+	recordAudit(ctx, uc.auditor, AuditEvent{
+		Entity:   model.AuditEntityLimit,
+		EntityID: strconv.FormatUint(uint64(id), 10),
+		Action:   action,
+		Before:   before,
+		After:    after,
+	})
 }
 
 type LimitWithRemaining struct {
@@ -59,48 +95,70 @@ type LimitWithRemaining struct {
 	RemainingLimit int64 `json:"remaining_limit"`
 }
 
-func (uc *limitUsecase) GetLimitByID(id uint) (*LimitWithRemaining, error) {
-	limit, err := uc.limitRepo.FindByID(id)
+// remainingLimit is what is left of the limit after the principal of its
+// active contracts, in the limit's currency.
+func remainingLimit(usage repository.LimitUsage) (money.Money, error) {
+	currency := currencyOf(usage.Limit.Currency)
+	return money.New(usage.Limit.Limit, currency).Sub(money.New(usage.UsedAmount, currency))
+}
+
+func newLimitWithRemaining(usage repository.LimitUsage) (LimitWithRemaining, error) {
+	remaining, err := remainingLimit(usage)
 	if err != nil {
-		return nil, err
+		return LimitWithRemaining{}, err
 	}
-	if limit == nil {
+	return LimitWithRemaining{
+		Limit:          usage.Limit,
+		RemainingLimit: remaining.Amount(),
+	}, nil
+}
+
+func (uc *limitUsecase) GetLimitByID(id uint) (*LimitWithRemaining, error) {
+	usage, err := uc.limitRepo.FindUsageByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return nil, errors.New("limit not found")
 	}
-
-	usedAmount, err := uc.transactionRepo.SumUsedAmount(limit.CustomerID, limit.Tenor)
 	if err != nil {
 		return nil, err
 	}
 
-	remaining := limit.Limit - usedAmount
-
-	return &LimitWithRemaining{
-		Limit:          *limit,
-		RemainingLimit: remaining,
-	}, nil
+	result, err := newLimitWithRemaining(*usage)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
 }
 
 func (uc *limitUsecase) GetLimitsByCustomer(customerID uint) ([]LimitWithRemaining, error) {
-	limits, err := uc.limitRepo.FindByCustomerID(customerID)
+	usages, err := uc.limitRepo.FindUsageByCustomerID(customerID)
 	if err != nil {
 		return nil, err
 	}
 
-	var result []LimitWithRemaining
-	for _, l := range limits {
-		usedAmount, err := uc.transactionRepo.SumUsedAmount(customerID, l.Tenor)
+	result := make([]LimitWithRemaining, 0, len(usages))
+	for _, usage := range usages {
+		limit, err := newLimitWithRemaining(usage)
 		if err != nil {
 			return nil, err
 		}
+		result = append(result, limit)
+	}
 
-		remaining := l.Limit - usedAmount
+	return result, nil
+}
 
-		result = append(result, LimitWithRemaining{
-			Limit:          l,
-			RemainingLimit: remaining,
-		})
+func (uc *limitUsecase) GetLimitByCustomerAndTenor(customerID uint, tenor int) (*LimitWithRemaining, error) {
+	usage, err := uc.limitRepo.FindUsageByCustomerAndTenor(customerID, tenor)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("limit for tenor not found")
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	result, err := newLimitWithRemaining(*usage)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
 }
@@ -0,0 +1,87 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/internal/usecase"
+
+	"gorm.io/gorm"
+)
+
+// fakeLimitStore holds one customer's limits and the amount used on each
+// tenor, and counts the repository calls made against it.
+type fakeLimitStore struct {
+	limits  []model.Limit
+	used    map[int]int64
+	queries int
+}
+
+func newFakeLimitStore(customerID uint, tenors int) *fakeLimitStore {
+	store := &fakeLimitStore{used: make(map[int]int64)}
+	for i := 1; i <= tenors; i++ {
+		store.limits = append(store.limits, model.Limit{
+			ID:         uint(i),
+			CustomerID: customerID,
+			Tenor:      i,
+			Limit:      int64(i) * 1_000_000,
+		})
+		store.used[i] = int64(i) * 250_000
+	}
+	return store
+}
+
+type mockLimitRepo struct {
+	repository.LimitRepository
+	store *fakeLimitStore
+}
+
+func (m *mockLimitRepo) FindUsageByCustomerID(customerID uint) ([]repository.LimitUsage, error) {
+	m.store.queries++
+	usages := make([]repository.LimitUsage, 0, len(m.store.limits))
+	for _, l := range m.store.limits {
+		usages = append(usages, repository.LimitUsage{Limit: l, UsedAmount: m.store.used[l.Tenor]})
+	}
+	return usages, nil
+}
+
+func (m *mockLimitRepo) FindUsageByCustomerAndTenor(customerID uint, tenor int) (*repository.LimitUsage, error) {
+	m.store.queries++
+	for _, l := range m.store.limits {
+		if l.Tenor == tenor {
+			return &repository.LimitUsage{Limit: l, UsedAmount: m.store.used[tenor]}, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestGetLimitsByCustomer_SingleQuery(t *testing.T) {
+	store := newFakeLimitStore(1, 12)
+	uc := usecase.NewLimitUsecase(&mockLimitRepo{store: store}, &mockAuditor{})
+
+	limits, err := uc.GetLimitsByCustomer(1)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(limits) != 12 {
+		t.Fatalf("expected 12 limits, got %d", len(limits))
+	}
+	if store.queries != 1 {
+		t.Errorf("expected 1 query, got %d", store.queries)
+	}
+	for _, l := range limits {
+		if want := l.Limit.Limit - store.used[l.Tenor]; l.RemainingLimit != want {
+			t.Errorf("tenor %d: expected remaining %d, got %d", l.Tenor, want, l.RemainingLimit)
+		}
+	}
+}
+
+func TestGetLimitByCustomerAndTenor_NotFound(t *testing.T) {
+	uc := usecase.NewLimitUsecase(&mockLimitRepo{store: newFakeLimitStore(1, 3)}, &mockAuditor{})
+
+	_, err := uc.GetLimitByCustomerAndTenor(1, 24)
+	if err == nil || err.Error() != "limit for tenor not found" {
+		t.Errorf("expected limit for tenor not found, got %v", err)
+	}
+}
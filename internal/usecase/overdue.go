@@ -0,0 +1,255 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/logger"
+	"xyz-multifinance/pkg/redact"
+
+	"gorm.io/gorm"
+)
+
+// LateFeePolicy accrues a daily late fee, in basis points of the installment
+// amount, once an installment is more than GraceDays past due. CapPercent
+// limits the total fee per installment; zero means uncapped.
+type LateFeePolicy struct {
+	DailyBps   int
+	GraceDays  int
+	CapPercent int
+}
+
+// LateFee returns the fee accrued on an installment of the given amount that
+// is dpd days past due.
+func (p LateFeePolicy) LateFee(amount int64, dpd int) int64 {
+	days := dpd - p.GraceDays
+	if days <= 0 || p.DailyBps <= 0 {
+		return 0
+	}
+
+	fee := amount * int64(p.DailyBps) * int64(days) / 10000
+	if p.CapPercent > 0 {
+		if limit := amount * int64(p.CapPercent) / 100; fee > limit {
+			fee = limit
+		}
+	}
+	return fee
+}
+
+type OverdueUsecase interface {
+	Recalculate(asOf time.Time) (*OverdueRunResult, error)
+	// GetCustomerDelinquency returns ErrCustomerForbidden unless the viewer
+	// is an admin or the customer's own user.
+	GetCustomerDelinquency(nik string, viewer Viewer) (*CustomerDelinquency, error)
+}
+
+type overdueUsecase struct {
+	txRepo          repository.TransactionRepository
+	installmentRepo repository.InstallmentRepository
+	customerRepo    repository.CustomerRepository
+	ledgerRepo      repository.LedgerRepository
+	policy          LateFeePolicy
+	db              *gorm.DB
+}
+
+func NewOverdueUsecase(
+	txRepo repository.TransactionRepository,
+	installmentRepo repository.InstallmentRepository,
+	customerRepo repository.CustomerRepository,
+	ledgerRepo repository.LedgerRepository,
+	policy LateFeePolicy,
+	db *gorm.DB,
+) OverdueUsecase {
+	return &overdueUsecase{
+		txRepo:          txRepo,
+		installmentRepo: installmentRepo,
+		customerRepo:    customerRepo,
+		ledgerRepo:      ledgerRepo,
+		policy:          policy,
+		db:              db,
+	}
+}
+
+type OverdueRunResult struct {
+	AsOf                time.Time `json:"as_of"`
+	OverdueContracts    int       `json:"overdue_contracts"`
+	OverdueInstallments int       `json:"overdue_installments"`
+	LateFeesAccrued     int64     `json:"late_fees_accrued"`
+}
+
+type ContractDelinquency struct {
+	TransactionID      uint   `json:"transaction_id"`
+	ContractNumber     string `json:"contract_number"`
+	DaysPastDue        int    `json:"days_past_due"`
+	Collectibility     int    `json:"collectibility"`
+	AgingBucket        string `json:"aging_bucket"`
+	OverdueAmount      int64  `json:"overdue_amount"`
+	LateFeeOutstanding int64  `json:"late_fee_outstanding"`
+}
+
+type CustomerDelinquency struct {
+	CustomerID     uint                  `json:"customer_id"`
+	NIK            string                `json:"nik"`
+	DaysPastDue    int                   `json:"days_past_due"`
+	Collectibility int                   `json:"collectibility"`
+	AgingBucket    string                `json:"aging_bucket"`
+	Contracts      []ContractDelinquency `json:"contracts"`
+}
+
+// Recalculate refreshes days past due, collectibility and late fees for every
+// active contract as of the given date. Fees are derived from the number of
+// days past due rather than incremented, so running it twice for the same
+// date changes nothing.
+func (uc *overdueUsecase) Recalculate(asOf time.Time) (*OverdueRunResult, error) {
+	asOf = startOfDay(asOf)
+	result := &OverdueRunResult{AsOf: asOf}
+
+	installments, err := uc.installmentRepo.FindOverdue(asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	maxDPD := make(map[uint]int)
+	lateFees := make(map[uint]int64)
+	var overdueIDs []uint
+	err = uc.db.Transaction(func(txDB *gorm.DB) error {
+		for _, inst := range installments {
+			dpd := daysBetween(inst.DueDate, asOf)
+			if current, ok := maxDPD[inst.TransactionID]; !ok || dpd > current {
+				if !ok {
+					overdueIDs = append(overdueIDs, inst.TransactionID)
+				}
+				maxDPD[inst.TransactionID] = dpd
+			}
+
+			if inst.PaidAmount >= inst.Amount {
+				continue
+			}
+			fee := uc.policy.LateFee(inst.Amount, dpd)
+			if fee > inst.LateFee {
+				if err := uc.installmentRepo.UpdateLateFee(txDB, inst.ID, fee); err != nil {
+					return err
+				}
+				lateFees[inst.TransactionID] += fee - inst.LateFee
+				result.LateFeesAccrued += fee - inst.LateFee
+			}
+		}
+
+		for _, id := range overdueIDs {
+			if err := postEntry(txDB, uc.ledgerRepo, lateFeeEntry(id, lateFees[id], asOf)); err != nil {
+				return err
+			}
+		}
+
+		for _, id := range overdueIDs {
+			if err := uc.txRepo.UpdateFields(txDB, id, agingFields(maxDPD[id])); err != nil {
+				return err
+			}
+		}
+
+		return uc.txRepo.ResetAging(txDB, overdueIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.OverdueContracts = len(overdueIDs)
+	result.OverdueInstallments = len(installments)
+
+	logger.Log.WithField("as_of", asOf.Format("2006-01-02")).
+		Infof("overdue recalculated: %d contracts, %d installments, late fees accrued %d",
+			result.OverdueContracts, result.OverdueInstallments, result.LateFeesAccrued)
+
+	return result, nil
+}
+
+func (uc *overdueUsecase) GetCustomerDelinquency(nik string, viewer Viewer) (*CustomerDelinquency, error) {
+	customer, err := uc.customerRepo.FindByNIK(nik)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+	if err := authorizeCustomer(customer, viewer); err != nil {
+		return nil, err
+	}
+
+	transactions, err := uc.txRepo.FindByCustomerID(customer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	installments, err := uc.installmentRepo.FindOverdueByCustomerID(customer.ID, startOfDay(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
+	overdue := make(map[uint]*ContractDelinquency)
+	for _, inst := range installments {
+		c, ok := overdue[inst.TransactionID]
+		if !ok {
+			c = &ContractDelinquency{}
+			overdue[inst.TransactionID] = c
+		}
+		c.OverdueAmount += inst.Amount - inst.PaidAmount
+		c.LateFeeOutstanding += inst.LateFee - inst.LateFeePaid
+	}
+
+	result := &CustomerDelinquency{
+		CustomerID: customer.ID,
+		NIK:        redact.MaskNIK(customer.NIK),
+		Contracts:  []ContractDelinquency{},
+	}
+	for _, tx := range transactions {
+		if !isActiveStatus(tx.Status) {
+			continue
+		}
+
+		contract := ContractDelinquency{
+			TransactionID:  tx.ID,
+			ContractNumber: tx.ContractNumber,
+			DaysPastDue:    tx.DaysPastDue,
+			Collectibility: tx.Collectibility,
+			AgingBucket:    tx.AgingBucket,
+		}
+		if c, ok := overdue[tx.ID]; ok {
+			contract.OverdueAmount = c.OverdueAmount
+			contract.LateFeeOutstanding = c.LateFeeOutstanding
+		}
+		result.Contracts = append(result.Contracts, contract)
+
+		if tx.DaysPastDue > result.DaysPastDue {
+			result.DaysPastDue = tx.DaysPastDue
+		}
+	}
+	result.Collectibility, result.AgingBucket = model.ClassifyDPD(result.DaysPastDue)
+
+	return result, nil
+}
+
+// agingFields are the transaction columns describing how far behind a
+// contract is.
+func agingFields(dpd int) map[string]interface{} {
+	collectibility, bucket := model.ClassifyDPD(dpd)
+	return map[string]interface{}{
+		"days_past_due":  dpd,
+		"collectibility": collectibility,
+		"aging_bucket":   bucket,
+	}
+}
+
+func isActiveStatus(status string) bool {
+	return status == model.TransactionStatusOngoing || status == model.TransactionStatusSuccess
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween counts calendar days from one date to another, ignoring the
+// time of day and daylight saving shifts.
+func daysBetween(from, to time.Time) int {
+	f := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	t := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(t.Sub(f).Hours() / 24)
+}
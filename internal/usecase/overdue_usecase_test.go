@@ -0,0 +1,64 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/usecase"
+)
+
+func TestLateFeePolicy(t *testing.T) {
+	policy := usecase.LateFeePolicy{DailyBps: 10, GraceDays: 3, CapPercent: 5}
+
+	cases := []struct {
+		dpd  int
+		want int64
+	}{
+		{dpd: 0, want: 0},
+		{dpd: 3, want: 0},
+		{dpd: 4, want: 1000},
+		{dpd: 13, want: 10000},
+		{dpd: 200, want: 50000},
+	}
+
+	for _, tc := range cases {
+		if got := policy.LateFee(1_000_000, tc.dpd); got != tc.want {
+			t.Errorf("dpd %d: expected late fee %d, got %d", tc.dpd, tc.want, got)
+		}
+	}
+}
+
+func TestClassifyDPD(t *testing.T) {
+	cases := []struct {
+		dpd            int
+		collectibility int
+		bucket         string
+	}{
+		{0, model.CollectibilityCurrent, model.AgingBucketCurrent},
+		{1, model.CollectibilitySpecial, model.AgingBucket1To30},
+		{30, model.CollectibilitySpecial, model.AgingBucket1To30},
+		{31, model.CollectibilitySubstandard, model.AgingBucket31To60},
+		{61, model.CollectibilityDoubtful, model.AgingBucket61To90},
+		{90, model.CollectibilityDoubtful, model.AgingBucket61To90},
+		{91, model.CollectibilityLoss, model.AgingBucketOver90},
+	}
+
+	for _, tc := range cases {
+		collectibility, bucket := model.ClassifyDPD(tc.dpd)
+		if collectibility != tc.collectibility || bucket != tc.bucket {
+			t.Errorf("dpd %d: expected kol %d/%s, got kol %d/%s", tc.dpd, tc.collectibility, tc.bucket, collectibility, bucket)
+		}
+	}
+}
+
+func TestGetCustomerDelinquency_ForbiddenForOtherUsers(t *testing.T) {
+	customerRepo := &mockCustomerRepo{
+		FindByNIKFunc: func(nik string) (*model.Customer, error) { return &model.Customer{ID: 3, UserID: 11}, nil },
+	}
+	uc := usecase.NewOverdueUsecase(nil, nil, customerRepo, nil, usecase.LateFeePolicy{}, nil)
+
+	if _, err := uc.GetCustomerDelinquency("3201", usecase.Viewer{UserID: 12, Role: "customer"}); !errors.Is(err, usecase.ErrCustomerForbidden) {
+		t.Errorf("err = %v, want another user's delinquency forbidden", err)
+	}
+}
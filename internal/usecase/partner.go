@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/pkg/apikey"
+)
+
+type PartnerUsecase interface {
+	CreatePartner(partner *model.Partner) (string, error)
+	UpdatePartner(id uint, fields map[string]interface{}) error
+	RotateAPIKey(id uint) (string, error)
+	GetPartnerByID(id uint) (*model.Partner, error)
+	GetAllPartners() ([]model.Partner, error)
+	Authenticate(key string) (*model.Partner, error)
+	CreateOutlet(outlet *model.Outlet) error
+	GetOutletsByPartner(partnerID uint) ([]model.Outlet, error)
+}
+
+type partnerUsecase struct {
+	partnerRepo repository.PartnerRepository
+	outletRepo  repository.OutletRepository
+}
+
+func NewPartnerUsecase(partnerRepo repository.PartnerRepository, outletRepo repository.OutletRepository) PartnerUsecase {
+	return &partnerUsecase{
+		partnerRepo: partnerRepo,
+		outletRepo:  outletRepo,
+	}
+}
+
+func (uc *partnerUsecase) CreatePartner(partner *model.Partner) (string, error) {
+	if partner.Code == "" || partner.Name == "" {
+		return "", errors.New("code and name are required")
+	}
+	if partner.AdminFee < 0 || partner.InterestRateBps < 0 {
+		return "", errors.New("admin fee and interest rate must not be negative")
+	}
+
+	key, keyID, keyHash, err := apikey.Generate()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	partner.APIKeyID = keyID
+	partner.APIKeyHash = keyHash
+	partner.Active = true
+	partner.CreatedAt = time.Now()
+	partner.UpdatedAt = time.Now()
+
+	if err := uc.partnerRepo.Create(partner); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func (uc *partnerUsecase) UpdatePartner(id uint, fields map[string]interface{}) error {
+	if _, err := uc.partnerRepo.FindByID(id); err != nil {
+		return errors.New("partner not found")
+	}
+
+	fields["updated_at"] = time.Now()
+	return uc.partnerRepo.Update(id, fields)
+}
+
+func (uc *partnerUsecase) RotateAPIKey(id uint) (string, error) {
+	if _, err := uc.partnerRepo.FindByID(id); err != nil {
+		return "", errors.New("partner not found")
+	}
+
+	key, keyID, keyHash, err := apikey.Generate()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	err = uc.partnerRepo.Update(id, map[string]interface{}{
+		"api_key_id":   keyID,
+		"api_key_hash": keyHash,
+		"updated_at":   time.Now(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func (uc *partnerUsecase) GetPartnerByID(id uint) (*model.Partner, error) {
+	return uc.partnerRepo.FindByID(id)
+}
+
+func (uc *partnerUsecase) GetAllPartners() ([]model.Partner, error) {
+	return uc.partnerRepo.FindAll()
+}
+
+func (uc *partnerUsecase) Authenticate(key string) (*model.Partner, error) {
+	keyID, err := apikey.ID(key)
+	if err != nil {
+		return nil, errors.New("invalid api key")
+	}
+
+	partner, err := uc.partnerRepo.FindByAPIKeyID(keyID)
+	if err != nil || !apikey.Matches(key, partner.APIKeyHash) {
+		return nil, errors.New("invalid api key")
+	}
+	if !partner.Active {
+		return nil, errors.New("partner is inactive")
+	}
+	return partner, nil
+}
+
+func (uc *partnerUsecase) CreateOutlet(outlet *model.Outlet) error {
+	if outlet.Code == "" || outlet.Name == "" {
+		return errors.New("code and name are required")
+	}
+	if _, err := uc.partnerRepo.FindByID(outlet.PartnerID); err != nil {
+		return errors.New("partner not found")
+	}
+
+	outlet.Active = true
+	outlet.CreatedAt = time.Now()
+	outlet.UpdatedAt = time.Now()
+	return uc.outletRepo.Create(outlet)
+}
+
+func (uc *partnerUsecase) GetOutletsByPartner(partnerID uint) ([]model.Outlet, error) {
+	return uc.outletRepo.FindByPartnerID(partnerID)
+}
@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+const PaymentChannelManual = "manual"
+
+type PaymentRequest struct {
+	TransactionID uint
+	Amount        int64
+	Channel       string
+	Reference     string
+	PaidAt        time.Time
+}
+
+type PaymentUsecase interface {
+	// Pay posts a repayment against a contract. Payments are idempotent on
+	// their reference: posting the same reference again returns the
+	// original payment and created is false.
+	Pay(req PaymentRequest) (payment *model.Payment, created bool, err error)
+	// GetPaymentsByTransaction returns ErrCustomerForbidden unless the viewer
+	// is an admin or the contract's customer.
+	GetPaymentsByTransaction(transactionID uint, viewer Viewer) ([]model.Payment, error)
+}
+
+type paymentUsecase struct {
+	paymentRepo     repository.PaymentRepository
+	txRepo          repository.TransactionRepository
+	installmentRepo repository.InstallmentRepository
+	ledgerRepo      repository.LedgerRepository
+	customerRepo    repository.CustomerRepository
+	db              *gorm.DB
+}
+
+func NewPaymentUsecase(
+	paymentRepo repository.PaymentRepository,
+	txRepo repository.TransactionRepository,
+	installmentRepo repository.InstallmentRepository,
+	ledgerRepo repository.LedgerRepository,
+	customerRepo repository.CustomerRepository,
+	db *gorm.DB,
+) PaymentUsecase {
+	return &paymentUsecase{
+		paymentRepo:     paymentRepo,
+		txRepo:          txRepo,
+		installmentRepo: installmentRepo,
+		ledgerRepo:      ledgerRepo,
+		customerRepo:    customerRepo,
+		db:              db,
+	}
+}
+
+// errReferenceTaken rolls back a payment whose reference was posted by a
+// concurrent request.
+var errReferenceTaken = errors.New("payment reference taken")
+
+func (uc *paymentUsecase) Pay(req PaymentRequest) (*model.Payment, bool, error) {
+	if req.Amount <= 0 {
+		return nil, false, errors.New("amount must be greater than zero")
+	}
+	if req.Channel == "" {
+		req.Channel = PaymentChannelManual
+	}
+	if req.Reference == "" {
+		req.Reference = fmt.Sprintf("%s-%d-%d", req.Channel, req.TransactionID, time.Now().UnixNano())
+	}
+	if req.PaidAt.IsZero() {
+		req.PaidAt = time.Now()
+	}
+
+	payment := &model.Payment{
+		TransactionID: req.TransactionID,
+		Amount:        req.Amount,
+		Channel:       req.Channel,
+		Reference:     req.Reference,
+		PaidAt:        req.PaidAt,
+		Status:        model.PaymentStatusPosted,
+	}
+
+	var existing *model.Payment
+	err := uc.db.Transaction(func(txDB *gorm.DB) error {
+		tx, err := uc.txRepo.FindByIDForUpdate(txDB, req.TransactionID)
+		if err != nil {
+			return errors.New("transaction not found")
+		}
+		// Checked under the contract lock, so a retry waits for the request
+		// it repeats and then finds its payment, even if that closed the
+		// contract.
+		existing, err = uc.paymentRepo.FindByReferenceForUpdate(txDB, req.Reference)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if !isActiveStatus(tx.Status) {
+			return fmt.Errorf("cannot post payment to a %s transaction", tx.Status)
+		}
+
+		installments, err := uc.installmentRepo.FindOpenForUpdate(txDB, tx.ID)
+		if err != nil {
+			return err
+		}
+
+		var outstanding int64
+		for _, inst := range installments {
+			outstanding += inst.Outstanding()
+		}
+		if req.Amount > outstanding {
+			return fmt.Errorf("amount exceeds outstanding balance of %d", outstanding)
+		}
+
+		payment.Allocations = allocatePayment(req.Amount, installments, req.PaidAt)
+		for i := range installments {
+			if err := uc.installmentRepo.Save(txDB, &installments[i]); err != nil {
+				return err
+			}
+		}
+
+		// The same reference posted concurrently to another contract is only
+		// caught by the unique index.
+		err = uc.paymentRepo.Create(txDB, payment)
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return errReferenceTaken
+		}
+		if err != nil {
+			return err
+		}
+
+		fields := agingFields(oldestOverdueDPD(installments, time.Now()))
+		entry := repaymentEntry(payment)
+		if req.Amount == outstanding {
+			fields["status"] = model.TransactionStatusClosed
+			balances, err := uc.ledgerRepo.ContractBalances(txDB, tx.ID)
+			if err != nil {
+				return err
+			}
+			entry = payoffEntry(payment, balances)
+		}
+		if err := postEntry(txDB, uc.ledgerRepo, entry); err != nil {
+			return err
+		}
+		return uc.txRepo.UpdateFields(txDB, tx.ID, fields)
+	})
+	if errors.Is(err, errReferenceTaken) {
+		existing, err = uc.paymentRepo.FindByReference(req.Reference)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if existing != nil {
+		if existing.TransactionID != req.TransactionID || existing.Amount != req.Amount {
+			return nil, false, errors.New("payment reference already used for a different payment")
+		}
+		return existing, false, nil
+	}
+
+	return payment, true, nil
+}
+
+func (uc *paymentUsecase) GetPaymentsByTransaction(transactionID uint, viewer Viewer) ([]model.Payment, error) {
+	tx, err := uc.txRepo.FindByID(transactionID)
+	if err != nil {
+		return nil, errors.New("transaction not found")
+	}
+	customer, err := uc.customerRepo.FindByID(tx.CustomerID)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+	if err := authorizeCustomer(customer, viewer); err != nil {
+		return nil, err
+	}
+	return uc.paymentRepo.FindByTransactionID(transactionID)
+}
+
+// allocatePayment applies amount to installments oldest first. Within an
+// installment the late fee is settled first, then interest, then principal.
+func allocatePayment(amount int64, installments []model.Installment, paidAt time.Time) []model.PaymentAllocation {
+	var allocations []model.PaymentAllocation
+	for i := range installments {
+		if amount <= 0 {
+			break
+		}
+		inst := &installments[i]
+
+		allocation := model.PaymentAllocation{InstallmentID: inst.ID}
+
+		allocation.LateFee = min(amount, inst.LateFee-inst.LateFeePaid)
+		inst.LateFeePaid += allocation.LateFee
+		amount -= allocation.LateFee
+
+		paid := min(amount, inst.Amount-inst.PaidAmount)
+		allocation.Interest = min(inst.PaidAmount+paid, inst.Interest) - min(inst.PaidAmount, inst.Interest)
+		allocation.Principal = paid - allocation.Interest
+		inst.PaidAmount += paid
+		amount -= paid
+
+		if allocation.LateFee == 0 && paid == 0 {
+			continue
+		}
+
+		switch {
+		case inst.Outstanding() == 0:
+			inst.Status = model.InstallmentStatusPaid
+			inst.PaidAt = &paidAt
+		default:
+			inst.Status = model.InstallmentStatusPartial
+		}
+		allocations = append(allocations, allocation)
+	}
+	return allocations
+}
+
+// oldestOverdueDPD returns the days past due of the oldest installment that is
+// still not fully paid, or zero when nothing is overdue.
+func oldestOverdueDPD(installments []model.Installment, asOf time.Time) int {
+	for _, inst := range installments {
+		if inst.Status == model.InstallmentStatusPaid {
+			continue
+		}
+		if dpd := daysBetween(inst.DueDate, asOf); dpd > 0 {
+			return dpd
+		}
+		return 0
+	}
+	return 0
+}
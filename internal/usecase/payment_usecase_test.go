@@ -0,0 +1,350 @@
+package usecase_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/internal/usecase"
+
+	"gorm.io/gorm"
+)
+
+func TestAllocatePayment(t *testing.T) {
+	paidAt := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	inst := func(id uint, paid, lateFee, lateFeePaid int64) model.Installment {
+		return model.Installment{ID: id, Amount: 1000, Principal: 800, Interest: 200, PaidAmount: paid, LateFee: lateFee, LateFeePaid: lateFeePaid, Status: model.InstallmentStatusUnpaid}
+	}
+
+	cases := []struct {
+		name         string
+		amount       int64
+		installments []model.Installment
+		want         []model.PaymentAllocation
+		wantStatus   []string
+	}{
+		{
+			"interest before principal", 300,
+			[]model.Installment{inst(1, 0, 0, 0)},
+			[]model.PaymentAllocation{{InstallmentID: 1, Interest: 200, Principal: 100}},
+			[]string{model.InstallmentStatusPartial},
+		},
+		{
+			"late fee before interest", 100,
+			[]model.Installment{inst(1, 0, 50, 0)},
+			[]model.PaymentAllocation{{InstallmentID: 1, LateFee: 50, Interest: 50}},
+			[]string{model.InstallmentStatusPartial},
+		},
+		{
+			"resumes a partly paid installment", 100,
+			[]model.Installment{inst(1, 150, 0, 0)},
+			[]model.PaymentAllocation{{InstallmentID: 1, Interest: 50, Principal: 50}},
+			[]string{model.InstallmentStatusPartial},
+		},
+		{
+			"oldest installment first", 1500,
+			[]model.Installment{inst(1, 0, 0, 0), inst(2, 0, 0, 0)},
+			[]model.PaymentAllocation{{InstallmentID: 1, Interest: 200, Principal: 800}, {InstallmentID: 2, Interest: 200, Principal: 300}},
+			[]string{model.InstallmentStatusPaid, model.InstallmentStatusPartial},
+		},
+		{
+			"settles the remaining late fee", 30,
+			[]model.Installment{inst(1, 1000, 50, 20)},
+			[]model.PaymentAllocation{{InstallmentID: 1, LateFee: 30}},
+			[]string{model.InstallmentStatusPaid},
+		},
+		{
+			"skips settled installments", 100,
+			[]model.Installment{inst(1, 1000, 0, 0), inst(2, 0, 0, 0)},
+			[]model.PaymentAllocation{{InstallmentID: 2, Interest: 100}},
+			[]string{model.InstallmentStatusUnpaid, model.InstallmentStatusPartial},
+		},
+	}
+	for _, tc := range cases {
+		got := usecase.AllocatePayment(tc.amount, tc.installments, paidAt)
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: allocations = %+v, want %+v", tc.name, got, tc.want)
+		}
+		for i, inst := range tc.installments {
+			if inst.Status != tc.wantStatus[i] {
+				t.Errorf("%s: installment %d status = %s, want %s", tc.name, inst.ID, inst.Status, tc.wantStatus[i])
+			}
+			if inst.Status == model.InstallmentStatusPaid && inst.PaidAt == nil {
+				t.Errorf("%s: installment %d is paid without paid_at", tc.name, inst.ID)
+			}
+		}
+	}
+}
+
+func TestOldestOverdueDPD(t *testing.T) {
+	asOf := time.Date(2024, 6, 10, 15, 0, 0, 0, time.UTC)
+	due := func(days int, status string) model.Installment {
+		return model.Installment{DueDate: asOf.AddDate(0, 0, days), Status: status}
+	}
+
+	cases := []struct {
+		name         string
+		installments []model.Installment
+		want         int
+	}{
+		{"no installments", nil, 0},
+		{"nothing due yet", []model.Installment{due(5, model.InstallmentStatusUnpaid)}, 0},
+		{"due today", []model.Installment{due(0, model.InstallmentStatusUnpaid)}, 0},
+		{"oldest unpaid counts", []model.Installment{due(-40, model.InstallmentStatusPartial), due(-10, model.InstallmentStatusUnpaid)}, 40},
+		{"paid installments are skipped", []model.Installment{due(-40, model.InstallmentStatusPaid), due(-10, model.InstallmentStatusUnpaid)}, 10},
+		{"oldest unpaid not due yet", []model.Installment{due(-40, model.InstallmentStatusPaid), due(3, model.InstallmentStatusUnpaid)}, 0},
+	}
+	for _, tc := range cases {
+		if got := usecase.OldestOverdueDPD(tc.installments, asOf); got != tc.want {
+			t.Errorf("%s: dpd = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+}
+
+type mockPayTxRepo struct {
+	repository.TransactionRepository
+	tx     model.Transaction
+	fields map[string]interface{}
+}
+
+func (m *mockPayTxRepo) FindByIDForUpdate(db *gorm.DB, id uint) (*model.Transaction, error) {
+	tx := m.tx
+	return &tx, nil
+}
+
+func (m *mockPayTxRepo) FindByID(id uint) (*model.Transaction, error) {
+	tx := m.tx
+	return &tx, nil
+}
+
+func (m *mockPayTxRepo) UpdateFields(db *gorm.DB, id uint, fields map[string]interface{}) error {
+	m.fields = fields
+	return nil
+}
+
+type mockPayInstallmentRepo struct {
+	repository.InstallmentRepository
+	installments []model.Installment
+	saved        []model.Installment
+}
+
+func (m *mockPayInstallmentRepo) FindOpenForUpdate(db *gorm.DB, transactionID uint) ([]model.Installment, error) {
+	return append([]model.Installment(nil), m.installments...), nil
+}
+
+func (m *mockPayInstallmentRepo) Save(db *gorm.DB, installment *model.Installment) error {
+	m.saved = append(m.saved, *installment)
+	return nil
+}
+
+type mockPayPaymentRepo struct {
+	repository.PaymentRepository
+	existing *model.Payment
+	// raced is a payment committed by a concurrent request: the insert hits
+	// the unique reference index and the payment becomes visible afterwards.
+	raced   *model.Payment
+	created []model.Payment
+}
+
+func (m *mockPayPaymentRepo) FindByReference(reference string) (*model.Payment, error) {
+	if m.existing == nil || m.existing.Reference != reference {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return m.existing, nil
+}
+
+func (m *mockPayPaymentRepo) FindByReferenceForUpdate(db *gorm.DB, reference string) (*model.Payment, error) {
+	return m.FindByReference(reference)
+}
+
+func (m *mockPayPaymentRepo) FindByTransactionID(transactionID uint) ([]model.Payment, error) {
+	return m.created, nil
+}
+
+func (m *mockPayPaymentRepo) Create(db *gorm.DB, payment *model.Payment) error {
+	if m.raced != nil && m.raced.Reference == payment.Reference {
+		m.existing = m.raced
+		return gorm.ErrDuplicatedKey
+	}
+	payment.ID = 30
+	m.created = append(m.created, *payment)
+	return nil
+}
+
+type mockJournalRepo struct {
+	repository.LedgerRepository
+	entries  []model.JournalEntry
+	balances map[string]int64
+}
+
+func (m *mockJournalRepo) ContractBalances(db *gorm.DB, transactionID uint) (map[string]int64, error) {
+	return m.balances, nil
+}
+
+func (m *mockJournalRepo) CreateEntry(db *gorm.DB, entry *model.JournalEntry) error {
+	m.entries = append(m.entries, *entry)
+	return nil
+}
+
+func newPayUsecase(t *testing.T, status string) (usecase.PaymentUsecase, *mockPayTxRepo, *mockPayInstallmentRepo, *mockPayPaymentRepo, *mockJournalRepo) {
+	due := time.Now().AddDate(0, 1, 0)
+	txRepo := &mockPayTxRepo{tx: model.Transaction{ID: 7, CustomerID: 3, Status: status}}
+	installmentRepo := &mockPayInstallmentRepo{installments: []model.Installment{
+		{ID: 1, TransactionID: 7, Sequence: 1, DueDate: due, Amount: 1000, Principal: 800, Interest: 200, Status: model.InstallmentStatusUnpaid},
+		{ID: 2, TransactionID: 7, Sequence: 2, DueDate: due.AddDate(0, 1, 0), Amount: 1000, Principal: 800, Interest: 200, Status: model.InstallmentStatusUnpaid},
+	}}
+	paymentRepo := &mockPayPaymentRepo{}
+	ledgerRepo := &mockJournalRepo{}
+	customerRepo := &mockCustomerRepo{
+		FindByIDFunc: func(id uint) (*model.Customer, error) { return &model.Customer{ID: id, UserID: 11}, nil },
+	}
+	uc := usecase.NewPaymentUsecase(paymentRepo, txRepo, installmentRepo, ledgerRepo, customerRepo, newTestDB(t))
+	return uc, txRepo, installmentRepo, paymentRepo, ledgerRepo
+}
+
+func TestPay_AllocatesAndBooksPayment(t *testing.T) {
+	uc, txRepo, installmentRepo, paymentRepo, ledgerRepo := newPayUsecase(t, model.TransactionStatusOngoing)
+
+	payment, created, err := uc.Pay(usecase.PaymentRequest{TransactionID: 7, Amount: 1200, Reference: "PAY-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !created || payment.ID != 30 || len(payment.Allocations) != 2 {
+		t.Fatalf("payment = %+v, created = %v, want a new payment over two installments", payment, created)
+	}
+	if len(installmentRepo.saved) != 2 || installmentRepo.saved[0].Status != model.InstallmentStatusPaid || installmentRepo.saved[1].PaidAmount != 200 {
+		t.Errorf("saved installments = %+v", installmentRepo.saved)
+	}
+	if len(paymentRepo.created) != 1 || len(ledgerRepo.entries) != 1 || ledgerRepo.entries[0].Type != model.JournalTypeRepayment {
+		t.Errorf("payments = %d, entries = %+v, want one repayment", len(paymentRepo.created), ledgerRepo.entries)
+	}
+	if _, closed := txRepo.fields["status"]; closed || txRepo.fields["days_past_due"] != 0 {
+		t.Errorf("transaction fields = %v, want it to stay open and current", txRepo.fields)
+	}
+}
+
+func TestPay_ClosesFullyPaidContract(t *testing.T) {
+	uc, txRepo, _, _, ledgerRepo := newPayUsecase(t, model.TransactionStatusOngoing)
+	// 150 of the 400 interest has been accrued so far.
+	ledgerRepo.balances = map[string]int64{
+		model.AccountLoanReceivable:     1600,
+		model.AccountInterestReceivable: 400,
+		model.AccountUnearnedInterest:   -250,
+	}
+
+	if _, _, err := uc.Pay(usecase.PaymentRequest{TransactionID: 7, Amount: 2000}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if txRepo.fields["status"] != model.TransactionStatusClosed {
+		t.Errorf("transaction fields = %v, want status closed", txRepo.fields)
+	}
+
+	if len(ledgerRepo.entries) != 1 {
+		t.Fatalf("entries = %+v, want one repayment", ledgerRepo.entries)
+	}
+	var unearnedDebit, incomeCredit, debits, credits int64
+	for _, line := range ledgerRepo.entries[0].Lines {
+		debits += line.Debit
+		credits += line.Credit
+		switch line.AccountCode {
+		case model.AccountUnearnedInterest:
+			unearnedDebit += line.Debit
+		case model.AccountInterestIncome:
+			incomeCredit += line.Credit
+		}
+	}
+	if unearnedDebit != 250 || incomeCredit != 250 {
+		t.Errorf("unearned debit = %d, income credit = %d, want the remaining 250 earned", unearnedDebit, incomeCredit)
+	}
+	if debits != credits {
+		t.Errorf("entry is unbalanced: debits %d, credits %d", debits, credits)
+	}
+}
+
+func TestPay_RejectsInvalidPayments(t *testing.T) {
+	cases := []struct {
+		name    string
+		status  string
+		amount  int64
+		wantErr string
+	}{
+		{"zero amount", model.TransactionStatusOngoing, 0, "greater than zero"},
+		{"more than outstanding", model.TransactionStatusOngoing, 2001, "exceeds outstanding balance of 2000"},
+		{"contract not active", model.TransactionStatusClosed, 100, "closed transaction"},
+	}
+	for _, tc := range cases {
+		uc, txRepo, installmentRepo, paymentRepo, ledgerRepo := newPayUsecase(t, tc.status)
+		_, _, err := uc.Pay(usecase.PaymentRequest{TransactionID: 7, Amount: tc.amount})
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("%s: err = %v, want %q", tc.name, err, tc.wantErr)
+		}
+		if txRepo.fields != nil || installmentRepo.saved != nil || paymentRepo.created != nil || ledgerRepo.entries != nil {
+			t.Errorf("%s: payment was written", tc.name)
+		}
+	}
+}
+
+func TestPay_IsIdempotentOnReference(t *testing.T) {
+	uc, _, installmentRepo, paymentRepo, _ := newPayUsecase(t, model.TransactionStatusOngoing)
+	paymentRepo.existing = &model.Payment{ID: 12, TransactionID: 7, Amount: 500, Reference: "PAY-1"}
+
+	payment, created, err := uc.Pay(usecase.PaymentRequest{TransactionID: 7, Amount: 500, Reference: "PAY-1"})
+	if err != nil || created || payment.ID != 12 {
+		t.Errorf("payment = %+v, created = %v, err = %v, want the original payment", payment, created, err)
+	}
+	if _, _, err := uc.Pay(usecase.PaymentRequest{TransactionID: 7, Amount: 600, Reference: "PAY-1"}); err == nil || !strings.Contains(err.Error(), "different payment") {
+		t.Errorf("err = %v, want the reference reuse rejected", err)
+	}
+	if installmentRepo.saved != nil || paymentRepo.created != nil {
+		t.Error("a repeated reference was posted again")
+	}
+}
+
+func TestPay_RetryFindsPaymentThatClosedContract(t *testing.T) {
+	uc, _, _, paymentRepo, _ := newPayUsecase(t, model.TransactionStatusClosed)
+	paymentRepo.existing = &model.Payment{ID: 12, TransactionID: 7, Amount: 2000, Reference: "PAY-1"}
+
+	payment, created, err := uc.Pay(usecase.PaymentRequest{TransactionID: 7, Amount: 2000, Reference: "PAY-1"})
+	if err != nil || created || payment.ID != 12 {
+		t.Errorf("payment = %+v, created = %v, err = %v, want the original payment", payment, created, err)
+	}
+}
+
+func TestPay_ReturnsConcurrentPaymentOnDuplicateReference(t *testing.T) {
+	uc, txRepo, _, paymentRepo, ledgerRepo := newPayUsecase(t, model.TransactionStatusOngoing)
+	paymentRepo.raced = &model.Payment{ID: 12, TransactionID: 7, Amount: 500, Reference: "PAY-1"}
+
+	payment, created, err := uc.Pay(usecase.PaymentRequest{TransactionID: 7, Amount: 500, Reference: "PAY-1"})
+	if err != nil || created || payment.ID != 12 {
+		t.Errorf("payment = %+v, created = %v, err = %v, want the concurrent payment", payment, created, err)
+	}
+	if txRepo.fields != nil || ledgerRepo.entries != nil {
+		t.Error("the losing request was booked")
+	}
+
+	paymentRepo.existing, paymentRepo.raced = nil, &model.Payment{ID: 13, TransactionID: 8, Amount: 500, Reference: "PAY-2"}
+	if _, _, err := uc.Pay(usecase.PaymentRequest{TransactionID: 7, Amount: 500, Reference: "PAY-2"}); err == nil || !strings.Contains(err.Error(), "different payment") {
+		t.Errorf("err = %v, want the reference reuse rejected", err)
+	}
+}
+
+func TestGetPaymentsByTransaction_OnlyForOwnerOrAdmin(t *testing.T) {
+	uc, _, _, _, _ := newPayUsecase(t, model.TransactionStatusOngoing)
+	if _, _, err := uc.Pay(usecase.PaymentRequest{TransactionID: 7, Amount: 500, Reference: "PAY-1"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := uc.GetPaymentsByTransaction(7, usecase.Viewer{UserID: 12, Role: "customer"}); !errors.Is(err, usecase.ErrCustomerForbidden) {
+		t.Errorf("err = %v, want another user's payments forbidden", err)
+	}
+	for _, viewer := range []usecase.Viewer{{UserID: 11, Role: "customer"}, {UserID: 1, Role: "admin"}} {
+		payments, err := uc.GetPaymentsByTransaction(7, viewer)
+		if err != nil || len(payments) != 1 {
+			t.Errorf("%+v: payments = %+v, err = %v, want the contract's payment", viewer, payments, err)
+		}
+	}
+}
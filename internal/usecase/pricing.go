@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"errors"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/pkg/money"
+)
+
+// PricingScheme is the admin fee and flat monthly interest rate, in basis
+// points, applied to a contract.
+type PricingScheme struct {
+	AdminFee        int64
+	InterestRateBps int
+}
+
+func partnerPricingScheme(partner *model.Partner) PricingScheme {
+	return PricingScheme{
+		AdminFee:        partner.AdminFee,
+		InterestRateBps: partner.InterestRateBps,
+	}
+}
+
+// currencyOf treats rows stored before currencies were recorded as rupiah.
+func currencyOf(code string) money.Currency {
+	if code == "" {
+		return money.IDR
+	}
+	return money.Currency(code)
+}
+
+// normalizeCurrency defaults an empty currency to rupiah and rejects codes
+// the money package does not know.
+func normalizeCurrency(code *string) error {
+	currency, err := money.ParseCurrency(string(currencyOf(*code)))
+	if err != nil {
+		return errors.New("unsupported currency")
+	}
+	*code = string(currency)
+	return nil
+}
+
+func txMoney(tx *model.Transaction, amount int64) money.Money {
+	return money.New(amount, currencyOf(tx.Currency))
+}
+
+// applyPricing overwrites the fee and interest amounts of tx using a
+// flat-rate scheme over the financed principal. The fraction of a rupiah
+// left by the rate is rounded with the given mode.
+func applyPricing(tx *model.Transaction, scheme PricingScheme, rounding money.RoundingMode) error {
+	if scheme.AdminFee < 0 || scheme.InterestRateBps < 0 {
+		return errors.New("admin fee and interest rate must not be negative")
+	}
+	interest, err := txMoney(tx, tx.Principal).MulRatio(int64(scheme.InterestRateBps)*int64(tx.Tenor), 10000, rounding)
+	if err != nil {
+		return err
+	}
+
+	tx.AdminFee = scheme.AdminFee
+	tx.InterestAmount = interest.Amount()
+	return nil
+}
+
+// setInstallmentAmount derives the monthly installment from the financed
+// principal and total interest, rounded up to the next rupiah.
+func setInstallmentAmount(tx *model.Transaction) error {
+	if tx.Tenor <= 0 {
+		return nil
+	}
+
+	total, err := txMoney(tx, tx.Principal).Add(txMoney(tx, tx.InterestAmount))
+	if err != nil {
+		return err
+	}
+	installment, err := total.Div(int64(tx.Tenor), money.RoundUp)
+	if err != nil {
+		return err
+	}
+
+	tx.InstallmentAmount = installment.Amount()
+	return nil
+}
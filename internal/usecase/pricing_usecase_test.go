@@ -0,0 +1,58 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/pkg/money"
+)
+
+func TestApplyPricing(t *testing.T) {
+	scheme := usecase.PricingScheme{AdminFee: 250_000, InterestRateBps: 150}
+
+	cases := []struct {
+		name         string
+		principal    int64
+		rounding     money.RoundingMode
+		wantInterest int64
+	}{
+		{"exact", 1_000_000, money.RoundHalfUp, 45_000},
+		{"fraction rounded down", 1_000_050, money.RoundDown, 45_002},
+		{"fraction rounded up", 1_000_050, money.RoundUp, 45_003},
+		{"below half", 1_000_050, money.RoundHalfUp, 45_002},
+		{"half up", 1_000_100, money.RoundHalfUp, 45_005},
+		{"half to even", 1_000_100, money.RoundHalfEven, 45_004},
+	}
+	for _, tc := range cases {
+		tx := &model.Transaction{Tenor: 3, Currency: "IDR", Principal: tc.principal, AdminFee: 1, InterestAmount: 1}
+		if err := usecase.ApplyPricing(tx, scheme, tc.rounding); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if tx.InterestAmount != tc.wantInterest || tx.AdminFee != 250_000 {
+			t.Errorf("%s: interest %d, admin fee %d, want %d and 250000", tc.name, tx.InterestAmount, tx.AdminFee, tc.wantInterest)
+		}
+	}
+}
+
+func TestSetInstallmentAmount(t *testing.T) {
+	cases := []struct {
+		name                string
+		tenor               int
+		principal, interest int64
+		want                int64
+	}{
+		{"divides evenly", 3, 900_000, 90_000, 330_000},
+		{"rounds up", 3, 1_000_100, 45_005, 348_369},
+		{"no tenor", 0, 1_000_000, 0, 0},
+	}
+	for _, tc := range cases {
+		tx := &model.Transaction{Tenor: tc.tenor, Currency: "IDR", Principal: tc.principal, InterestAmount: tc.interest}
+		if err := usecase.SetInstallmentAmount(tx); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tc.name, err)
+		}
+		if tx.InstallmentAmount != tc.want {
+			t.Errorf("%s: installment = %d, want %d", tc.name, tx.InstallmentAmount, tc.want)
+		}
+	}
+}
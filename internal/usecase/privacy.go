@@ -0,0 +1,416 @@
+package usecase
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/logger"
+	"xyz-multifinance/storage"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOpenContracts      = errors.New("customer still has open contracts; erasure is only possible once they are closed")
+	ErrCustomerAnonymized = errors.New("customer data has already been erased")
+	ErrExportUnavailable  = errors.New("export archive is not available")
+)
+
+// erasureBlockingStatuses are the contract statuses under which the
+// customer's identity is still needed to disburse or collect.
+var erasureBlockingStatuses = map[string]bool{
+	model.TransactionStatusApproved: true,
+	model.TransactionStatusSuccess:  true,
+	model.TransactionStatusOngoing:  true,
+}
+
+// PrivacyPolicy controls data subject request processing: how long export
+// archives are kept and how many requests one run handles.
+type PrivacyPolicy struct {
+	ExportRetention time.Duration
+	BatchSize       int
+}
+
+type PrivacyRunResult struct {
+	Processed int `json:"processed"`
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+	Expired   int `json:"expired"`
+}
+
+// PrivacyExport is a finished export archive ready to be streamed. The
+// caller must close Body.
+type PrivacyExport struct {
+	Filename string
+	Size     int64
+	Body     io.ReadCloser
+}
+
+type PrivacyUsecase interface {
+	// RequestExport queues a ZIP export of the customer's profile, KYC
+	// documents, contracts and payments.
+	RequestExport(nik string, requestedBy uint, reason string) (*model.PrivacyRequest, error)
+	// RequestErasure queues the anonymization of the customer. Contracts,
+	// installments and payments are kept, no longer linked to a person.
+	RequestErasure(nik string, requestedBy uint, reason string) (*model.PrivacyRequest, error)
+	// ProcessPending works through queued requests and deletes export
+	// archives past their retention period.
+	ProcessPending(ctx context.Context, now time.Time) (*PrivacyRunResult, error)
+	GetRequest(id uint) (*model.PrivacyRequest, error)
+	GetRequestsByCustomer(nik string) ([]model.PrivacyRequest, error)
+	DownloadExport(ctx context.Context, id uint) (*PrivacyExport, error)
+}
+
+type privacyUsecase struct {
+	privacyRepo     repository.PrivacyRequestRepository
+	customerRepo    repository.CustomerRepository
+	userRepo        repository.UserRepository
+	txRepo          repository.TransactionRepository
+	installmentRepo repository.InstallmentRepository
+	paymentRepo     repository.PaymentRepository
+	store           storage.BlobStore
+	policy          PrivacyPolicy
+	db              *gorm.DB
+}
+
+func NewPrivacyUsecase(
+	privacyRepo repository.PrivacyRequestRepository,
+	customerRepo repository.CustomerRepository,
+	userRepo repository.UserRepository,
+	txRepo repository.TransactionRepository,
+	installmentRepo repository.InstallmentRepository,
+	paymentRepo repository.PaymentRepository,
+	store storage.BlobStore,
+	policy PrivacyPolicy,
+	db *gorm.DB,
+) PrivacyUsecase {
+	if policy.BatchSize <= 0 {
+		policy.BatchSize = 10
+	}
+	return &privacyUsecase{
+		privacyRepo:     privacyRepo,
+		customerRepo:    customerRepo,
+		userRepo:        userRepo,
+		txRepo:          txRepo,
+		installmentRepo: installmentRepo,
+		paymentRepo:     paymentRepo,
+		store:           store,
+		policy:          policy,
+		db:              db,
+	}
+}
+
+func (uc *privacyUsecase) RequestExport(nik string, requestedBy uint, reason string) (*model.PrivacyRequest, error) {
+	return uc.request(nik, model.PrivacyRequestExport, requestedBy, reason)
+}
+
+func (uc *privacyUsecase) RequestErasure(nik string, requestedBy uint, reason string) (*model.PrivacyRequest, error) {
+	return uc.request(nik, model.PrivacyRequestErasure, requestedBy, reason)
+}
+
+func (uc *privacyUsecase) request(nik, requestType string, requestedBy uint, reason string) (*model.PrivacyRequest, error) {
+	customer, err := uc.customerRepo.FindByNIK(nik)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+	if customer.AnonymizedAt != nil {
+		return nil, ErrCustomerAnonymized
+	}
+	if requestType == model.PrivacyRequestErasure {
+		if err := uc.checkErasable(customer.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	request := &model.PrivacyRequest{
+		CustomerID:  customer.ID,
+		Type:        requestType,
+		Status:      model.PrivacyStatusPending,
+		Reason:      truncate(reason, 255),
+		RequestedBy: requestedBy,
+	}
+	if err := uc.privacyRepo.Create(request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func (uc *privacyUsecase) checkErasable(customerID uint) error {
+	transactions, err := uc.txRepo.FindByCustomerID(customerID)
+	if err != nil {
+		return err
+	}
+	for _, tx := range transactions {
+		if erasureBlockingStatuses[tx.Status] {
+			return ErrOpenContracts
+		}
+	}
+	return nil
+}
+
+func (uc *privacyUsecase) ProcessPending(ctx context.Context, now time.Time) (*PrivacyRunResult, error) {
+	result := &PrivacyRunResult{}
+
+	pending, err := uc.privacyRepo.FindPending(uc.policy.BatchSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, request := range pending {
+		claimed, err := uc.privacyRepo.Claim(request.ID)
+		if err != nil {
+			return result, err
+		}
+		if !claimed {
+			continue
+		}
+		result.Processed++
+
+		fields, procErr := uc.process(ctx, &request, now)
+		if procErr != nil {
+			fields = map[string]interface{}{
+				"status":     model.PrivacyStatusFailed,
+				"last_error": truncate(procErr.Error(), 255),
+			}
+			result.Failed++
+		} else {
+			fields["status"] = model.PrivacyStatusCompleted
+			fields["completed_at"] = now
+			fields["last_error"] = ""
+			result.Completed++
+		}
+		if err := uc.privacyRepo.Update(request.ID, fields); err != nil {
+			return result, err
+		}
+	}
+
+	expired, err := uc.privacyRepo.FindExpiredExports(now, uc.policy.BatchSize)
+	if err != nil {
+		return result, err
+	}
+	for _, request := range expired {
+		if err := uc.store.Delete(ctx, request.ResultKey); err != nil {
+			return result, fmt.Errorf("privacy request %d: %w", request.ID, err)
+		}
+		if err := uc.privacyRepo.Update(request.ID, map[string]interface{}{
+			"status":     model.PrivacyStatusExpired,
+			"result_key": "",
+		}); err != nil {
+			return result, err
+		}
+		result.Expired++
+	}
+
+	return result, nil
+}
+
+// process carries out one request and returns the fields to store on it.
+func (uc *privacyUsecase) process(ctx context.Context, request *model.PrivacyRequest, now time.Time) (map[string]interface{}, error) {
+	customer, err := uc.customerRepo.FindByID(request.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("customer %d not found", request.CustomerID)
+	}
+	if customer.AnonymizedAt != nil {
+		return nil, ErrCustomerAnonymized
+	}
+
+	if request.Type == model.PrivacyRequestErasure {
+		return map[string]interface{}{}, uc.erase(ctx, customer, now)
+	}
+
+	archive, err := uc.buildExport(ctx, customer)
+	if err != nil {
+		return nil, err
+	}
+	key := fmt.Sprintf("privacy/exports/%d/%d.zip", customer.ID, request.ID)
+	if err := uc.store.Put(ctx, key, bytes.NewReader(archive), int64(len(archive)), "application/zip"); err != nil {
+		return nil, fmt.Errorf("failed to store export: %w", err)
+	}
+	return map[string]interface{}{
+		"result_key": key,
+		"expires_at": now.Add(uc.policy.ExportRetention),
+	}, nil
+}
+
+type exportContract struct {
+	model.Transaction
+	Installments []model.Installment `json:"installments"`
+}
+
+// buildExport writes everything held about a customer into a ZIP archive:
+// profile.json, contracts.json (with their schedules), payments.json and the
+// KYC photos under documents/.
+func (uc *privacyUsecase) buildExport(ctx context.Context, customer *model.Customer) ([]byte, error) {
+	transactions, err := uc.txRepo.FindByCustomerID(customer.ID)
+	if err != nil {
+		return nil, err
+	}
+	contracts := make([]exportContract, 0, len(transactions))
+	payments := []model.Payment{}
+	for _, tx := range transactions {
+		installments, err := uc.installmentRepo.FindByTransactionID(tx.ID)
+		if err != nil {
+			return nil, err
+		}
+		contracts = append(contracts, exportContract{Transaction: tx, Installments: installments})
+
+		txPayments, err := uc.paymentRepo.FindByTransactionID(tx.ID)
+		if err != nil {
+			return nil, err
+		}
+		payments = append(payments, txPayments...)
+	}
+
+	// The export goes to the customer themselves, so it carries their data
+	// in full.
+	customer.UnmaskPII()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", customer},
+		{"contracts.json", contracts},
+		{"payments.json", payments},
+	}
+	for _, file := range files {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.data); err != nil {
+			return nil, err
+		}
+	}
+
+	documents := []struct{ docType, key string }{
+		{model.KYCDocumentKTP, customer.KTPPhoto},
+		{model.KYCDocumentSelfie, customer.SelfiePhoto},
+	}
+	for _, doc := range documents {
+		if doc.key == "" {
+			continue
+		}
+		if err := uc.addDocument(ctx, zw, "documents/"+doc.docType+path.Ext(doc.key), doc.key); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (uc *privacyUsecase) addDocument(ctx context.Context, zw *zip.Writer, name, key string) error {
+	body, _, err := uc.store.Get(ctx, key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	defer body.Close()
+
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, body)
+	return err
+}
+
+// erase anonymizes the customer and its login, then deletes the KYC photos
+// and any export archives. Objects are deleted only after the rows stop
+// referring to them; leftovers are found by the gc command.
+func (uc *privacyUsecase) erase(ctx context.Context, customer *model.Customer, now time.Time) error {
+	if err := uc.checkErasable(customer.ID); err != nil {
+		return err
+	}
+	user, err := uc.userRepo.FindByID(customer.UserID)
+	if err != nil {
+		return err
+	}
+	requests, err := uc.privacyRepo.FindByCustomerID(customer.ID)
+	if err != nil {
+		return err
+	}
+
+	err = uc.db.Transaction(func(txDB *gorm.DB) error {
+		if err := uc.customerRepo.Anonymize(txDB, customer.ID, now); err != nil {
+			return err
+		}
+		if user != nil && user.Role != "admin" {
+			if err := uc.userRepo.Anonymize(txDB, user.ID); err != nil {
+				return err
+			}
+		}
+		return uc.privacyRepo.ExpireExports(txDB, customer.ID)
+	})
+	if err != nil {
+		return err
+	}
+
+	keys := []string{customer.KTPPhoto, customer.SelfiePhoto}
+	for _, request := range requests {
+		keys = append(keys, request.ResultKey)
+	}
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := uc.store.Delete(ctx, key); err != nil {
+			logger.Log.Warnf("erasure of customer %d: failed to delete object: %v", customer.ID, err)
+		}
+	}
+	return nil
+}
+
+func (uc *privacyUsecase) GetRequest(id uint) (*model.PrivacyRequest, error) {
+	request, err := uc.privacyRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("privacy request not found")
+	}
+	return request, nil
+}
+
+func (uc *privacyUsecase) GetRequestsByCustomer(nik string) ([]model.PrivacyRequest, error) {
+	customer, err := uc.customerRepo.FindByNIK(nik)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+	return uc.privacyRepo.FindByCustomerID(customer.ID)
+}
+
+func (uc *privacyUsecase) DownloadExport(ctx context.Context, id uint) (*PrivacyExport, error) {
+	request, err := uc.GetRequest(id)
+	if err != nil {
+		return nil, err
+	}
+	if request.Type != model.PrivacyRequestExport || request.Status != model.PrivacyStatusCompleted || request.ResultKey == "" {
+		return nil, ErrExportUnavailable
+	}
+	body, info, err := uc.store.Get(ctx, request.ResultKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, ErrExportUnavailable
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &PrivacyExport{
+		Filename: fmt.Sprintf("customer-%d-export-%d.zip", request.CustomerID, request.ID),
+		Size:     info.Size,
+		Body:     body,
+	}, nil
+}
@@ -0,0 +1,189 @@
+package usecase_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/pkg/urlsign"
+	"xyz-multifinance/storage"
+)
+
+type mockPrivacyRequestRepo struct {
+	repository.PrivacyRequestRepository
+	requests []model.PrivacyRequest
+}
+
+func (m *mockPrivacyRequestRepo) Create(request *model.PrivacyRequest) error {
+	request.ID = uint(len(m.requests) + 1)
+	m.requests = append(m.requests, *request)
+	return nil
+}
+
+func (m *mockPrivacyRequestRepo) FindByID(id uint) (*model.PrivacyRequest, error) {
+	for i := range m.requests {
+		if m.requests[i].ID == id {
+			request := m.requests[i]
+			return &request, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+
+func (m *mockPrivacyRequestRepo) FindPending(limit int) ([]model.PrivacyRequest, error) {
+	var pending []model.PrivacyRequest
+	for _, request := range m.requests {
+		if request.Status == model.PrivacyStatusPending {
+			pending = append(pending, request)
+		}
+	}
+	return pending, nil
+}
+
+func (m *mockPrivacyRequestRepo) FindExpiredExports(now time.Time, limit int) ([]model.PrivacyRequest, error) {
+	return nil, nil
+}
+
+func (m *mockPrivacyRequestRepo) FindResultKeys() ([]string, error) {
+	var keys []string
+	for _, request := range m.requests {
+		if request.ResultKey != "" {
+			keys = append(keys, request.ResultKey)
+		}
+	}
+	return keys, nil
+}
+
+func (m *mockPrivacyRequestRepo) Claim(id uint) (bool, error) {
+	return true, m.Update(id, map[string]interface{}{"status": model.PrivacyStatusProcessing})
+}
+
+func (m *mockPrivacyRequestRepo) Update(id uint, fields map[string]interface{}) error {
+	for i := range m.requests {
+		if m.requests[i].ID != id {
+			continue
+		}
+		if status, ok := fields["status"].(string); ok {
+			m.requests[i].Status = status
+		}
+		if key, ok := fields["result_key"].(string); ok {
+			m.requests[i].ResultKey = key
+		}
+		if lastError, ok := fields["last_error"].(string); ok {
+			m.requests[i].LastError = lastError
+		}
+	}
+	return nil
+}
+
+type mockPrivacyTxRepo struct {
+	repository.TransactionRepository
+	transactions []model.Transaction
+}
+
+func (m *mockPrivacyTxRepo) FindByCustomerID(customerID uint) ([]model.Transaction, error) {
+	return m.transactions, nil
+}
+
+func newPrivacyUsecase(t *testing.T, transactions []model.Transaction) (usecase.PrivacyUsecase, *mockPrivacyRequestRepo) {
+	t.Helper()
+	store := storage.NewFileSystemStore(t.TempDir(), "http://localhost/api/v1/files", urlsign.New("files"))
+	ktp := []byte("ktp image bytes")
+	if err := store.Put(context.Background(), "kyc/3201/ktp_1.jpg", bytes.NewReader(ktp), int64(len(ktp)), "image/jpeg"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+
+	customer := &model.Customer{ID: 7, UserID: 42, NIK: "3201", FullName: "Budi Santoso", KTPPhoto: "kyc/3201/ktp_1.jpg"}
+	customers := &mockCustomerRepo{
+		FindByNIKFunc: func(nik string) (*model.Customer, error) { return customer, nil },
+		FindByIDFunc:  func(id uint) (*model.Customer, error) { return customer, nil },
+	}
+	installments := &mockInstallmentRepo{installments: []model.Installment{
+		{ID: 1, TransactionID: 11, Sequence: 1, Amount: 1100, Status: model.InstallmentStatusPaid},
+	}}
+	payments := &mockPaymentRepo{payments: []model.Payment{
+		{ID: 1, TransactionID: 11, Amount: 1100, Reference: "P1", Status: model.PaymentStatusPosted},
+	}}
+	requests := &mockPrivacyRequestRepo{}
+	uc := usecase.NewPrivacyUsecase(requests, customers, &mockUserRepo{}, &mockPrivacyTxRepo{transactions: transactions},
+		installments, payments, store, usecase.PrivacyPolicy{ExportRetention: 72 * time.Hour}, nil)
+	return uc, requests
+}
+
+func TestProcessPending_BuildsExportArchive(t *testing.T) {
+	uc, requests := newPrivacyUsecase(t, []model.Transaction{
+		{ID: 11, CustomerID: 7, ContractNumber: "CN-11", Status: model.TransactionStatusClosed},
+	})
+	request, err := uc.RequestExport("3201", 1, "customer asked by email")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := uc.ProcessPending(context.Background(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Completed != 1 || result.Failed != 0 {
+		t.Fatalf("result = %+v, last error %q", result, requests.requests[0].LastError)
+	}
+
+	export, err := uc.DownloadExport(context.Background(), request.ID)
+	if err != nil {
+		t.Fatalf("download: %v", err)
+	}
+	defer export.Body.Close()
+	data, err := io.ReadAll(export.Body)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("not a zip archive: %v", err)
+	}
+	files := map[string]string{}
+	for _, file := range archive.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", file.Name, err)
+		}
+		body, _ := io.ReadAll(rc)
+		rc.Close()
+		files[file.Name] = string(body)
+	}
+	for _, name := range []string{"profile.json", "contracts.json", "payments.json", "documents/ktp.jpg"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("archive is missing %s (has %v)", name, archive.File)
+		}
+	}
+	if files["documents/ktp.jpg"] != "ktp image bytes" {
+		t.Errorf("ktp = %q", files["documents/ktp.jpg"])
+	}
+	if !bytes.Contains([]byte(files["contracts.json"]), []byte(`"CN-11"`)) {
+		t.Errorf("contracts.json = %s", files["contracts.json"])
+	}
+	if !bytes.Contains([]byte(files["profile.json"]), []byte(`"nik": "3201"`)) {
+		t.Errorf("profile.json = %s, want the customer's data unmasked", files["profile.json"])
+	}
+}
+
+func TestRequestErasure_RefusesOpenContracts(t *testing.T) {
+	uc, requests := newPrivacyUsecase(t, []model.Transaction{
+		{ID: 11, CustomerID: 7, Status: model.TransactionStatusClosed},
+		{ID: 12, CustomerID: 7, Status: model.TransactionStatusOngoing},
+	})
+
+	_, err := uc.RequestErasure("3201", 1, "")
+	if !errors.Is(err, usecase.ErrOpenContracts) {
+		t.Fatalf("err = %v, want ErrOpenContracts", err)
+	}
+	if len(requests.requests) != 0 {
+		t.Errorf("request was queued: %+v", requests.requests)
+	}
+}
@@ -0,0 +1,433 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/pkg/bankstatement"
+	"xyz-multifinance/pkg/vanumber"
+
+	"gorm.io/gorm"
+)
+
+const PaymentChannelBankTransfer = "bank_transfer"
+
+// ReconciliationPolicy tells the matcher which digit runs can be virtual
+// account numbers and how many days before its due date a payment may
+// arrive and still count as paying that installment.
+type ReconciliationPolicy struct {
+	VAPrefix        string
+	MatchWindowDays int
+}
+
+type ReconciliationReport struct {
+	Import      *model.StatementImport   `json:"import"`
+	Summary     []repository.StatusTotal `json:"summary"`
+	TotalCredit int64                    `json:"total_credit"`
+	Posted      int64                    `json:"posted"`
+	NeedsReview int64                    `json:"needs_review"`
+}
+
+type ReconciliationUsecase interface {
+	// Import reads a statement, posts every credit that matches exactly one
+	// expected installment and queues the rest for manual matching.
+	Import(filename, format string, r io.Reader, importedBy uint) (*ReconciliationReport, error)
+	GetImports() ([]model.StatementImport, error)
+	GetReport(importID uint) (*ReconciliationReport, error)
+	GetQueue() ([]model.StatementLine, error)
+	// ResolveLine posts a queued line to the given contract.
+	ResolveLine(lineID, transactionID, resolvedBy uint, note string) (*model.StatementLine, error)
+	IgnoreLine(lineID, resolvedBy uint, note string) (*model.StatementLine, error)
+}
+
+type reconciliationUsecase struct {
+	reconRepo       repository.ReconciliationRepository
+	vaRepo          repository.VirtualAccountRepository
+	txRepo          repository.TransactionRepository
+	installmentRepo repository.InstallmentRepository
+	paymentRepo     repository.PaymentRepository
+	paymentUC       PaymentUsecase
+	policy          ReconciliationPolicy
+	db              *gorm.DB
+}
+
+func NewReconciliationUsecase(
+	reconRepo repository.ReconciliationRepository,
+	vaRepo repository.VirtualAccountRepository,
+	txRepo repository.TransactionRepository,
+	installmentRepo repository.InstallmentRepository,
+	paymentRepo repository.PaymentRepository,
+	paymentUC PaymentUsecase,
+	policy ReconciliationPolicy,
+	db *gorm.DB,
+) ReconciliationUsecase {
+	return &reconciliationUsecase{
+		reconRepo:       reconRepo,
+		vaRepo:          vaRepo,
+		txRepo:          txRepo,
+		installmentRepo: installmentRepo,
+		paymentRepo:     paymentRepo,
+		paymentUC:       paymentUC,
+		policy:          policy,
+		db:              db,
+	}
+}
+
+func (uc *reconciliationUsecase) Import(filename, format string, r io.Reader, importedBy uint) (*ReconciliationReport, error) {
+	if format == "" {
+		format = bankstatement.DetectFormat(filename)
+	}
+	format = strings.ToLower(format)
+
+	parsed, err := bankstatement.Parse(format, r)
+	if errors.Is(err, bankstatement.ErrUnknownFormat) {
+		return nil, errors.New("format must be csv or mt940")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed) == 0 {
+		return nil, errors.New("statement has no lines")
+	}
+
+	statementImport := &model.StatementImport{
+		FileName:   filename,
+		Format:     format,
+		ImportedBy: importedBy,
+		LineCount:  len(parsed),
+	}
+	accounts := make([]*model.VirtualAccount, len(parsed))
+	for i, l := range parsed {
+		line := model.StatementLine{
+			LineNumber:  l.Number,
+			ValueDate:   l.Date,
+			Amount:      l.Amount,
+			Reference:   truncate(l.Reference, 100),
+			Description: truncate(l.Description, 255),
+		}
+		if accounts[i], err = uc.match(&line); err != nil {
+			return nil, err
+		}
+		statementImport.Lines = append(statementImport.Lines, line)
+	}
+
+	// The import is stored before anything is posted, so every payment it
+	// posts can be traced back to its line.
+	if err := uc.db.Transaction(func(txDB *gorm.DB) error {
+		return uc.reconRepo.CreateImport(txDB, statementImport)
+	}); err != nil {
+		return nil, err
+	}
+	for i := range statementImport.Lines {
+		line := &statementImport.Lines[i]
+		if line.Status != model.StatementLineMatched {
+			continue
+		}
+		if err := uc.post(line, accounts[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return uc.GetReport(statementImport.ID)
+}
+
+// match works out what one line pays and returns the virtual account it was
+// paid into, if any. Payments are idempotent on their reference, so a line
+// from a file imported before, or one the virtual account webhook already
+// posted, is recognised as already posted.
+func (uc *reconciliationUsecase) match(line *model.StatementLine) (*model.VirtualAccount, error) {
+	if line.Amount <= 0 {
+		line.Status = model.StatementLineIgnored
+		line.Note = "debit"
+		return nil, nil
+	}
+
+	account := uc.findAccount(line.Reference + " " + line.Description)
+	if account != nil {
+		line.VANumber = account.Number
+	}
+	if payment, err := uc.paymentRepo.FindByReference(paymentReference(line, account)); err == nil {
+		line.Status = model.StatementLineAlreadyPosted
+		line.TransactionID = &payment.TransactionID
+		line.PaymentID = &payment.ID
+		return account, nil
+	}
+	if account == nil {
+		line.Status = model.StatementLineUnmatched
+		line.Note = "no virtual account number found"
+		return nil, nil
+	}
+
+	candidates, err := uc.candidates(account)
+	if err != nil {
+		return nil, err
+	}
+	line.Status, line.TransactionID, line.Note = matchLine(line.Amount, line.ValueDate, candidates, uc.policy.MatchWindowDays)
+	return account, nil
+}
+
+// post pays a stored, exactly matched line to its contract. A payment the
+// contract refuses sends the line to the review queue instead.
+func (uc *reconciliationUsecase) post(line *model.StatementLine, account *model.VirtualAccount) error {
+	payment, _, err := uc.paymentUC.Pay(PaymentRequest{
+		TransactionID: *line.TransactionID,
+		Amount:        line.Amount,
+		Channel:       PaymentChannelVirtualAccount,
+		Reference:     paymentReference(line, account),
+		PaidAt:        line.ValueDate,
+	})
+	if err != nil {
+		line.Status = model.StatementLineUnmatched
+		line.Note = truncate(err.Error(), 255)
+	} else {
+		line.PaymentID = &payment.ID
+	}
+	return uc.reconRepo.SaveLine(uc.db, line)
+}
+
+var digitRun = regexp.MustCompile(`\d{10,20}`)
+
+// findAccount returns the first known virtual account whose number appears
+// in text.
+func (uc *reconciliationUsecase) findAccount(text string) *model.VirtualAccount {
+	for _, number := range digitRun.FindAllString(text, -1) {
+		if !strings.HasPrefix(number, uc.policy.VAPrefix) || !vanumber.Valid(number) {
+			continue
+		}
+		if account, err := uc.vaRepo.FindByNumber(number); err == nil && account.Active {
+			return account
+		}
+	}
+	return nil
+}
+
+// candidates returns the open installments of every active contract a
+// payment into the account could be for.
+func (uc *reconciliationUsecase) candidates(account *model.VirtualAccount) (map[uint][]model.Installment, error) {
+	var ids []uint
+	if account.TransactionID != nil {
+		tx, err := uc.txRepo.FindByID(*account.TransactionID)
+		if err != nil {
+			return nil, err
+		}
+		if isActiveStatus(tx.Status) {
+			ids = append(ids, tx.ID)
+		}
+	} else {
+		transactions, err := uc.txRepo.FindByCustomerID(account.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+		for _, tx := range transactions {
+			if isActiveStatus(tx.Status) {
+				ids = append(ids, tx.ID)
+			}
+		}
+	}
+
+	candidates := make(map[uint][]model.Installment, len(ids))
+	if len(ids) == 0 {
+		return candidates, nil
+	}
+	installments, err := uc.installmentRepo.FindByTransactionIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		candidates[id] = nil
+	}
+	for _, inst := range installments {
+		if inst.Status != model.InstallmentStatusPaid {
+			candidates[inst.TransactionID] = append(candidates[inst.TransactionID], inst)
+		}
+	}
+	return candidates, nil
+}
+
+// matchLine compares a credit with what each candidate contract expects. The
+// expected amounts of a contract are its open installments settled oldest
+// first: paying the next one, or the next several, is an exact match as long
+// as the last of them falls due no more than windowDays after the payment.
+func matchLine(amount int64, date time.Time, candidates map[uint][]model.Installment, windowDays int) (string, *uint, string) {
+	if len(candidates) == 0 {
+		return model.StatementLineUnmatched, nil, "no active contract for virtual account"
+	}
+
+	ids := make([]uint, 0, len(candidates))
+	for id := range candidates {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	latestDue := startOfDay(date).AddDate(0, 0, windowDays)
+	var exact, covering []uint
+	for _, id := range ids {
+		installments := candidates[id]
+		sort.Slice(installments, func(i, j int) bool { return installments[i].Sequence < installments[j].Sequence })
+
+		var expected int64
+		for _, inst := range installments {
+			expected += inst.Outstanding()
+			if expected == amount && !inst.DueDate.After(latestDue) {
+				exact = append(exact, id)
+				break
+			}
+		}
+		if amount <= expected {
+			covering = append(covering, id)
+		}
+	}
+
+	switch {
+	case len(exact) == 1:
+		return model.StatementLineMatched, &exact[0], ""
+	case len(exact) > 1:
+		return model.StatementLineAmbiguous, nil, fmt.Sprintf("amount matches %d contracts", len(exact))
+	case len(covering) == 1:
+		return model.StatementLinePartial, &covering[0], "amount does not match the installments due"
+	case len(covering) > 1:
+		return model.StatementLineAmbiguous, nil, fmt.Sprintf("amount fits %d contracts", len(covering))
+	}
+	return model.StatementLineUnmatched, nil, "amount exceeds the outstanding balance"
+}
+
+// paymentReference is the reference the line is posted under. Lines paid
+// into a virtual account use the same reference as the webhook so that a
+// payment is never posted by both.
+func paymentReference(line *model.StatementLine, account *model.VirtualAccount) string {
+	if account != nil && line.Reference != "" {
+		return fmt.Sprintf("VA-%s-%s", account.BankCode, line.Reference)
+	}
+	if line.Reference != "" {
+		return "STMT-" + line.Reference
+	}
+	return fmt.Sprintf("STMT-%s-%d-%d", line.ValueDate.Format("20060102"), line.Amount, line.LineNumber)
+}
+
+func (uc *reconciliationUsecase) GetImports() ([]model.StatementImport, error) {
+	return uc.reconRepo.FindImports()
+}
+
+func (uc *reconciliationUsecase) GetReport(importID uint) (*ReconciliationReport, error) {
+	statementImport, err := uc.reconRepo.FindImportByID(importID)
+	if err != nil {
+		return nil, errors.New("import not found")
+	}
+	summary, err := uc.reconRepo.Summarize(importID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReconciliationReport{Import: statementImport, Summary: summary}
+	for _, total := range summary {
+		switch total.Status {
+		case model.StatementLineMatched, model.StatementLineResolved:
+			report.Posted += total.Amount
+		case model.StatementLinePartial, model.StatementLineAmbiguous, model.StatementLineUnmatched:
+			report.NeedsReview += total.Amount
+		}
+	}
+	for _, line := range statementImport.Lines {
+		if line.Amount > 0 {
+			report.TotalCredit += line.Amount
+		}
+	}
+	return report, nil
+}
+
+func (uc *reconciliationUsecase) GetQueue() ([]model.StatementLine, error) {
+	return uc.reconRepo.FindQueue()
+}
+
+func (uc *reconciliationUsecase) ResolveLine(lineID, transactionID, resolvedBy uint, note string) (*model.StatementLine, error) {
+	line, err := uc.reconRepo.FindLine(lineID)
+	if err != nil {
+		return nil, errors.New("statement line not found")
+	}
+	if !needsReview(line.Status) {
+		return nil, fmt.Errorf("cannot resolve a %s line", line.Status)
+	}
+
+	var account *model.VirtualAccount
+	if line.VANumber != "" {
+		account, _ = uc.vaRepo.FindByNumber(line.VANumber)
+	}
+	channel := PaymentChannelBankTransfer
+	if account != nil {
+		channel = PaymentChannelVirtualAccount
+	}
+
+	payment, _, err := uc.paymentUC.Pay(PaymentRequest{
+		TransactionID: transactionID,
+		Amount:        line.Amount,
+		Channel:       channel,
+		Reference:     paymentReference(line, account),
+		PaidAt:        line.ValueDate,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.close(lineID, model.StatementLineResolved, resolvedBy, note, func(l *model.StatementLine) {
+		l.TransactionID = &payment.TransactionID
+		l.PaymentID = &payment.ID
+	})
+}
+
+func (uc *reconciliationUsecase) IgnoreLine(lineID, resolvedBy uint, note string) (*model.StatementLine, error) {
+	line, err := uc.reconRepo.FindLine(lineID)
+	if err != nil {
+		return nil, errors.New("statement line not found")
+	}
+	if !needsReview(line.Status) {
+		return nil, fmt.Errorf("cannot ignore a %s line", line.Status)
+	}
+	return uc.close(lineID, model.StatementLineIgnored, resolvedBy, note, nil)
+}
+
+// close moves a queued line to its final status.
+func (uc *reconciliationUsecase) close(lineID uint, status string, resolvedBy uint, note string, apply func(*model.StatementLine)) (*model.StatementLine, error) {
+	var line *model.StatementLine
+	err := uc.db.Transaction(func(txDB *gorm.DB) error {
+		var err error
+		line, err = uc.reconRepo.FindLineForUpdate(txDB, lineID)
+		if err != nil {
+			return errors.New("statement line not found")
+		}
+		if !needsReview(line.Status) {
+			return fmt.Errorf("line was already %s", line.Status)
+		}
+
+		now := time.Now()
+		line.Status = status
+		line.ResolvedBy = &resolvedBy
+		line.ResolvedAt = &now
+		if note != "" {
+			line.Note = truncate(note, 255)
+		}
+		if apply != nil {
+			apply(line)
+		}
+		return uc.reconRepo.SaveLine(txDB, line)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+func needsReview(status string) bool {
+	for _, s := range model.StatementLineNeedsReview {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package usecase_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/pkg/vanumber"
+
+	"gorm.io/gorm"
+)
+
+func TestMatchLine(t *testing.T) {
+	paidOn := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	due := func(days int) time.Time { return paidOn.AddDate(0, 0, days) }
+	contract := func(transactionID uint, dueDays ...int) []model.Installment {
+		var installments []model.Installment
+		for i, days := range dueDays {
+			installments = append(installments, model.Installment{
+				TransactionID: transactionID, Sequence: i + 1, DueDate: due(days), Amount: 1000,
+			})
+		}
+		return installments
+	}
+
+	cases := []struct {
+		name       string
+		amount     int64
+		candidates map[uint][]model.Installment
+		wantStatus string
+		wantTx     uint
+	}{
+		{"no contracts", 1000, nil, model.StatementLineUnmatched, 0},
+		{"next installment", 1000, map[uint][]model.Installment{7: contract(7, 5, 35)}, model.StatementLineMatched, 7},
+		{"next two installments", 2000, map[uint][]model.Installment{7: contract(7, -3, 5)}, model.StatementLineMatched, 7},
+		{"due too far ahead", 1000, map[uint][]model.Installment{7: contract(7, 20)}, model.StatementLinePartial, 7},
+		{"less than due", 600, map[uint][]model.Installment{7: contract(7, 5)}, model.StatementLinePartial, 7},
+		{"more than outstanding", 5000, map[uint][]model.Installment{7: contract(7, 5, 35)}, model.StatementLineUnmatched, 0},
+		{"two contracts expect it", 1000, map[uint][]model.Installment{7: contract(7, 5), 8: contract(8, 3)}, model.StatementLineAmbiguous, 0},
+		{"only one contract expects it", 2000, map[uint][]model.Installment{7: contract(7, 5), 8: contract(8, 3, 7)}, model.StatementLineMatched, 8},
+		{"fits two contracts partially", 500, map[uint][]model.Installment{7: contract(7, 5), 8: contract(8, 3)}, model.StatementLineAmbiguous, 0},
+	}
+	for _, tc := range cases {
+		status, transactionID, _ := usecase.MatchLine(tc.amount, paidOn, tc.candidates, 7)
+		var got uint
+		if transactionID != nil {
+			got = *transactionID
+		}
+		if status != tc.wantStatus || got != tc.wantTx {
+			t.Errorf("%s: got %s for contract %d, want %s for contract %d", tc.name, status, got, tc.wantStatus, tc.wantTx)
+		}
+	}
+}
+
+type mockReconRepo struct {
+	repository.ReconciliationRepository
+	events []string
+	lines  []model.StatementLine
+}
+
+func (m *mockReconRepo) CreateImport(tx *gorm.DB, statementImport *model.StatementImport) error {
+	m.events = append(m.events, "create import")
+	statementImport.ID = 1
+	for i := range statementImport.Lines {
+		statementImport.Lines[i].ID = uint(i + 1)
+	}
+	m.lines = statementImport.Lines
+	return nil
+}
+
+func (m *mockReconRepo) SaveLine(tx *gorm.DB, line *model.StatementLine) error {
+	m.events = append(m.events, "save line "+line.Status)
+	return nil
+}
+
+func (m *mockReconRepo) FindImportByID(id uint) (*model.StatementImport, error) {
+	return &model.StatementImport{ID: id, Lines: m.lines}, nil
+}
+
+func (m *mockReconRepo) Summarize(importID uint) ([]repository.StatusTotal, error) {
+	return nil, nil
+}
+
+type mockReconVARepo struct {
+	repository.VirtualAccountRepository
+	account model.VirtualAccount
+}
+
+func (m *mockReconVARepo) FindByNumber(number string) (*model.VirtualAccount, error) {
+	if number != m.account.Number {
+		return nil, gorm.ErrRecordNotFound
+	}
+	account := m.account
+	return &account, nil
+}
+
+type mockReconTxRepo struct {
+	repository.TransactionRepository
+}
+
+func (m *mockReconTxRepo) FindByID(id uint) (*model.Transaction, error) {
+	return &model.Transaction{ID: id, Status: model.TransactionStatusOngoing}, nil
+}
+
+type mockReconInstallmentRepo struct {
+	repository.InstallmentRepository
+	installments []model.Installment
+}
+
+func (m *mockReconInstallmentRepo) FindByTransactionIDs(transactionIDs []uint) ([]model.Installment, error) {
+	return m.installments, nil
+}
+
+type mockReconPaymentRepo struct {
+	repository.PaymentRepository
+}
+
+func (m *mockReconPaymentRepo) FindByReference(reference string) (*model.Payment, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+type mockReconPaymentUsecase struct {
+	usecase.PaymentUsecase
+	repo   *mockReconRepo
+	refuse string
+}
+
+func (m *mockReconPaymentUsecase) Pay(req usecase.PaymentRequest) (*model.Payment, bool, error) {
+	m.repo.events = append(m.repo.events, "pay "+req.Reference)
+	if req.Reference == m.refuse {
+		return nil, false, errors.New("transaction is not active")
+	}
+	return &model.Payment{ID: 50, TransactionID: req.TransactionID, Amount: req.Amount}, true, nil
+}
+
+func TestImport_StoresLinesBeforePosting(t *testing.T) {
+	number, err := vanumber.New("8808", vanumber.KindContract, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	today := time.Now().Format("2006-01-02")
+	statement := "date,amount,reference,description\n" +
+		today + ",1000,TRX1,TRANSFER " + number + "\n" +
+		today + ",1000,TRX2,TRANSFER " + number + "\n" +
+		today + ",-50,FEE,ADMIN\n"
+
+	reconRepo := &mockReconRepo{}
+	paymentUC := &mockReconPaymentUsecase{repo: reconRepo, refuse: "VA-014-TRX2"}
+	uc := usecase.NewReconciliationUsecase(
+		reconRepo,
+		&mockReconVARepo{account: model.VirtualAccount{Number: number, BankCode: "014", CustomerID: 3, TransactionID: ptrUint(7), Active: true}},
+		&mockReconTxRepo{},
+		&mockReconInstallmentRepo{installments: []model.Installment{{TransactionID: 7, Sequence: 1, DueDate: time.Now(), Amount: 1000}}},
+		&mockReconPaymentRepo{},
+		paymentUC,
+		usecase.ReconciliationPolicy{VAPrefix: "8808", MatchWindowDays: 7},
+		newTestDB(t),
+	)
+
+	if _, err := uc.Import("statement.csv", "", strings.NewReader(statement), 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []string{
+		"create import",
+		"pay VA-014-TRX1", "save line matched",
+		"pay VA-014-TRX2", "save line unmatched",
+	}
+	if strings.Join(reconRepo.events, "; ") != strings.Join(want, "; ") {
+		t.Errorf("events = %q, want %q", reconRepo.events, want)
+	}
+	if reconRepo.lines[0].PaymentID == nil || *reconRepo.lines[0].PaymentID != 50 {
+		t.Errorf("line 1 payment = %v, want 50", reconRepo.lines[0].PaymentID)
+	}
+	if reconRepo.lines[1].Note != "transaction is not active" {
+		t.Errorf("line 2 note = %q, want the refusal", reconRepo.lines[1].Note)
+	}
+	if reconRepo.lines[2].Status != model.StatementLineIgnored {
+		t.Errorf("debit line status = %s, want ignored", reconRepo.lines[2].Status)
+	}
+}
+
+func ptrUint(v uint) *uint { return &v }
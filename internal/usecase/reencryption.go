@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/storage"
+)
+
+// ObjectRewrapper is a blob store that can re-encrypt a stored object under
+// the active master key and report whether it had to be rewritten.
+type ObjectRewrapper interface {
+	storage.BlobStore
+	Rewrap(ctx context.Context, key string) (bool, error)
+}
+
+type ReencryptionUsecase interface {
+	// Reencrypt seals every customer's encrypted columns and KYC images with
+	// the active master key, so that retired keys can be removed from the
+	// ring afterwards. KYC images still stored under a key containing the
+	// customer's NIK are moved to a random directory first. It is safe to
+	// interrupt and run again.
+	Reencrypt(ctx context.Context) (*ReencryptionResult, error)
+}
+
+type ReencryptionResult struct {
+	Customers        int `json:"customers"`
+	Objects          int `json:"objects"`
+	ObjectsRewrapped int `json:"objects_rewrapped"`
+	ObjectsMissing   int `json:"objects_missing"`
+	ObjectsMoved     int `json:"objects_moved"`
+}
+
+const reencryptionBatchSize = 200
+
+type reencryptionUsecase struct {
+	customerRepo repository.CustomerRepository
+	objects      ObjectRewrapper
+}
+
+func NewReencryptionUsecase(customerRepo repository.CustomerRepository, objects ObjectRewrapper) ReencryptionUsecase {
+	return &reencryptionUsecase{
+		customerRepo: customerRepo,
+		objects:      objects,
+	}
+}
+
+func (uc *reencryptionUsecase) Reencrypt(ctx context.Context) (*ReencryptionResult, error) {
+	result := &ReencryptionResult{}
+	var afterID uint
+	for {
+		customers, err := uc.customerRepo.FindBatch(afterID, reencryptionBatchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(customers) == 0 {
+			return result, nil
+		}
+
+		for i := range customers {
+			if err := ctx.Err(); err != nil {
+				return result, err
+			}
+			customer := &customers[i]
+			if err := uc.customerRepo.SaveEncrypted(customer); err != nil {
+				return result, fmt.Errorf("customer %d: %w", customer.ID, err)
+			}
+			result.Customers++
+
+			if err := uc.moveNIKObjects(ctx, customer, result); err != nil {
+				return result, fmt.Errorf("customer %d: %w", customer.ID, err)
+			}
+			for _, key := range []string{customer.KTPPhoto, customer.SelfiePhoto} {
+				if key == "" {
+					continue
+				}
+				result.Objects++
+				changed, err := uc.objects.Rewrap(ctx, key)
+				if errors.Is(err, storage.ErrNotFound) {
+					result.ObjectsMissing++
+					continue
+				}
+				if err != nil {
+					return result, fmt.Errorf("customer %d object %s: %w", customer.ID, key, err)
+				}
+				if changed {
+					result.ObjectsRewrapped++
+				}
+			}
+		}
+		afterID = customers[len(customers)-1].ID
+	}
+}
+
+// moveNIKObjects copies KYC images whose key still contains the customer's
+// NIK to a new random directory, points the customer at the copies and only
+// then deletes the originals. An original left behind by an interrupted run
+// is no longer referenced and is removed by the gc command.
+func (uc *reencryptionUsecase) moveNIKObjects(ctx context.Context, customer *model.Customer, result *ReencryptionResult) error {
+	if customer.NIK == "" {
+		return nil
+	}
+	nikDir := "kyc/" + customer.NIK + "/"
+	dir := storage.NewKYCDir()
+	keys := []*string{&customer.KTPPhoto, &customer.SelfiePhoto}
+	var moved []string
+	for _, key := range keys {
+		if !strings.HasPrefix(*key, nikDir) {
+			continue
+		}
+		newKey := dir + "/" + path.Base(*key)
+		err := storage.CopyObject(ctx, uc.objects, *key, newKey)
+		if errors.Is(err, storage.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("move object %s: %w", *key, err)
+		}
+		moved = append(moved, *key)
+		*key = newKey
+	}
+	if len(moved) == 0 {
+		return nil
+	}
+
+	if err := uc.customerRepo.UpdatePhotos(customer.ID, customer.KTPPhoto, customer.SelfiePhoto); err != nil {
+		return err
+	}
+	for _, key := range moved {
+		_ = uc.objects.Delete(ctx, key)
+	}
+	result.ObjectsMoved += len(moved)
+	return nil
+}
@@ -0,0 +1,146 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/storage"
+)
+
+type mockRewrapper struct {
+	storage.BlobStore
+	keys    []string
+	missing map[string]bool
+	data    map[string]string
+	deleted []string
+}
+
+func (m *mockRewrapper) Get(ctx context.Context, key string) (io.ReadCloser, *storage.ObjectInfo, error) {
+	data, ok := m.data[key]
+	if !ok {
+		return nil, nil, storage.ErrNotFound
+	}
+	return io.NopCloser(strings.NewReader(data)), &storage.ObjectInfo{Size: int64(len(data)), ContentType: "image/jpeg"}, nil
+}
+
+func (m *mockRewrapper) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r); err != nil {
+		return err
+	}
+	m.data[key] = buf.String()
+	return nil
+}
+
+func (m *mockRewrapper) Delete(ctx context.Context, key string) error {
+	m.deleted = append(m.deleted, key)
+	delete(m.data, key)
+	return nil
+}
+
+func (m *mockRewrapper) Rewrap(ctx context.Context, key string) (bool, error) {
+	if m.missing[key] {
+		return false, storage.ErrNotFound
+	}
+	m.keys = append(m.keys, key)
+	return key != "kyc/2/ktp.jpg", nil
+}
+
+func TestReencrypt_WalksAllCustomersInBatches(t *testing.T) {
+	customers := make([]model.Customer, 250)
+	for i := range customers {
+		customers[i] = model.Customer{ID: uint(i + 1)}
+	}
+	customers[0].KTPPhoto = "kyc/1/ktp.jpg"
+	customers[0].SelfiePhoto = "kyc/1/selfie.jpg"
+	customers[1].KTPPhoto = "kyc/2/ktp.jpg"
+	customers[2].KTPPhoto = "kyc/3/ktp.jpg"
+
+	var saved []uint
+	repo := &mockCustomerRepo{
+		FindBatchFunc: func(afterID uint, limit int) ([]model.Customer, error) {
+			start := int(afterID)
+			end := min(start+limit, len(customers))
+			if start >= end {
+				return nil, nil
+			}
+			return customers[start:end], nil
+		},
+		SaveEncryptedFunc: func(customer *model.Customer) error {
+			saved = append(saved, customer.ID)
+			return nil
+		},
+	}
+	objects := &mockRewrapper{missing: map[string]bool{"kyc/3/ktp.jpg": true}}
+
+	result, err := usecase.NewReencryptionUsecase(repo, objects).Reencrypt(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(saved) != 250 || saved[249] != 250 {
+		t.Errorf("saved %d customers, want all 250", len(saved))
+	}
+	want := usecase.ReencryptionResult{Customers: 250, Objects: 4, ObjectsRewrapped: 2, ObjectsMissing: 1}
+	if *result != want {
+		t.Errorf("result = %+v, want %+v", *result, want)
+	}
+}
+
+func TestReencrypt_MovesObjectsOutOfNIKDirectories(t *testing.T) {
+	customers := []model.Customer{
+		{ID: 1, NIK: "3201010101010001", KTPPhoto: "kyc/3201010101010001/ktp_1.jpg", SelfiePhoto: "kyc/3201010101010001/selfie_1.jpg"},
+		{ID: 2, NIK: "3201010101010002", KTPPhoto: "kyc/0a1b2c/ktp_1.jpg"},
+		{ID: 3, NIK: "3201010101010003", KTPPhoto: "kyc/3201010101010003/ktp_1.jpg"},
+	}
+	updated := map[uint][2]string{}
+	repo := &mockCustomerRepo{
+		FindBatchFunc: func(afterID uint, limit int) ([]model.Customer, error) {
+			if afterID > 0 {
+				return nil, nil
+			}
+			return customers, nil
+		},
+		UpdatePhotosFunc: func(id uint, ktpPhoto, selfiePhoto string) error {
+			updated[id] = [2]string{ktpPhoto, selfiePhoto}
+			return nil
+		},
+	}
+	objects := &mockRewrapper{
+		missing: map[string]bool{"kyc/3201010101010003/ktp_1.jpg": true},
+		data: map[string]string{
+			"kyc/3201010101010001/ktp_1.jpg":    "ktp",
+			"kyc/3201010101010001/selfie_1.jpg": "selfie",
+			"kyc/0a1b2c/ktp_1.jpg":              "other",
+		},
+	}
+
+	result, err := usecase.NewReencryptionUsecase(repo, objects).Reencrypt(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ObjectsMoved != 2 || result.ObjectsMissing != 1 {
+		t.Errorf("result = %+v, want 2 moved and 1 missing", *result)
+	}
+	if len(updated) != 1 {
+		t.Fatalf("updated photos of %d customers, want only customer 1: %v", len(updated), updated)
+	}
+	keys := updated[1]
+	for i, want := range []string{"ktp", "selfie"} {
+		if strings.Contains(keys[i], customers[0].NIK) || objects.data[keys[i]] != want {
+			t.Errorf("photo %d moved to %q holding %q, want an opaque key holding %q", i, keys[i], objects.data[keys[i]], want)
+		}
+	}
+	if len(objects.deleted) != 2 {
+		t.Errorf("deleted %v, want both originals", objects.deleted)
+	}
+	for _, key := range objects.keys {
+		if strings.Contains(key, "3201010101010001") {
+			t.Errorf("rewrapped the old key %s", key)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"time"
+
+	"xyz-multifinance/internal/model"
+)
+
+// buildSchedule splits a contract into monthly installments starting one month
+// after start. Principal and interest are each split evenly across the tenor;
+// the rupiah that do not divide evenly go to the earliest installments, so the
+// totals always match.
+func buildSchedule(tx *model.Transaction, start time.Time) ([]model.Installment, error) {
+	if tx.Tenor <= 0 {
+		return nil, nil
+	}
+
+	principals, err := txMoney(tx, tx.Principal).Split(tx.Tenor)
+	if err != nil {
+		return nil, err
+	}
+	interests, err := txMoney(tx, tx.InterestAmount).Split(tx.Tenor)
+	if err != nil {
+		return nil, err
+	}
+	first := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+
+	installments := make([]model.Installment, 0, tx.Tenor)
+	for i := 0; i < tx.Tenor; i++ {
+		amount, err := principals[i].Add(interests[i])
+		if err != nil {
+			return nil, err
+		}
+
+		installments = append(installments, model.Installment{
+			TransactionID: tx.ID,
+			Sequence:      i + 1,
+			DueDate:       first.AddDate(0, i+1, 0),
+			Principal:     principals[i].Amount(),
+			Interest:      interests[i].Amount(),
+			Amount:        amount.Amount(),
+			Status:        model.InstallmentStatusUnpaid,
+		})
+	}
+
+	return installments, nil
+}
@@ -0,0 +1,364 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/pkg/nik"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// seedProvider is the payout provider recorded on disbursements completed by
+// the seed command.
+const seedProvider = "seed"
+
+// SeedFixtures is the development or demo data loaded by the seed command.
+type SeedFixtures struct {
+	Users     []SeedUser     `yaml:"users"`
+	Customers []SeedCustomer `yaml:"customers"`
+}
+
+// SeedUser is created with Password, or with a random password reported in
+// SeedResult.Passwords when it is empty, so shared environments need not keep
+// passwords in the fixture file.
+type SeedUser struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Role     string `yaml:"role"`
+}
+
+// SeedCustomer belongs to the user with username User. Its NIK is generated
+// from Region, DateOfBirth and Female; the serial defaults to one more than
+// the number of earlier customers in the file sharing those, so the same file
+// always yields the same NIKs.
+type SeedCustomer struct {
+	User         string            `yaml:"user"`
+	FullName     string            `yaml:"full_name"`
+	LegalName    string            `yaml:"legal_name"`
+	PlaceOfBirth string            `yaml:"place_of_birth"`
+	DateOfBirth  string            `yaml:"date_of_birth"`
+	Female       bool              `yaml:"female"`
+	Region       string            `yaml:"region"`
+	Serial       int               `yaml:"serial"`
+	Salary       int64             `yaml:"salary"`
+	Limits       []SeedLimit       `yaml:"limits"`
+	Transactions []SeedTransaction `yaml:"transactions"`
+}
+
+type SeedLimit struct {
+	Tenor    int    `yaml:"tenor"`
+	Amount   int64  `yaml:"amount"`
+	Currency string `yaml:"currency"`
+}
+
+// SeedTransaction is priced and checked against the customer's limit like
+// any new contract. When DisbursedDaysAgo is set the contract is paid out
+// that many days before the seed runs, which builds its installment schedule;
+// otherwise it stays approved for the disbursement job to pay out.
+type SeedTransaction struct {
+	ContractNumber   string `yaml:"contract_number"`
+	Tenor            int    `yaml:"tenor"`
+	OTR              int64  `yaml:"otr"`
+	DownPayment      int64  `yaml:"down_payment"`
+	AssetName        string `yaml:"asset_name"`
+	DisbursedDaysAgo *int   `yaml:"disbursed_days_ago"`
+}
+
+type SeedCount struct {
+	Created  int `json:"created"`
+	Existing int `json:"existing"`
+}
+
+type SeedResult struct {
+	Users        SeedCount `json:"users"`
+	Customers    SeedCount `json:"customers"`
+	Limits       SeedCount `json:"limits"`
+	Transactions SeedCount `json:"transactions"`
+	Disbursed    int       `json:"disbursed"`
+	// Passwords holds the generated password of every user created in this
+	// run, by username. They are not stored anywhere else.
+	Passwords map[string]string `json:"-"`
+}
+
+type SeedUsecase interface {
+	// Seed creates whatever in fixtures does not exist yet. Users, customers,
+	// limits and transactions are matched by username, NIK, tenor and
+	// contract number and are left untouched when found, so it is safe to
+	// run again, also after a failed run.
+	Seed(ctx context.Context, fixtures *SeedFixtures, now time.Time) (*SeedResult, error)
+}
+
+type seedUsecase struct {
+	userRepo       repository.UserRepository
+	customerRepo   repository.CustomerRepository
+	limitRepo      repository.LimitRepository
+	txRepo         repository.TransactionRepository
+	customerUC     CustomerUsecase
+	limitUC        LimitUsecase
+	transactionUC  TransactionUsecase
+	disbursementUC DisbursementUsecase
+}
+
+func NewSeedUsecase(
+	userRepo repository.UserRepository,
+	customerRepo repository.CustomerRepository,
+	limitRepo repository.LimitRepository,
+	txRepo repository.TransactionRepository,
+	customerUC CustomerUsecase,
+	limitUC LimitUsecase,
+	transactionUC TransactionUsecase,
+	disbursementUC DisbursementUsecase,
+) SeedUsecase {
+	return &seedUsecase{
+		userRepo:       userRepo,
+		customerRepo:   customerRepo,
+		limitRepo:      limitRepo,
+		txRepo:         txRepo,
+		customerUC:     customerUC,
+		limitUC:        limitUC,
+		transactionUC:  transactionUC,
+		disbursementUC: disbursementUC,
+	}
+}
+
+func (uc *seedUsecase) Seed(ctx context.Context, fixtures *SeedFixtures, now time.Time) (*SeedResult, error) {
+	niks, err := fixtures.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SeedResult{}
+	for _, u := range fixtures.Users {
+		if err := uc.seedUser(u, now, result); err != nil {
+			return result, fmt.Errorf("user %s: %w", u.Username, err)
+		}
+	}
+	for i, c := range fixtures.Customers {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		if err := uc.seedCustomer(ctx, c, niks[i], now, result); err != nil {
+			return result, fmt.Errorf("customers[%d]: %w", i, err)
+		}
+	}
+	return result, nil
+}
+
+func (uc *seedUsecase) seedUser(u SeedUser, now time.Time, result *SeedResult) error {
+	_, err := uc.userRepo.FindByUsername(u.Username)
+	if err == nil {
+		result.Users.Existing++
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	password := u.Password
+	if password == "" {
+		if password, err = generatePassword(); err != nil {
+			return err
+		}
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	user := &model.User{
+		Username:  u.Username,
+		Password:  string(hashedPassword),
+		Role:      u.Role,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := uc.userRepo.Create(user); err != nil {
+		return err
+	}
+	result.Users.Created++
+	if u.Password == "" {
+		if result.Passwords == nil {
+			result.Passwords = make(map[string]string)
+		}
+		result.Passwords[u.Username] = password
+	}
+	return nil
+}
+
+func generatePassword() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func (uc *seedUsecase) seedCustomer(ctx context.Context, c SeedCustomer, nik string, now time.Time, result *SeedResult) error {
+	customer, err := uc.customerRepo.FindByNIK(nik)
+	switch {
+	case err == nil:
+		result.Customers.Existing++
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		user, err := uc.userRepo.FindByUsername(c.User)
+		if err != nil {
+			return fmt.Errorf("user %s: %w", c.User, err)
+		}
+		dateOfBirth, _ := time.Parse("2006-01-02", c.DateOfBirth)
+		customer = &model.Customer{
+			UserID:     user.ID,
+			NIK:        nik,
+			FullName:   c.FullName,
+			LegalName:  c.LegalName,
+			PlaceBirth: c.PlaceOfBirth,
+			DateBirth:  dateOfBirth,
+			Salary:     c.Salary,
+		}
+		if err := uc.customerUC.CreateCustomer(ctx, customer); err != nil {
+			return err
+		}
+		result.Customers.Created++
+	default:
+		return err
+	}
+
+	for _, l := range c.Limits {
+		if err := uc.seedLimit(ctx, customer.ID, l, result); err != nil {
+			return fmt.Errorf("limit for tenor %d: %w", l.Tenor, err)
+		}
+	}
+	for _, t := range c.Transactions {
+		if err := uc.seedTransaction(ctx, customer.ID, t, now, result); err != nil {
+			return fmt.Errorf("transaction %s: %w", t.ContractNumber, err)
+		}
+	}
+	return nil
+}
+
+func (uc *seedUsecase) seedLimit(ctx context.Context, customerID uint, l SeedLimit, result *SeedResult) error {
+	_, err := uc.limitRepo.FindUsageByCustomerAndTenor(customerID, l.Tenor)
+	if err == nil {
+		result.Limits.Existing++
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	limit := &model.Limit{
+		CustomerID: customerID,
+		Tenor:      l.Tenor,
+		Limit:      l.Amount,
+		Currency:   l.Currency,
+	}
+	if err := uc.limitUC.CreateLimit(ctx, limit); err != nil {
+		return err
+	}
+	result.Limits.Created++
+	return nil
+}
+
+func (uc *seedUsecase) seedTransaction(ctx context.Context, customerID uint, t SeedTransaction, now time.Time, result *SeedResult) error {
+	tx, err := uc.txRepo.FindByContractNumber(t.ContractNumber)
+	switch {
+	case err == nil:
+		if tx.CustomerID != customerID {
+			return errors.New("contract number belongs to another customer")
+		}
+		result.Transactions.Existing++
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		tx = &model.Transaction{
+			ContractNumber: t.ContractNumber,
+			CustomerID:     customerID,
+			Tenor:          t.Tenor,
+			OTR:            t.OTR,
+			DownPayment:    t.DownPayment,
+			AssetName:      t.AssetName,
+		}
+		if err := uc.transactionUC.CreateTransaction(ctx, tx); err != nil {
+			return err
+		}
+		result.Transactions.Created++
+	default:
+		return err
+	}
+
+	// A contract created by an earlier, interrupted run is still approved and
+	// is paid out now.
+	if t.DisbursedDaysAgo == nil || tx.Status != model.TransactionStatusApproved {
+		return nil
+	}
+	disbursedAt := now.AddDate(0, 0, -*t.DisbursedDaysAgo)
+	if _, err := uc.disbursementUC.CompleteManually(ctx, tx.ID, seedProvider, tx.ContractNumber, disbursedAt); err != nil {
+		return err
+	}
+	result.Disbursed++
+	return nil
+}
+
+// validate checks the whole file before anything is written and returns the
+// NIK of every customer.
+func (f *SeedFixtures) validate() ([]string, error) {
+	usernames := make(map[string]bool)
+	for i, u := range f.Users {
+		if u.Username == "" {
+			return nil, fmt.Errorf("users[%d]: username is required", i)
+		}
+		if u.Role != "admin" && u.Role != "user" {
+			return nil, fmt.Errorf("users[%d]: role must be admin or user", i)
+		}
+		if usernames[u.Username] {
+			return nil, fmt.Errorf("users[%d]: duplicate username %s", i, u.Username)
+		}
+		usernames[u.Username] = true
+	}
+
+	niks := make([]string, len(f.Customers))
+	seen := make(map[string]bool)
+	serials := make(map[string]int)
+	contracts := make(map[string]bool)
+	for i, c := range f.Customers {
+		if c.User == "" || c.FullName == "" {
+			return nil, fmt.Errorf("customers[%d]: user and full_name are required", i)
+		}
+		dateOfBirth, err := time.Parse("2006-01-02", c.DateOfBirth)
+		if err != nil {
+			return nil, fmt.Errorf("customers[%d]: date_of_birth must be YYYY-MM-DD", i)
+		}
+
+		key := fmt.Sprintf("%s/%s/%t", c.Region, c.DateOfBirth, c.Female)
+		serials[key]++
+		serial := c.Serial
+		if serial == 0 {
+			serial = serials[key]
+		}
+		niks[i], err = nik.New(c.Region, dateOfBirth, c.Female, serial)
+		if err != nil {
+			return nil, fmt.Errorf("customers[%d]: %w", i, err)
+		}
+		if seen[niks[i]] {
+			return nil, fmt.Errorf("customers[%d]: generates the same NIK as an earlier customer; set serial", i)
+		}
+		seen[niks[i]] = true
+
+		for _, t := range c.Transactions {
+			if t.ContractNumber == "" {
+				return nil, fmt.Errorf("customers[%d]: contract_number is required", i)
+			}
+			if contracts[t.ContractNumber] {
+				return nil, fmt.Errorf("customers[%d]: duplicate contract_number %s", i, t.ContractNumber)
+			}
+			contracts[t.ContractNumber] = true
+			if t.DisbursedDaysAgo != nil && *t.DisbursedDaysAgo < 0 {
+				return nil, fmt.Errorf("customers[%d]: disbursed_days_ago of %s must not be negative", i, t.ContractNumber)
+			}
+		}
+	}
+	return niks, nil
+}
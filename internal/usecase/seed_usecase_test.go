@@ -0,0 +1,146 @@
+package usecase_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/internal/usecase"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type mockSeedLimitRepo struct {
+	repository.LimitRepository
+}
+
+func (m *mockSeedLimitRepo) FindUsageByCustomerAndTenor(customerID uint, tenor int) (*repository.LimitUsage, error) {
+	return &repository.LimitUsage{Limit: model.Limit{CustomerID: customerID, Tenor: tenor}}, nil
+}
+
+type mockSeedTxRepo struct {
+	repository.TransactionRepository
+}
+
+func (m *mockSeedTxRepo) FindByContractNumber(contractNumber string) (*model.Transaction, error) {
+	return &model.Transaction{ContractNumber: contractNumber, CustomerID: 7, Status: model.TransactionStatusOngoing}, nil
+}
+
+func seedFixtures() *usecase.SeedFixtures {
+	daysAgo := 20
+	customer := usecase.SeedCustomer{
+		User:        "dian",
+		FullName:    "Dian Erwansyah",
+		DateOfBirth: "1996-05-03",
+		Region:      "647101",
+		Limits:      []usecase.SeedLimit{{Tenor: 3, Amount: 15000000}},
+	}
+	twin := customer
+	twin.FullName = "Dion Erwansyah"
+	twin.Limits = nil
+	twin.Transactions = []usecase.SeedTransaction{{ContractNumber: "CNTR1", Tenor: 3, OTR: 1000000, DisbursedDaysAgo: &daysAgo}}
+
+	return &usecase.SeedFixtures{
+		Users:     []usecase.SeedUser{{Username: "dian", Password: "password123", Role: "user"}},
+		Customers: []usecase.SeedCustomer{customer, twin},
+	}
+}
+
+func TestSeed_LeavesExistingRecordsAlone(t *testing.T) {
+	var niks []string
+	userRepo := &mockUserRepo{
+		FindByUsernameFunc: func(username string) (*model.User, error) {
+			return &model.User{ID: 1, Username: username}, nil
+		},
+		CreateFunc: func(user *model.User) error {
+			t.Errorf("user %s should not be created again", user.Username)
+			return nil
+		},
+	}
+	customerRepo := &mockCustomerRepo{
+		FindByNIKFunc: func(nik string) (*model.Customer, error) {
+			niks = append(niks, nik)
+			return &model.Customer{ID: 7, NIK: nik}, nil
+		},
+	}
+
+	uc := usecase.NewSeedUsecase(userRepo, customerRepo, &mockSeedLimitRepo{}, &mockSeedTxRepo{}, nil, nil, nil, nil)
+	result, err := uc.Seed(context.Background(), seedFixtures(), time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := usecase.SeedResult{
+		Users:        usecase.SeedCount{Existing: 1},
+		Customers:    usecase.SeedCount{Existing: 2},
+		Limits:       usecase.SeedCount{Existing: 1},
+		Transactions: usecase.SeedCount{Existing: 1},
+	}
+	if !reflect.DeepEqual(*result, want) {
+		t.Errorf("result = %+v, want %+v", *result, want)
+	}
+	// Customers sharing region, birth date and gender get consecutive serials.
+	if len(niks) != 2 || niks[0] != "6471010305960001" || niks[1] != "6471010305960002" {
+		t.Errorf("niks = %v, want 6471010305960001 and 6471010305960002", niks)
+	}
+}
+
+func TestSeed_RejectsInvalidFixturesBeforeWriting(t *testing.T) {
+	duplicateNIK := seedFixtures()
+	duplicateNIK.Customers[1].Serial = 1
+
+	badRole := seedFixtures()
+	badRole.Users[0].Role = "root"
+
+	badDate := seedFixtures()
+	badDate.Customers[0].DateOfBirth = "03-05-1996"
+
+	for name, fixtures := range map[string]*usecase.SeedFixtures{
+		"same NIK":      duplicateNIK,
+		"role must be":  badRole,
+		"date_of_birth": badDate,
+	} {
+		// The repositories are nil: validation has to fail before any of
+		// them is used.
+		uc := usecase.NewSeedUsecase(nil, nil, nil, nil, nil, nil, nil, nil)
+		_, err := uc.Seed(context.Background(), fixtures, time.Now())
+		if err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("err = %v, want one mentioning %q", err, name)
+		}
+	}
+}
+
+func TestSeed_GeneratesMissingPasswords(t *testing.T) {
+	var created []model.User
+	userRepo := &mockUserRepo{
+		FindByUsernameFunc: func(username string) (*model.User, error) {
+			return nil, gorm.ErrRecordNotFound
+		},
+		CreateFunc: func(user *model.User) error {
+			created = append(created, *user)
+			return nil
+		},
+	}
+	fixtures := &usecase.SeedFixtures{Users: []usecase.SeedUser{
+		{Username: "admin", Role: "admin"},
+		{Username: "dian", Password: "password123", Role: "user"},
+	}}
+
+	uc := usecase.NewSeedUsecase(userRepo, nil, nil, nil, nil, nil, nil, nil)
+	result, err := uc.Seed(context.Background(), fixtures, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Passwords) != 1 || result.Passwords["admin"] == "" {
+		t.Fatalf("passwords = %v, want one generated for admin only", result.Passwords)
+	}
+	if len(created) != 2 || bcrypt.CompareHashAndPassword([]byte(created[0].Password), []byte(result.Passwords["admin"])) != nil {
+		t.Errorf("admin was not stored with the generated password")
+	}
+}
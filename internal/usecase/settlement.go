@@ -0,0 +1,273 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+// SettlementPolicy prices early settlement. Interest on installments that are
+// not due yet is waived by InterestRebatePercent, and a penalty of
+// PenaltyPercent of the outstanding principal is charged on top.
+type SettlementPolicy struct {
+	InterestRebatePercent int
+	PenaltyPercent        int
+	QuoteValidity         time.Duration
+}
+
+type SettlementRequest struct {
+	TransactionID uint
+	QuoteID       uint
+	Channel       string
+	Reference     string
+}
+
+type SettlementUsecase interface {
+	// Quote prices the early settlement of a contract. While an open quote
+	// is still valid and the contract has not changed, that quote is
+	// returned instead of issuing a new one. It returns ErrCustomerForbidden
+	// unless the viewer is an admin or the contract's customer.
+	Quote(transactionID uint, viewer Viewer) (*model.SettlementQuote, error)
+	// Settle executes a quote: it posts the settlement payment, closes every
+	// open installment and marks the contract settled, which releases its
+	// principal from the customer's limit.
+	Settle(req SettlementRequest) (*model.SettlementQuote, *model.Payment, error)
+}
+
+type settlementUsecase struct {
+	quoteRepo       repository.SettlementQuoteRepository
+	txRepo          repository.TransactionRepository
+	installmentRepo repository.InstallmentRepository
+	paymentRepo     repository.PaymentRepository
+	ledgerRepo      repository.LedgerRepository
+	customerRepo    repository.CustomerRepository
+	policy          SettlementPolicy
+	db              *gorm.DB
+}
+
+func NewSettlementUsecase(
+	quoteRepo repository.SettlementQuoteRepository,
+	txRepo repository.TransactionRepository,
+	installmentRepo repository.InstallmentRepository,
+	paymentRepo repository.PaymentRepository,
+	ledgerRepo repository.LedgerRepository,
+	customerRepo repository.CustomerRepository,
+	policy SettlementPolicy,
+	db *gorm.DB,
+) SettlementUsecase {
+	return &settlementUsecase{
+		quoteRepo:       quoteRepo,
+		txRepo:          txRepo,
+		installmentRepo: installmentRepo,
+		paymentRepo:     paymentRepo,
+		ledgerRepo:      ledgerRepo,
+		customerRepo:    customerRepo,
+		policy:          policy,
+		db:              db,
+	}
+}
+
+// settlementBreakdown is a quote computed from the open installments of a
+// contract, with the rebate granted on each installment.
+type settlementBreakdown struct {
+	principal int64
+	interest  int64
+	rebate    int64
+	lateFee   int64
+	penalty   int64
+	rebates   []int64
+}
+
+func (b settlementBreakdown) total() int64 {
+	return b.principal + b.interest - b.rebate + b.lateFee + b.penalty
+}
+
+func (b settlementBreakdown) matches(quote *model.SettlementQuote) bool {
+	return b.principal == quote.OutstandingPrincipal &&
+		b.interest == quote.OutstandingInterest &&
+		b.rebate == quote.InterestRebate &&
+		b.lateFee == quote.OutstandingLateFee &&
+		b.penalty == quote.PenaltyFee
+}
+
+// breakdown prices the settlement of the given open installments as of a
+// date. Installment payments are applied to interest first, so whatever was
+// paid beyond the interest has reduced the principal.
+func (uc *settlementUsecase) breakdown(installments []model.Installment, asOf time.Time) settlementBreakdown {
+	b := settlementBreakdown{rebates: make([]int64, len(installments))}
+	for i, inst := range installments {
+		interest := inst.Interest - min(inst.PaidAmount, inst.Interest)
+		b.interest += interest
+		b.principal += inst.Amount - inst.PaidAmount - interest
+		b.lateFee += inst.LateFee - inst.LateFeePaid
+
+		if inst.DueDate.After(asOf) {
+			b.rebates[i] = interest * int64(uc.policy.InterestRebatePercent) / 100
+			b.rebate += b.rebates[i]
+		}
+	}
+	b.penalty = b.principal * int64(uc.policy.PenaltyPercent) / 100
+	return b
+}
+
+func (uc *settlementUsecase) Quote(transactionID uint, viewer Viewer) (*model.SettlementQuote, error) {
+	tx, err := uc.txRepo.FindByID(transactionID)
+	if err != nil {
+		return nil, errors.New("transaction not found")
+	}
+	customer, err := uc.customerRepo.FindByID(tx.CustomerID)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+	if err := authorizeCustomer(customer, viewer); err != nil {
+		return nil, err
+	}
+	if !isActiveStatus(tx.Status) {
+		return nil, fmt.Errorf("cannot settle a %s transaction", tx.Status)
+	}
+
+	installments, err := uc.installmentRepo.FindByTransactionID(tx.ID)
+	if err != nil {
+		return nil, err
+	}
+	open := installments[:0]
+	for _, inst := range installments {
+		if inst.Status != model.InstallmentStatusPaid {
+			open = append(open, inst)
+		}
+	}
+	if len(open) == 0 {
+		return nil, errors.New("transaction has nothing outstanding")
+	}
+
+	now := time.Now()
+	asOf := startOfDay(now)
+	b := uc.breakdown(open, asOf)
+	if existing, err := uc.quoteRepo.FindOpen(tx.ID, now); err == nil && b.matches(existing) {
+		return existing, nil
+	}
+
+	quote := &model.SettlementQuote{
+		TransactionID:        tx.ID,
+		AsOf:                 asOf,
+		OutstandingPrincipal: b.principal,
+		OutstandingInterest:  b.interest,
+		InterestRebate:       b.rebate,
+		OutstandingLateFee:   b.lateFee,
+		PenaltyFee:           b.penalty,
+		TotalAmount:          b.total(),
+		ValidUntil:           now.Add(uc.policy.QuoteValidity),
+		Status:               model.SettlementQuoteStatusOpen,
+	}
+	if err := uc.quoteRepo.Create(quote); err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+func (uc *settlementUsecase) Settle(req SettlementRequest) (*model.SettlementQuote, *model.Payment, error) {
+	if req.Channel == "" {
+		req.Channel = PaymentChannelManual
+	}
+	if req.Reference == "" {
+		req.Reference = fmt.Sprintf("settlement-%d", req.QuoteID)
+	}
+
+	var quote *model.SettlementQuote
+	var payment *model.Payment
+	err := uc.db.Transaction(func(txDB *gorm.DB) error {
+		tx, err := uc.txRepo.FindByIDForUpdate(txDB, req.TransactionID)
+		if err != nil {
+			return errors.New("transaction not found")
+		}
+
+		quote, err = uc.quoteRepo.FindByIDForUpdate(txDB, req.QuoteID)
+		if err != nil || quote.TransactionID != tx.ID {
+			return errors.New("settlement quote not found")
+		}
+		if quote.Status != model.SettlementQuoteStatusOpen {
+			return errors.New("settlement quote already executed")
+		}
+		if !isActiveStatus(tx.Status) {
+			return fmt.Errorf("cannot settle a %s transaction", tx.Status)
+		}
+
+		now := time.Now()
+		if now.After(quote.ValidUntil) {
+			return errors.New("settlement quote has expired")
+		}
+
+		installments, err := uc.installmentRepo.FindOpenForUpdate(txDB, tx.ID)
+		if err != nil {
+			return err
+		}
+		b := uc.breakdown(installments, quote.AsOf)
+		if !b.matches(quote) {
+			return errors.New("contract changed since the quote was issued, request a new quote")
+		}
+
+		payment = &model.Payment{
+			TransactionID: tx.ID,
+			Amount:        quote.TotalAmount,
+			Channel:       req.Channel,
+			Reference:     req.Reference,
+			PaidAt:        now,
+			Status:        model.PaymentStatusPosted,
+		}
+		for i := range installments {
+			inst := &installments[i]
+			interest := inst.Interest - min(inst.PaidAmount, inst.Interest)
+			payment.Allocations = append(payment.Allocations, model.PaymentAllocation{
+				InstallmentID: inst.ID,
+				LateFee:       inst.LateFee - inst.LateFeePaid,
+				Interest:      interest - b.rebates[i],
+				Principal:     inst.Amount - inst.PaidAmount - interest,
+			})
+
+			inst.PaidAmount = inst.Amount
+			inst.LateFeePaid = inst.LateFee
+			inst.Status = model.InstallmentStatusPaid
+			inst.PaidAt = &now
+			if err := uc.installmentRepo.Save(txDB, inst); err != nil {
+				return err
+			}
+		}
+
+		// The unique index on the reference rejects a reference that is
+		// already taken, also by a payment posted concurrently.
+		err = uc.paymentRepo.Create(txDB, payment)
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return errors.New("payment reference already used")
+		}
+		if err != nil {
+			return err
+		}
+		balances, err := uc.ledgerRepo.ContractBalances(txDB, tx.ID)
+		if err != nil {
+			return err
+		}
+		if err := postEntry(txDB, uc.ledgerRepo, settlementEntry(payment, quote, balances)); err != nil {
+			return err
+		}
+
+		quote.Status = model.SettlementQuoteStatusExecuted
+		quote.PaymentID = &payment.ID
+		if err := uc.quoteRepo.Save(txDB, quote); err != nil {
+			return err
+		}
+
+		fields := agingFields(0)
+		fields["status"] = model.TransactionStatusSettled
+		return uc.txRepo.UpdateFields(txDB, tx.ID, fields)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return quote, payment, nil
+}
@@ -0,0 +1,235 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/internal/usecase"
+
+	"gorm.io/gorm"
+)
+
+type mockSettlementTxRepo struct {
+	repository.TransactionRepository
+	tx *model.Transaction
+}
+
+func (m *mockSettlementTxRepo) FindByID(id uint) (*model.Transaction, error) {
+	return m.tx, nil
+}
+
+type mockInstallmentRepo struct {
+	repository.InstallmentRepository
+	installments []model.Installment
+}
+
+func (m *mockInstallmentRepo) FindByTransactionID(transactionID uint) ([]model.Installment, error) {
+	return m.installments, nil
+}
+
+type mockSettlementQuoteRepo struct {
+	repository.SettlementQuoteRepository
+	created *model.SettlementQuote
+	creates int
+}
+
+func (m *mockSettlementQuoteRepo) Create(quote *model.SettlementQuote) error {
+	quote.ID = uint(m.creates + 1)
+	m.created = quote
+	m.creates++
+	return nil
+}
+
+func (m *mockSettlementQuoteRepo) FindOpen(transactionID uint, validAt time.Time) (*model.SettlementQuote, error) {
+	if m.created == nil || m.created.Status != model.SettlementQuoteStatusOpen || !m.created.ValidUntil.After(validAt) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return m.created, nil
+}
+
+func (m *mockSettlementQuoteRepo) FindByIDForUpdate(db *gorm.DB, id uint) (*model.SettlementQuote, error) {
+	if m.created == nil || m.created.ID != id {
+		return nil, gorm.ErrRecordNotFound
+	}
+	quote := *m.created
+	return &quote, nil
+}
+
+func (m *mockSettlementQuoteRepo) Save(db *gorm.DB, quote *model.SettlementQuote) error {
+	m.created = quote
+	return nil
+}
+
+func TestQuote_RebatesInterestNotYetDue(t *testing.T) {
+	now := time.Now()
+	installments := []model.Installment{
+		// Paid in full, ignored.
+		{ID: 1, DueDate: now.AddDate(0, -2, 0), Principal: 1000, Interest: 100, Amount: 1100, PaidAmount: 1100, Status: model.InstallmentStatusPaid},
+		// Overdue and partly paid: interest is settled first, so 50 of principal remains.
+		{ID: 2, DueDate: now.AddDate(0, -1, 0), Principal: 1000, Interest: 100, Amount: 1100, PaidAmount: 1050, LateFee: 30, Status: model.InstallmentStatusPartial},
+		{ID: 3, DueDate: now.AddDate(0, 1, 0), Principal: 1000, Interest: 100, Amount: 1100, Status: model.InstallmentStatusUnpaid},
+		{ID: 4, DueDate: now.AddDate(0, 2, 0), Principal: 1000, Interest: 100, Amount: 1100, Status: model.InstallmentStatusUnpaid},
+	}
+
+	quoteRepo := &mockSettlementQuoteRepo{}
+	uc := usecase.NewSettlementUsecase(
+		quoteRepo,
+		&mockSettlementTxRepo{tx: &model.Transaction{ID: 7, Status: model.TransactionStatusOngoing}},
+		&mockInstallmentRepo{installments: installments},
+		nil,
+		nil,
+		customersOwnedBy(11),
+		usecase.SettlementPolicy{InterestRebatePercent: 50, PenaltyPercent: 2, QuoteValidity: time.Hour},
+		nil,
+	)
+
+	quote, err := uc.Quote(7, usecase.Viewer{Role: "admin"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if quoteRepo.created != quote {
+		t.Fatal("expected quote to be stored")
+	}
+
+	want := model.SettlementQuote{
+		OutstandingPrincipal: 2050,
+		OutstandingInterest:  200,
+		InterestRebate:       100,
+		OutstandingLateFee:   30,
+		PenaltyFee:           41,
+		TotalAmount:          2050 + 200 - 100 + 30 + 41,
+	}
+	if quote.OutstandingPrincipal != want.OutstandingPrincipal ||
+		quote.OutstandingInterest != want.OutstandingInterest ||
+		quote.InterestRebate != want.InterestRebate ||
+		quote.OutstandingLateFee != want.OutstandingLateFee ||
+		quote.PenaltyFee != want.PenaltyFee ||
+		quote.TotalAmount != want.TotalAmount {
+		t.Errorf("unexpected quote %+v, want %+v", *quote, want)
+	}
+	if quote.Status != model.SettlementQuoteStatusOpen || !quote.ValidUntil.After(now) {
+		t.Errorf("expected an open quote valid in the future, got %s until %s", quote.Status, quote.ValidUntil)
+	}
+}
+
+func TestQuote_OnlyForOwnerOrAdmin(t *testing.T) {
+	uc := usecase.NewSettlementUsecase(
+		&mockSettlementQuoteRepo{},
+		&mockSettlementTxRepo{tx: &model.Transaction{ID: 7, CustomerID: 3, Status: model.TransactionStatusOngoing}},
+		&mockInstallmentRepo{installments: []model.Installment{{ID: 1, DueDate: time.Now(), Principal: 1000, Amount: 1000}}},
+		nil,
+		nil,
+		customersOwnedBy(11),
+		usecase.SettlementPolicy{QuoteValidity: time.Hour},
+		nil,
+	)
+
+	if _, err := uc.Quote(7, usecase.Viewer{UserID: 12, Role: "customer"}); !errors.Is(err, usecase.ErrCustomerForbidden) {
+		t.Errorf("err = %v, want another user's quote forbidden", err)
+	}
+	if _, err := uc.Quote(7, usecase.Viewer{UserID: 11, Role: "customer"}); err != nil {
+		t.Errorf("err = %v, want the customer's own quote", err)
+	}
+}
+
+func TestQuote_RejectsClosedTransaction(t *testing.T) {
+	uc := usecase.NewSettlementUsecase(
+		&mockSettlementQuoteRepo{},
+		&mockSettlementTxRepo{tx: &model.Transaction{ID: 7, Status: model.TransactionStatusClosed}},
+		&mockInstallmentRepo{},
+		nil,
+		nil,
+		customersOwnedBy(11),
+		usecase.SettlementPolicy{},
+		nil,
+	)
+
+	if _, err := uc.Quote(7, usecase.Viewer{Role: "admin"}); err == nil {
+		t.Fatal("expected error for closed transaction")
+	}
+}
+
+func TestQuote_ReturnsOpenQuoteUntilContractChanges(t *testing.T) {
+	installmentRepo := &mockInstallmentRepo{installments: []model.Installment{
+		{ID: 1, DueDate: time.Now().AddDate(0, 1, 0), Principal: 1000, Interest: 100, Amount: 1100, Status: model.InstallmentStatusUnpaid},
+	}}
+	quoteRepo := &mockSettlementQuoteRepo{}
+	uc := usecase.NewSettlementUsecase(
+		quoteRepo,
+		&mockSettlementTxRepo{tx: &model.Transaction{ID: 7, Status: model.TransactionStatusOngoing}},
+		installmentRepo,
+		nil,
+		nil,
+		customersOwnedBy(11),
+		usecase.SettlementPolicy{QuoteValidity: time.Hour},
+		nil,
+	)
+	admin := usecase.Viewer{Role: "admin"}
+
+	first, err := uc.Quote(7, admin)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := uc.Quote(7, admin)
+	if err != nil || second.ID != first.ID || quoteRepo.creates != 1 {
+		t.Errorf("second quote = %+v, err = %v, creates = %d, want the open quote returned", second, err, quoteRepo.creates)
+	}
+
+	installmentRepo.installments[0].PaidAmount = 500
+	installmentRepo.installments[0].Status = model.InstallmentStatusPartial
+	third, err := uc.Quote(7, admin)
+	if err != nil || third.ID == first.ID || third.TotalAmount != 600 {
+		t.Errorf("quote after a payment = %+v, err = %v, want a new quote for 600", third, err)
+	}
+}
+
+type mockDuplicatePaymentRepo struct {
+	repository.PaymentRepository
+}
+
+func (m *mockDuplicatePaymentRepo) Create(db *gorm.DB, payment *model.Payment) error {
+	return gorm.ErrDuplicatedKey
+}
+
+func TestSettle_RejectsUsedReference(t *testing.T) {
+	installments := []model.Installment{
+		{ID: 1, TransactionID: 7, DueDate: time.Now().AddDate(0, 1, 0), Principal: 1000, Interest: 100, Amount: 1100, Status: model.InstallmentStatusUnpaid},
+	}
+	quoteRepo := &mockSettlementQuoteRepo{}
+	uc := usecase.NewSettlementUsecase(
+		quoteRepo,
+		&mockPayTxRepo{tx: model.Transaction{ID: 7, CustomerID: 3, Status: model.TransactionStatusOngoing}},
+		&mockPayInstallmentRepo{installments: installments},
+		&mockDuplicatePaymentRepo{},
+		&mockJournalRepo{},
+		customersOwnedBy(11),
+		usecase.SettlementPolicy{QuoteValidity: time.Hour},
+		newTestDB(t),
+	)
+	// The quote is priced from the same open installments Settle sees.
+	quoteUC := usecase.NewSettlementUsecase(
+		quoteRepo,
+		&mockSettlementTxRepo{tx: &model.Transaction{ID: 7, Status: model.TransactionStatusOngoing}},
+		&mockInstallmentRepo{installments: installments},
+		nil,
+		nil,
+		customersOwnedBy(11),
+		usecase.SettlementPolicy{QuoteValidity: time.Hour},
+		nil,
+	)
+	quote, err := quoteUC.Quote(7, usecase.Viewer{Role: "admin"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, _, err = uc.Settle(usecase.SettlementRequest{TransactionID: 7, QuoteID: quote.ID, Reference: "PAY-1"})
+	if err == nil || err.Error() != "payment reference already used" {
+		t.Errorf("err = %v, want the used reference reported", err)
+	}
+	if quoteRepo.created.Status != model.SettlementQuoteStatusOpen {
+		t.Errorf("quote status = %s, want it still open", quoteRepo.created.Status)
+	}
+}
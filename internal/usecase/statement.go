@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/pkg/redact"
+)
+
+const (
+	StatementFormatPDF = "pdf"
+	StatementFormatCSV = "csv"
+)
+
+// ErrStatementForbidden is returned when the viewer may not see the
+// customer the contract belongs to.
+var ErrStatementForbidden = errors.New("not allowed to view this statement")
+
+// StatementViewer is the user asking for a statement. Only admins and the
+// user the customer record belongs to may see it.
+type StatementViewer struct {
+	UserID uint
+	Role   string
+}
+
+// StatementBranding is printed on the header and footer of PDF statements.
+type StatementBranding struct {
+	CompanyName string
+	Address     string
+	Contact     string
+	// LogoPath is an optional PNG or JPEG shown next to the company name.
+	LogoPath string
+}
+
+// ContractStatement is everything shown on a contract's statement of
+// account. NIK is masked, as statements are downloaded and passed around.
+type ContractStatement struct {
+	GeneratedAt  time.Time           `json:"generated_at"`
+	Transaction  model.Transaction   `json:"transaction"`
+	CustomerName string              `json:"customer_name"`
+	NIK          string              `json:"nik"`
+	Installments []model.Installment `json:"installments"`
+	Payments     []model.Payment     `json:"payments"`
+	Summary      StatementSummary    `json:"summary"`
+}
+
+type StatementSummary struct {
+	Principal            int64      `json:"principal"`
+	TotalInterest        int64      `json:"total_interest"`
+	LateFeesCharged      int64      `json:"late_fees_charged"`
+	TotalPaid            int64      `json:"total_paid"`
+	OutstandingPrincipal int64      `json:"outstanding_principal"`
+	OutstandingInterest  int64      `json:"outstanding_interest"`
+	OutstandingLateFee   int64      `json:"outstanding_late_fee"`
+	TotalOutstanding     int64      `json:"total_outstanding"`
+	OverdueAmount        int64      `json:"overdue_amount"`
+	NextDueDate          *time.Time `json:"next_due_date"`
+}
+
+// RenderedStatement is a statement file ready to be sent.
+type RenderedStatement struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+type StatementUsecase interface {
+	GetStatement(transactionID uint, viewer StatementViewer) (*ContractStatement, error)
+	RenderStatement(transactionID uint, format string, viewer StatementViewer) (*RenderedStatement, error)
+}
+
+type statementUsecase struct {
+	txRepo          repository.TransactionRepository
+	customerRepo    repository.CustomerRepository
+	installmentRepo repository.InstallmentRepository
+	paymentRepo     repository.PaymentRepository
+	branding        StatementBranding
+}
+
+func NewStatementUsecase(
+	txRepo repository.TransactionRepository,
+	customerRepo repository.CustomerRepository,
+	installmentRepo repository.InstallmentRepository,
+	paymentRepo repository.PaymentRepository,
+	branding StatementBranding,
+) StatementUsecase {
+	return &statementUsecase{
+		txRepo:          txRepo,
+		customerRepo:    customerRepo,
+		installmentRepo: installmentRepo,
+		paymentRepo:     paymentRepo,
+		branding:        branding,
+	}
+}
+
+func (uc *statementUsecase) GetStatement(transactionID uint, viewer StatementViewer) (*ContractStatement, error) {
+	tx, err := uc.txRepo.FindByID(transactionID)
+	if err != nil {
+		return nil, errors.New("transaction not found")
+	}
+	customer, err := uc.customerRepo.FindByID(tx.CustomerID)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+	if !customer.AccessibleBy(viewer.UserID, viewer.Role) {
+		return nil, ErrStatementForbidden
+	}
+	installments, err := uc.installmentRepo.FindByTransactionID(tx.ID)
+	if err != nil {
+		return nil, err
+	}
+	payments, err := uc.paymentRepo.FindByTransactionID(tx.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	return &ContractStatement{
+		GeneratedAt:  now,
+		Transaction:  *tx,
+		CustomerName: customer.FullName,
+		NIK:          redact.MaskNIK(customer.NIK),
+		Installments: installments,
+		Payments:     payments,
+		Summary:      summarizeStatement(tx, installments, payments, now),
+	}, nil
+}
+
+// summarizeStatement totals the schedule and payments. Payments settle the
+// late fee, then interest, then principal of each installment, so whatever
+// has been paid beyond an installment's interest has reduced its principal.
+func summarizeStatement(tx *model.Transaction, installments []model.Installment, payments []model.Payment, now time.Time) StatementSummary {
+	summary := StatementSummary{Principal: tx.Principal}
+	today := startOfDay(now)
+
+	for _, inst := range installments {
+		summary.TotalInterest += inst.Interest
+		summary.LateFeesCharged += inst.LateFee
+
+		interestPaid := min(inst.PaidAmount, inst.Interest)
+		summary.OutstandingInterest += inst.Interest - interestPaid
+		summary.OutstandingPrincipal += max(inst.Principal-(inst.PaidAmount-interestPaid), 0)
+		summary.OutstandingLateFee += inst.LateFee - inst.LateFeePaid
+
+		if inst.Status == model.InstallmentStatusPaid {
+			continue
+		}
+		if inst.DueDate.Before(today) {
+			summary.OverdueAmount += inst.Outstanding()
+		} else if summary.NextDueDate == nil || inst.DueDate.Before(*summary.NextDueDate) {
+			due := inst.DueDate
+			summary.NextDueDate = &due
+		}
+	}
+
+	for _, payment := range payments {
+		if payment.Status == model.PaymentStatusPosted {
+			summary.TotalPaid += payment.Amount
+		}
+	}
+
+	summary.TotalOutstanding = summary.OutstandingPrincipal + summary.OutstandingInterest + summary.OutstandingLateFee
+	return summary
+}
+
+func (uc *statementUsecase) RenderStatement(transactionID uint, format string, viewer StatementViewer) (*RenderedStatement, error) {
+	if format == "" {
+		format = StatementFormatPDF
+	}
+	if format != StatementFormatPDF && format != StatementFormatCSV {
+		return nil, errors.New("format must be pdf or csv")
+	}
+
+	statement, err := uc.GetStatement(transactionID, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	rendered := &RenderedStatement{
+		Filename: fmt.Sprintf("statement-%s-%s.%s", statement.Transaction.ContractNumber, statement.GeneratedAt.Format("20060102"), format),
+	}
+	switch format {
+	case StatementFormatCSV:
+		rendered.ContentType = "text/csv"
+		err = writeStatementCSV(&buf, statement)
+	default:
+		rendered.ContentType = "application/pdf"
+		err = writeStatementPDF(&buf, statement, uc.branding)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("render statement: %w", err)
+	}
+
+	rendered.Data = buf.Bytes()
+	return rendered, nil
+}
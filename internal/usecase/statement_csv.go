@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// writeStatementCSV writes the statement as a single table. The section
+// column tells summary, schedule and payment rows apart so the file can be
+// filtered in a spreadsheet.
+func writeStatementCSV(w io.Writer, s *ContractStatement) error {
+	cw := csv.NewWriter(w)
+	tx := s.Transaction
+
+	write := func(record ...string) {
+		_ = cw.Write(record)
+	}
+	amount := func(v int64) string {
+		return strconv.FormatInt(v, 10)
+	}
+	date := func(t time.Time) string {
+		return t.Format("2006-01-02")
+	}
+
+	write("section", "sequence", "date", "description", "principal", "interest", "amount", "late_fee", "paid", "outstanding", "status")
+
+	summary := []struct {
+		label string
+		value int64
+	}{
+		{"principal", s.Summary.Principal},
+		{"total_interest", s.Summary.TotalInterest},
+		{"late_fees_charged", s.Summary.LateFeesCharged},
+		{"total_paid", s.Summary.TotalPaid},
+		{"outstanding_principal", s.Summary.OutstandingPrincipal},
+		{"outstanding_interest", s.Summary.OutstandingInterest},
+		{"outstanding_late_fee", s.Summary.OutstandingLateFee},
+		{"total_outstanding", s.Summary.TotalOutstanding},
+		{"overdue_amount", s.Summary.OverdueAmount},
+	}
+	write("contract", "", date(s.GeneratedAt), tx.ContractNumber, "", "", "", "", "", "", tx.Status)
+	for _, row := range summary {
+		write("summary", "", "", row.label, "", "", amount(row.value), "", "", "", "")
+	}
+
+	for _, inst := range s.Installments {
+		write("schedule", strconv.Itoa(inst.Sequence), date(inst.DueDate), "installment "+strconv.Itoa(inst.Sequence),
+			amount(inst.Principal), amount(inst.Interest), amount(inst.Amount), amount(inst.LateFee),
+			amount(inst.PaidAmount+inst.LateFeePaid), amount(inst.Outstanding()), inst.Status)
+	}
+
+	for _, payment := range s.Payments {
+		var principal, interest, lateFee int64
+		for _, allocation := range payment.Allocations {
+			principal += allocation.Principal
+			interest += allocation.Interest
+			lateFee += allocation.LateFee
+		}
+		write("payment", "", date(payment.PaidAt), payment.Channel+" "+payment.Reference,
+			amount(principal), amount(interest), amount(payment.Amount), amount(lateFee), "", "", payment.Status)
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
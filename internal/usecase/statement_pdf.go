@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"xyz-multifinance/pkg/money"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// Brand colour used for the header band and table headings.
+var statementBrandColor = [3]int{0, 82, 147}
+
+// writeStatementPDF renders the statement on A4 pages with the company
+// header, the contract details and summary, the installment schedule and
+// the payment history.
+func writeStatementPDF(w io.Writer, s *ContractStatement, branding StatementBranding) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 18)
+	pdf.SetCreationDate(s.GeneratedAt)
+	pdf.SetCatalogSort(true)
+	pdf.AliasNbPages("")
+
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	companyName := branding.CompanyName
+	if companyName == "" {
+		companyName = "XYZ Multifinance"
+	}
+	pdf.SetTitle(tr("Statement of Account "+s.Transaction.ContractNumber), false)
+	pdf.SetAuthor(tr(companyName), false)
+
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	contentWidth := pageWidth - left - right
+
+	pdf.SetHeaderFunc(func() {
+		pdf.SetFillColor(statementBrandColor[0], statementBrandColor[1], statementBrandColor[2])
+		pdf.Rect(0, 0, pageWidth, 24, "F")
+
+		textX := left
+		if branding.LogoPath != "" {
+			pdf.ImageOptions(branding.LogoPath, left, 4, 0, 16, false, fpdf.ImageOptions{ReadDpi: true}, 0, "")
+			textX = left + 20
+		}
+		pdf.SetTextColor(255, 255, 255)
+		pdf.SetXY(textX, 6)
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(0, 6, tr(companyName), "", 2, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 8)
+		if branding.Address != "" {
+			pdf.CellFormat(0, 4, tr(branding.Address), "", 2, "L", false, 0, "")
+		}
+		if branding.Contact != "" {
+			pdf.CellFormat(0, 4, tr(branding.Contact), "", 2, "L", false, 0, "")
+		}
+		pdf.SetTextColor(0, 0, 0)
+		pdf.SetY(30)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-13)
+		pdf.SetFont("Helvetica", "", 7)
+		pdf.SetTextColor(110, 110, 110)
+		pdf.CellFormat(contentWidth/2, 5, tr("Generated "+s.GeneratedAt.Format("02 Jan 2006 15:04")+" - this statement is computer generated and needs no signature."), "", 0, "L", false, 0, "")
+		pdf.CellFormat(contentWidth/2, 5, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	pdf.AddPage()
+	tx := s.Transaction
+	amount := func(v int64) string {
+		return formatStatementAmount(txMoney(&tx, v))
+	}
+	date := func(t time.Time) string {
+		return t.Format("02 Jan 2006")
+	}
+
+	pdf.SetFont("Helvetica", "B", 13)
+	pdf.CellFormat(0, 8, "Statement of Account", "", 1, "L", false, 0, "")
+	pdf.Ln(1)
+
+	details := [][2]string{
+		{"Contract number", tx.ContractNumber},
+		{"Customer", s.CustomerName},
+		{"NIK", s.NIK},
+		{"Asset", tx.AssetName},
+		{"Tenor", fmt.Sprintf("%d months", tx.Tenor)},
+		{"Status", strings.ReplaceAll(tx.Status, "_", " ")},
+		{"OTR", amount(tx.OTR)},
+		{"Down payment", amount(tx.DownPayment)},
+		{"Admin fee", amount(tx.AdminFee)},
+		{"Monthly installment", amount(tx.InstallmentAmount)},
+	}
+	if tx.DisbursedAt != nil {
+		details = append(details, [2]string{"Disbursed", date(*tx.DisbursedAt)})
+	}
+	sectionTitle(pdf, "Contract")
+	keyValueRows(pdf, tr, details, contentWidth)
+
+	summary := [][2]string{
+		{"Principal financed", amount(s.Summary.Principal)},
+		{"Total interest", amount(s.Summary.TotalInterest)},
+		{"Late fees charged", amount(s.Summary.LateFeesCharged)},
+		{"Total paid", amount(s.Summary.TotalPaid)},
+		{"Outstanding principal", amount(s.Summary.OutstandingPrincipal)},
+		{"Outstanding interest", amount(s.Summary.OutstandingInterest)},
+		{"Outstanding late fees", amount(s.Summary.OutstandingLateFee)},
+		{"Total outstanding", amount(s.Summary.TotalOutstanding)},
+		{"Overdue", amount(s.Summary.OverdueAmount)},
+	}
+	if s.Summary.NextDueDate != nil {
+		summary = append(summary, [2]string{"Next due date", date(*s.Summary.NextDueDate)})
+	}
+	sectionTitle(pdf, "Summary as of "+date(s.GeneratedAt))
+	keyValueRows(pdf, tr, summary, contentWidth)
+
+	sectionTitle(pdf, "Installment schedule")
+	scheduleWidths := []float64{10, 24, 27, 25, 27, 22, 25, 20}
+	tableHeader(pdf, scheduleWidths, []string{"No", "Due date", "Principal", "Interest", "Amount", "Late fee", "Paid", "Status"})
+	pdf.SetFont("Helvetica", "", 8)
+	for i, inst := range s.Installments {
+		tableRow(pdf, scheduleWidths, "CCRRRRRC", i%2 == 1, []string{
+			strconv.Itoa(inst.Sequence),
+			date(inst.DueDate),
+			amount(inst.Principal),
+			amount(inst.Interest),
+			amount(inst.Amount),
+			amount(inst.LateFee),
+			amount(inst.PaidAmount + inst.LateFeePaid),
+			inst.Status,
+		})
+	}
+	if len(s.Installments) == 0 {
+		pdf.CellFormat(contentWidth, 6, "No schedule yet: the contract has not been disbursed.", "", 1, "L", false, 0, "")
+	}
+
+	sectionTitle(pdf, "Payments")
+	paymentWidths := []float64{24, 50, 26, 26, 27, 27}
+	tableHeader(pdf, paymentWidths, []string{"Date", "Reference", "Channel", "Amount", "To principal", "Status"})
+	pdf.SetFont("Helvetica", "", 8)
+	for i, payment := range s.Payments {
+		var principal int64
+		for _, allocation := range payment.Allocations {
+			principal += allocation.Principal
+		}
+		tableRow(pdf, paymentWidths, "CLCRRC", i%2 == 1, []string{
+			date(payment.PaidAt),
+			tr(truncate(payment.Reference, 30)),
+			strings.ReplaceAll(payment.Channel, "_", " "),
+			amount(payment.Amount),
+			amount(principal),
+			payment.Status,
+		})
+	}
+	if len(s.Payments) == 0 {
+		pdf.CellFormat(contentWidth, 6, "No payments received.", "", 1, "L", false, 0, "")
+	}
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+func sectionTitle(pdf *fpdf.Fpdf, title string) {
+	pdf.Ln(3)
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetTextColor(statementBrandColor[0], statementBrandColor[1], statementBrandColor[2])
+	pdf.CellFormat(0, 7, title, "B", 1, "L", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(1)
+}
+
+// keyValueRows lays label/value pairs out in two columns.
+func keyValueRows(pdf *fpdf.Fpdf, tr func(string) string, rows [][2]string, width float64) {
+	labelWidth, valueWidth := width/4, width/4
+	for i, row := range rows {
+		pdf.SetFont("Helvetica", "", 9)
+		pdf.CellFormat(labelWidth, 5.5, row[0], "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "B", 9)
+		ln := 0
+		if i%2 == 1 || i == len(rows)-1 {
+			ln = 1
+		}
+		pdf.CellFormat(valueWidth, 5.5, tr(row[1]), "", ln, "L", false, 0, "")
+	}
+}
+
+func tableHeader(pdf *fpdf.Fpdf, widths []float64, titles []string) {
+	pdf.SetFont("Helvetica", "B", 8)
+	pdf.SetFillColor(statementBrandColor[0], statementBrandColor[1], statementBrandColor[2])
+	pdf.SetTextColor(255, 255, 255)
+	for i, title := range titles {
+		pdf.CellFormat(widths[i], 6, title, "", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetTextColor(0, 0, 0)
+}
+
+// tableRow prints one row; aligns holds the L, C or R alignment of each
+// column.
+func tableRow(pdf *fpdf.Fpdf, widths []float64, aligns string, shaded bool, cells []string) {
+	pdf.SetFillColor(238, 243, 248)
+	for i, cell := range cells {
+		pdf.CellFormat(widths[i], 5.5, cell, "", 0, aligns[i:i+1], shaded, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+// formatStatementAmount groups the whole part of an amount in thousands,
+// e.g. "IDR 1,250,000".
+func formatStatementAmount(m money.Money) string {
+	s := m.String()
+	code, number, _ := strings.Cut(s, " ")
+	sign := ""
+	if strings.HasPrefix(number, "-") {
+		sign, number = "-", number[1:]
+	}
+	whole, fraction, hasFraction := strings.Cut(number, ".")
+
+	var grouped strings.Builder
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(d)
+	}
+	if hasFraction {
+		return code + " " + sign + grouped.String() + "." + fraction
+	}
+	return code + " " + sign + grouped.String()
+}
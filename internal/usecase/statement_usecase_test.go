@@ -0,0 +1,123 @@
+package usecase_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"testing"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/internal/usecase"
+)
+
+type mockPaymentRepo struct {
+	repository.PaymentRepository
+	payments []model.Payment
+}
+
+func (m *mockPaymentRepo) FindByTransactionID(transactionID uint) ([]model.Payment, error) {
+	return m.payments, nil
+}
+
+var statementAdmin = usecase.StatementViewer{UserID: 1, Role: "admin"}
+
+func newStatementUsecase() usecase.StatementUsecase {
+	now := time.Now()
+	tx := &model.Transaction{ID: 7, CustomerID: 3, ContractNumber: "CN-7", Tenor: 3, Principal: 3000, Currency: "IDR", Status: model.TransactionStatusOngoing}
+	installments := []model.Installment{
+		{ID: 1, Sequence: 1, DueDate: now.AddDate(0, -1, 0), Principal: 1000, Interest: 100, Amount: 1100, PaidAmount: 1100, Status: model.InstallmentStatusPaid},
+		// Overdue, 150 paid: interest first, so 50 of principal is covered.
+		{ID: 2, Sequence: 2, DueDate: now.AddDate(0, 0, -5), Principal: 1000, Interest: 100, Amount: 1100, PaidAmount: 150, LateFee: 20, Status: model.InstallmentStatusPartial},
+		{ID: 3, Sequence: 3, DueDate: now.AddDate(0, 1, 0), Principal: 1000, Interest: 100, Amount: 1100, Status: model.InstallmentStatusUnpaid},
+	}
+	payments := []model.Payment{
+		{ID: 1, Amount: 1250, Channel: "manual", Reference: "P1", PaidAt: now.AddDate(0, -1, 0), Status: model.PaymentStatusPosted},
+		{ID: 2, Amount: 500, Channel: "manual", Reference: "P2", PaidAt: now.AddDate(0, 0, -1), Status: model.PaymentStatusReversed},
+	}
+
+	return usecase.NewStatementUsecase(
+		&mockSettlementTxRepo{tx: tx},
+		&mockCustomerRepo{FindByIDFunc: func(id uint) (*model.Customer, error) {
+			return &model.Customer{ID: id, UserID: 9, FullName: "Budi Santoso", NIK: "3171234567890001"}, nil
+		}},
+		&mockInstallmentRepo{installments: installments},
+		&mockPaymentRepo{payments: payments},
+		usecase.StatementBranding{CompanyName: "XYZ Multifinance"},
+	)
+}
+
+func TestGetStatement_Summary(t *testing.T) {
+	statement, err := newStatementUsecase().GetStatement(7, statementAdmin)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if statement.NIK != "3171********0001" {
+		t.Errorf("nik = %s, want it masked", statement.NIK)
+	}
+
+	s := statement.Summary
+	if s.OutstandingPrincipal != 1950 || s.OutstandingInterest != 100 || s.OutstandingLateFee != 20 {
+		t.Errorf("outstanding = %d/%d/%d, want 1950/100/20", s.OutstandingPrincipal, s.OutstandingInterest, s.OutstandingLateFee)
+	}
+	if s.TotalOutstanding != 2070 {
+		t.Errorf("total outstanding = %d, want 2070", s.TotalOutstanding)
+	}
+	if s.TotalPaid != 1250 {
+		t.Errorf("total paid = %d, want 1250 (reversed payments excluded)", s.TotalPaid)
+	}
+	if s.OverdueAmount != 970 {
+		t.Errorf("overdue = %d, want 970", s.OverdueAmount)
+	}
+	if s.NextDueDate == nil {
+		t.Error("expected a next due date")
+	}
+}
+
+func TestRenderStatement(t *testing.T) {
+	uc := newStatementUsecase()
+
+	pdf, err := uc.RenderStatement(7, "pdf", statementAdmin)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pdf.ContentType != "application/pdf" || !bytes.HasPrefix(pdf.Data, []byte("%PDF-")) {
+		t.Errorf("expected a PDF document, got %q", pdf.ContentType)
+	}
+
+	file, err := uc.RenderStatement(7, "csv", statementAdmin)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(file.Data)).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid csv: %v", err)
+	}
+	sections := map[string]int{}
+	for _, record := range records[1:] {
+		sections[record[0]]++
+	}
+	if sections["schedule"] != 3 || sections["payment"] != 2 || sections["summary"] == 0 {
+		t.Errorf("unexpected sections: %v", sections)
+	}
+
+	if _, err := uc.RenderStatement(7, "xlsx", statementAdmin); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestGetStatement_OnlyOwnerOrAdmin(t *testing.T) {
+	uc := newStatementUsecase()
+
+	if _, err := uc.GetStatement(7, usecase.StatementViewer{UserID: 9, Role: "user"}); err != nil {
+		t.Errorf("owner: unexpected error: %v", err)
+	}
+	if _, err := uc.GetStatement(7, usecase.StatementViewer{UserID: 4, Role: "user"}); !errors.Is(err, usecase.ErrStatementForbidden) {
+		t.Errorf("other user: err = %v, want ErrStatementForbidden", err)
+	}
+	if _, err := uc.RenderStatement(7, "csv", usecase.StatementViewer{UserID: 4, Role: "user"}); !errors.Is(err, usecase.ErrStatementForbidden) {
+		t.Errorf("other user render: err = %v, want ErrStatementForbidden", err)
+	}
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/storage"
+)
+
+// ObjectCollector lists and removes stored objects.
+type ObjectCollector interface {
+	List(ctx context.Context, prefix string) ([]storage.ObjectEntry, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type StorageGCUsecase interface {
+	// Collect compares the blob store with the customer table and the
+	// privacy export archives. It reports objects nothing references
+	// (orphans) and customer photos whose object is gone (missing), and
+	// deletes the orphans when asked to.
+	Collect(ctx context.Context, opts StorageGCOptions) (*StorageGCResult, error)
+}
+
+// StorageGCOptions controls a collection run. Objects younger than MinAge
+// are never treated as orphans: under the write-ahead order of customer
+// writes, a fresh object may belong to a row that is not committed yet.
+type StorageGCOptions struct {
+	Delete bool
+	MinAge time.Duration
+}
+
+type StorageGCResult struct {
+	Customers int             `json:"customers"`
+	Objects   int             `json:"objects"`
+	Orphans   []string        `json:"orphans"`
+	Deleted   int             `json:"deleted"`
+	Missing   []MissingObject `json:"missing"`
+}
+
+// MissingObject is a customer photo whose object is not in the store.
+type MissingObject struct {
+	CustomerID uint   `json:"customer_id"`
+	Column     string `json:"column"`
+	Key        string `json:"key"`
+}
+
+const storageGCBatchSize = 200
+
+type storageGCUsecase struct {
+	customerRepo repository.CustomerRepository
+	privacyRepo  repository.PrivacyRequestRepository
+	objects      ObjectCollector
+	now          func() time.Time
+}
+
+func NewStorageGCUsecase(customerRepo repository.CustomerRepository, privacyRepo repository.PrivacyRequestRepository, objects ObjectCollector) StorageGCUsecase {
+	return &storageGCUsecase{
+		customerRepo: customerRepo,
+		privacyRepo:  privacyRepo,
+		objects:      objects,
+		now:          time.Now,
+	}
+}
+
+func (uc *storageGCUsecase) Collect(ctx context.Context, opts StorageGCOptions) (*StorageGCResult, error) {
+	// List before reading the customers: an object written after the
+	// listing cannot be mistaken for an orphan, and one whose row is
+	// committed in between is still seen as referenced.
+	entries, err := uc.objects.List(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	stored := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		stored[entry.Key] = true
+	}
+
+	result := &StorageGCResult{Objects: len(entries), Orphans: []string{}, Missing: []MissingObject{}}
+	referenced := make(map[string]bool)
+	var afterID uint
+	for {
+		// Deleted customers are included; their photos are kept until the
+		// customer's data is erased.
+		customers, err := uc.customerRepo.FindBatch(afterID, storageGCBatchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(customers) == 0 {
+			break
+		}
+		for _, customer := range customers {
+			result.Customers++
+			photos := []MissingObject{
+				{CustomerID: customer.ID, Column: "photo_ktp", Key: customer.KTPPhoto},
+				{CustomerID: customer.ID, Column: "photo_selfie", Key: customer.SelfiePhoto},
+			}
+			for _, photo := range photos {
+				if photo.Key == "" {
+					continue
+				}
+				referenced[photo.Key] = true
+				if !stored[photo.Key] {
+					result.Missing = append(result.Missing, photo)
+				}
+			}
+		}
+		afterID = customers[len(customers)-1].ID
+	}
+
+	exports, err := uc.privacyRepo.FindResultKeys()
+	if err != nil {
+		return result, err
+	}
+	for _, key := range exports {
+		referenced[key] = true
+	}
+
+	cutoff := uc.now().Add(-opts.MinAge)
+	for _, entry := range entries {
+		if referenced[entry.Key] || entry.LastModified.After(cutoff) {
+			continue
+		}
+		result.Orphans = append(result.Orphans, entry.Key)
+		if !opts.Delete {
+			continue
+		}
+		if err := uc.objects.Delete(ctx, entry.Key); err != nil {
+			return result, fmt.Errorf("object %s: %w", entry.Key, err)
+		}
+		result.Deleted++
+	}
+	return result, nil
+}
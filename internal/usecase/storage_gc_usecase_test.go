@@ -0,0 +1,80 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/storage"
+)
+
+type mockObjectCollector struct {
+	entries []storage.ObjectEntry
+	deleted []string
+}
+
+func (m *mockObjectCollector) List(ctx context.Context, prefix string) ([]storage.ObjectEntry, error) {
+	return m.entries, nil
+}
+
+func (m *mockObjectCollector) Delete(ctx context.Context, key string) error {
+	m.deleted = append(m.deleted, key)
+	return nil
+}
+
+func TestCollect_FindsOrphansAndMissingObjects(t *testing.T) {
+	old := time.Now().Add(-48 * time.Hour)
+	objects := &mockObjectCollector{entries: []storage.ObjectEntry{
+		{Key: "kyc/1/ktp_1.jpg", LastModified: old},
+		{Key: "kyc/1/ktp_0.jpg", LastModified: old},
+		{Key: "kyc/2/selfie_1.jpg", LastModified: old},
+		{Key: "kyc/3/ktp_1.jpg", LastModified: time.Now()},
+		{Key: "privacy/exports/2/7.zip", LastModified: old},
+	}}
+	customers := []model.Customer{
+		{ID: 1, KTPPhoto: "kyc/1/ktp_1.jpg", SelfiePhoto: "kyc/1/selfie_1.jpg"},
+		{ID: 2, SelfiePhoto: "kyc/2/selfie_1.jpg"},
+	}
+	repo := &mockCustomerRepo{
+		FindBatchFunc: func(afterID uint, limit int) ([]model.Customer, error) {
+			if afterID > 0 {
+				return nil, nil
+			}
+			return customers, nil
+		},
+	}
+	privacyRepo := &mockPrivacyRequestRepo{requests: []model.PrivacyRequest{
+		{ID: 7, CustomerID: 2, ResultKey: "privacy/exports/2/7.zip"},
+	}}
+	uc := usecase.NewStorageGCUsecase(repo, privacyRepo, objects)
+
+	result, err := uc.Collect(context.Background(), usecase.StorageGCOptions{MinAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Customers != 2 || result.Objects != 5 {
+		t.Errorf("result = %+v", result)
+	}
+	// The fresh kyc/3 object may belong to a row that is not committed yet.
+	if strings.Join(result.Orphans, ",") != "kyc/1/ktp_0.jpg" {
+		t.Errorf("orphans = %v", result.Orphans)
+	}
+	want := usecase.MissingObject{CustomerID: 1, Column: "photo_selfie", Key: "kyc/1/selfie_1.jpg"}
+	if len(result.Missing) != 1 || result.Missing[0] != want {
+		t.Errorf("missing = %+v", result.Missing)
+	}
+	if len(objects.deleted) != 0 || result.Deleted != 0 {
+		t.Errorf("dry run deleted %v", objects.deleted)
+	}
+
+	result, err = uc.Collect(context.Background(), usecase.StorageGCOptions{Delete: true, MinAge: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Deleted != 1 || strings.Join(objects.deleted, ",") != "kyc/1/ktp_0.jpg" {
+		t.Errorf("deleted %v (%d)", objects.deleted, result.Deleted)
+	}
+}
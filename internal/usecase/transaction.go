@@ -1,85 +1,283 @@
 package usecase
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
 	"xyz-multifinance/internal/model"
 	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/pkg/money"
 
 	"gorm.io/gorm"
 )
 
 type TransactionUsecase interface {
-	CreateTransaction(tx *model.Transaction) error
-	UpdateTransaction(id uint, tx *model.Transaction) error
-	DeleteTransaction(id uint) error
+	CreateTransaction(ctx context.Context, tx *model.Transaction) error
+	UpdateTransaction(ctx context.Context, id uint, tx *model.Transaction) error
 	GetTransactionByID(id uint) (*model.Transaction, error)
-	GetTransactionsByCustomer(customerID uint) ([]model.Transaction, error)
+	// GetTransactionsByNIK returns ErrCustomerForbidden unless the viewer is
+	// an admin or the customer's own user.
+	GetTransactionsByNIK(nik string, viewer Viewer) ([]model.Transaction, error)
 	GetAllTransactions() ([]model.Transaction, error)
+	CreatePartnerTransaction(ctx context.Context, partnerID uint, tx *model.Transaction) error
+	GetTransactionsByPartner(partnerID uint) ([]model.Transaction, error)
+	GetPartnerTransactionByID(partnerID uint, id uint) (*model.Transaction, error)
+}
+
+// TransactionPolicy holds the configurable business rules applied when a
+// contract is created.
+type TransactionPolicy struct {
+	OTRTolerancePercent int
+	InterestRounding    money.RoundingMode
+	// DirectPricing prices contracts made without a partner.
+	DirectPricing PricingScheme
 }
 
 type transactionUsecase struct {
-	txRepo       repository.TransactionRepository
-	limitRepo    repository.LimitRepository
-	customerRepo repository.CustomerRepository
-	db           *gorm.DB
+	txRepo           repository.TransactionRepository
+	limitRepo        repository.LimitRepository
+	customerRepo     repository.CustomerRepository
+	installmentRepo  repository.InstallmentRepository
+	partnerRepo      repository.PartnerRepository
+	outletRepo       repository.OutletRepository
+	assetRepo        repository.AssetRepository
+	dpRuleRepo       repository.DownPaymentRuleRepository
+	disbursementRepo repository.DisbursementRepository
+	auditor          Auditor
+	policy           TransactionPolicy
+	db               *gorm.DB
 }
 
 func NewTransactionUsecase(
 	txRepo repository.TransactionRepository,
 	limitRepo repository.LimitRepository,
 	customerRepo repository.CustomerRepository,
+	installmentRepo repository.InstallmentRepository,
+	partnerRepo repository.PartnerRepository,
+	outletRepo repository.OutletRepository,
+	assetRepo repository.AssetRepository,
+	dpRuleRepo repository.DownPaymentRuleRepository,
+	disbursementRepo repository.DisbursementRepository,
+	auditor Auditor,
+	policy TransactionPolicy,
 	db *gorm.DB,
 ) TransactionUsecase {
 	return &transactionUsecase{
-		txRepo:       txRepo,
-		limitRepo:    limitRepo,
-		customerRepo: customerRepo,
-		db:           db,
+		txRepo:           txRepo,
+		limitRepo:        limitRepo,
+		customerRepo:     customerRepo,
+		installmentRepo:  installmentRepo,
+		partnerRepo:      partnerRepo,
+		outletRepo:       outletRepo,
+		assetRepo:        assetRepo,
+		dpRuleRepo:       dpRuleRepo,
+		disbursementRepo: disbursementRepo,
+		auditor:          auditor,
+		policy:           policy,
+		db:               db,
 	}
 }
 
-func (uc *transactionUsecase) CreateTransaction(tx *model.Transaction) error {
+func (uc *transactionUsecase) CreateTransaction(ctx context.Context, tx *model.Transaction) error {
 	_, err := uc.customerRepo.FindByID(tx.CustomerID)
 	if err != nil {
 		return errors.New("customer not found")
 	}
 
-	limits, err := uc.limitRepo.FindByCustomerID(tx.CustomerID)
-	if err != nil {
-		return errors.New("limit not found")
+	if err := uc.price(tx); err != nil {
+		return err
 	}
 
-	var limitAmount int64
-	for _, l := range limits {
-		if l.Tenor == tx.Tenor {
-			limitAmount = l.Limit
-			break
-		}
-	}
-	if limitAmount == 0 {
+	usage, err := uc.limitRepo.FindUsageByCustomerAndTenor(tx.CustomerID, tx.Tenor)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return errors.New("limit for tenor not found")
 	}
-
-	totalUsed, err := uc.txRepo.SumUsedAmount(tx.CustomerID, tx.Tenor)
 	if err != nil {
 		return errors.New("failed to calculate used limit")
 	}
 
-	if totalUsed+tx.InstallmentAmount > limitAmount {
-		return errors.New("transaction amount exceeds limit")
+	if err := checkLimit(usage, tx, 0); err != nil {
+		return err
+	}
+
+	var outlet *model.Outlet
+	if tx.OutletID != nil {
+		if outlet, err = uc.outletRepo.FindByID(*tx.OutletID); err != nil {
+			return errors.New("outlet not found")
+		}
 	}
 
+	// The contract is activated, and its schedule built, once the principal
+	// has been paid out to the dealer.
+	tx.Status = model.TransactionStatusApproved
+	tx.DisbursedAt = nil
+	tx.DaysPastDue = 0
+	tx.Collectibility, tx.AgingBucket = model.ClassifyDPD(0)
+
 	err = uc.db.Transaction(func(txDB *gorm.DB) error {
 		if err := uc.txRepo.Create(txDB, tx); err != nil {
 			return err
 		}
-		return nil
+		return uc.disbursementRepo.Create(txDB, newDisbursement(tx, outlet))
 	})
+	if err != nil {
+		return err
+	}
+
+	uc.audit(ctx, model.AuditActionCreate, tx.ID, nil, tx)
+	return nil
+}
+
+// price validates the asset and down payment, derives the financed principal
+// and computes the fee, interest and installment amounts from it with the
+// partner's scheme or, for direct contracts, the configured one. Amounts
+// sent by the client are never used.
+func (uc *transactionUsecase) price(tx *model.Transaction) error {
+	if tx.Tenor <= 0 {
+		return errors.New("tenor must be greater than zero")
+	}
+	if tx.AdminFee < 0 || tx.InterestAmount < 0 {
+		return errors.New("admin_fee and interest_amount must not be negative")
+	}
+	if tx.OTR <= 0 {
+		return errors.New("otr must be greater than zero")
+	}
+	if tx.DownPayment < 0 || tx.DownPayment >= tx.OTR {
+		return errors.New("down_payment must be at least zero and less than otr")
+	}
+	if err := normalizeCurrency(&tx.Currency); err != nil {
+		return err
+	}
+
+	// asset_id stays optional for direct contracts: the catalog lists what
+	// partners sell, while direct contracts also finance goods outside it.
+	// Without an asset the OTR is not checked against a catalog price and
+	// only down payment rules for all categories apply.
+	var category string
+	if tx.AssetID != nil {
+		asset, err := uc.checkAsset(tx)
+		if err != nil {
+			return err
+		}
+		category = asset.Category
+	}
 
-	return err
+	if err := uc.checkDownPayment(tx, category); err != nil {
+		return err
+	}
+	principal, err := txMoney(tx, tx.OTR).Sub(txMoney(tx, tx.DownPayment))
+	if err != nil {
+		return err
+	}
+	tx.Principal = principal.Amount()
+
+	if tx.PartnerID != nil {
+		err = uc.applyPartnerScheme(tx)
+	} else {
+		err = applyPricing(tx, uc.policy.DirectPricing, uc.policy.InterestRounding)
+	}
+	if err != nil {
+		return err
+	}
+	if tx.AdminFee >= tx.Principal {
+		return errors.New("admin fee must be less than the financed principal")
+	}
+
+	return setInstallmentAmount(tx)
 }
 
-func (uc *transactionUsecase) UpdateTransaction(id uint, updatedTx *model.Transaction) error {
+// checkLimit verifies that the principal of tx fits in what is left of the
+// limit once released, the principal of a contract being replaced, is given
+// back.
+func checkLimit(usage *repository.LimitUsage, tx *model.Transaction, released int64) error {
+	remaining, err := remainingLimit(*usage)
+	if err != nil {
+		return err
+	}
+	if remaining, err = remaining.Add(money.New(released, remaining.Currency())); err != nil {
+		return err
+	}
+
+	cmp, err := txMoney(tx, tx.Principal).Cmp(remaining)
+	if errors.Is(err, money.ErrCurrencyMismatch) {
+		return errors.New("transaction currency does not match the limit currency")
+	}
+	if err != nil {
+		return err
+	}
+	if cmp > 0 {
+		return errors.New("transaction amount exceeds limit")
+	}
+	return nil
+}
+
+// checkAsset validates the submitted OTR against the catalog price of the
+// asset and fills in the asset name when the caller left it empty.
+func (uc *transactionUsecase) checkAsset(tx *model.Transaction) (*model.Asset, error) {
+	asset, err := uc.assetRepo.FindByID(*tx.AssetID)
+	if err != nil {
+		return nil, errors.New("asset not found")
+	}
+
+	diff := tx.OTR - asset.OTRPrice
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff*100 > asset.OTRPrice*int64(uc.policy.OTRTolerancePercent) {
+		return nil, fmt.Errorf("otr must be within %d%% of the catalog price %d", uc.policy.OTRTolerancePercent, asset.OTRPrice)
+	}
+
+	if tx.AssetName == "" {
+		tx.AssetName = asset.DisplayName()
+	}
+	return asset, nil
+}
+
+// checkDownPayment enforces the most specific minimum down payment rule for
+// the asset category and tenor. Without a matching rule any down payment,
+// including none, is accepted.
+func (uc *transactionUsecase) checkDownPayment(tx *model.Transaction, category string) error {
+	rules, err := uc.dpRuleRepo.FindApplicable(category, tx.Tenor)
+	if err != nil {
+		return errors.New("failed to load down payment rules")
+	}
+
+	rule := mostSpecificRule(rules)
+	if rule == nil {
+		return nil
+	}
+	if tx.DownPayment*100 < tx.OTR*int64(rule.MinPercent) {
+		return fmt.Errorf("down_payment must be at least %d%% of otr", rule.MinPercent)
+	}
+	return nil
+}
+
+// applyPartnerScheme checks that the outlet belongs to the transaction's
+// partner and prices the contract with that partner's scheme.
+func (uc *transactionUsecase) applyPartnerScheme(tx *model.Transaction) error {
+	partner, err := uc.partnerRepo.FindByID(*tx.PartnerID)
+	if err != nil || !partner.Active {
+		return errors.New("partner not found")
+	}
+
+	if tx.OutletID == nil {
+		return errors.New("outlet_id is required for partner transactions")
+	}
+	outlet, err := uc.outletRepo.FindByID(*tx.OutletID)
+	if err != nil || outlet.PartnerID != partner.ID || !outlet.Active {
+		return errors.New("outlet not found")
+	}
+
+	return applyPricing(tx, partnerPricingScheme(partner), uc.policy.InterestRounding)
+}
+
+// UpdateTransaction reprices a contract that has not been paid out yet. Its
+// schedule is only built on disbursement, so nothing else needs rebuilding;
+// the pending disbursement is updated to the new principal.
+func (uc *transactionUsecase) UpdateTransaction(ctx context.Context, id uint, updatedTx *model.Transaction) error {
 	existingTx, err := uc.txRepo.FindByID(id)
 	if err != nil {
 		return errors.New("transaction not found")
@@ -88,60 +286,134 @@ func (uc *transactionUsecase) UpdateTransaction(id uint, updatedTx *model.Transa
 	if existingTx.CustomerID != updatedTx.CustomerID {
 		return errors.New("customer ID mismatch")
 	}
-
-	limits, err := uc.limitRepo.FindByCustomerID(updatedTx.CustomerID)
-	if err != nil {
-		return errors.New("limit not found")
+	if err := checkUpdatable(existingTx.Status, ""); err != nil {
+		return err
 	}
 
-	var limitAmount int64
-	for _, l := range limits {
-		if l.Tenor == updatedTx.Tenor {
-			limitAmount = l.Limit
-			break
-		}
+	// The contract number and the dealer are part of the contract's identity
+	// and keep it priced under the same partner scheme.
+	updatedTx.ID = id
+	updatedTx.ContractNumber = existingTx.ContractNumber
+	updatedTx.PartnerID = existingTx.PartnerID
+	updatedTx.OutletID = existingTx.OutletID
+	if err := uc.price(updatedTx); err != nil {
+		return err
 	}
-	if limitAmount == 0 {
+
+	usage, err := uc.limitRepo.FindUsageByCustomerAndTenor(updatedTx.CustomerID, updatedTx.Tenor)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
 		return errors.New("limit for tenor not found")
 	}
-
-	totalUsed, err := uc.txRepo.SumUsedAmount(updatedTx.CustomerID, updatedTx.Tenor)
 	if err != nil {
 		return errors.New("failed to calculate used limit")
 	}
 
-	newTotal := totalUsed - existingTx.InstallmentAmount + updatedTx.InstallmentAmount
-	if newTotal > limitAmount {
-		return errors.New("transaction amount exceeds limit")
+	var released int64
+	if existingTx.Tenor == updatedTx.Tenor {
+		released = existingTx.Principal
+	}
+	if err := checkLimit(usage, updatedTx, released); err != nil {
+		return err
 	}
 
 	err = uc.db.Transaction(func(txDB *gorm.DB) error {
-		if err := uc.txRepo.Update(txDB, id, updatedTx); err != nil {
+		locked, err := uc.txRepo.FindByIDForUpdate(txDB, id)
+		if err != nil {
 			return err
 		}
-		return nil
+		d, err := uc.disbursementRepo.FindByTransactionIDForUpdate(txDB, id)
+		if err != nil {
+			return err
+		}
+		if err := checkUpdatable(locked.Status, d.Status); err != nil {
+			return err
+		}
+
+		if err := uc.txRepo.UpdatePricing(txDB, updatedTx); err != nil {
+			return err
+		}
+		d.Amount = disbursementAmount(updatedTx)
+		d.Currency = updatedTx.Currency
+		return uc.disbursementRepo.Save(txDB, d)
 	})
+	if err != nil {
+		return err
+	}
 
-	return err
+	after, err := uc.txRepo.FindByID(id)
+	if err != nil {
+		return err
+	}
+	uc.audit(ctx, model.AuditActionUpdate, id, existingTx, after)
+	return nil
 }
 
-func (uc *transactionUsecase) DeleteTransaction(id uint) error {
-	_, err := uc.txRepo.FindByID(id)
-	if err != nil {
-		return errors.New("transaction not found")
+// checkUpdatable allows changing a contract only while it is approved and its
+// payout has not been sent, or has failed and waits for a retry. An empty
+// disbursement status skips that part of the check.
+func checkUpdatable(txStatus, disbursementStatus string) error {
+	if txStatus != model.TransactionStatusApproved {
+		return fmt.Errorf("cannot update a %s transaction", txStatus)
+	}
+	switch disbursementStatus {
+	case "", model.DisbursementStatusPending, model.DisbursementStatusFailed:
+		return nil
 	}
+	return fmt.Errorf("cannot update a transaction whose disbursement is %s", disbursementStatus)
+}
 
-	return uc.txRepo.Delete(id)
+func (uc *transactionUsecase) audit(ctx context.Context, action string, id uint, before, after *model.Transaction) {
+	recordAudit(ctx, uc.auditor, AuditEvent{
+		Entity:   model.AuditEntityTransaction,
+		EntityID: strconv.FormatUint(uint64(id), 10),
+		Action:   action,
+		Before:   before,
+		After:    after,
+	})
 }
 
 func (uc *transactionUsecase) GetTransactionByID(id uint) (*model.Transaction, error) {
 	return uc.txRepo.FindByID(id)
 }
 
-func (uc *transactionUsecase) GetTransactionsByCustomer(customerID uint) ([]model.Transaction, error) {
-	return uc.txRepo.FindByCustomerID(customerID)
+func (uc *transactionUsecase) GetTransactionsByNIK(nik string, viewer Viewer) ([]model.Transaction, error) {
+	customer, err := uc.customerRepo.FindByNIK(nik)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+	if err := authorizeCustomer(customer, viewer); err != nil {
+		return nil, err
+	}
+	return uc.txRepo.FindByCustomerID(customer.ID)
 }
 
 func (uc *transactionUsecase) GetAllTransactions() ([]model.Transaction, error) {
 	return uc.txRepo.FindAll()
 }
+
+func (uc *transactionUsecase) CreatePartnerTransaction(ctx context.Context, partnerID uint, tx *model.Transaction) error {
+	if tx.AssetID == nil {
+		return errors.New("asset_id is required for partner transactions")
+	}
+	// A partner only submits the terms of a new contract. Its identity,
+	// status and servicing fields are the platform's, whatever the request
+	// carried.
+	tx.ID = 0
+	tx.PartnerID = &partnerID
+	tx.Status = model.TransactionStatusApproved
+	tx.DisbursedAt = nil
+	tx.CreatedAt, tx.UpdatedAt = time.Time{}, time.Time{}
+	return uc.CreateTransaction(ctx, tx)
+}
+
+func (uc *transactionUsecase) GetTransactionsByPartner(partnerID uint) ([]model.Transaction, error) {
+	return uc.txRepo.FindByPartnerID(partnerID)
+}
+
+func (uc *transactionUsecase) GetPartnerTransactionByID(partnerID uint, id uint) (*model.Transaction, error) {
+	tx, err := uc.txRepo.FindByID(id)
+	if err != nil || tx.PartnerID == nil || *tx.PartnerID != partnerID {
+		return nil, errors.New("transaction not found")
+	}
+	return tx, nil
+}
@@ -0,0 +1,465 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/pkg/money"
+
+	"gorm.io/gorm"
+)
+
+type mockDPRuleRepo struct {
+	repository.DownPaymentRuleRepository
+	rules   []model.DownPaymentRule
+	created []model.DownPaymentRule
+	deleted []uint
+}
+
+func (m *mockDPRuleRepo) FindApplicable(category string, tenor int) ([]model.DownPaymentRule, error) {
+	var applicable []model.DownPaymentRule
+	for _, rule := range m.rules {
+		if (rule.Category == "" || rule.Category == category) && (rule.Tenor == 0 || rule.Tenor == tenor) {
+			applicable = append(applicable, rule)
+		}
+	}
+	return applicable, nil
+}
+
+func (m *mockDPRuleRepo) Create(rule *model.DownPaymentRule) error {
+	m.created = append(m.created, *rule)
+	return nil
+}
+
+func (m *mockDPRuleRepo) FindByID(id uint) (*model.DownPaymentRule, error) {
+	for _, rule := range m.rules {
+		if rule.ID == id {
+			return &rule, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockDPRuleRepo) Delete(id uint) error {
+	m.deleted = append(m.deleted, id)
+	return nil
+}
+
+func TestMostSpecificRule(t *testing.T) {
+	catchAll := model.DownPaymentRule{ID: 1, MinPercent: 10}
+	byTenor := model.DownPaymentRule{ID: 2, Tenor: 12, MinPercent: 15}
+	byCategory := model.DownPaymentRule{ID: 3, Category: model.AssetCategoryCar, MinPercent: 20}
+	exact := model.DownPaymentRule{ID: 4, Category: model.AssetCategoryCar, Tenor: 12, MinPercent: 25}
+
+	cases := []struct {
+		name  string
+		rules []model.DownPaymentRule
+		want  uint
+	}{
+		{"none", nil, 0},
+		{"catch-all only", []model.DownPaymentRule{catchAll}, 1},
+		{"tenor beats catch-all", []model.DownPaymentRule{catchAll, byTenor}, 2},
+		{"category beats tenor", []model.DownPaymentRule{byTenor, byCategory, catchAll}, 3},
+		{"category and tenor beat all", []model.DownPaymentRule{catchAll, byCategory, exact, byTenor}, 4},
+	}
+	for _, tc := range cases {
+		got := usecase.MostSpecificRule(tc.rules)
+		var id uint
+		if got != nil {
+			id = got.ID
+		}
+		if id != tc.want {
+			t.Errorf("%s: picked rule %d, want %d", tc.name, id, tc.want)
+		}
+	}
+}
+
+func TestCheckDownPayment(t *testing.T) {
+	repo := &mockDPRuleRepo{rules: []model.DownPaymentRule{
+		{ID: 1, MinPercent: 10},
+		{ID: 2, Category: model.AssetCategoryCar, Tenor: 12, MinPercent: 30},
+		{ID: 3, Category: model.AssetCategoryMotorcycle, MinPercent: 0},
+	}}
+
+	cases := []struct {
+		name        string
+		category    string
+		tenor       int
+		downPayment int64
+		wantErr     string
+	}{
+		{"catch-all met", model.AssetCategoryWhiteGoods, 6, 1_000_000, ""},
+		{"catch-all missed", model.AssetCategoryWhiteGoods, 6, 999_999, "at least 10%"},
+		{"specific rule met", model.AssetCategoryCar, 12, 3_000_000, ""},
+		{"specific rule missed", model.AssetCategoryCar, 12, 2_000_000, "at least 30%"},
+		{"other tenor falls back", model.AssetCategoryCar, 6, 1_000_000, ""},
+		{"zero percent rule allows no down payment", model.AssetCategoryMotorcycle, 6, 0, ""},
+	}
+	for _, tc := range cases {
+		tx := &model.Transaction{Tenor: tc.tenor, OTR: 10_000_000, DownPayment: tc.downPayment}
+		err := usecase.CheckDownPayment(repo, tx, tc.category)
+		if tc.wantErr == "" && err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		}
+		if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+			t.Errorf("%s: err = %v, want %q", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestDownPaymentRuleCRUD(t *testing.T) {
+	repo := &mockDPRuleRepo{rules: []model.DownPaymentRule{{ID: 5, MinPercent: 10}}}
+	uc := usecase.NewDownPaymentRuleUsecase(repo)
+
+	if err := uc.CreateRule(&model.DownPaymentRule{Category: model.AssetCategoryCar, Tenor: 12, MinPercent: 0}); err != nil {
+		t.Errorf("a 0%% rule should be accepted: %v", err)
+	}
+	for name, rule := range map[string]model.DownPaymentRule{
+		"category":    {Category: "boat", MinPercent: 10},
+		"tenor":       {Tenor: -1, MinPercent: 10},
+		"min_percent": {MinPercent: 100},
+	} {
+		if err := uc.CreateRule(&rule); err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("err = %v, want one mentioning %s", err, name)
+		}
+	}
+	if len(repo.created) != 1 {
+		t.Errorf("created %d rules, want 1", len(repo.created))
+	}
+
+	if err := uc.DeleteRule(5); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := uc.DeleteRule(6); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+	if len(repo.deleted) != 1 || repo.deleted[0] != 5 {
+		t.Errorf("deleted = %v, want [5]", repo.deleted)
+	}
+}
+
+type mockUpdateTxRepo struct {
+	repository.TransactionRepository
+	tx     model.Transaction
+	priced *model.Transaction
+}
+
+func (m *mockUpdateTxRepo) FindByID(id uint) (*model.Transaction, error) {
+	tx := m.tx
+	return &tx, nil
+}
+
+func (m *mockUpdateTxRepo) FindByIDForUpdate(db *gorm.DB, id uint) (*model.Transaction, error) {
+	return m.FindByID(id)
+}
+
+func (m *mockUpdateTxRepo) UpdatePricing(db *gorm.DB, tx *model.Transaction) error {
+	priced := *tx
+	m.priced = &priced
+	return nil
+}
+
+type mockUpdateLimitRepo struct {
+	repository.LimitRepository
+}
+
+func (m *mockUpdateLimitRepo) FindUsageByCustomerAndTenor(customerID uint, tenor int) (*repository.LimitUsage, error) {
+	return &repository.LimitUsage{Limit: model.Limit{CustomerID: customerID, Tenor: tenor, Limit: 10_000_000}, UsedAmount: 8_000_000}, nil
+}
+
+type mockUpdateDisbursementRepo struct {
+	repository.DisbursementRepository
+	d     model.Disbursement
+	saved *model.Disbursement
+}
+
+func (m *mockUpdateDisbursementRepo) FindByTransactionIDForUpdate(db *gorm.DB, transactionID uint) (*model.Disbursement, error) {
+	d := m.d
+	return &d, nil
+}
+
+func (m *mockUpdateDisbursementRepo) Save(db *gorm.DB, d *model.Disbursement) error {
+	m.saved = d
+	return nil
+}
+
+func newUpdateTransactionUsecase(t *testing.T, status, disbursementStatus string) (usecase.TransactionUsecase, *mockUpdateTxRepo, *mockUpdateDisbursementRepo) {
+	txRepo := &mockUpdateTxRepo{tx: model.Transaction{
+		ID: 7, ContractNumber: "CN-7", CustomerID: 3, Tenor: 6, Currency: "IDR",
+		OTR: 10_000_000, DownPayment: 2_000_000, Principal: 8_000_000, Status: status,
+	}}
+	disbursementRepo := &mockUpdateDisbursementRepo{d: model.Disbursement{ID: 9, TransactionID: 7, Amount: 8_000_000, Currency: "IDR", Status: disbursementStatus}}
+	uc := usecase.NewTransactionUsecase(
+		txRepo, &mockUpdateLimitRepo{}, nil, nil, nil, nil, nil, &mockDPRuleRepo{}, disbursementRepo,
+		&mockAuditor{}, usecase.TransactionPolicy{DirectPricing: usecase.PricingScheme{AdminFee: 50_000, InterestRateBps: 150}}, newTestDB(t),
+	)
+	return uc, txRepo, disbursementRepo
+}
+
+func TestUpdateTransaction_RepricesPendingContract(t *testing.T) {
+	uc, txRepo, disbursementRepo := newUpdateTransactionUsecase(t, model.TransactionStatusApproved, model.DisbursementStatusPending)
+
+	update := &model.Transaction{CustomerID: 3, ContractNumber: "CN-OTHER", Tenor: 6, OTR: 10_000_000, DownPayment: 1_000_000, AdminFee: 100_000, InterestAmount: 600_000, Status: model.TransactionStatusClosed}
+	if err := uc.UpdateTransaction(context.Background(), 7, update); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if txRepo.priced == nil || txRepo.priced.Principal != 9_000_000 || txRepo.priced.ContractNumber != "CN-7" {
+		t.Fatalf("priced = %+v, want principal 9000000 under CN-7", txRepo.priced)
+	}
+	// The direct scheme prices the contract, not the amounts the client sent.
+	if txRepo.priced.AdminFee != 50_000 || txRepo.priced.InterestAmount != 810_000 || txRepo.priced.InstallmentAmount != 1_635_000 {
+		t.Errorf("priced = %+v, want admin fee 50000, interest 810000 and installment 1635000", txRepo.priced)
+	}
+	// The dealer is paid the principal less the admin fee it collected.
+	if disbursementRepo.saved == nil || disbursementRepo.saved.Amount != 8_950_000 {
+		t.Errorf("disbursement = %+v, want its amount updated to 8950000", disbursementRepo.saved)
+	}
+}
+
+func TestUpdateTransaction_RejectsNegativeAmounts(t *testing.T) {
+	for _, update := range []*model.Transaction{
+		{CustomerID: 3, Tenor: 6, OTR: 10_000_000, DownPayment: 1_000_000, AdminFee: -1},
+		{CustomerID: 3, Tenor: 6, OTR: 10_000_000, DownPayment: 1_000_000, InterestAmount: -500_000},
+	} {
+		uc, txRepo, _ := newUpdateTransactionUsecase(t, model.TransactionStatusApproved, model.DisbursementStatusPending)
+		if err := uc.UpdateTransaction(context.Background(), 7, update); err == nil || !strings.Contains(err.Error(), "must not be negative") {
+			t.Errorf("update %+v: err = %v, want negative amounts rejected", update, err)
+		}
+		if txRepo.priced != nil {
+			t.Errorf("update %+v was stored", update)
+		}
+	}
+}
+
+func TestUpdateTransaction_RefusesDisbursedContracts(t *testing.T) {
+	cases := []struct {
+		status, disbursementStatus string
+	}{
+		{model.TransactionStatusOngoing, model.DisbursementStatusSucceeded},
+		{model.TransactionStatusApproved, model.DisbursementStatusProcessing},
+		{model.TransactionStatusCancelled, model.DisbursementStatusCancelled},
+	}
+	for _, tc := range cases {
+		uc, txRepo, disbursementRepo := newUpdateTransactionUsecase(t, tc.status, tc.disbursementStatus)
+		update := &model.Transaction{CustomerID: 3, Tenor: 6, OTR: 10_000_000, DownPayment: 1_000_000}
+		if err := uc.UpdateTransaction(context.Background(), 7, update); err == nil || !strings.Contains(err.Error(), "cannot update") {
+			t.Errorf("%s/%s: err = %v, want a refusal", tc.status, tc.disbursementStatus, err)
+		}
+		if txRepo.priced != nil || disbursementRepo.saved != nil {
+			t.Errorf("%s/%s: contract was written", tc.status, tc.disbursementStatus)
+		}
+	}
+}
+
+type mockPartnerTxRepo struct {
+	repository.TransactionRepository
+	created *model.Transaction
+	stored  model.Transaction
+}
+
+func (m *mockPartnerTxRepo) Create(db *gorm.DB, tx *model.Transaction) error {
+	created := *tx
+	m.created = &created
+	tx.ID = 21
+	return nil
+}
+
+func (m *mockPartnerTxRepo) FindByID(id uint) (*model.Transaction, error) {
+	if id != m.stored.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	tx := m.stored
+	return &tx, nil
+}
+
+type mockPartnerLimitRepo struct {
+	repository.LimitRepository
+}
+
+func (m *mockPartnerLimitRepo) FindUsageByCustomerAndTenor(customerID uint, tenor int) (*repository.LimitUsage, error) {
+	return &repository.LimitUsage{Limit: model.Limit{CustomerID: customerID, Tenor: tenor, Limit: 50_000_000}}, nil
+}
+
+type mockPartnerRepo struct {
+	repository.PartnerRepository
+	partners []model.Partner
+}
+
+func (m *mockPartnerRepo) FindByID(id uint) (*model.Partner, error) {
+	for _, partner := range m.partners {
+		if partner.ID == id {
+			return &partner, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type mockOutletRepo struct {
+	repository.OutletRepository
+	outlets []model.Outlet
+}
+
+func (m *mockOutletRepo) FindByID(id uint) (*model.Outlet, error) {
+	for _, outlet := range m.outlets {
+		if outlet.ID == id {
+			return &outlet, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type mockCatalogRepo struct {
+	repository.AssetRepository
+}
+
+func (m *mockCatalogRepo) FindByID(id uint) (*model.Asset, error) {
+	return &model.Asset{ID: id, Category: model.AssetCategoryMotorcycle, Brand: "Yamaha", Model: "NMAX", Year: 2024, OTRPrice: 30_000_000}, nil
+}
+
+type mockPartnerDisbursementRepo struct {
+	repository.DisbursementRepository
+	created *model.Disbursement
+}
+
+func (m *mockPartnerDisbursementRepo) Create(db *gorm.DB, d *model.Disbursement) error {
+	m.created = d
+	return nil
+}
+
+type partnerTransactionFixture struct {
+	uc               usecase.TransactionUsecase
+	txRepo           *mockPartnerTxRepo
+	disbursementRepo *mockPartnerDisbursementRepo
+}
+
+// newPartnerTransactionFixture has two partners, 1 with outlets 10 and 12
+// (inactive) and 2 with outlet 20, and an inactive partner 3 with outlet 30.
+func newPartnerTransactionFixture(t *testing.T) *partnerTransactionFixture {
+	f := &partnerTransactionFixture{txRepo: &mockPartnerTxRepo{}, disbursementRepo: &mockPartnerDisbursementRepo{}}
+	partners := &mockPartnerRepo{partners: []model.Partner{
+		{ID: 1, Code: "DLR1", AdminFee: 300_000, InterestRateBps: 150, Active: true},
+		{ID: 2, Code: "DLR2", AdminFee: 100_000, InterestRateBps: 200, Active: true},
+		{ID: 3, Code: "DLR3", AdminFee: 100_000, InterestRateBps: 200},
+	}}
+	outlets := &mockOutletRepo{outlets: []model.Outlet{
+		{ID: 10, PartnerID: 1, BankCode: "014", BankAccountNumber: "111", BankAccountName: "Dealer Satu", Active: true},
+		{ID: 12, PartnerID: 1},
+		{ID: 20, PartnerID: 2, Active: true},
+		{ID: 30, PartnerID: 3, Active: true},
+	}}
+	f.uc = usecase.NewTransactionUsecase(
+		f.txRepo, &mockPartnerLimitRepo{}, &mockCustomerRepo{}, nil, partners, outlets, &mockCatalogRepo{}, &mockDPRuleRepo{},
+		f.disbursementRepo, &mockAuditor{}, usecase.TransactionPolicy{OTRTolerancePercent: 5, InterestRounding: money.RoundHalfUp}, newTestDB(t),
+	)
+	return f
+}
+
+func partnerContract(outletID uint) *model.Transaction {
+	return &model.Transaction{
+		CustomerID: 3, Tenor: 6, OTR: 30_000_000, DownPayment: 6_000_000,
+		AssetID: ptrUint(5), OutletID: ptrUint(outletID), AdminFee: 1, InterestAmount: 1,
+	}
+}
+
+func TestCreatePartnerTransaction_PricesWithPartnerScheme(t *testing.T) {
+	f := newPartnerTransactionFixture(t)
+	tx := partnerContract(10)
+
+	if err := f.uc.CreatePartnerTransaction(context.Background(), 1, tx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	created := f.txRepo.created
+	// 24,000,000 financed at 1.5% a month over 6 months.
+	if created.PartnerID == nil || *created.PartnerID != 1 || created.Principal != 24_000_000 ||
+		created.AdminFee != 300_000 || created.InterestAmount != 2_160_000 || created.InstallmentAmount != 4_360_000 {
+		t.Errorf("created = %+v, want it priced with partner 1's scheme", created)
+	}
+	if created.AssetName != "Yamaha NMAX 2024" {
+		t.Errorf("asset name = %q, want it taken from the catalog", created.AssetName)
+	}
+	d := f.disbursementRepo.created
+	if d == nil || d.Amount != 23_700_000 || d.BankAccountNumber != "111" || d.Status != model.DisbursementStatusPending {
+		t.Errorf("disbursement = %+v, want the principal less the admin fee paid to outlet 10", d)
+	}
+}
+
+func TestCreatePartnerTransaction_IgnoresClientLifecycleFields(t *testing.T) {
+	f := newPartnerTransactionFixture(t)
+	disbursedAt := time.Now()
+	tx := partnerContract(10)
+	tx.ID = 99
+	tx.PartnerID = ptrUint(2)
+	tx.Status = model.TransactionStatusOngoing
+	tx.DisbursedAt = &disbursedAt
+
+	if err := f.uc.CreatePartnerTransaction(context.Background(), 1, tx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	created := f.txRepo.created
+	if created.ID != 0 || *created.PartnerID != 1 || created.Status != model.TransactionStatusApproved || created.DisbursedAt != nil {
+		t.Errorf("created = %+v, want a new approved contract of partner 1", created)
+	}
+}
+
+func TestCreatePartnerTransaction_ScopesOutletToPartner(t *testing.T) {
+	cases := []struct {
+		name      string
+		partnerID uint
+		tx        *model.Transaction
+		wantErr   string
+	}{
+		{"another partner's outlet", 1, partnerContract(20), "outlet not found"},
+		{"inactive outlet", 1, partnerContract(12), "outlet not found"},
+		{"unknown outlet", 1, partnerContract(99), "outlet not found"},
+		{"inactive partner", 3, partnerContract(30), "partner not found"},
+		{"no outlet", 1, &model.Transaction{CustomerID: 3, Tenor: 6, OTR: 30_000_000, AssetID: ptrUint(5)}, "outlet_id is required"},
+		{"no asset", 1, &model.Transaction{CustomerID: 3, Tenor: 6, OTR: 30_000_000, OutletID: ptrUint(10)}, "asset_id is required"},
+	}
+	for _, tc := range cases {
+		f := newPartnerTransactionFixture(t)
+		err := f.uc.CreatePartnerTransaction(context.Background(), tc.partnerID, tc.tx)
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("%s: err = %v, want %q", tc.name, err, tc.wantErr)
+		}
+		if f.txRepo.created != nil {
+			t.Errorf("%s: contract was created", tc.name)
+		}
+	}
+}
+
+func TestGetPartnerTransactionByID_ScopesToPartner(t *testing.T) {
+	f := newPartnerTransactionFixture(t)
+	f.txRepo.stored = model.Transaction{ID: 21, PartnerID: ptrUint(1)}
+
+	if tx, err := f.uc.GetPartnerTransactionByID(1, 21); err != nil || tx.ID != 21 {
+		t.Errorf("tx = %+v, err = %v, want partner 1 to see its contract", tx, err)
+	}
+	if _, err := f.uc.GetPartnerTransactionByID(2, 21); err == nil {
+		t.Error("partner 2 could read partner 1's contract")
+	}
+
+	f.txRepo.stored = model.Transaction{ID: 22}
+	if _, err := f.uc.GetPartnerTransactionByID(1, 22); err == nil {
+		t.Error("a partner could read a direct contract")
+	}
+}
+
+func TestGetTransactionsByNIK_ForbiddenForOtherUsers(t *testing.T) {
+	uc := usecase.NewTransactionUsecase(
+		nil, nil, customersOwnedBy(11), nil, nil, nil, nil, nil, nil,
+		&mockAuditor{}, usecase.TransactionPolicy{}, nil,
+	)
+
+	if _, err := uc.GetTransactionsByNIK("3201", usecase.Viewer{UserID: 12, Role: "customer"}); !errors.Is(err, usecase.ErrCustomerForbidden) {
+		t.Errorf("err = %v, want another user's transactions forbidden", err)
+	}
+}
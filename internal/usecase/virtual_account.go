@@ -0,0 +1,221 @@
+package usecase
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/pkg/vanumber"
+	"xyz-multifinance/pkg/webhooksig"
+)
+
+const PaymentChannelVirtualAccount = "virtual_account"
+
+// ErrInvalidWebhookSignature is returned for notifications that are not
+// signed with the shared secret or whose timestamp is too old.
+var ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+
+// VirtualAccountPolicy holds the bank's company prefix for generated numbers
+// and the secret and clock tolerance used to verify its notifications.
+type VirtualAccountPolicy struct {
+	Prefix           string
+	BankCode         string
+	WebhookSecret    string
+	WebhookTolerance time.Duration
+}
+
+// VANotification is the payment notification the bank posts to the
+// webhook.
+type VANotification struct {
+	VANumber      string `json:"va_number"`
+	Amount        int64  `json:"amount"`
+	BankReference string `json:"bank_reference"`
+	PaidAt        string `json:"paid_at"`
+}
+
+type VirtualAccountUsecase interface {
+	// CreateCustomerAccount, CreateContractAccount and GetCustomerAccounts
+	// return ErrCustomerForbidden unless the viewer is an admin or the
+	// customer's own user.
+	CreateCustomerAccount(nik string, viewer Viewer) (*model.VirtualAccount, error)
+	CreateContractAccount(transactionID uint, viewer Viewer) (*model.VirtualAccount, error)
+	GetCustomerAccounts(nik string, viewer Viewer) ([]model.VirtualAccount, error)
+	// HandleNotification verifies a signed payment notification and posts
+	// it as a repayment. The bank reference is the payment reference, so a
+	// notification delivered twice is only posted once; created is false
+	// for the repeat.
+	HandleNotification(header http.Header, body []byte) (payment *model.Payment, created bool, err error)
+}
+
+type virtualAccountUsecase struct {
+	vaRepo       repository.VirtualAccountRepository
+	customerRepo repository.CustomerRepository
+	txRepo       repository.TransactionRepository
+	paymentRepo  repository.PaymentRepository
+	paymentUC    PaymentUsecase
+	policy       VirtualAccountPolicy
+}
+
+func NewVirtualAccountUsecase(
+	vaRepo repository.VirtualAccountRepository,
+	customerRepo repository.CustomerRepository,
+	txRepo repository.TransactionRepository,
+	paymentRepo repository.PaymentRepository,
+	paymentUC PaymentUsecase,
+	policy VirtualAccountPolicy,
+) VirtualAccountUsecase {
+	return &virtualAccountUsecase{
+		vaRepo:       vaRepo,
+		customerRepo: customerRepo,
+		txRepo:       txRepo,
+		paymentRepo:  paymentRepo,
+		paymentUC:    paymentUC,
+		policy:       policy,
+	}
+}
+
+func (uc *virtualAccountUsecase) CreateCustomerAccount(nik string, viewer Viewer) (*model.VirtualAccount, error) {
+	customer, err := uc.customerRepo.FindByNIK(nik)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+	if err := authorizeCustomer(customer, viewer); err != nil {
+		return nil, err
+	}
+
+	return uc.create(vanumber.KindCustomer, customer.ID, &model.VirtualAccount{CustomerID: customer.ID})
+}
+
+func (uc *virtualAccountUsecase) CreateContractAccount(transactionID uint, viewer Viewer) (*model.VirtualAccount, error) {
+	tx, err := uc.txRepo.FindByID(transactionID)
+	if err != nil {
+		return nil, errors.New("transaction not found")
+	}
+	customer, err := uc.customerRepo.FindByID(tx.CustomerID)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+	if err := authorizeCustomer(customer, viewer); err != nil {
+		return nil, err
+	}
+	if !isActiveStatus(tx.Status) && tx.Status != model.TransactionStatusApproved {
+		return nil, fmt.Errorf("cannot open a virtual account for a %s transaction", tx.Status)
+	}
+
+	return uc.create(vanumber.KindContract, tx.ID, &model.VirtualAccount{CustomerID: tx.CustomerID, TransactionID: &tx.ID})
+}
+
+// create stores the account unless it already exists. Numbers are derived
+// from the owner's id, so asking twice returns the same account.
+func (uc *virtualAccountUsecase) create(kind vanumber.Kind, ownerID uint, account *model.VirtualAccount) (*model.VirtualAccount, error) {
+	number, err := vanumber.New(uc.policy.Prefix, kind, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if existing, err := uc.vaRepo.FindByNumber(number); err == nil {
+		return existing, nil
+	}
+
+	account.Number = number
+	account.BankCode = uc.policy.BankCode
+	account.Active = true
+	if err := uc.vaRepo.Create(account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (uc *virtualAccountUsecase) GetCustomerAccounts(nik string, viewer Viewer) ([]model.VirtualAccount, error) {
+	customer, err := uc.customerRepo.FindByNIK(nik)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+	if err := authorizeCustomer(customer, viewer); err != nil {
+		return nil, err
+	}
+	return uc.vaRepo.FindByCustomerID(customer.ID)
+}
+
+func (uc *virtualAccountUsecase) HandleNotification(header http.Header, body []byte) (*model.Payment, bool, error) {
+	if uc.policy.WebhookSecret == "" {
+		return nil, false, ErrInvalidWebhookSignature
+	}
+	err := webhooksig.Verify([]byte(uc.policy.WebhookSecret), header.Get(webhooksig.Header), body, time.Now(), uc.policy.WebhookTolerance)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrInvalidWebhookSignature, err)
+	}
+
+	var n VANotification
+	if err := json.Unmarshal(body, &n); err != nil {
+		return nil, false, errors.New("invalid notification body")
+	}
+	if n.BankReference == "" {
+		return nil, false, errors.New("bank_reference is required")
+	}
+	if !vanumber.Valid(n.VANumber) {
+		return nil, false, errors.New("invalid va_number")
+	}
+
+	var paidAt time.Time
+	if n.PaidAt != "" {
+		if paidAt, err = time.Parse(time.RFC3339, n.PaidAt); err != nil {
+			return nil, false, errors.New("invalid paid_at format, use RFC3339")
+		}
+	}
+
+	account, err := uc.vaRepo.FindByNumber(n.VANumber)
+	if err != nil || !account.Active {
+		return nil, false, errors.New("virtual account not found")
+	}
+
+	// A repeated notification must find the original payment even when the
+	// first one closed the contract it was posted to.
+	reference := fmt.Sprintf("VA-%s-%s", account.BankCode, n.BankReference)
+	if existing, err := uc.paymentRepo.FindByReference(reference); err == nil {
+		if existing.Amount != n.Amount {
+			return nil, false, errors.New("payment reference already used for a different payment")
+		}
+		return existing, false, nil
+	}
+
+	transactionID, err := uc.payee(account)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return uc.paymentUC.Pay(PaymentRequest{
+		TransactionID: transactionID,
+		Amount:        n.Amount,
+		Channel:       PaymentChannelVirtualAccount,
+		Reference:     reference,
+		PaidAt:        paidAt,
+	})
+}
+
+// payee is the contract a payment into the account is posted to: the
+// account's own contract, or the customer's oldest active one.
+func (uc *virtualAccountUsecase) payee(account *model.VirtualAccount) (uint, error) {
+	if account.TransactionID != nil {
+		return *account.TransactionID, nil
+	}
+
+	transactions, err := uc.txRepo.FindByCustomerID(account.CustomerID)
+	if err != nil {
+		return 0, err
+	}
+	var payee *model.Transaction
+	for i := range transactions {
+		tx := &transactions[i]
+		if isActiveStatus(tx.Status) && (payee == nil || tx.ID < payee.ID) {
+			payee = tx
+		}
+	}
+	if payee == nil {
+		return 0, errors.New("customer has no active contract")
+	}
+	return payee.ID, nil
+}
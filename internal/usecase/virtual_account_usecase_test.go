@@ -0,0 +1,216 @@
+package usecase_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/pkg/vanumber"
+	"xyz-multifinance/pkg/webhooksig"
+
+	"gorm.io/gorm"
+)
+
+const testWebhookSecret = "webhook-secret"
+
+type mockVATxRepo struct {
+	repository.TransactionRepository
+	transactions []model.Transaction
+}
+
+func (m *mockVATxRepo) FindByCustomerID(customerID uint) ([]model.Transaction, error) {
+	return m.transactions, nil
+}
+
+func (m *mockVATxRepo) FindByID(id uint) (*model.Transaction, error) {
+	for i := range m.transactions {
+		if m.transactions[i].ID == id {
+			tx := m.transactions[i]
+			return &tx, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type mockVAPaymentUsecase struct {
+	usecase.PaymentUsecase
+	requests []usecase.PaymentRequest
+}
+
+func (m *mockVAPaymentUsecase) Pay(req usecase.PaymentRequest) (*model.Payment, bool, error) {
+	m.requests = append(m.requests, req)
+	return &model.Payment{ID: 40, TransactionID: req.TransactionID, Amount: req.Amount, Reference: req.Reference}, true, nil
+}
+
+type notificationFixture struct {
+	uc          usecase.VirtualAccountUsecase
+	paymentRepo *mockPayPaymentRepo
+	paymentUC   *mockVAPaymentUsecase
+	number      string
+}
+
+// newNotificationFixture opens an account for customer 3, tied to contract
+// 7 when contract is true.
+func newNotificationFixture(t *testing.T, contract bool, transactions ...model.Transaction) *notificationFixture {
+	kind, ownerID, account := vanumber.KindCustomer, uint(3), model.VirtualAccount{BankCode: "014", CustomerID: 3, Active: true}
+	if contract {
+		kind, ownerID, account.TransactionID = vanumber.KindContract, 7, ptrUint(7)
+	}
+	number, err := vanumber.New("8808", kind, ownerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	account.Number = number
+
+	f := &notificationFixture{paymentRepo: &mockPayPaymentRepo{}, paymentUC: &mockVAPaymentUsecase{}, number: number}
+	f.uc = usecase.NewVirtualAccountUsecase(
+		&mockReconVARepo{account: account}, nil, &mockVATxRepo{transactions: transactions}, f.paymentRepo, f.paymentUC,
+		usecase.VirtualAccountPolicy{Prefix: "8808", BankCode: "014", WebhookSecret: testWebhookSecret, WebhookTolerance: 5 * time.Minute},
+	)
+	return f
+}
+
+func (f *notificationFixture) notify(amount int64, signedAt time.Time, secret string) (*model.Payment, bool, error) {
+	body := []byte(fmt.Sprintf(`{"va_number":%q,"amount":%d,"bank_reference":"BR-1","paid_at":"2024-06-10T09:30:00+07:00"}`, f.number, amount))
+	header := http.Header{}
+	header.Set(webhooksig.Header, webhooksig.Sign([]byte(secret), signedAt, body))
+	return f.uc.HandleNotification(header, body)
+}
+
+func TestHandleNotification_PostsToContractAccount(t *testing.T) {
+	f := newNotificationFixture(t, true)
+
+	payment, created, err := f.notify(1000, time.Now(), testWebhookSecret)
+	if err != nil || !created || payment.ID != 40 {
+		t.Fatalf("payment = %+v, created = %v, err = %v", payment, created, err)
+	}
+	if len(f.paymentUC.requests) != 1 {
+		t.Fatalf("posted %d payments, want 1", len(f.paymentUC.requests))
+	}
+	req := f.paymentUC.requests[0]
+	paidAt := time.Date(2024, 6, 10, 2, 30, 0, 0, time.UTC)
+	if req.TransactionID != 7 || req.Amount != 1000 || req.Reference != "VA-014-BR-1" ||
+		req.Channel != usecase.PaymentChannelVirtualAccount || !req.PaidAt.Equal(paidAt) {
+		t.Errorf("request = %+v", req)
+	}
+}
+
+func TestHandleNotification_CustomerAccountPaysOldestActiveContract(t *testing.T) {
+	f := newNotificationFixture(t, false,
+		model.Transaction{ID: 12, Status: model.TransactionStatusOngoing},
+		model.Transaction{ID: 9, Status: model.TransactionStatusClosed},
+		model.Transaction{ID: 10, Status: model.TransactionStatusOngoing},
+		model.Transaction{ID: 11, Status: model.TransactionStatusApproved},
+	)
+
+	if _, _, err := f.notify(1000, time.Now(), testWebhookSecret); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(f.paymentUC.requests) != 1 || f.paymentUC.requests[0].TransactionID != 10 {
+		t.Errorf("requests = %+v, want the payment posted to contract 10", f.paymentUC.requests)
+	}
+
+	idle := newNotificationFixture(t, false, model.Transaction{ID: 9, Status: model.TransactionStatusClosed})
+	if _, _, err := idle.notify(1000, time.Now(), testWebhookSecret); err == nil || !strings.Contains(err.Error(), "no active contract") {
+		t.Errorf("err = %v, want the payment refused", err)
+	}
+}
+
+func TestHandleNotification_RepeatedDelivery(t *testing.T) {
+	f := newNotificationFixture(t, true)
+	f.paymentRepo.existing = &model.Payment{ID: 31, TransactionID: 7, Amount: 1000, Reference: "VA-014-BR-1"}
+
+	payment, created, err := f.notify(1000, time.Now(), testWebhookSecret)
+	if err != nil || created || payment.ID != 31 {
+		t.Errorf("payment = %+v, created = %v, err = %v, want the original payment", payment, created, err)
+	}
+	if _, _, err := f.notify(1500, time.Now(), testWebhookSecret); err == nil || !strings.Contains(err.Error(), "different payment") {
+		t.Errorf("err = %v, want a changed amount refused", err)
+	}
+	if len(f.paymentUC.requests) != 0 {
+		t.Errorf("a repeated notification was posted again: %+v", f.paymentUC.requests)
+	}
+}
+
+func TestHandleNotification_RejectsBadSignatures(t *testing.T) {
+	cases := []struct {
+		name     string
+		signedAt time.Time
+		secret   string
+	}{
+		{"wrong secret", time.Now(), "guess"},
+		{"expired", time.Now().Add(-10 * time.Minute), testWebhookSecret},
+		{"from the future", time.Now().Add(10 * time.Minute), testWebhookSecret},
+	}
+	for _, tc := range cases {
+		f := newNotificationFixture(t, true)
+		if _, _, err := f.notify(1000, tc.signedAt, tc.secret); !errors.Is(err, usecase.ErrInvalidWebhookSignature) {
+			t.Errorf("%s: err = %v, want an invalid signature", tc.name, err)
+		}
+		if len(f.paymentUC.requests) != 0 {
+			t.Errorf("%s: payment was posted", tc.name)
+		}
+	}
+
+	f := newNotificationFixture(t, true)
+	body := []byte(`{"va_number":"` + f.number + `","amount":1000,"bank_reference":"BR-1"}`)
+	if _, _, err := f.uc.HandleNotification(http.Header{}, body); !errors.Is(err, usecase.ErrInvalidWebhookSignature) {
+		t.Errorf("unsigned: err = %v, want an invalid signature", err)
+	}
+}
+
+func TestHandleNotification_RejectsUnknownAccounts(t *testing.T) {
+	f := newNotificationFixture(t, true)
+	other, err := vanumber.New("8808", vanumber.KindContract, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, number := range map[string]string{"invalid va_number": "12345", "virtual account not found": other} {
+		f.number = number
+		if _, _, err := f.notify(1000, time.Now(), testWebhookSecret); err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("err = %v, want %q", err, name)
+		}
+	}
+}
+
+func TestVirtualAccounts_OnlyForOwnerOrAdmin(t *testing.T) {
+	customer := &model.Customer{ID: 3, UserID: 11}
+	customerRepo := &mockCustomerRepo{
+		FindByNIKFunc: func(nik string) (*model.Customer, error) { return customer, nil },
+		FindByIDFunc:  func(id uint) (*model.Customer, error) { return customer, nil },
+	}
+	number, err := vanumber.New("8808", vanumber.KindCustomer, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc := usecase.NewVirtualAccountUsecase(
+		&mockReconVARepo{account: model.VirtualAccount{Number: number, CustomerID: 3, Active: true}}, customerRepo,
+		&mockVATxRepo{transactions: []model.Transaction{{ID: 7, CustomerID: 3, Status: model.TransactionStatusOngoing}}},
+		&mockPayPaymentRepo{}, &mockVAPaymentUsecase{}, usecase.VirtualAccountPolicy{Prefix: "8808", BankCode: "014"},
+	)
+
+	stranger := usecase.Viewer{UserID: 12, Role: "customer"}
+	if _, err := uc.CreateCustomerAccount("3201", stranger); !errors.Is(err, usecase.ErrCustomerForbidden) {
+		t.Errorf("create customer account: err = %v, want forbidden", err)
+	}
+	if _, err := uc.CreateContractAccount(7, stranger); !errors.Is(err, usecase.ErrCustomerForbidden) {
+		t.Errorf("create contract account: err = %v, want forbidden", err)
+	}
+	if _, err := uc.GetCustomerAccounts("3201", stranger); !errors.Is(err, usecase.ErrCustomerForbidden) {
+		t.Errorf("list accounts: err = %v, want forbidden", err)
+	}
+
+	for _, viewer := range []usecase.Viewer{{UserID: 11, Role: "customer"}, {UserID: 1, Role: "admin"}} {
+		account, err := uc.CreateCustomerAccount("3201", viewer)
+		if err != nil || account.Number != number {
+			t.Errorf("%+v: account = %+v, err = %v, want the customer's account", viewer, account, err)
+		}
+	}
+}
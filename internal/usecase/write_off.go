@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+
+	"gorm.io/gorm"
+)
+
+type WriteOffRequest struct {
+	TransactionID uint
+	Note          string
+	WrittenOffBy  uint
+}
+
+type WriteOffUsecase interface {
+	// WriteOff gives up on collecting an active contract. Its receivables are
+	// cleared to the write-off expense account and the contract is marked
+	// written off.
+	WriteOff(req WriteOffRequest) (*model.JournalEntry, error)
+}
+
+type writeOffUsecase struct {
+	txRepo     repository.TransactionRepository
+	ledgerRepo repository.LedgerRepository
+	db         *gorm.DB
+}
+
+func NewWriteOffUsecase(
+	txRepo repository.TransactionRepository,
+	ledgerRepo repository.LedgerRepository,
+	db *gorm.DB,
+) WriteOffUsecase {
+	return &writeOffUsecase{
+		txRepo:     txRepo,
+		ledgerRepo: ledgerRepo,
+		db:         db,
+	}
+}
+
+func (uc *writeOffUsecase) WriteOff(req WriteOffRequest) (*model.JournalEntry, error) {
+	var entry *model.JournalEntry
+	err := uc.db.Transaction(func(txDB *gorm.DB) error {
+		tx, err := uc.txRepo.FindByIDForUpdate(txDB, req.TransactionID)
+		if err != nil {
+			return errors.New("transaction not found")
+		}
+		if !isActiveStatus(tx.Status) {
+			return fmt.Errorf("cannot write off a %s transaction", tx.Status)
+		}
+
+		balances, err := uc.ledgerRepo.ContractBalances(txDB, tx.ID)
+		if err != nil {
+			return err
+		}
+
+		description := fmt.Sprintf("Write-off of contract %s by user %d", tx.ContractNumber, req.WrittenOffBy)
+		if req.Note != "" {
+			description += ": " + req.Note
+		}
+		entry = writeOffEntry(tx.ID, balances, time.Now(), description)
+		if err := postEntry(txDB, uc.ledgerRepo, entry); err != nil {
+			return err
+		}
+
+		return uc.txRepo.UpdateFields(txDB, tx.ID, map[string]interface{}{
+			"status": model.TransactionStatusWrittenOff,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,83 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"xyz-multifinance/pkg/redact"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+// GormLogger sends GORM's output through Log, so that RedactHook applies to
+// it, and logs SQL statements with the values of sensitive columns replaced
+// before they are interpolated.
+type GormLogger struct {
+	level         gormLogger.LogLevel
+	slowThreshold time.Duration
+}
+
+func NewGormLogger(level gormLogger.LogLevel) *GormLogger {
+	return &GormLogger{level: level, slowThreshold: 200 * time.Millisecond}
+}
+
+func (l *GormLogger) LogMode(level gormLogger.LogLevel) gormLogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormLogger.Info {
+		Log.WithField("source", utils.FileWithLineNum()).Infof(msg, args...)
+	}
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormLogger.Warn {
+		Log.WithField("source", utils.FileWithLineNum()).Warnf(msg, args...)
+	}
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormLogger.Error {
+		Log.WithField("source", utils.FileWithLineNum()).Errorf(msg, args...)
+	}
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormLogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+	slow := elapsed > l.slowThreshold
+	if !(failed && l.level >= gormLogger.Error) && !(slow && l.level >= gormLogger.Warn) && l.level < gormLogger.Info {
+		return
+	}
+
+	sql, rows := fc()
+	entry := Log.WithFields(logrus.Fields{
+		"source":   utils.FileWithLineNum(),
+		"duration": elapsed,
+		"rows":     rows,
+	})
+	switch {
+	case failed && l.level >= gormLogger.Error:
+		entry.WithField("error", err).Error(sql)
+	case slow && l.level >= gormLogger.Warn:
+		entry.Warn(sql)
+	default:
+		entry.Info(sql)
+	}
+}
+
+// ParamsFilter is called by GORM with the bound parameters of a statement
+// before they are interpolated into the SQL handed to Trace.
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, redact.SQLParams(sql, params)
+}
@@ -21,4 +21,5 @@ func Setup() {
 
 	Log.SetOutput(os.Stdout)
 	Log.SetLevel(logrus.InfoLevel)
+	Log.AddHook(RedactHook{})
 }
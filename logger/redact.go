@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"xyz-multifinance/pkg/redact"
+
+	"github.com/sirupsen/logrus"
+)
+
+// RedactHook scrubs NIKs, passwords, tokens and salaries from every entry
+// before it is written: fields with a sensitive name are blanked and the
+// message and text fields are passed through redact.String.
+type RedactHook struct{}
+
+func (RedactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (RedactHook) Fire(entry *logrus.Entry) error {
+	entry.Message = redact.String(entry.Message)
+	for key, value := range entry.Data {
+		if redact.IsSensitiveKey(key) {
+			entry.Data[key] = redact.Placeholder
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			entry.Data[key] = redact.String(v)
+		case error:
+			entry.Data[key] = redact.String(v.Error())
+		}
+	}
+	return nil
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
 	"xyz-multifinance/config"
 	"xyz-multifinance/database"
 	"xyz-multifinance/logger"
@@ -17,18 +19,39 @@ func main() {
 	// Init Logger
 	logger.Setup()
 
+	// Init Encryption
+	routing.ConfigureEncryption(cfg)
+
 	// Init Database
 	db, err := database.Connect(cfg)
 	if err != nil {
 		log.Fatalf("failed to connect to database: %v", err)
 	}
 
+	// Run a maintenance command instead of the server, e.g. `./main migrate up`
+	if len(os.Args) > 1 {
+		if err := routing.RunCommand(context.Background(), db, cfg, os.Args[1:]); err != nil {
+			log.Fatalf("%s failed: %v", os.Args[1], err)
+		}
+		return
+	}
+
+	// Refuse to serve against a schema the binary does not expect
+	if err := database.CheckSchema(db); err != nil {
+		log.Fatalf("%v", err)
+	}
+
 	// Init Router
 	r := gin.Default()
 
 	// Register Routes
 	routing.SetupRoutes(r, db, cfg)
 
+	// Start Background Jobs
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	routing.StartJobs(ctx, db, cfg)
+
 	// Run Server
 	if err := r.Run(":" + cfg.AppPort); err != nil {
 		log.Fatalf("failed to run server: %v", err)
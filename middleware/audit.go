@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/logger"
+	"xyz-multifinance/pkg/audit"
+	"xyz-multifinance/pkg/redact"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Audit attributes the request to the authenticated user or partner, so
+// usecases can record who changed what. It must run after Auth or
+// PartnerAuth; on provider webhooks, which neither authenticates, the
+// request is attributed to the system. A successful state-changing request
+// for which no usecase recorded an entry is logged as a request-level entry,
+// so every change leaves a trace even where no before/after diff is kept.
+func Audit(auditor usecase.Auditor) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actor := audit.Actor{
+			UserID:    c.GetUint("user_id"),
+			Role:      c.GetString("role"),
+			PartnerID: c.GetUint("partner_id"),
+			IP:        c.ClientIP(),
+			RequestID: c.GetString("request_id"),
+		}
+		if actor.UserID == 0 && actor.PartnerID == 0 {
+			actor.Role = audit.SystemRole
+		}
+		ctx := audit.NewContext(c.Request.Context(), actor)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if !isStateChanging(c.Request.Method) || c.Writer.Status() >= http.StatusBadRequest || audit.Recorded(ctx) {
+			return
+		}
+		err := auditor.Record(ctx, usecase.AuditEvent{
+			Entity:   model.AuditEntityRequest,
+			EntityID: c.FullPath(),
+			Action:   c.Request.Method,
+			After: map[string]interface{}{
+				// Paths carry NIKs, e.g. /customers/:nik/virtual-accounts.
+				"path":   redact.String(c.Request.URL.Path),
+				"status": c.Writer.Status(),
+			},
+		})
+		if err != nil {
+			logger.Log.WithError(err).Error("failed to record audit entry for request")
+		}
+	}
+}
+
+func isStateChanging(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
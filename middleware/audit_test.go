@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/pkg/audit"
+
+	"github.com/gin-gonic/gin"
+)
+
+type recordingAuditor struct {
+	events []usecase.AuditEvent
+	actors []audit.Actor
+}
+
+func (a *recordingAuditor) Record(ctx context.Context, event usecase.AuditEvent) error {
+	actor, _ := audit.ActorFrom(ctx)
+	a.events = append(a.events, event)
+	a.actors = append(a.actors, actor)
+	return nil
+}
+
+func TestAudit_RecordsRouteAndMasksNIKInPath(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	auditor := &recordingAuditor{}
+	r := gin.New()
+	r.POST("/customers/:nik/virtual-accounts", func(c *gin.Context) {
+		c.Set("user_id", uint(5))
+		c.Set("role", "user")
+		c.Next()
+	}, Audit(auditor), func(c *gin.Context) {
+		c.Status(http.StatusCreated)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/customers/3201010101010001/virtual-accounts", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(auditor.events) != 1 {
+		t.Fatalf("recorded %d entries, want 1", len(auditor.events))
+	}
+	event := auditor.events[0]
+	if event.Entity != model.AuditEntityRequest || event.EntityID != "/customers/:nik/virtual-accounts" {
+		t.Errorf("entity = %s %s, want the route", event.Entity, event.EntityID)
+	}
+	path := event.After.(map[string]interface{})["path"]
+	if path != "/customers/3201********0001/virtual-accounts" {
+		t.Errorf("path = %v, want the NIK masked", path)
+	}
+	if auditor.actors[0].UserID != 5 || auditor.actors[0].Role != "user" {
+		t.Errorf("actor = %+v, want user 5", auditor.actors[0])
+	}
+}
+
+func TestAudit_AttributesWebhooksToSystem(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	auditor := &recordingAuditor{}
+	r := gin.New()
+	r.POST("/webhooks/va-payments", Audit(auditor), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/webhooks/va-payments", nil))
+
+	if len(auditor.actors) != 1 || auditor.actors[0].Role != audit.SystemRole {
+		t.Fatalf("actors = %+v, want one system actor", auditor.actors)
+	}
+}
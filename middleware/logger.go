@@ -27,6 +27,7 @@ func Logger() gin.HandlerFunc {
 			"duration":   duration,
 			"client_ip":  c.ClientIP(),
 			"user_agent": c.Request.UserAgent(),
+			"request_id": c.GetString("request_id"),
 		})
 
 		if len(c.Errors) > 0 {
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+
+	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/logger"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PartnerAuth authenticates dealer integrations by their X-API-Key header and
+// scopes the request to that partner.
+func PartnerAuth(partnerUC usecase.PartnerUsecase) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader("X-API-Key")
+		if key == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: no api key provided"})
+			return
+		}
+
+		partner, err := partnerUC.Authenticate(key)
+		if err != nil {
+			logger.Log.Warnf("failed to authenticate partner: %v", err)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: invalid api key"})
+			return
+		}
+
+		c.Set("partner_id", partner.ID)
+
+		c.Next()
+	}
+}
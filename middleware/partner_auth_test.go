@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+type stubPartnerUsecase struct {
+	usecase.PartnerUsecase
+	keys map[string]model.Partner
+}
+
+func (s *stubPartnerUsecase) Authenticate(key string) (*model.Partner, error) {
+	partner, ok := s.keys[key]
+	if !ok {
+		return nil, errors.New("invalid api key")
+	}
+	return &partner, nil
+}
+
+func TestPartnerAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	previous := logger.Log
+	logger.Log = logrus.New()
+	logger.Log.SetOutput(io.Discard)
+	t.Cleanup(func() { logger.Log = previous })
+
+	partners := &stubPartnerUsecase{keys: map[string]model.Partner{"pk_live.secret": {ID: 4, Active: true}}}
+	r := gin.New()
+	r.GET("/partner/transactions", PartnerAuth(partners), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"partner_id": c.GetUint("partner_id")})
+	})
+
+	cases := []struct {
+		name       string
+		key        string
+		wantStatus int
+		wantBody   string
+	}{
+		{"valid key", "pk_live.secret", http.StatusOK, `{"partner_id":4}`},
+		{"no key", "", http.StatusUnauthorized, `{"error":"Unauthorized: no api key provided"}`},
+		{"unknown key", "pk_live.guess", http.StatusUnauthorized, `{"error":"Unauthorized: invalid api key"}`},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/partner/transactions", nil)
+		if tc.key != "" {
+			req.Header.Set("X-API-Key", tc.key)
+		}
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		if rec.Code != tc.wantStatus || rec.Body.String() != tc.wantBody {
+			t.Errorf("%s: got %d %s, want %d %s", tc.name, rec.Code, rec.Body.String(), tc.wantStatus, tc.wantBody)
+		}
+	}
+}
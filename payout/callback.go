@@ -0,0 +1,32 @@
+package payout
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// CallbackTokenHeader carries the shared secret the local providers expect
+// on callbacks.
+const CallbackTokenHeader = "X-Callback-Token"
+
+// parseTokenCallback checks the shared callback token and decodes a JSON
+// Callback body.
+func parseTokenCallback(token string, header http.Header, body []byte) (*Callback, error) {
+	if token == "" || subtle.ConstantTimeCompare([]byte(header.Get(CallbackTokenHeader)), []byte(token)) != 1 {
+		return nil, fmt.Errorf("%w: bad callback token", ErrInvalidCallback)
+	}
+
+	var cb Callback
+	if err := json.Unmarshal(body, &cb); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCallback, err)
+	}
+	if cb.Reference == "" {
+		return nil, fmt.Errorf("%w: missing reference", ErrInvalidCallback)
+	}
+	if cb.Status != StatusSucceeded && cb.Status != StatusFailed {
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidCallback, cb.Status)
+	}
+	return &cb, nil
+}
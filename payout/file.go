@@ -0,0 +1,75 @@
+package payout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileProvider writes each payout instruction as a JSON line to a daily file
+// in Dir, the way a bank host-to-host batch would be handed over. Payouts stay
+// processing until a callback reports their outcome.
+type FileProvider struct {
+	Dir           string
+	CallbackToken string
+
+	mu sync.Mutex
+}
+
+func NewFileProvider(dir, callbackToken string) *FileProvider {
+	return &FileProvider{Dir: dir, CallbackToken: callbackToken}
+}
+
+func (p *FileProvider) Name() string {
+	return "file"
+}
+
+type fileInstruction struct {
+	Request
+	ProviderReference string    `json:"provider_reference"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+func (p *FileProvider) Send(ctx context.Context, req Request) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	instruction := fileInstruction{
+		Request:           req,
+		ProviderReference: fmt.Sprintf("FILE-%s-%d", req.Reference, now.UnixNano()),
+		CreatedAt:         now,
+	}
+	line, err := json.Marshal(instruction)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := os.MkdirAll(p.Dir, 0o750); err != nil {
+		return nil, err
+	}
+	name := filepath.Join(p.Dir, "payouts-"+now.Format("20060102")+".jsonl")
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o640)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return nil, err
+	}
+	return &Result{ProviderReference: instruction.ProviderReference, Status: StatusProcessing}, nil
+}
+
+func (p *FileProvider) ParseCallback(header http.Header, body []byte) (*Callback, error) {
+	return parseTokenCallback(p.CallbackToken, header, body)
+}
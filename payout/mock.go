@@ -0,0 +1,76 @@
+package payout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// MockProvider completes payouts in memory. By default every payout
+// succeeds immediately; FailNext and Async change that for tests and local
+// runs.
+type MockProvider struct {
+	CallbackToken string
+
+	mu       sync.Mutex
+	async    bool
+	failNext int
+	requests []Request
+}
+
+func NewMockProvider(callbackToken string) *MockProvider {
+	return &MockProvider{CallbackToken: callbackToken}
+}
+
+func (p *MockProvider) Name() string {
+	return "mock"
+}
+
+// FailNext makes the next n calls to Send fail with a retryable error.
+func (p *MockProvider) FailNext(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.failNext = n
+}
+
+// Async makes Send report payouts as processing, to be completed by a
+// callback.
+func (p *MockProvider) Async(async bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.async = async
+}
+
+// Requests returns every payout request received so far.
+func (p *MockProvider) Requests() []Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Request(nil), p.requests...)
+}
+
+func (p *MockProvider) Send(ctx context.Context, req Request) (*Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests = append(p.requests, req)
+
+	if p.failNext > 0 {
+		p.failNext--
+		return nil, errors.New("mock provider unavailable")
+	}
+
+	status := StatusSucceeded
+	if p.async {
+		status = StatusProcessing
+	}
+	return &Result{ProviderReference: fmt.Sprintf("MOCK-%d", len(p.requests)), Status: status}, nil
+}
+
+func (p *MockProvider) ParseCallback(header http.Header, body []byte) (*Callback, error) {
+	return parseTokenCallback(p.CallbackToken, header, body)
+}
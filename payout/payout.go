@@ -0,0 +1,54 @@
+// Package payout sends disbursements to dealers through a payout provider
+// and interprets the provider's callbacks.
+package payout
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+type Status string
+
+const (
+	// StatusProcessing means the provider accepted the payout and will
+	// report the outcome in a callback.
+	StatusProcessing Status = "processing"
+	StatusSucceeded  Status = "succeeded"
+	StatusFailed     Status = "failed"
+)
+
+// ErrInvalidCallback is returned by ParseCallback for requests that are not
+// authentic or cannot be read.
+var ErrInvalidCallback = errors.New("payout: invalid callback")
+
+type Request struct {
+	Reference     string `json:"reference"`
+	Amount        int64  `json:"amount"`
+	Currency      string `json:"currency"`
+	BankCode      string `json:"bank_code"`
+	AccountNumber string `json:"account_number"`
+	AccountName   string `json:"account_name"`
+}
+
+type Result struct {
+	ProviderReference string
+	Status            Status
+	Reason            string
+}
+
+type Callback struct {
+	Reference         string `json:"reference"`
+	ProviderReference string `json:"provider_reference"`
+	Status            Status `json:"status"`
+	Reason            string `json:"reason"`
+}
+
+// Provider moves money to a bank account. Send returns an error only for
+// failures worth retrying, such as timeouts; a payout the provider refused
+// is reported as a Result with StatusFailed.
+type Provider interface {
+	Name() string
+	Send(ctx context.Context, req Request) (*Result, error)
+	ParseCallback(header http.Header, body []byte) (*Callback, error)
+}
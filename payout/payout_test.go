@@ -0,0 +1,51 @@
+package payout
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestMockProvider_FailNextThenSucceeds(t *testing.T) {
+	p := NewMockProvider("secret")
+	p.FailNext(1)
+
+	req := Request{Reference: "DSB-1", Amount: 1000, Currency: "IDR"}
+	if _, err := p.Send(context.Background(), req); err == nil {
+		t.Fatal("expected the first send to fail")
+	}
+	res, err := p.Send(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if res.Status != StatusSucceeded {
+		t.Errorf("status = %s, want %s", res.Status, StatusSucceeded)
+	}
+	if got := len(p.Requests()); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+}
+
+func TestParseCallback_RequiresToken(t *testing.T) {
+	p := NewMockProvider("secret")
+	body := []byte(`{"reference":"DSB-1","provider_reference":"MOCK-1","status":"succeeded"}`)
+
+	if _, err := p.ParseCallback(http.Header{}, body); !errors.Is(err, ErrInvalidCallback) {
+		t.Fatalf("expected ErrInvalidCallback without a token, got %v", err)
+	}
+
+	header := http.Header{}
+	header.Set(CallbackTokenHeader, "secret")
+	cb, err := p.ParseCallback(header, body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cb.Reference != "DSB-1" || cb.Status != StatusSucceeded {
+		t.Errorf("callback = %+v", cb)
+	}
+
+	if _, err := p.ParseCallback(header, []byte(`{"reference":"DSB-1","status":"done"}`)); !errors.Is(err, ErrInvalidCallback) {
+		t.Fatalf("expected ErrInvalidCallback for an unknown status, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+const prefix = "xyzp"
+
+// Generate returns a new partner API key in the form xyzp_<id>_<secret>
+// together with its public id and the hash that should be stored.
+func Generate() (key, id, hash string, err error) {
+	idBytes := make([]byte, 6)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", "", "", err
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+
+	id = hex.EncodeToString(idBytes)
+	key = prefix + "_" + id + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	return key, id, Hash(key), nil
+}
+
+// ID extracts the public id from a key so the partner can be looked up
+// before the secret is compared.
+func ID(key string) (string, error) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != prefix || parts[1] == "" || parts[2] == "" {
+		return "", errors.New("malformed api key")
+	}
+	return parts[1], nil
+}
+
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func Matches(key, hash string) bool {
+	return subtle.ConstantTimeCompare([]byte(Hash(key)), []byte(hash)) == 1
+}
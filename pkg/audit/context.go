@@ -0,0 +1,57 @@
+// Package audit carries the actor of a request through context.Context and
+// computes the before/after changes recorded in the audit log.
+package audit
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// Actor is who performed an operation and through which request.
+// PartnerID is set for dealer integrations authenticated by API key.
+type Actor struct {
+	UserID    uint
+	Role      string
+	PartnerID uint
+	IP        string
+	RequestID string
+}
+
+// SystemRole is the role recorded for changes the application makes on its
+// own: background jobs and provider webhooks.
+const SystemRole = "system"
+
+type scope struct {
+	actor    Actor
+	recorded atomic.Int32
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx that carries actor.
+func NewContext(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, contextKey{}, &scope{actor: actor})
+}
+
+// ActorFrom returns the actor carried by ctx. Operations started outside a
+// request, such as background jobs, report false.
+func ActorFrom(ctx context.Context) (Actor, bool) {
+	s, ok := ctx.Value(contextKey{}).(*scope)
+	if !ok {
+		return Actor{}, false
+	}
+	return s.actor, true
+}
+
+// MarkRecorded notes that an entry was written for the request in ctx.
+func MarkRecorded(ctx context.Context) {
+	if s, ok := ctx.Value(contextKey{}).(*scope); ok {
+		s.recorded.Add(1)
+	}
+}
+
+// Recorded reports whether any entry was written for the request in ctx.
+func Recorded(ctx context.Context) bool {
+	s, ok := ctx.Value(contextKey{}).(*scope)
+	return ok && s.recorded.Load() > 0
+}
@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"xyz-multifinance/pkg/redact"
+)
+
+// Change is the value of a field before and after an operation. From is nil
+// for created records and To is nil for deleted ones.
+type Change struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// ignoredFields change on every write and carry no information.
+var ignoredFields = map[string]bool{"updated_at": true}
+
+// Diff compares the JSON forms of before and after, either of which may be
+// nil, and returns the fields that differ. Values of sensitive fields, and of
+// the fields named in redactFields, are replaced with a placeholder so the
+// log records that they changed but not what they hold.
+func Diff(before, after interface{}, redactFields ...string) (map[string]Change, error) {
+	from, err := toFields(before)
+	if err != nil {
+		return nil, err
+	}
+	to, err := toFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	redacted := make(map[string]bool, len(redactFields))
+	for _, field := range redactFields {
+		redacted[field] = true
+	}
+
+	changes := make(map[string]Change)
+	for _, fields := range []map[string]interface{}{from, to} {
+		for field := range fields {
+			if _, seen := changes[field]; seen || ignoredFields[field] {
+				continue
+			}
+			oldValue, newValue := from[field], to[field]
+			if reflect.DeepEqual(oldValue, newValue) {
+				continue
+			}
+			if redacted[field] || redact.IsSensitiveKey(field) {
+				oldValue, newValue = placeholder(oldValue), placeholder(newValue)
+			}
+			changes[field] = Change{From: oldValue, To: newValue}
+		}
+	}
+	return changes, nil
+}
+
+func toFields(v interface{}) (map[string]interface{}, error) {
+	if v == nil || (reflect.ValueOf(v).Kind() == reflect.Pointer && reflect.ValueOf(v).IsNil()) {
+		return map[string]interface{}{}, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// placeholder hides a value but keeps an absent one absent, so a redacted
+// field that was set on creation still shows as new.
+func placeholder(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return redact.Placeholder
+}
@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"context"
+	"testing"
+
+	"xyz-multifinance/pkg/redact"
+)
+
+type record struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	NIK       string `json:"nik"`
+	Limit     int64  `json:"limit"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func TestDiff(t *testing.T) {
+	before := &record{ID: 1, Name: "Budi", NIK: "3201010101010001", Limit: 1000, UpdatedAt: "a"}
+	after := &record{ID: 1, Name: "Budi S", NIK: "3201010101010002", Limit: 1000, UpdatedAt: "b"}
+
+	changes, err := Diff(before, after, "name")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("changes = %+v, want name and nik only", changes)
+	}
+	for _, field := range []string{"name", "nik"} {
+		if changes[field] != (Change{From: redact.Placeholder, To: redact.Placeholder}) {
+			t.Errorf("%s = %+v, want redacted", field, changes[field])
+		}
+	}
+
+	changes, err = Diff(nil, &record{ID: 2, Limit: 500})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if changes["limit"] != (Change{From: nil, To: float64(500)}) || changes["nik"].To != redact.Placeholder {
+		t.Errorf("create diff = %+v", changes)
+	}
+
+	var deleted *record
+	changes, err = Diff(before, deleted)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if changes["limit"] != (Change{From: float64(1000), To: nil}) {
+		t.Errorf("delete diff = %+v", changes)
+	}
+}
+
+func TestRecorded(t *testing.T) {
+	ctx := NewContext(context.Background(), Actor{UserID: 7, RequestID: "abc"})
+	if Recorded(ctx) {
+		t.Fatal("fresh context reports an entry")
+	}
+	MarkRecorded(ctx)
+	if !Recorded(ctx) {
+		t.Error("entry not reported")
+	}
+	if actor, ok := ActorFrom(ctx); !ok || actor.UserID != 7 {
+		t.Errorf("actor = %+v, %v", actor, ok)
+	}
+	if _, ok := ActorFrom(context.Background()); ok {
+		t.Error("background context has an actor")
+	}
+}
@@ -0,0 +1,88 @@
+// Package bankstatement reads bank mutation files into statement lines.
+//
+// Two formats are supported: a CSV export with a header row and SWIFT MT940.
+// Amounts are whole currency units; a line with a fractional amount is
+// rejected since rupiah has no minor unit.
+package bankstatement
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FormatCSV   = "csv"
+	FormatMT940 = "mt940"
+)
+
+var ErrUnknownFormat = errors.New("bankstatement: unknown format")
+
+// Line is one mutation on the account. Credits are positive and debits
+// negative.
+type Line struct {
+	Number      int       `json:"number"`
+	Date        time.Time `json:"date"`
+	Amount      int64     `json:"amount"`
+	Reference   string    `json:"reference"`
+	Description string    `json:"description"`
+}
+
+// Credit reports whether the line is money coming in.
+func (l Line) Credit() bool {
+	return l.Amount > 0
+}
+
+// ParseError reports a line that could not be read. Number is the line of
+// the file for CSV and the statement line for MT940.
+type ParseError struct {
+	Number  int
+	Message string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("bankstatement: line %d: %s", e.Number, e.Message)
+}
+
+// Parse reads a statement in the given format.
+func Parse(format string, r io.Reader) ([]Line, error) {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return ParseCSV(r)
+	case FormatMT940:
+		return ParseMT940(r)
+	}
+	return nil, ErrUnknownFormat
+}
+
+// DetectFormat guesses the format from a file name, falling back to CSV.
+func DetectFormat(filename string) string {
+	name := strings.ToLower(filename)
+	for _, ext := range []string{".sta", ".mt940", ".940"} {
+		if strings.HasSuffix(name, ext) {
+			return FormatMT940
+		}
+	}
+	return FormatCSV
+}
+
+// parseAmount reads a whole amount written with the given decimal separator.
+// A fraction is allowed only if it is zero.
+func parseAmount(s string, decimal byte) (int64, error) {
+	s = strings.TrimSpace(s)
+	whole, fraction := s, ""
+	if i := strings.IndexByte(s, decimal); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+	}
+	if strings.Trim(fraction, "0") != "" {
+		return 0, errors.New("fractional amount")
+	}
+	amount, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid amount")
+	}
+	return amount, nil
+}
@@ -0,0 +1,69 @@
+package bankstatement
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	input := `date,amount,type,reference,description
+2024-01-05,450000.00,CR,BCA001,TRF VA 8890820000000429
+05/01/2024,125000,DB,BCA002,ADMIN FEE
+2024-01-06,-1000,,BCA003,CORRECTION
+`
+	lines, err := ParseCSV(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+	if l := lines[0]; l.Amount != 450000 || l.Reference != "BCA001" || l.Date.Format("2006-01-02") != "2024-01-05" || !l.Credit() {
+		t.Errorf("line 1 = %+v", l)
+	}
+	if l := lines[1]; l.Amount != -125000 || l.Date.Format("2006-01-02") != "2024-01-05" {
+		t.Errorf("line 2 = %+v", l)
+	}
+	if l := lines[2]; l.Amount != -1000 || l.Number != 4 {
+		t.Errorf("line 3 = %+v", l)
+	}
+}
+
+func TestParseCSV_RejectsFractionalAmount(t *testing.T) {
+	_, err := ParseCSV(strings.NewReader("date,amount,reference,description\n2024-01-05,10.50,X,Y\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("expected a line 2 error, got %v", err)
+	}
+}
+
+func TestParseMT940(t *testing.T) {
+	input := `{1:F01BANKIDJAXXXX0000000000}{2:I940BANKIDJAXXXXN}{4:
+:20:STMT240105
+:25:1234567890
+:28C:1/1
+:60F:C240104IDR1000000,00
+:61:2401050105C450000,00NTRFNONREF//BCA001
+:86:TRF VA 8890820000000429
+BUDI SANTOSO
+:61:240105D125000,NMSCNONREF//BCA002
+:86:ADMIN FEE
+:61:240106RD5000,00NTRFREF-99
+:62F:C240106IDR1330000,00
+-}`
+	lines, err := ParseMT940(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+	if l := lines[0]; l.Amount != 450000 || l.Reference != "BCA001" || l.Description != "TRF VA 8890820000000429 BUDI SANTOSO" || l.Date.Format("2006-01-02") != "2024-01-05" {
+		t.Errorf("line 1 = %+v", l)
+	}
+	if l := lines[1]; l.Amount != -125000 || l.Description != "ADMIN FEE" {
+		t.Errorf("line 2 = %+v", l)
+	}
+	if l := lines[2]; l.Amount != 5000 || l.Reference != "REF-99" || l.Description != "" {
+		t.Errorf("line 3 = %+v", l)
+	}
+}
@@ -0,0 +1,89 @@
+package bankstatement
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+var csvDateLayouts = []string{"2006-01-02", "02/01/2006", "02-01-2006"}
+
+// ParseCSV reads a statement with the header date,amount,reference,
+// description and an optional type column. Debits are either negative
+// amounts or rows whose type is D, DB or DEBIT.
+func ParseCSV(r io.Reader) ([]Line, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("bankstatement: csv file is empty")
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"date", "amount", "reference", "description"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("bankstatement: missing column: %s", required)
+		}
+	}
+
+	var lines []Line
+	number := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		number++
+		if err != nil {
+			return nil, &ParseError{Number: number, Message: err.Error()}
+		}
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		date, err := parseCSVDate(field("date"))
+		if err != nil {
+			return nil, &ParseError{Number: number, Message: "invalid date"}
+		}
+		amount, err := parseAmount(field("amount"), '.')
+		if err != nil {
+			return nil, &ParseError{Number: number, Message: err.Error()}
+		}
+		switch strings.ToUpper(field("type")) {
+		case "D", "DB", "DEBIT":
+			if amount > 0 {
+				amount = -amount
+			}
+		}
+
+		lines = append(lines, Line{
+			Number:      number,
+			Date:        date,
+			Amount:      amount,
+			Reference:   field("reference"),
+			Description: field("description"),
+		})
+	}
+	return lines, nil
+}
+
+func parseCSVDate(s string) (time.Time, error) {
+	for _, layout := range csvDateLayouts {
+		if date, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, errors.New("invalid date")
+}
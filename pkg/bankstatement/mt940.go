@@ -0,0 +1,105 @@
+package bankstatement
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// statementLine matches the body of an MT940 :61: field: value date, an
+// optional entry date, the debit/credit mark, an optional funds code, the
+// amount, the transaction type and the references.
+var statementLine = regexp.MustCompile(`^(\d{6})(\d{4})?(RC|RD|C|D)([A-Z])?(\d+,\d*)([A-Z][A-Z0-9]{3})([^/]*)(?://(.*))?$`)
+
+// ParseMT940 reads the :61: statement lines of an MT940 file together with
+// the :86: information that follows each of them. The bank reference is
+// preferred over the account owner's reference when both are present.
+func ParseMT940(r io.Reader) ([]Line, error) {
+	scanner := bufio.NewScanner(r)
+
+	var lines []Line
+	var current *Line
+	var tag string
+	var info []string
+	flush := func() {
+		if current != nil {
+			current.Description = strings.TrimSpace(strings.Join(info, " "))
+			lines = append(lines, *current)
+		}
+		current, info = nil, nil
+	}
+
+	for scanner.Scan() {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if text == "" || strings.HasPrefix(text, "{") || strings.HasPrefix(text, "-}") {
+			continue
+		}
+
+		if strings.HasPrefix(text, ":") {
+			end := strings.Index(text[1:], ":")
+			if end < 0 {
+				continue
+			}
+			tag, text = text[1:end+1], text[end+2:]
+
+			switch tag {
+			case "61":
+				flush()
+				line, err := parseStatementLine(len(lines)+1, text)
+				if err != nil {
+					return nil, err
+				}
+				current = line
+				continue
+			case "86":
+				if current != nil {
+					info = append(info, strings.TrimSpace(text))
+				}
+				continue
+			default:
+				flush()
+				continue
+			}
+		}
+
+		// Continuation of the previous field.
+		if current != nil && tag == "86" {
+			info = append(info, strings.TrimSpace(text))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	return lines, nil
+}
+
+func parseStatementLine(number int, text string) (*Line, error) {
+	m := statementLine.FindStringSubmatch(strings.TrimSpace(text))
+	if m == nil {
+		return nil, &ParseError{Number: number, Message: "malformed :61: field"}
+	}
+
+	date, err := time.ParseInLocation("060102", m[1], time.Local)
+	if err != nil {
+		return nil, &ParseError{Number: number, Message: "invalid value date"}
+	}
+	amount, err := parseAmount(m[5], ',')
+	if err != nil {
+		return nil, &ParseError{Number: number, Message: err.Error()}
+	}
+	// RC reverses a credit and RD a debit.
+	if m[3] == "D" || m[3] == "RC" {
+		amount = -amount
+	}
+
+	reference := strings.TrimSpace(m[8])
+	if owner := strings.TrimSpace(m[7]); reference == "" && owner != "NONREF" {
+		reference = owner
+	}
+
+	return &Line{Number: number, Date: date, Amount: amount, Reference: reference}, nil
+}
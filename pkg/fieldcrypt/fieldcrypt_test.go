@@ -0,0 +1,42 @@
+package fieldcrypt
+
+import (
+	"strings"
+	"testing"
+
+	"xyz-multifinance/pkg/keyring"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	ring, err := keyring.Parse("k1:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=", "k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	Configure(ring, []byte("index-key"))
+	defer Configure(nil, nil)
+
+	stored, err := Encrypt("nik", "3201010101010001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(stored, "enc:v1:k1:") || strings.Contains(stored, "3201010101010001") {
+		t.Errorf("stored value %q", stored)
+	}
+	if again, _ := Encrypt("nik", "3201010101010001"); again == stored {
+		t.Error("encryption is deterministic")
+	}
+
+	if got, err := Decrypt("nik", stored); err != nil || got != "3201010101010001" {
+		t.Errorf("Decrypt = %q, %v", got, err)
+	}
+	if _, err := Decrypt("full_name", stored); err == nil {
+		t.Error("value decrypted under another column")
+	}
+	if got, err := Decrypt("nik", "12345"); err != nil || got != "12345" {
+		t.Errorf("Decrypt of plaintext = %q, %v", got, err)
+	}
+
+	if BlindIndex("3201010101010001") != BlindIndex("3201010101010001") || BlindIndex("1") == BlindIndex("2") {
+		t.Error("blind index is not a deterministic function of the value")
+	}
+}
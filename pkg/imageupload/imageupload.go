@@ -0,0 +1,254 @@
+// Package imageupload validates uploaded photos and rewrites them into a
+// clean image.
+//
+// The type of an upload is taken from its magic bytes, never from the file
+// name or the client's Content-Type, and only JPEG, PNG and HEIC are
+// accepted. Every accepted image is fully decoded, turned upright according
+// to its EXIF orientation, scaled down to the configured maximum dimension
+// and encoded again, so EXIF/GPS metadata and anything appended to or
+// embedded in the original file are dropped. JPEG and HEIC come out as JPEG,
+// PNG stays PNG.
+package imageupload
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	stddraw "image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime/multipart"
+
+	"github.com/gen2brain/heic"
+	"golang.org/x/image/draw"
+)
+
+const (
+	TypeJPEG = "image/jpeg"
+	TypePNG  = "image/png"
+	TypeHEIC = "image/heic"
+)
+
+// Error codes reported by Process.
+const (
+	CodeFileTooLarge    = "file_too_large"
+	CodeUnsupportedType = "unsupported_type"
+	CodeInvalidImage    = "invalid_image"
+	CodeTooManyPixels   = "too_many_pixels"
+)
+
+// maxPixels bounds the size of the decoded bitmap, so a small file that
+// claims huge dimensions cannot exhaust memory.
+const maxPixels = 50_000_000
+
+const jpegQuality = 90
+
+// Error is a validation failure that can be shown to the client.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Policy limits what is accepted. MaxBytes applies to the uploaded file and
+// MaxDimension to the longest side of the stored image; zero disables a
+// limit.
+type Policy struct {
+	MaxBytes     int64
+	MaxDimension int
+}
+
+// Image is a re-encoded upload ready to be stored.
+type Image struct {
+	Data        []byte
+	ContentType string
+	Extension   string
+	Width       int
+	Height      int
+}
+
+// ProcessFile checks the declared size of a multipart upload before reading
+// it and then processes its content.
+func ProcessFile(header *multipart.FileHeader, policy Policy) (*Image, error) {
+	if policy.MaxBytes > 0 && header.Size > policy.MaxBytes {
+		return nil, tooLarge(policy.MaxBytes)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Process(file, policy)
+}
+
+// Process reads an upload and returns it re-encoded. Validation failures
+// are returned as *Error.
+func Process(r io.Reader, policy Policy) (*Image, error) {
+	if policy.MaxBytes > 0 {
+		r = io.LimitReader(r, policy.MaxBytes+1)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if policy.MaxBytes > 0 && int64(len(data)) > policy.MaxBytes {
+		return nil, tooLarge(policy.MaxBytes)
+	}
+
+	img, contentType, err := decodeImage(data)
+	if err != nil {
+		return nil, err
+	}
+	img = fit(img, policy.MaxDimension)
+
+	var out bytes.Buffer
+	result := &Image{Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}
+	if contentType == TypePNG {
+		err = png.Encode(&out, img)
+		result.ContentType, result.Extension = TypePNG, ".png"
+	} else {
+		err = jpeg.Encode(&out, img, &jpeg.Options{Quality: jpegQuality})
+		result.ContentType, result.Extension = TypeJPEG, ".jpg"
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Data = out.Bytes()
+	return result, nil
+}
+
+// Thumbnail scales a stored image down to fit within maxDimension and
+// returns it as JPEG, flattened onto a white background.
+func Thumbnail(data []byte, maxDimension int) (*Image, error) {
+	img, _, err := decodeImage(data)
+	if err != nil {
+		return nil, err
+	}
+	img = fit(img, maxDimension)
+
+	flat := image.NewRGBA(img.Bounds())
+	stddraw.Draw(flat, flat.Bounds(), image.White, image.Point{}, stddraw.Src)
+	stddraw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, stddraw.Over)
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, flat, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return &Image{
+		Data:        out.Bytes(),
+		ContentType: TypeJPEG,
+		Extension:   ".jpg",
+		Width:       flat.Bounds().Dx(),
+		Height:      flat.Bounds().Dy(),
+	}, nil
+}
+
+// decodeImage identifies data by its magic bytes and decodes it, turning
+// JPEGs upright according to their EXIF orientation.
+func decodeImage(data []byte) (image.Image, string, error) {
+	contentType := Detect(data)
+	if contentType == "" {
+		return nil, "", &Error{Code: CodeUnsupportedType, Message: "only JPEG, PNG and HEIC images are accepted"}
+	}
+
+	config, err := decodeConfig(contentType, data)
+	if err != nil || config.Width <= 0 || config.Height <= 0 {
+		return nil, "", invalidImage()
+	}
+	if int64(config.Width)*int64(config.Height) > maxPixels {
+		return nil, "", &Error{Code: CodeTooManyPixels, Message: fmt.Sprintf("image is %dx%d pixels, which is too large", config.Width, config.Height)}
+	}
+
+	img, err := decode(contentType, data)
+	if err != nil {
+		return nil, "", invalidImage()
+	}
+	if contentType == TypeJPEG {
+		img = orient(img, jpegOrientation(data))
+	}
+	return img, contentType, nil
+}
+
+// Detect returns the content type of an accepted image format from its
+// leading bytes, or "" for anything else.
+func Detect(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return TypeJPEG
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return TypePNG
+	case isHEIC(data):
+		return TypeHEIC
+	}
+	return ""
+}
+
+// isHEIC looks for an ISO BMFF "ftyp" box whose major brand is one of the
+// HEIF image brands.
+func isHEIC(data []byte) bool {
+	if len(data) < 12 || string(data[4:8]) != "ftyp" {
+		return false
+	}
+	switch string(data[8:12]) {
+	case "heic", "heix", "heim", "heis", "hevc", "hevx", "mif1", "msf1":
+		return true
+	}
+	return false
+}
+
+func decodeConfig(contentType string, data []byte) (image.Config, error) {
+	switch contentType {
+	case TypeJPEG:
+		return jpeg.DecodeConfig(bytes.NewReader(data))
+	case TypePNG:
+		return png.DecodeConfig(bytes.NewReader(data))
+	default:
+		return heic.DecodeConfig(bytes.NewReader(data))
+	}
+}
+
+func decode(contentType string, data []byte) (image.Image, error) {
+	switch contentType {
+	case TypeJPEG:
+		return jpeg.Decode(bytes.NewReader(data))
+	case TypePNG:
+		return png.Decode(bytes.NewReader(data))
+	default:
+		return heic.Decode(bytes.NewReader(data))
+	}
+}
+
+// fit scales img down so that neither side exceeds maxDimension.
+func fit(img image.Image, maxDimension int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if maxDimension <= 0 || (w <= maxDimension && h <= maxDimension) {
+		return img
+	}
+	if w >= h {
+		h = max(1, h*maxDimension/w)
+		w = maxDimension
+	} else {
+		w = max(1, w*maxDimension/h)
+		h = maxDimension
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+func tooLarge(maxBytes int64) *Error {
+	limit := fmt.Sprintf("%d bytes", maxBytes)
+	if maxBytes%(1<<20) == 0 {
+		limit = fmt.Sprintf("%d MB", maxBytes>>20)
+	}
+	return &Error{Code: CodeFileTooLarge, Message: "file exceeds the maximum size of " + limit}
+}
+
+func invalidImage() *Error {
+	return &Error{Code: CodeInvalidImage, Message: "file is not a readable image"}
+}
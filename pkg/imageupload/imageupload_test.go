@@ -0,0 +1,141 @@
+package imageupload
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testImage(w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withExif inserts an APP1 segment carrying an orientation tag and some
+// marker bytes standing in for GPS data right after the JPEG's SOI marker.
+func withExif(data []byte, orientation uint16) []byte {
+	tiff := []byte("II*\x00\x08\x00\x00\x00")
+	tiff = binary.LittleEndian.AppendUint16(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.LittleEndian.AppendUint16(tiff, 3)
+	tiff = binary.LittleEndian.AppendUint32(tiff, 1)
+	tiff = binary.LittleEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0)
+	tiff = append(tiff, "GPS-6.1754S-106.8272E"...)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, app1...)
+	return append(out, data[2:]...)
+}
+
+func TestProcessStripsMetadataAndAppliesOrientation(t *testing.T) {
+	data := withExif(encodeJPEG(t, testImage(40, 20)), 6)
+	data = append(data, []byte("PK\x03\x04 appended payload")...)
+
+	img, err := Process(bytes.NewReader(data), Policy{MaxBytes: 1 << 20})
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if img.ContentType != TypeJPEG || img.Extension != ".jpg" {
+		t.Errorf("type = %s %s", img.ContentType, img.Extension)
+	}
+	if img.Width != 20 || img.Height != 40 {
+		t.Errorf("size = %dx%d, want 20x40 after a 90° turn", img.Width, img.Height)
+	}
+	for _, leftover := range []string{"Exif", "GPS-", "payload"} {
+		if bytes.Contains(img.Data, []byte(leftover)) {
+			t.Errorf("output still contains %q", leftover)
+		}
+	}
+}
+
+func TestProcessScalesDownToMaxDimension(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(300, 150)); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := Process(&buf, Policy{MaxDimension: 100})
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if img.ContentType != TypePNG || img.Width != 100 || img.Height != 50 {
+		t.Errorf("got %s %dx%d, want image/png 100x50", img.ContentType, img.Width, img.Height)
+	}
+}
+
+func TestProcessRejects(t *testing.T) {
+	jpegData := encodeJPEG(t, testImage(10, 10))
+	tests := []struct {
+		name   string
+		data   []byte
+		policy Policy
+		code   string
+	}{
+		{"script named as photo", []byte("<?php system($_GET['c']); ?>"), Policy{}, CodeUnsupportedType},
+		{"gif", []byte("GIF89a\x01\x00\x01\x00"), Policy{}, CodeUnsupportedType},
+		{"too large", jpegData, Policy{MaxBytes: int64(len(jpegData)) - 1}, CodeFileTooLarge},
+		{"truncated jpeg", jpegData[:20], Policy{}, CodeInvalidImage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Process(bytes.NewReader(tt.data), tt.policy)
+			var uploadErr *Error
+			if !errors.As(err, &uploadErr) || uploadErr.Code != tt.code {
+				t.Errorf("err = %v, want code %s", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestDetectHEIC(t *testing.T) {
+	header := []byte("\x00\x00\x00\x18ftypheic\x00\x00\x00\x00mif1heic")
+	if got := Detect(header); got != TypeHEIC {
+		t.Errorf("Detect = %q, want %s", got, TypeHEIC)
+	}
+	if got := Detect([]byte("\x00\x00\x00\x18ftypisom")); got != "" {
+		t.Errorf("Detect(mp4) = %q, want empty", got)
+	}
+}
+
+func TestThumbnail(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(400, 200)); err != nil {
+		t.Fatal(err)
+	}
+
+	thumb, err := Thumbnail(buf.Bytes(), 160)
+	if err != nil {
+		t.Fatalf("Thumbnail: %v", err)
+	}
+	if thumb.ContentType != TypeJPEG || thumb.Width != 160 || thumb.Height != 80 {
+		t.Errorf("got %s %dx%d, want image/jpeg 160x80", thumb.ContentType, thumb.Width, thumb.Height)
+	}
+	if Detect(thumb.Data) != TypeJPEG {
+		t.Error("thumbnail is not a JPEG")
+	}
+}
@@ -0,0 +1,102 @@
+// Package keyring holds the master keys used for encryption at rest.
+//
+// Keys are identified by a short id so every ciphertext can record which
+// key sealed it. New data is always sealed with the active key; older keys
+// stay in the ring only to open what they sealed until it has been
+// re-encrypted.
+package keyring
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	ErrUnknownKey = errors.New("keyring: unknown key id")
+	ErrDecrypt    = errors.New("keyring: message authentication failed")
+)
+
+type Keyring struct {
+	active string
+	keys   map[string]cipher.AEAD
+}
+
+// Parse reads a comma-separated list of "id:base64key" pairs, each key being
+// 32 random bytes, and makes active the key new data is sealed with.
+func Parse(spec, active string) (*Keyring, error) {
+	ring := &Keyring{active: active, keys: make(map[string]cipher.AEAD)}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || id == "" || len(id) > 32 || strings.ContainsAny(id, ":,") {
+			return nil, fmt.Errorf("keyring: malformed entry %q, want id:base64key", entry)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("keyring: key %q must be 32 bytes encoded in base64", id)
+		}
+		aead, err := newAEAD(key)
+		if err != nil {
+			return nil, err
+		}
+		ring.keys[id] = aead
+	}
+
+	if len(ring.keys) == 0 {
+		return nil, errors.New("keyring: no keys configured")
+	}
+	if _, ok := ring.keys[active]; !ok {
+		return nil, fmt.Errorf("keyring: active key %q is not in the ring", active)
+	}
+	return ring, nil
+}
+
+// Active returns the id of the key used to seal new data.
+func (r *Keyring) Active() string {
+	return r.active
+}
+
+// Seal encrypts plaintext with the active key using AES-256-GCM and returns
+// the key id and nonce-prefixed ciphertext. additionalData is authenticated
+// but not encrypted; the same value must be passed to Open.
+func (r *Keyring) Seal(plaintext, additionalData []byte) (string, []byte, error) {
+	aead := r.keys[r.active]
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", nil, err
+	}
+	return r.active, aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// Open decrypts a ciphertext produced by Seal with the key it names.
+func (r *Keyring) Open(keyID string, ciphertext, additionalData []byte) ([]byte, error) {
+	aead, ok := r.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, keyID)
+	}
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, additionalData)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
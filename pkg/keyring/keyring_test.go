@@ -0,0 +1,33 @@
+package keyring
+
+import "testing"
+
+const key1 = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct{ spec, active string }{
+		{"", "k1"},
+		{"k1", "k1"},
+		{"k1:c2hvcnQ=", "k1"},
+		{"k1:" + key1, "k2"},
+	} {
+		if _, err := Parse(tt.spec, tt.active); err == nil {
+			t.Errorf("Parse(%q, %q) succeeded", tt.spec, tt.active)
+		}
+	}
+
+	ring, err := Parse("k1:"+key1, "k1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID, sealed, err := ring.Seal([]byte("secret"), []byte("nik"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain, err := ring.Open(keyID, sealed, []byte("nik")); err != nil || string(plain) != "secret" {
+		t.Errorf("Open = %q, %v", plain, err)
+	}
+	if _, err := ring.Open(keyID, sealed, []byte("salary")); err != ErrDecrypt {
+		t.Errorf("Open with other data: err = %v, want ErrDecrypt", err)
+	}
+}
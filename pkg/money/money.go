@@ -0,0 +1,233 @@
+// Package money represents amounts as integer minor units of a currency and
+// provides arithmetic that refuses to overflow or to mix currencies.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	ErrOverflow         = errors.New("money: amount overflows int64")
+	ErrDivideByZero     = errors.New("money: division by zero")
+	ErrUnknownCurrency  = errors.New("money: unknown currency")
+	ErrInvalidRatios    = errors.New("money: ratios must be non-negative and not all zero")
+)
+
+// Currency is an ISO 4217 currency code.
+type Currency string
+
+const (
+	IDR Currency = "IDR"
+	USD Currency = "USD"
+	SGD Currency = "SGD"
+)
+
+// minorUnits is the number of decimal places of the minor unit the amounts
+// are stored in. Rupiah amounts are kept in whole rupiah.
+var minorUnits = map[Currency]int{
+	IDR: 0,
+	USD: 2,
+	SGD: 2,
+}
+
+// ParseCurrency validates a currency code, accepting lower case.
+func ParseCurrency(code string) (Currency, error) {
+	c := Currency(strings.ToUpper(strings.TrimSpace(code)))
+	if _, ok := minorUnits[c]; !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+	return c, nil
+}
+
+// MinorUnits returns the number of decimal places of the currency's minor
+// unit.
+func (c Currency) MinorUnits() int {
+	return minorUnits[c]
+}
+
+// Money is an amount in minor units of a currency. The zero value is zero of
+// no currency and should not be used in arithmetic.
+type Money struct {
+	amount   int64
+	currency Currency
+}
+
+func New(amount int64, currency Currency) Money {
+	return Money{amount: amount, currency: currency}
+}
+
+func (m Money) Amount() int64 {
+	return m.amount
+}
+
+func (m Money) Currency() Currency {
+	return m.currency
+}
+
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.amount < 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.amount > 0
+}
+
+func (m Money) sameCurrency(o Money) error {
+	if m.currency != o.currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, o.currency)
+	}
+	return nil
+}
+
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	if (o.amount > 0 && m.amount > math.MaxInt64-o.amount) || (o.amount < 0 && m.amount < math.MinInt64-o.amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{amount: m.amount + o.amount, currency: m.currency}, nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	if (o.amount < 0 && m.amount > math.MaxInt64+o.amount) || (o.amount > 0 && m.amount < math.MinInt64+o.amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{amount: m.amount - o.amount, currency: m.currency}, nil
+}
+
+func (m Money) Mul(n int64) (Money, error) {
+	return m.MulRatio(n, 1, RoundDown)
+}
+
+// MulRatio returns m * num / den rounded with the given mode. The
+// intermediate product is computed exactly, so only the result has to fit.
+func (m Money) MulRatio(num, den int64, mode RoundingMode) (Money, error) {
+	if den == 0 {
+		return Money{}, ErrDivideByZero
+	}
+
+	product := new(big.Int).Mul(big.NewInt(m.amount), big.NewInt(num))
+	result := divRound(product, big.NewInt(den), mode)
+	if !result.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{amount: result.Int64(), currency: m.currency}, nil
+}
+
+// Div returns m / n rounded with the given mode.
+func (m Money) Div(n int64, mode RoundingMode) (Money, error) {
+	return m.MulRatio(1, n, mode)
+}
+
+// Cmp compares two amounts of the same currency and returns -1, 0 or +1.
+func (m Money) Cmp(o Money) (int, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.amount < o.amount:
+		return -1, nil
+	case m.amount > o.amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Allocate splits m in proportion to ratios without losing a minor unit.
+// Each part is first rounded toward zero; the units left over are handed
+// out one at a time from the first part onwards.
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, ErrInvalidRatios
+	}
+	var total int64
+	for _, r := range ratios {
+		if r < 0 || total > math.MaxInt64-r {
+			return nil, ErrInvalidRatios
+		}
+		total += r
+	}
+	if total == 0 {
+		return nil, ErrInvalidRatios
+	}
+
+	parts := make([]Money, len(ratios))
+	remainder := m.amount
+	for i, r := range ratios {
+		part, err := m.MulRatio(r, total, RoundDown)
+		if err != nil {
+			return nil, err
+		}
+		parts[i] = part
+		remainder -= part.amount
+	}
+
+	unit := int64(1)
+	if remainder < 0 {
+		unit = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(parts) {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i].amount += unit
+		remainder -= unit
+	}
+	return parts, nil
+}
+
+// Split divides m into n parts that differ by at most one minor unit.
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, ErrDivideByZero
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// Sum adds up amounts of one currency. The sum of nothing is zero in the
+// given currency.
+func Sum(currency Currency, values ...Money) (Money, error) {
+	total := New(0, currency)
+	for _, v := range values {
+		var err error
+		if total, err = total.Add(v); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// String formats the amount with its currency, e.g. "IDR 1500000" or
+// "USD 12.50".
+func (m Money) String() string {
+	units := m.currency.MinorUnits()
+	if units == 0 {
+		return fmt.Sprintf("%s %d", m.currency, m.amount)
+	}
+
+	sign := ""
+	abs := new(big.Int).Abs(big.NewInt(m.amount))
+	if m.amount < 0 {
+		sign = "-"
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(units)), nil)
+	whole, frac := new(big.Int).QuoRem(abs, scale, new(big.Int))
+	return fmt.Sprintf("%s %s%s.%0*d", m.currency, sign, whole.String(), units, frac.Int64())
+}
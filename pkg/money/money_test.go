@@ -0,0 +1,117 @@
+package money_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"xyz-multifinance/pkg/money"
+)
+
+func TestAdd_Overflow(t *testing.T) {
+	_, err := money.New(math.MaxInt64, money.IDR).Add(money.New(1, money.IDR))
+	if !errors.Is(err, money.ErrOverflow) {
+		t.Fatalf("expected ErrOverflow, got %v", err)
+	}
+
+	_, err = money.New(math.MinInt64, money.IDR).Sub(money.New(1, money.IDR))
+	if !errors.Is(err, money.ErrOverflow) {
+		t.Fatalf("expected ErrOverflow on sub, got %v", err)
+	}
+}
+
+func TestAdd_CurrencyMismatch(t *testing.T) {
+	_, err := money.New(1, money.IDR).Add(money.New(1, money.USD))
+	if !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Fatalf("expected ErrCurrencyMismatch, got %v", err)
+	}
+}
+
+func TestMulRatio_Rounding(t *testing.T) {
+	cases := []struct {
+		amount, num, den int64
+		mode             money.RoundingMode
+		want             int64
+	}{
+		{25, 1, 10, money.RoundHalfUp, 3},
+		{25, 1, 10, money.RoundHalfEven, 2},
+		{35, 1, 10, money.RoundHalfEven, 4},
+		{-25, 1, 10, money.RoundHalfUp, -3},
+		{-25, 1, 10, money.RoundHalfEven, -2},
+		{21, 1, 10, money.RoundUp, 3},
+		{29, 1, 10, money.RoundDown, 2},
+		{26, 1, 10, money.RoundHalfEven, 3},
+		// Intermediate product overflows int64, the result does not.
+		{math.MaxInt64, 3, 4, money.RoundDown, 6917529027641081855},
+	}
+
+	for _, tc := range cases {
+		got, err := money.New(tc.amount, money.IDR).MulRatio(tc.num, tc.den, tc.mode)
+		if err != nil {
+			t.Fatalf("%d*%d/%d (%s): unexpected error %v", tc.amount, tc.num, tc.den, tc.mode, err)
+		}
+		if got.Amount() != tc.want {
+			t.Errorf("%d*%d/%d (%s): expected %d, got %d", tc.amount, tc.num, tc.den, tc.mode, tc.want, got.Amount())
+		}
+	}
+
+	if _, err := money.New(math.MaxInt64, money.IDR).Mul(2); !errors.Is(err, money.ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+	if _, err := money.New(1, money.IDR).Div(0, money.RoundDown); !errors.Is(err, money.ErrDivideByZero) {
+		t.Errorf("expected ErrDivideByZero, got %v", err)
+	}
+}
+
+func TestSplit_KeepsEveryUnit(t *testing.T) {
+	parts, err := money.New(10_000_001, money.IDR).Split(12)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var total int64
+	for i, p := range parts {
+		total += p.Amount()
+		want := int64(833_333)
+		if i < 5 {
+			want = 833_334
+		}
+		if p.Amount() != want {
+			t.Errorf("part %d: expected %d, got %d", i, want, p.Amount())
+		}
+	}
+	if total != 10_000_001 {
+		t.Errorf("expected parts to add up to 10000001, got %d", total)
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	parts, err := money.New(100, money.IDR).Allocate(1, 0, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parts[0].Amount() != 34 || parts[1].Amount() != 0 || parts[2].Amount() != 66 {
+		t.Errorf("unexpected allocation %v", parts)
+	}
+
+	parts, err = money.New(-5, money.IDR).Split(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parts[0].Amount() != -3 || parts[1].Amount() != -2 {
+		t.Errorf("unexpected negative split %v", parts)
+	}
+
+	if _, err := money.New(100, money.IDR).Allocate(0, 0); !errors.Is(err, money.ErrInvalidRatios) {
+		t.Errorf("expected ErrInvalidRatios, got %v", err)
+	}
+}
+
+func TestString(t *testing.T) {
+	if got := money.New(1500000, money.IDR).String(); got != "IDR 1500000" {
+		t.Errorf("unexpected %q", got)
+	}
+	if got := money.New(-1205, money.USD).String(); got != "USD -12.05" {
+		t.Errorf("unexpected %q", got)
+	}
+}
@@ -0,0 +1,79 @@
+package money
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// RoundingMode decides what happens to the fraction of a minor unit left
+// over by a division.
+type RoundingMode int
+
+const (
+	// RoundDown truncates toward zero.
+	RoundDown RoundingMode = iota
+	// RoundUp rounds away from zero whenever there is a fraction.
+	RoundUp
+	// RoundHalfUp rounds to the nearest unit, halves away from zero.
+	RoundHalfUp
+	// RoundHalfEven rounds to the nearest unit, halves to the even
+	// neighbour (banker's rounding).
+	RoundHalfEven
+)
+
+var roundingNames = map[string]RoundingMode{
+	"down":      RoundDown,
+	"up":        RoundUp,
+	"half_up":   RoundHalfUp,
+	"half_even": RoundHalfEven,
+}
+
+// ParseRoundingMode accepts down, up, half_up and half_even.
+func ParseRoundingMode(name string) (RoundingMode, error) {
+	mode, ok := roundingNames[name]
+	if !ok {
+		return 0, fmt.Errorf("money: unknown rounding mode %q", name)
+	}
+	return mode, nil
+}
+
+func (r RoundingMode) String() string {
+	for name, mode := range roundingNames {
+		if mode == r {
+			return name
+		}
+	}
+	return fmt.Sprintf("RoundingMode(%d)", int(r))
+}
+
+// divRound divides num by den and rounds the quotient with mode.
+func divRound(num, den *big.Int, mode RoundingMode) *big.Int {
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	if rem.Sign() == 0 {
+		return quo
+	}
+
+	// The exact result lies between quo and quo+direction.
+	direction := int64(num.Sign() * den.Sign())
+
+	// Compare twice the remainder with the divisor to find out whether the
+	// fraction is below, at or above one half.
+	twice := new(big.Int).Abs(rem)
+	twice.Lsh(twice, 1)
+	half := twice.Cmp(new(big.Int).Abs(den))
+
+	away := false
+	switch mode {
+	case RoundUp:
+		away = true
+	case RoundHalfUp:
+		away = half >= 0
+	case RoundHalfEven:
+		away = half > 0 || (half == 0 && quo.Bit(0) == 1)
+	}
+
+	if away {
+		quo.Add(quo, big.NewInt(direction))
+	}
+	return quo
+}
@@ -0,0 +1,84 @@
+// Package nik builds and checks Indonesian national identity numbers
+// (Nomor Induk Kependudukan).
+//
+// A NIK is sixteen digits: the six-digit code of the district that issued it
+// (province, regency, district), the holder's date of birth as DDMMYY with 40
+// added to the day for women, and a serial among people registered in that
+// district with the same date of birth.
+package nik
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const Length = 16
+
+var (
+	ErrInvalidRegion = errors.New("nik: region must be a six-digit district code")
+	ErrInvalidSerial = errors.New("nik: serial must be between 1 and 9999")
+)
+
+// New returns the NIK of a person born on birthDate and registered in region
+// with the given serial.
+func New(region string, birthDate time.Time, female bool, serial int) (string, error) {
+	if !validRegion(region) {
+		return "", ErrInvalidRegion
+	}
+	if serial < 1 || serial > 9999 {
+		return "", ErrInvalidSerial
+	}
+
+	day := birthDate.Day()
+	if female {
+		day += 40
+	}
+	return fmt.Sprintf("%s%02d%02d%02d%04d", region, day, int(birthDate.Month()), birthDate.Year()%100, serial), nil
+}
+
+// Valid reports whether nik is sixteen digits with a plausible district code,
+// a real date of birth and a non-zero serial.
+func Valid(nik string) bool {
+	if len(nik) != Length || !digits(nik) || !validRegion(nik[:6]) || nik[12:] == "0000" {
+		return false
+	}
+
+	day, _ := strconv.Atoi(nik[6:8])
+	month, _ := strconv.Atoi(nik[8:10])
+	year, _ := strconv.Atoi(nik[10:12])
+	if day > 40 {
+		day -= 40
+	}
+	// Only the last two digits of the year are known, so a date is accepted
+	// when it exists in either century (29 February 1900 does not, 2000 does).
+	return realDate(1900+year, month, day) || realDate(2000+year, month, day)
+}
+
+// validRegion accepts six digits whose province code is in the range used by
+// Dukcapil (11 to 94) and whose regency and district parts are not zero.
+func validRegion(region string) bool {
+	if len(region) != 6 || !digits(region) {
+		return false
+	}
+	province, _ := strconv.Atoi(region[:2])
+	return province >= 11 && province <= 94 && region[2:4] != "00" && region[4:6] != "00"
+}
+
+func realDate(year, month, day int) bool {
+	if month < 1 || month > 12 || day < 1 {
+		return false
+	}
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	return t.Day() == day && int(t.Month()) == month
+}
+
+func digits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
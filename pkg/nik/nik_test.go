@@ -0,0 +1,70 @@
+package nik
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	birth := time.Date(1996, time.May, 3, 0, 0, 0, 0, time.UTC)
+
+	male, err := New("647101", birth, false, 12)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "6471010305960012"; male != want {
+		t.Errorf("nik = %s, want %s", male, want)
+	}
+
+	female, err := New("647101", birth, true, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "6471014305960001"; female != want {
+		t.Errorf("nik = %s, want %s", female, want)
+	}
+
+	for _, nik := range []string{male, female} {
+		if !Valid(nik) {
+			t.Errorf("%s should be valid", nik)
+		}
+	}
+}
+
+func TestNew_Rejects(t *testing.T) {
+	birth := time.Date(1990, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := New("0971", birth, false, 1); err != ErrInvalidRegion {
+		t.Errorf("err = %v, want ErrInvalidRegion", err)
+	}
+	if _, err := New("310000", birth, false, 1); err != ErrInvalidRegion {
+		t.Errorf("err = %v, want ErrInvalidRegion", err)
+	}
+	if _, err := New("317101", birth, false, 0); err != ErrInvalidSerial {
+		t.Errorf("err = %v, want ErrInvalidSerial", err)
+	}
+	if _, err := New("317101", birth, false, 10000); err != ErrInvalidSerial {
+		t.Errorf("err = %v, want ErrInvalidSerial", err)
+	}
+}
+
+func TestValid(t *testing.T) {
+	for _, nik := range []string{"3171012902000001", "3171016912850001"} {
+		if !Valid(nik) {
+			t.Errorf("%s should be valid", nik)
+		}
+	}
+	for _, nik := range []string{
+		"",
+		"12345",
+		"317101010190000a",
+		"0971010101900001", // province 09 does not exist
+		"3171013202900001", // 32 February
+		"3171012902010001", // 29 February in a non-leap year
+		"3171010113900001", // month 13
+		"3171010101900000", // serial 0000
+	} {
+		if Valid(nik) {
+			t.Errorf("%s should be invalid", nik)
+		}
+	}
+}
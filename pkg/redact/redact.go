@@ -0,0 +1,63 @@
+// Package redact hides personal data and credentials in API responses and
+// log output.
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Placeholder replaces a redacted value.
+const Placeholder = "[REDACTED]"
+
+// sensitiveKeys are field and column names whose values are never logged.
+// Names containing "password", "token" or "secret" are sensitive as well.
+var sensitiveKeys = map[string]bool{
+	"nik":           true,
+	"salary":        true,
+	"authorization": true,
+	"api_key":       true,
+	"api_key_hash":  true,
+	"x-api-key":     true,
+	"signature":     true,
+}
+
+// IsSensitiveKey reports whether values stored under a field or column name
+// must be redacted.
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(strings.Trim(key, "`\"' "))
+	if i := strings.LastIndexByte(key, '.'); i >= 0 {
+		key = strings.Trim(key[i+1:], "`\"")
+	}
+	return sensitiveKeys[key] ||
+		strings.Contains(key, "password") ||
+		strings.Contains(key, "token") ||
+		strings.Contains(key, "secret")
+}
+
+// MaskNIK keeps the first and last four digits of a NIK, which identify the
+// region and the registration sequence, and hides the digits carrying the
+// date of birth: 3201********0001.
+func MaskNIK(nik string) string {
+	if len(nik) <= 8 {
+		return strings.Repeat("*", len(nik))
+	}
+	return nik[:4] + strings.Repeat("*", len(nik)-8) + nik[len(nik)-4:]
+}
+
+var (
+	nikPattern    = regexp.MustCompile(`\b\d{16}\b`)
+	bearerPattern = regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9\-._~+/]+=*`)
+	jwtPattern    = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	pairPattern   = regexp.MustCompile("(?i)([\"'`]?([a-z_\\-]*(?:password|token|secret)[a-z_\\-]*|nik|salary|authorization|api_key|x-api-key|signature)[\"'`]?\\s*[:=]\\s*)(\"[^\"]*\"|'[^']*'|[^\\s,&}\\)]+)")
+)
+
+// String redacts free text such as log messages, request paths and SQL: it
+// masks anything shaped like a NIK, drops bearer tokens and JWTs, and blanks
+// the value of key=value and "key": value pairs with a sensitive key.
+func String(s string) string {
+	s = bearerPattern.ReplaceAllString(s, Placeholder)
+	s = jwtPattern.ReplaceAllString(s, Placeholder)
+	s = pairPattern.ReplaceAllString(s, "${1}"+Placeholder)
+	return nikPattern.ReplaceAllStringFunc(s, MaskNIK)
+}
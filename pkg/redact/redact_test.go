@@ -0,0 +1,66 @@
+package redact
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMaskNIK(t *testing.T) {
+	if got := MaskNIK("3201010101900001"); got != "3201********0001" {
+		t.Errorf("MaskNIK = %s", got)
+	}
+	if got := MaskNIK("1234"); got != "****" {
+		t.Errorf("MaskNIK of a short value = %s", got)
+	}
+}
+
+func TestString(t *testing.T) {
+	cases := map[string]string{
+		"GET /api/v1/customers/3201010101900001/exposure":            "GET /api/v1/customers/3201********0001/exposure",
+		"Authorization: Bearer abc.def-ghi":                          "Authorization: " + Placeholder,
+		"token eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOjF9.c2lnbmF0dXJl":       "token " + Placeholder,
+		`{"username":"admin","password":"hunter2"}`:                  `{"username":"admin","password":` + Placeholder + `}`,
+		"UPDATE `customers` SET `salary`=5000000,`full_name`='Budi'": "UPDATE `customers` SET `salary`=" + Placeholder + ",`full_name`='Budi'",
+		"nik = '3201010101900001'":                                   "nik = " + Placeholder,
+		"transaction 42 settled":                                     "transaction 42 settled",
+	}
+	for in, want := range cases {
+		if got := String(in); got != want {
+			t.Errorf("String(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSQLParams(t *testing.T) {
+	cases := []struct {
+		sql    string
+		params []interface{}
+		want   []interface{}
+	}{
+		{
+			"INSERT INTO `customers` (`user_id`,`nik`,`full_name`,`salary`) VALUES (?,?,?,?),(?,?,?,?)",
+			[]interface{}{1, "3201", "Budi", 5000000, 2, "3202", "Ani", 7000000},
+			[]interface{}{1, Placeholder, "Budi", Placeholder, 2, Placeholder, "Ani", Placeholder},
+		},
+		{
+			"UPDATE `customers` SET `full_name`=?,`salary`=?,`updated_at`=? WHERE (nik_hash = ? OR (nik_hash IS NULL AND nik = ?))",
+			[]interface{}{"Budi", 5000000, "2024-05-01", "abc", "3201"},
+			[]interface{}{"Budi", Placeholder, "2024-05-01", "abc", Placeholder},
+		},
+		{
+			"SELECT * FROM `users` WHERE username = ? AND `users`.`password` IN (?,?) AND id > ?",
+			[]interface{}{"admin", "a", "b", 3},
+			[]interface{}{"admin", Placeholder, Placeholder, 3},
+		},
+		{
+			"SELECT * FROM `customers` WHERE note = 'what?' AND nik = ?",
+			[]interface{}{"3201"},
+			[]interface{}{Placeholder},
+		},
+	}
+	for _, tc := range cases {
+		if got := SQLParams(tc.sql, tc.params); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("SQLParams(%q) = %v, want %v", tc.sql, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package redact
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	insertColumnsPattern = regexp.MustCompile(`(?is)^\s*INSERT\s+INTO\s+\S+\s*\(([^)]*)\)\s*VALUES`)
+	comparisonPattern    = regexp.MustCompile("(?i)`?([a-z0-9_]+)`?\\s*(?:=|<>|!=|<=|>=|<|>|\\bLIKE|\\bIN\\s*\\()\\s*$")
+)
+
+// SQLParams returns a copy of the bound parameters of a statement with the
+// values of sensitive columns replaced by Placeholder. A parameter's column
+// is taken from the INSERT column list or from the comparison or assignment
+// it appears in; parameters whose column cannot be told are kept.
+func SQLParams(sql string, params []interface{}) []interface{} {
+	columns := placeholderColumns(sql)
+	redacted := make([]interface{}, len(params))
+	copy(redacted, params)
+	for i := range redacted {
+		if i < len(columns) && IsSensitiveKey(columns[i]) {
+			redacted[i] = Placeholder
+		}
+	}
+	return redacted
+}
+
+// placeholderColumns returns the column each "?" placeholder of sql is
+// bound to, or "" where it cannot be told.
+func placeholderColumns(sql string) []string {
+	var insertColumns []string
+	valuesAt := -1
+	if m := insertColumnsPattern.FindStringSubmatchIndex(sql); m != nil {
+		for _, column := range strings.Split(sql[m[2]:m[3]], ",") {
+			insertColumns = append(insertColumns, strings.Trim(strings.TrimSpace(column), "`\""))
+		}
+		valuesAt = m[1]
+	}
+
+	var columns []string
+	var quote byte
+	listColumn := ""
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == ')':
+			listColumn = ""
+		case c == '?':
+			column := ""
+			if valuesAt >= 0 && i > valuesAt && len(insertColumns) > 0 {
+				column = insertColumns[len(columns)%len(insertColumns)]
+			} else if m := comparisonPattern.FindStringSubmatch(sql[max(0, i-80):i]); m != nil {
+				column = m[1]
+				if strings.HasSuffix(strings.TrimSpace(sql[:i]), "(") {
+					listColumn = column
+				}
+			} else if strings.HasSuffix(strings.TrimSpace(sql[:i]), ",") {
+				column = listColumn
+			}
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
@@ -0,0 +1,30 @@
+package urlsign
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	signer := New("secret")
+	now := time.Unix(1700000000, 0)
+	query := signer.Sign("/files/kyc/a.jpg", now.Add(time.Minute))
+
+	if err := signer.Verify("/files/kyc/a.jpg", query, now); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := signer.Verify("/files/kyc/b.jpg", query, now); err != ErrInvalidSignature {
+		t.Errorf("other path: err = %v, want ErrInvalidSignature", err)
+	}
+	if err := New("other").Verify("/files/kyc/a.jpg", query, now); err != ErrInvalidSignature {
+		t.Errorf("other secret: err = %v, want ErrInvalidSignature", err)
+	}
+	if err := signer.Verify("/files/kyc/a.jpg", query, now.Add(2*time.Minute)); err != ErrExpired {
+		t.Errorf("expired: err = %v, want ErrExpired", err)
+	}
+
+	query.Set("expires", "9999999999")
+	if err := signer.Verify("/files/kyc/a.jpg", query, now); err != ErrInvalidSignature {
+		t.Errorf("extended expiry: err = %v, want ErrInvalidSignature", err)
+	}
+}
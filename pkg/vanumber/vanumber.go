@@ -0,0 +1,80 @@
+// Package vanumber builds and checks virtual account numbers.
+//
+// A number is the bank's company prefix, one digit telling whether the
+// account belongs to a customer or to a single contract, the owner's id
+// padded to nine digits and a Luhn check digit, so that a mistyped number is
+// rejected before it is looked up.
+package vanumber
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Kind byte
+
+const (
+	KindCustomer Kind = '1'
+	KindContract Kind = '2'
+)
+
+const idDigits = 9
+
+var (
+	ErrInvalidPrefix = errors.New("vanumber: prefix must be 1 to 8 digits")
+	ErrIDTooLarge    = errors.New("vanumber: id does not fit in the number")
+	ErrInvalidKind   = errors.New("vanumber: unknown kind")
+)
+
+// New returns the virtual account number of the given owner.
+func New(prefix string, kind Kind, id uint) (string, error) {
+	if len(prefix) == 0 || len(prefix) > 8 || !digits(prefix) {
+		return "", ErrInvalidPrefix
+	}
+	if kind != KindCustomer && kind != KindContract {
+		return "", ErrInvalidKind
+	}
+	if id >= 1e9 {
+		return "", ErrIDTooLarge
+	}
+
+	body := fmt.Sprintf("%s%c%0*d", prefix, kind, idDigits, id)
+	return body + string(checkDigit(body)), nil
+}
+
+// Valid reports whether number is made of digits and ends with the right
+// check digit.
+func Valid(number string) bool {
+	if len(number) < 2 || !digits(number) {
+		return false
+	}
+	body := number[:len(number)-1]
+	return number[len(number)-1] == checkDigit(body)
+}
+
+// checkDigit computes the Luhn check digit of a string of digits.
+func checkDigit(body string) byte {
+	sum := 0
+	double := true
+	for i := len(body) - 1; i >= 0; i-- {
+		d := int(body[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func digits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package vanumber
+
+import "testing"
+
+func TestNew(t *testing.T) {
+	number, err := New("88908", KindContract, 42)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "889082000000042"; number[:len(number)-1] != want {
+		t.Errorf("number = %s, want prefix %s", number, want)
+	}
+	if !Valid(number) {
+		t.Errorf("%s should be valid", number)
+	}
+}
+
+func TestValid_KnownLuhnNumbers(t *testing.T) {
+	for _, number := range []string{"79927398713", "4111111111111111"} {
+		if !Valid(number) {
+			t.Errorf("%s should be valid", number)
+		}
+	}
+	for _, number := range []string{"79927398710", "4111111111111112", "", "12a4"} {
+		if Valid(number) {
+			t.Errorf("%s should be invalid", number)
+		}
+	}
+}
+
+func TestNew_Rejects(t *testing.T) {
+	if _, err := New("88a", KindCustomer, 1); err != ErrInvalidPrefix {
+		t.Errorf("err = %v, want ErrInvalidPrefix", err)
+	}
+	if _, err := New("88908", KindCustomer, 1e9); err != ErrIDTooLarge {
+		t.Errorf("err = %v, want ErrIDTooLarge", err)
+	}
+	if _, err := New("88908", Kind('9'), 1); err != ErrInvalidKind {
+		t.Errorf("err = %v, want ErrInvalidKind", err)
+	}
+}
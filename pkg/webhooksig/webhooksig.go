@@ -0,0 +1,89 @@
+// Package webhooksig signs and verifies webhook requests.
+//
+// The signature header has the form "t=<unix seconds>,v1=<hex HMAC-SHA256>"
+// where the MAC covers "<timestamp>.<body>". Binding the timestamp into the
+// MAC lets the receiver reject a captured request replayed outside the
+// tolerance window; replays inside the window must be made harmless by the
+// receiver's own idempotency.
+package webhooksig
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header is the HTTP header carrying the signature.
+const Header = "X-Signature"
+
+var (
+	ErrMissingSignature = errors.New("webhooksig: missing signature")
+	ErrMalformed        = errors.New("webhooksig: malformed signature header")
+	ErrMismatch         = errors.New("webhooksig: signature mismatch")
+	ErrExpired          = errors.New("webhooksig: timestamp outside tolerance")
+)
+
+// Sign returns the signature header value for body sent at ts.
+func Sign(secret []byte, ts time.Time, body []byte) string {
+	t := strconv.FormatInt(ts.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac(secret, t, body))
+}
+
+// Verify checks header against body. The timestamp must be within tolerance
+// of now in either direction.
+func Verify(secret []byte, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	if header == "" {
+		return ErrMissingSignature
+	}
+
+	var t string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return ErrMalformed
+		}
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			sig, err := hex.DecodeString(value)
+			if err != nil {
+				return ErrMalformed
+			}
+			signatures = append(signatures, sig)
+		}
+	}
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrMalformed
+	}
+
+	expected := mac(secret, t, body)
+	matched := false
+	for _, sig := range signatures {
+		if hmac.Equal(sig, expected) {
+			matched = true
+		}
+	}
+	if !matched {
+		return ErrMismatch
+	}
+
+	if skew := now.Sub(time.Unix(unix, 0)); skew > tolerance || skew < -tolerance {
+		return ErrExpired
+	}
+	return nil
+}
+
+func mac(secret []byte, t string, body []byte) []byte {
+	h := hmac.New(sha256.New, secret)
+	h.Write([]byte(t))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,38 @@
+package webhooksig
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVerify(t *testing.T) {
+	secret := []byte("secret")
+	body := []byte(`{"va_number":"8890820000000429","amount":1000}`)
+	sent := time.Unix(1700000000, 0)
+	header := Sign(secret, sent, body)
+
+	tests := []struct {
+		name   string
+		secret []byte
+		header string
+		body   []byte
+		now    time.Time
+		want   error
+	}{
+		{"valid", secret, header, body, sent.Add(time.Minute), nil},
+		{"tampered body", secret, header, []byte(`{"va_number":"8890820000000429","amount":9000}`), sent, ErrMismatch},
+		{"wrong secret", []byte("other"), header, body, sent, ErrMismatch},
+		{"replayed later", secret, header, body, sent.Add(6 * time.Minute), ErrExpired},
+		{"from the future", secret, header, body, sent.Add(-6 * time.Minute), ErrExpired},
+		{"missing", secret, "", body, sent, ErrMissingSignature},
+		{"malformed", secret, "garbage", body, sent, ErrMalformed},
+		{"no v1", secret, "t=1700000000", body, sent, ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify(tt.secret, tt.header, tt.body, tt.now, 5*time.Minute); err != tt.want {
+				t.Errorf("Verify() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package routing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"xyz-multifinance/config"
+	"xyz-multifinance/database"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/storage"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/gorm"
+)
+
+// RunCommand runs a maintenance command given on the command line instead
+// of starting the server. Every command but migrate needs an up-to-date
+// schema.
+func RunCommand(ctx context.Context, db *gorm.DB, cfg config.Config, args []string) error {
+	if args[0] == "migrate" {
+		return migrate(db, args[1:])
+	}
+	if err := database.CheckSchema(db); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "reencrypt":
+		return reencrypt(ctx, db, cfg)
+	case "gc":
+		return collectGarbage(ctx, db, cfg, args[1:])
+	case "seed":
+		return seed(ctx, db, cfg, args[1:])
+	}
+	return fmt.Errorf("unknown command %q (available: migrate, reencrypt, gc, seed)", args[0])
+}
+
+// migrate applies, reverts or lists the schema migrations built into the
+// binary: `migrate up`, `migrate down [-steps N]`, `migrate force VERSION` or
+// `migrate status`.
+func migrate(db *gorm.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: migrate up|down|force|status")
+	}
+	migrator, err := database.NewMigrator(db, database.Migrations())
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			fmt.Printf("applied: %d_%s\n", migration.Version, migration.Name)
+		}
+		if err == nil && len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+		return err
+	case "down":
+		flags := flag.NewFlagSet("migrate down", flag.ContinueOnError)
+		steps := flags.Int("steps", 1, "number of migrations to revert")
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *steps < 1 {
+			return errors.New("-steps must be at least 1")
+		}
+		reverted, err := migrator.Down(*steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted: %d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+	case "force":
+		if len(args) != 2 {
+			return errors.New("usage: migrate force VERSION")
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || version < 0 {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		if err := migrator.Force(version); err != nil {
+			return err
+		}
+		fmt.Printf("schema recorded at version %d\n", version)
+		return nil
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			switch {
+			case status.Dirty:
+				state = "dirty"
+			case status.Applied && status.AppliedAt != nil:
+				state = "applied " + status.AppliedAt.Format(time.RFC3339)
+			case status.Applied:
+				state = "applied"
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, state)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown migrate command %q (available: up, down, force, status)", args[0])
+}
+
+func reencrypt(ctx context.Context, db *gorm.DB, cfg config.Config) error {
+	store, _ := blobStores(cfg)
+	encrypted, ok := store.(*storage.EncryptedStore)
+	if !ok {
+		return errors.New("ENCRYPTION_KEYS and ENCRYPTION_ACTIVE_KEY must be set")
+	}
+
+	reencryptionUC := usecase.NewReencryptionUsecase(repository.NewCustomerRepository(db), encrypted)
+	result, err := reencryptionUC.Reencrypt(ctx)
+	if result != nil {
+		fmt.Printf("customers re-encrypted: %d\n", result.Customers)
+		fmt.Printf("objects checked: %d, rewrapped: %d, moved: %d, missing: %d\n", result.Objects, result.ObjectsRewrapped, result.ObjectsMoved, result.ObjectsMissing)
+	}
+	return err
+}
+
+// collectGarbage reports stored objects neither a customer nor a privacy
+// export references and customer photos whose object is gone. Orphans are only deleted with -delete.
+func collectGarbage(ctx context.Context, db *gorm.DB, cfg config.Config, args []string) error {
+	flags := flag.NewFlagSet("gc", flag.ContinueOnError)
+	remove := flags.Bool("delete", false, "delete orphaned objects instead of only listing them")
+	minAge := flags.Duration("min-age", 24*time.Hour, "leave objects younger than this alone")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	store, _ := blobStores(cfg)
+	gcUC := usecase.NewStorageGCUsecase(repository.NewCustomerRepository(db), repository.NewPrivacyRequestRepository(db), store)
+	result, err := gcUC.Collect(ctx, usecase.StorageGCOptions{Delete: *remove, MinAge: *minAge})
+	if result != nil {
+		for _, key := range result.Orphans {
+			fmt.Printf("orphan: %s\n", key)
+		}
+		for _, missing := range result.Missing {
+			fmt.Printf("missing: customer %d %s %s\n", missing.CustomerID, missing.Column, missing.Key)
+		}
+		fmt.Printf("customers: %d, objects: %d, orphans: %d (deleted: %d), missing: %d\n",
+			result.Customers, result.Objects, len(result.Orphans), result.Deleted, len(result.Missing))
+	}
+	return err
+}
+
+// seed loads the fixtures of the current environment, or the file given with
+// -file, into the database. It never runs in production.
+func seed(ctx context.Context, db *gorm.DB, cfg config.Config, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	env := flags.String("env", cfg.AppEnv, "environment whose built-in fixtures are loaded")
+	file := flags.String("file", "", "load fixtures from this YAML file instead")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if strings.EqualFold(cfg.AppEnv, "production") || strings.EqualFold(*env, "production") {
+		return errors.New("refusing to seed a production database")
+	}
+
+	var data []byte
+	var err error
+	if *file != "" {
+		data, err = os.ReadFile(*file)
+	} else {
+		data, err = fs.ReadFile(database.Seeds(), *env+".yaml")
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("no built-in fixtures for environment %q", *env)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	var fixtures usecase.SeedFixtures
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&fixtures); err != nil {
+		return fmt.Errorf("invalid fixtures: %w", err)
+	}
+	for i, user := range fixtures.Users {
+		if user.Password == "" {
+			fixtures.Users[i].Password = os.Getenv(seedPasswordEnv(user.Username))
+		}
+	}
+
+	userRepo := repository.NewUserRepository(db)
+	customerRepo := repository.NewCustomerRepository(db)
+	limitRepo := repository.NewLimitRepository(db)
+	transactionRepo := repository.NewTransactionRepository(db)
+	installmentRepo := repository.NewInstallmentRepository(db)
+	disbursementRepo := repository.NewDisbursementRepository(db)
+	auditUC := usecase.NewAuditUsecase(repository.NewAuditLogRepository(db))
+
+	transactionUC := usecase.NewTransactionUsecase(
+		transactionRepo, limitRepo, customerRepo, installmentRepo,
+		repository.NewPartnerRepository(db), repository.NewOutletRepository(db), repository.NewAssetRepository(db),
+		repository.NewDownPaymentRuleRepository(db), disbursementRepo,
+		auditUC,
+		transactionPolicy(cfg),
+		db,
+	)
+	disbursementUC := usecase.NewDisbursementUsecase(
+		disbursementRepo, transactionRepo, installmentRepo, repository.NewLedgerRepository(db), customerRepo,
+		payoutProvider(cfg), disbursementPolicy(cfg), auditUC, db,
+	)
+	seedUC := usecase.NewSeedUsecase(
+		userRepo, customerRepo, limitRepo, transactionRepo,
+		usecase.NewCustomerUsecase(customerRepo, userRepo, auditUC),
+		usecase.NewLimitUsecase(limitRepo, auditUC),
+		transactionUC,
+		disbursementUC,
+	)
+
+	result, err := seedUC.Seed(ctx, &fixtures, time.Now())
+	if result != nil {
+		for _, row := range []struct {
+			name  string
+			count usecase.SeedCount
+		}{
+			{"users", result.Users},
+			{"customers", result.Customers},
+			{"limits", result.Limits},
+			{"transactions", result.Transactions},
+		} {
+			fmt.Printf("%s: %d created, %d existing\n", row.name, row.count.Created, row.count.Existing)
+		}
+		fmt.Printf("contracts disbursed: %d\n", result.Disbursed)
+
+		usernames := make([]string, 0, len(result.Passwords))
+		for username := range result.Passwords {
+			usernames = append(usernames, username)
+		}
+		sort.Strings(usernames)
+		for _, username := range usernames {
+			fmt.Printf("generated password for %s: %s (shown only once)\n", username, result.Passwords[username])
+		}
+	}
+	return err
+}
+
+var envNameUnsafe = regexp.MustCompile(`[^A-Z0-9]+`)
+
+// seedPasswordEnv is the environment variable holding the password of a
+// fixture user that has none in the file, e.g. SEED_PASSWORD_ADMIN.
+func seedPasswordEnv(username string) string {
+	return "SEED_PASSWORD_" + envNameUnsafe.ReplaceAllString(strings.ToUpper(username), "_")
+}
@@ -0,0 +1,117 @@
+package routing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"xyz-multifinance/config"
+	"xyz-multifinance/internal/model"
+	"xyz-multifinance/internal/repository"
+	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/logger"
+	"xyz-multifinance/pkg/audit"
+	"xyz-multifinance/scheduler"
+
+	"gorm.io/gorm"
+)
+
+// StartJobs registers the background jobs that run alongside the HTTP server.
+func StartJobs(ctx context.Context, db *gorm.DB, cfg config.Config) {
+	transactionRepo := repository.NewTransactionRepository(db)
+	installmentRepo := repository.NewInstallmentRepository(db)
+	customerRepo := repository.NewCustomerRepository(db)
+	ledgerRepo := repository.NewLedgerRepository(db)
+	accrualRepo := repository.NewInterestAccrualRepository(db)
+	disbursementRepo := repository.NewDisbursementRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	auditUC := usecase.NewAuditUsecase(repository.NewAuditLogRepository(db))
+
+	overdueUC := usecase.NewOverdueUsecase(transactionRepo, installmentRepo, customerRepo, ledgerRepo, lateFeePolicy(cfg), db)
+	accrualUC := usecase.NewAccrualUsecase(accrualRepo, transactionRepo, installmentRepo, ledgerRepo, cfg.InterestAccrualMode, db)
+	disbursementUC := usecase.NewDisbursementUsecase(
+		disbursementRepo, transactionRepo, installmentRepo, ledgerRepo, customerRepo,
+		payoutProvider(cfg), disbursementPolicy(cfg), auditUC, db,
+	)
+
+	blobStore, _ := blobStores(cfg)
+	privacyUC := usecase.NewPrivacyUsecase(
+		repository.NewPrivacyRequestRepository(db), customerRepo, repository.NewUserRepository(db),
+		transactionRepo, installmentRepo, paymentRepo, blobStore, privacyPolicy(cfg), db,
+	)
+
+	scheduler.Daily(ctx, "overdue", cfg.OverdueJobHour, cfg.OverdueJobMinute, auditedJob(ctx, auditUC, "overdue", func(ctx context.Context, now time.Time) (interface{}, bool, error) {
+		result, err := overdueUC.Recalculate(now)
+		return result, true, err
+	}))
+
+	scheduler.Daily(ctx, "interest-accrual", cfg.AccrualJobHour, cfg.AccrualJobMinute, auditedJob(ctx, auditUC, "interest-accrual", func(ctx context.Context, now time.Time) (interface{}, bool, error) {
+		result, err := accrualUC.Run(now)
+		return result, true, err
+	}))
+
+	scheduler.Every(ctx, "disbursement", time.Duration(cfg.DisbursementPollSeconds)*time.Second, auditedJob(ctx, auditUC, "disbursement", func(ctx context.Context, now time.Time) (interface{}, bool, error) {
+		result, err := disbursementUC.ProcessDue(ctx, now)
+		return result, result != nil && (result.Attempted > 0 || result.Released > 0), err
+	}))
+
+	scheduler.Every(ctx, "privacy-requests", time.Duration(cfg.PrivacyPollSeconds)*time.Second, auditedJob(ctx, auditUC, "privacy-requests", func(ctx context.Context, now time.Time) (interface{}, bool, error) {
+		result, err := privacyUC.ProcessPending(ctx, now)
+		return result, result != nil && *result != (usecase.PrivacyRunResult{}), err
+	}))
+}
+
+// auditedJob runs job as the system, so the changes it makes are attributed
+// to it, and records a summary of every run that changed something. Polling
+// jobs report whether they did; the daily ones always do.
+func auditedJob(ctx context.Context, auditor usecase.Auditor, name string, job func(ctx context.Context, now time.Time) (interface{}, bool, error)) scheduler.Job {
+	return func(now time.Time) error {
+		runCtx := audit.NewContext(ctx, audit.Actor{
+			Role:      audit.SystemRole,
+			RequestID: fmt.Sprintf("%s-%s", name, now.Format("20060102T150405")),
+		})
+		result, changed, err := job(runCtx, now)
+		if !changed && err == nil {
+			return nil
+		}
+
+		after := map[string]interface{}{"result": result}
+		if err != nil {
+			after["error"] = err.Error()
+		}
+		if auditErr := auditor.Record(runCtx, usecase.AuditEvent{
+			Entity:   model.AuditEntityJob,
+			EntityID: name,
+			Action:   model.AuditActionRun,
+			After:    after,
+		}); auditErr != nil {
+			logger.Log.WithError(auditErr).WithField("job", name).Error("failed to record audit entry for job run")
+		}
+		return err
+	}
+}
+
+func transactionPolicy(cfg config.Config) usecase.TransactionPolicy {
+	return usecase.TransactionPolicy{
+		OTRTolerancePercent: cfg.OTRTolerancePercent,
+		InterestRounding:    cfg.InterestRounding,
+		DirectPricing: usecase.PricingScheme{
+			AdminFee:        cfg.DirectAdminFee,
+			InterestRateBps: cfg.DirectInterestRateBps,
+		},
+	}
+}
+
+func lateFeePolicy(cfg config.Config) usecase.LateFeePolicy {
+	return usecase.LateFeePolicy{
+		DailyBps:   cfg.LateFeeDailyBps,
+		GraceDays:  cfg.LateFeeGraceDays,
+		CapPercent: cfg.LateFeeCapPercent,
+	}
+}
+
+func privacyPolicy(cfg config.Config) usecase.PrivacyPolicy {
+	return usecase.PrivacyPolicy{
+		ExportRetention: time.Duration(cfg.PrivacyExportRetentionHours) * time.Hour,
+	}
+}
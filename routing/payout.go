@@ -0,0 +1,26 @@
+package routing
+
+import (
+	"time"
+
+	"xyz-multifinance/config"
+	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/payout"
+)
+
+func payoutProvider(cfg config.Config) payout.Provider {
+	if cfg.PayoutProvider == "mock" {
+		return payout.NewMockProvider(cfg.PayoutCallbackToken)
+	}
+	return payout.NewFileProvider(cfg.PayoutFileDir, cfg.PayoutCallbackToken)
+}
+
+func disbursementPolicy(cfg config.Config) usecase.DisbursementPolicy {
+	return usecase.DisbursementPolicy{
+		MaxAttempts:    cfg.DisbursementMaxAttempts,
+		RetryBaseDelay: time.Duration(cfg.DisbursementRetryBaseSeconds) * time.Second,
+		RetryMaxDelay:  time.Duration(cfg.DisbursementRetryMaxSeconds) * time.Second,
+		StaleAfter:     time.Duration(cfg.DisbursementStaleSeconds) * time.Second,
+		BatchSize:      100,
+	}
+}
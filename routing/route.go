@@ -1,11 +1,14 @@
 package routing
 
 import (
+	"time"
+
 	"xyz-multifinance/config"
 	"xyz-multifinance/internal/delivery/http"
 	"xyz-multifinance/internal/repository"
 	"xyz-multifinance/internal/usecase"
 	"xyz-multifinance/middleware"
+	"xyz-multifinance/pkg/urlsign"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -13,6 +16,7 @@ import (
 
 func SetupRoutes(r *gin.Engine, db *gorm.DB, cfg config.Config) {
 	// Middleware global
+	r.Use(middleware.RequestID())
 	r.Use(middleware.Logger())
 	r.Use(middleware.Recovery())
 	r.Use(middleware.CORS())
@@ -22,19 +26,136 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, cfg config.Config) {
 	transactionRepo := repository.NewTransactionRepository(db)
 	limitRepo := repository.NewLimitRepository(db)
 	customerRepo := repository.NewCustomerRepository(db)
+	installmentRepo := repository.NewInstallmentRepository(db)
+	partnerRepo := repository.NewPartnerRepository(db)
+	outletRepo := repository.NewOutletRepository(db)
+	assetRepo := repository.NewAssetRepository(db)
+	dpRuleRepo := repository.NewDownPaymentRuleRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	settlementQuoteRepo := repository.NewSettlementQuoteRepository(db)
+	cancellationRepo := repository.NewCancellationRepository(db)
+	ledgerRepo := repository.NewLedgerRepository(db)
+	accrualRepo := repository.NewInterestAccrualRepository(db)
+	disbursementRepo := repository.NewDisbursementRepository(db)
+	vaRepo := repository.NewVirtualAccountRepository(db)
+	reconciliationRepo := repository.NewReconciliationRepository(db)
+	kycDocumentAccessRepo := repository.NewKYCDocumentAccessRepository(db)
+	privacyRequestRepo := repository.NewPrivacyRequestRepository(db)
+	auditLogRepo := repository.NewAuditLogRepository(db)
 
 	userUC := usecase.NewUserUsecase(userRepo)
 	userHandler := http.NewAuthHandler(userUC)
 
-	customerUC := usecase.NewCustomerUsecase(customerRepo, userRepo)
-	customerHandler := http.NewCustomerHandler(customerUC)
+	auditUC := usecase.NewAuditUsecase(auditLogRepo)
+	auditHandler := http.NewAuditHandler(auditUC)
+
+	customerUC := usecase.NewCustomerUsecase(customerRepo, userRepo, auditUC)
+	blobStore, servedStore := blobStores(cfg)
+	customerHandler := http.NewCustomerHandler(customerUC, blobStore, uploadPolicy(cfg))
+
+	kycDocumentUC := usecase.NewKYCDocumentUsecase(
+		customerRepo, kycDocumentAccessRepo, blobStore, urlsign.New(cfg.StorageURLSecret), kycDocumentPolicy(cfg),
+	)
+	kycDocumentHandler := http.NewKYCDocumentHandler(kycDocumentUC)
+
+	privacyUC := usecase.NewPrivacyUsecase(
+		privacyRequestRepo, customerRepo, userRepo, transactionRepo, installmentRepo, paymentRepo,
+		blobStore, privacyPolicy(cfg), db,
+	)
+	privacyHandler := http.NewPrivacyHandler(privacyUC)
 
-	limitUC := usecase.NewLimitUsecase(limitRepo, transactionRepo)
+	limitUC := usecase.NewLimitUsecase(limitRepo, auditUC)
 	limitHandler := http.NewLimitHandler(limitUC)
 
-	transactionUC := usecase.NewTransactionUsecase(transactionRepo, limitRepo, customerRepo, db)
+	transactionUC := usecase.NewTransactionUsecase(
+		transactionRepo, limitRepo, customerRepo, installmentRepo, partnerRepo, outletRepo, assetRepo, dpRuleRepo, disbursementRepo,
+		auditUC,
+		transactionPolicy(cfg),
+		db,
+	)
 	transactionHandler := http.NewTransactionHandler(transactionUC)
 
+	exposureUC := usecase.NewExposureUsecase(customerRepo, limitRepo, transactionRepo)
+	exposureHandler := http.NewExposureHandler(exposureUC)
+
+	partnerUC := usecase.NewPartnerUsecase(partnerRepo, outletRepo)
+	partnerHandler := http.NewPartnerHandler(partnerUC)
+
+	assetUC := usecase.NewAssetUsecase(assetRepo)
+	assetHandler := http.NewAssetHandler(assetUC)
+
+	dpRuleUC := usecase.NewDownPaymentRuleUsecase(dpRuleRepo)
+	dpRuleHandler := http.NewDownPaymentRuleHandler(dpRuleUC)
+
+	paymentUC := usecase.NewPaymentUsecase(paymentRepo, transactionRepo, installmentRepo, ledgerRepo, customerRepo, db)
+	paymentHandler := http.NewPaymentHandler(paymentUC)
+
+	vaUC := usecase.NewVirtualAccountUsecase(
+		vaRepo, customerRepo, transactionRepo, paymentRepo, paymentUC,
+		usecase.VirtualAccountPolicy{
+			Prefix:           cfg.VAPrefix,
+			BankCode:         cfg.VABankCode,
+			WebhookSecret:    cfg.VAWebhookSecret,
+			WebhookTolerance: time.Duration(cfg.VAWebhookToleranceSeconds) * time.Second,
+		},
+	)
+	vaHandler := http.NewVirtualAccountHandler(vaUC)
+
+	reconciliationUC := usecase.NewReconciliationUsecase(
+		reconciliationRepo, vaRepo, transactionRepo, installmentRepo, paymentRepo, paymentUC,
+		usecase.ReconciliationPolicy{
+			VAPrefix:        cfg.VAPrefix,
+			MatchWindowDays: cfg.ReconciliationMatchWindowDays,
+		},
+		db,
+	)
+	reconciliationHandler := http.NewReconciliationHandler(reconciliationUC)
+
+	statementUC := usecase.NewStatementUsecase(
+		transactionRepo, customerRepo, installmentRepo, paymentRepo,
+		usecase.StatementBranding{
+			CompanyName: cfg.StatementCompanyName,
+			Address:     cfg.StatementCompanyAddress,
+			Contact:     cfg.StatementCompanyContact,
+			LogoPath:    cfg.StatementLogoPath,
+		},
+	)
+	statementHandler := http.NewStatementHandler(statementUC)
+
+	overdueUC := usecase.NewOverdueUsecase(transactionRepo, installmentRepo, customerRepo, ledgerRepo, lateFeePolicy(cfg), db)
+	overdueHandler := http.NewOverdueHandler(overdueUC)
+
+	settlementUC := usecase.NewSettlementUsecase(
+		settlementQuoteRepo, transactionRepo, installmentRepo, paymentRepo, ledgerRepo, customerRepo,
+		usecase.SettlementPolicy{
+			InterestRebatePercent: cfg.SettlementInterestRebatePercent,
+			PenaltyPercent:        cfg.SettlementPenaltyPercent,
+			QuoteValidity:         time.Duration(cfg.SettlementQuoteValidityHours) * time.Hour,
+		},
+		db,
+	)
+	settlementHandler := http.NewSettlementHandler(settlementUC)
+
+	cancellationUC := usecase.NewCancellationUsecase(
+		cancellationRepo, transactionRepo, installmentRepo, paymentRepo, ledgerRepo, disbursementRepo, customerRepo,
+		usecase.CancellationPolicy{CoolingOffDays: cfg.CancellationCoolingOffDays},
+		db,
+	)
+	cancellationHandler := http.NewCancellationHandler(cancellationUC)
+
+	ledgerUC := usecase.NewLedgerUsecase(ledgerRepo)
+	writeOffUC := usecase.NewWriteOffUsecase(transactionRepo, ledgerRepo, db)
+	ledgerHandler := http.NewLedgerHandler(ledgerUC, writeOffUC)
+
+	accrualUC := usecase.NewAccrualUsecase(accrualRepo, transactionRepo, installmentRepo, ledgerRepo, cfg.InterestAccrualMode, db)
+	accrualHandler := http.NewAccrualHandler(accrualUC)
+
+	disbursementUC := usecase.NewDisbursementUsecase(
+		disbursementRepo, transactionRepo, installmentRepo, ledgerRepo, customerRepo,
+		payoutProvider(cfg), disbursementPolicy(cfg), auditUC, db,
+	)
+	disbursementHandler := http.NewDisbursementHandler(disbursementUC)
+
 	// Public routes
 	api := r.Group("/api/v1")
 	api.GET("/health", func(c *gin.Context) {
@@ -42,10 +163,18 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, cfg config.Config) {
 	})
 
 	api.POST("/login", userHandler.Login)
+	// Provider webhooks are audited as the system.
+	api.POST("/payouts/callback", middleware.Audit(auditUC), disbursementHandler.PayoutCallback)
+	api.POST("/webhooks/va-payments", middleware.Audit(auditUC), vaHandler.PaymentNotification)
+	api.GET("/kyc-documents/:customer_id/:type", kycDocumentHandler.OpenSignedDocument)
+	if servedStore != nil {
+		api.GET("/files/*key", http.NewFileHandler(servedStore).Download)
+	}
 
 	// Protected routes
 	protected := api.Group("/")
 	protected.Use(middleware.Auth(cfg))
+	protected.Use(middleware.Audit(auditUC))
 
 	protected.POST("/users", userHandler.CreateUser)
 
@@ -54,6 +183,16 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, cfg config.Config) {
 	protected.GET("/customers/:nik", customerHandler.GetCustomerByNIK)
 	protected.PUT("/customers/:nik", customerHandler.UpdateCustomer)
 	protected.DELETE("/customers/:nik", customerHandler.DeleteCustomer)
+	protected.GET("/customers/:nik/exposure", exposureHandler.GetCustomerExposure)
+	protected.GET("/customers/:nik/delinquency", overdueHandler.GetCustomerDelinquency)
+	protected.GET("/customers/:nik/transactions", transactionHandler.GetTransactionsByCustomer)
+	protected.GET("/customers/:nik/documents/:type", kycDocumentHandler.GetDocument)
+	protected.POST("/customers/:nik/documents/:type/url", kycDocumentHandler.IssueSignedURL)
+	protected.GET("/customers/:nik/document-access-log", middleware.AdminOnly(), kycDocumentHandler.GetAccessLog)
+	protected.POST("/customers/:nik/privacy-requests", middleware.AdminOnly(), privacyHandler.CreateRequest)
+	protected.GET("/customers/:nik/privacy-requests", middleware.AdminOnly(), privacyHandler.GetRequestsByCustomer)
+	protected.POST("/customers/:nik/virtual-accounts", vaHandler.CreateCustomerAccount)
+	protected.GET("/customers/:nik/virtual-accounts", vaHandler.GetCustomerAccounts)
 
 	// Limit routes
 	protected.POST("/limits", limitHandler.CreateLimit)
@@ -65,7 +204,85 @@ func SetupRoutes(r *gin.Engine, db *gorm.DB, cfg config.Config) {
 
 	// Transaction routes
 	protected.POST("/transactions", transactionHandler.CreateTransaction)
-	protected.GET("/transactions/:nik", transactionHandler.GetTransactionsByCustomer)
+	protected.GET("/transactions/:id", transactionHandler.GetTransactionByID)
+	protected.GET("/transactions/:id/statement", statementHandler.GetStatement)
+	protected.GET("/transactions/:id/settlement-quote", settlementHandler.GetSettlementQuote)
+	protected.POST("/transactions/:id/settle", middleware.AdminOnly(), settlementHandler.Settle)
+	protected.POST("/transactions/:id/cancel", middleware.AdminOnly(), cancellationHandler.CancelTransaction)
+	protected.GET("/transactions/:id/cancellation", cancellationHandler.GetCancellation)
+	protected.POST("/transactions/:id/write-off", middleware.AdminOnly(), ledgerHandler.WriteOffTransaction)
+	protected.GET("/transactions/:id/disbursement", disbursementHandler.GetDisbursement)
+	protected.POST("/transactions/:id/disbursement/complete", middleware.AdminOnly(), disbursementHandler.CompleteDisbursement)
+	protected.POST("/transactions/:id/virtual-account", vaHandler.CreateContractAccount)
+
+	// Disbursement routes
+	protected.PUT("/disbursements/:id", middleware.AdminOnly(), disbursementHandler.UpdateDisbursement)
+	protected.POST("/disbursements/:id/retry", middleware.AdminOnly(), disbursementHandler.RetryDisbursement)
+	protected.POST("/disbursements/process", middleware.AdminOnly(), disbursementHandler.ProcessDisbursements)
+
+	// Data subject (PDP) request routes
+	protected.GET("/privacy-requests/:id", middleware.AdminOnly(), privacyHandler.GetRequest)
+	protected.GET("/privacy-requests/:id/download", middleware.AdminOnly(), privacyHandler.DownloadExport)
+	protected.POST("/privacy-requests/process", middleware.AdminOnly(), privacyHandler.ProcessRequests)
+
+	// Audit log routes
+	protected.GET("/audit-logs", middleware.AdminOnly(), auditHandler.GetAuditLogs)
+	protected.GET("/audit-logs/verify", middleware.AdminOnly(), auditHandler.VerifyChain)
+
+	// Payment routes
+	protected.POST("/payments", middleware.AdminOnly(), paymentHandler.CreatePayment)
+	protected.GET("/payments/transaction/:transaction_id", paymentHandler.GetPaymentsByTransaction)
+
+	// Bank statement reconciliation routes
+	protected.POST("/reconciliation/imports", middleware.AdminOnly(), reconciliationHandler.ImportStatement)
+	protected.GET("/reconciliation/imports", middleware.AdminOnly(), reconciliationHandler.GetImports)
+	protected.GET("/reconciliation/imports/:id/report", middleware.AdminOnly(), reconciliationHandler.GetReport)
+	protected.GET("/reconciliation/queue", middleware.AdminOnly(), reconciliationHandler.GetQueue)
+	protected.POST("/reconciliation/lines/:id/resolve", middleware.AdminOnly(), reconciliationHandler.ResolveLine)
+	protected.POST("/reconciliation/lines/:id/ignore", middleware.AdminOnly(), reconciliationHandler.IgnoreLine)
+
+	// Overdue routes
+	protected.POST("/overdue/recalculate", middleware.AdminOnly(), overdueHandler.Recalculate)
+
+	// Ledger routes
+	protected.GET("/ledger/accounts", middleware.AdminOnly(), ledgerHandler.GetAccounts)
+	protected.GET("/ledger/accounts/:code/statement", middleware.AdminOnly(), ledgerHandler.GetAccountStatement)
+	protected.GET("/ledger/trial-balance", middleware.AdminOnly(), ledgerHandler.GetTrialBalance)
+
+	// Interest accrual routes
+	protected.POST("/accruals/run", middleware.AdminOnly(), accrualHandler.RunAccrual)
+	protected.GET("/accruals/report", middleware.AdminOnly(), accrualHandler.GetAccrualReport)
+
+	// Asset catalog routes
+	protected.GET("/assets", assetHandler.GetAssets)
+	protected.GET("/assets/:id", assetHandler.GetAssetByID)
+	protected.POST("/assets", middleware.AdminOnly(), assetHandler.CreateAsset)
+	protected.POST("/assets/import", middleware.AdminOnly(), assetHandler.ImportAssets)
+	protected.PUT("/assets/:id", middleware.AdminOnly(), assetHandler.UpdateAsset)
+	protected.DELETE("/assets/:id", middleware.AdminOnly(), assetHandler.DeleteAsset)
+
+	// Down payment rule routes
+	protected.GET("/down-payment-rules", dpRuleHandler.GetRules)
+	protected.POST("/down-payment-rules", middleware.AdminOnly(), dpRuleHandler.CreateRule)
+	protected.DELETE("/down-payment-rules/:id", middleware.AdminOnly(), dpRuleHandler.DeleteRule)
+
+	// Partner management routes
+	protected.POST("/partners", middleware.AdminOnly(), partnerHandler.CreatePartner)
+	protected.GET("/partners", middleware.AdminOnly(), partnerHandler.GetPartners)
+	protected.GET("/partners/:id", middleware.AdminOnly(), partnerHandler.GetPartnerByID)
+	protected.PUT("/partners/:id", middleware.AdminOnly(), partnerHandler.UpdatePartner)
+	protected.POST("/partners/:id/api-key", middleware.AdminOnly(), partnerHandler.RotateAPIKey)
+	protected.POST("/partners/:id/outlets", middleware.AdminOnly(), partnerHandler.CreateOutlet)
+	protected.GET("/partners/:id/outlets", middleware.AdminOnly(), partnerHandler.GetOutlets)
+
+	// Partner-scoped routes, authenticated with X-API-Key
+	partner := api.Group("/partner")
+	partner.Use(middleware.PartnerAuth(partnerUC))
+	partner.Use(middleware.Audit(auditUC))
+
+	partner.POST("/transactions", transactionHandler.CreatePartnerTransaction)
+	partner.GET("/transactions", transactionHandler.GetPartnerTransactions)
+	partner.GET("/transactions/:id", transactionHandler.GetPartnerTransactionByID)
 
 	// Handle no route/method
 	r.NoRoute(func(c *gin.Context) {
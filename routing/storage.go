@@ -0,0 +1,87 @@
+package routing
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"xyz-multifinance/config"
+	"xyz-multifinance/internal/usecase"
+	"xyz-multifinance/pkg/fieldcrypt"
+	"xyz-multifinance/pkg/imageupload"
+	"xyz-multifinance/pkg/keyring"
+	"xyz-multifinance/pkg/urlsign"
+	"xyz-multifinance/storage"
+)
+
+// ConfigureEncryption sets up field-level encryption of customer data. It
+// must run before the database is used.
+func ConfigureEncryption(cfg config.Config) {
+	ring := encryptionKeyring(cfg)
+	if ring == nil {
+		log.Println("ENCRYPTION_KEYS not set, customer data and KYC images are stored unencrypted")
+	} else if cfg.BlindIndexKey == "" {
+		log.Fatalf("BLIND_INDEX_KEY is required when ENCRYPTION_KEYS is set")
+	}
+	fieldcrypt.Configure(ring, []byte(cfg.BlindIndexKey))
+}
+
+// encryptionKeyring returns the master keyring, or nil when encryption at
+// rest is not configured.
+func encryptionKeyring(cfg config.Config) *keyring.Keyring {
+	if cfg.EncryptionKeys == "" {
+		return nil
+	}
+	ring, err := keyring.Parse(cfg.EncryptionKeys, cfg.EncryptionActiveKey)
+	if err != nil {
+		log.Fatalf("failed to configure encryption: %v", err)
+	}
+	return ring
+}
+
+// blobStores returns the configured blob store and, when the application
+// serves its signed links (filesystem or encrypted store), the same store
+// again for the download route.
+func blobStores(cfg config.Config) (storage.BlobStore, storage.ServedStore) {
+	var store storage.BlobStore
+	var served storage.ServedStore
+	if cfg.StorageBackend == "s3" {
+		s3Store, err := storage.NewS3Store(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+			PathStyle: cfg.S3PathStyle,
+		})
+		if err != nil {
+			log.Fatalf("failed to configure blob store: %v", err)
+		}
+		store = s3Store
+	} else {
+		fsStore := storage.NewFileSystemStore(cfg.StorageDir, cfg.StoragePublicURL, urlsign.New(cfg.StorageURLSecret))
+		store, served = fsStore, fsStore
+	}
+
+	if ring := encryptionKeyring(cfg); ring != nil {
+		encrypted := storage.NewEncryptedStore(store, ring, cfg.StoragePublicURL, urlsign.New(cfg.StorageURLSecret))
+		return encrypted, encrypted
+	}
+	return store, served
+}
+
+func uploadPolicy(cfg config.Config) imageupload.Policy {
+	return imageupload.Policy{
+		MaxBytes:     int64(cfg.MaxUploadSizeMB) << 20,
+		MaxDimension: cfg.UploadMaxImageDimension,
+	}
+}
+
+func kycDocumentPolicy(cfg config.Config) usecase.KYCDocumentPolicy {
+	return usecase.KYCDocumentPolicy{
+		BaseURL:       strings.TrimSuffix(cfg.AppBaseURL, "/") + "/api/v1",
+		URLTTL:        time.Duration(cfg.KYCSignedURLTTLSeconds) * time.Second,
+		MaxURLTTL:     time.Duration(cfg.KYCSignedURLMaxTTLSeconds) * time.Second,
+		ThumbnailSize: cfg.KYCThumbnailSize,
+	}
+}
@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"xyz-multifinance/logger"
+)
+
+// Job is a unit of background work. It receives the time it was triggered.
+type Job func(now time.Time) error
+
+// Daily runs job once immediately, so missed runs are caught up after a
+// restart, and then every day at the given local hour and minute until ctx
+// is cancelled.
+func Daily(ctx context.Context, name string, hour, minute int, job Job) {
+	go func() {
+		run(name, job, time.Now())
+
+		for {
+			next := nextDaily(time.Now(), hour, minute)
+			timer := time.NewTimer(time.Until(next))
+
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case now := <-timer.C:
+				run(name, job, now)
+			}
+		}
+	}()
+}
+
+// Every runs job once immediately and then every interval until ctx is
+// cancelled. A run that takes longer than interval delays the next one
+// rather than overlapping it. A non-positive interval disables the job.
+func Every(ctx context.Context, name string, interval time.Duration, job Job) {
+	if interval <= 0 {
+		logger.Log.WithField("job", name).Warn("job disabled: interval must be positive")
+		return
+	}
+
+	go func() {
+		run(name, job, time.Now())
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				run(name, job, now)
+			}
+		}
+	}()
+}
+
+func nextDaily(now time.Time, hour, minute int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, minute, 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+func run(name string, job Job, now time.Time) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			logger.Log.WithField("job", name).Errorf("job panicked: %v", rec)
+		}
+	}()
+
+	start := time.Now()
+	if err := job(now); err != nil {
+		logger.Log.WithField("job", name).Errorf("job failed: %v", err)
+		return
+	}
+	logger.Log.WithField("job", name).Infof("job finished in %s", time.Since(start))
+}
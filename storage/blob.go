@@ -0,0 +1,87 @@
+// Package storage keeps binary objects such as KYC images in a pluggable
+// blob store. Objects are addressed by slash-separated keys; callers persist
+// the key, never a filesystem path or URL.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("storage: object not found")
+	ErrInvalidKey = errors.New("storage: invalid object key")
+)
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	Size        int64
+	ContentType string
+}
+
+// ObjectEntry is an object returned by List.
+type ObjectEntry struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// BlobStore stores objects by key. Deleting a key that does not exist is not
+// an error. List returns every object whose key starts with prefix, in key
+// order. SignedURL returns a URL that lets anyone holding it read the object
+// until expiry passes.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]ObjectEntry, error)
+	SignedURL(key string, expiry time.Duration) (string, error)
+}
+
+// ServedStore is a BlobStore whose signed URLs are served by the
+// application, which checks them with Verify before streaming the object.
+type ServedStore interface {
+	BlobStore
+	Verify(key string, query url.Values) error
+}
+
+// ValidateKey rejects keys that are empty, absolute or that could escape the
+// store's root through "." or ".." segments.
+func ValidateKey(key string) error {
+	if key == "" || len(key) > 1024 || strings.HasPrefix(key, "/") || strings.ContainsAny(key, "\\\x00") {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
+
+// escapeKey percent-encodes every byte of key except the unreserved
+// characters and the "/" separators, as S3 expects in a request path.
+func escapeKey(key string) string {
+	return uriEncode(key, false)
+}
+
+func uriEncode(s string, encodeSlash bool) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&15])
+	}
+	return b.String()
+}
@@ -0,0 +1,259 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"xyz-multifinance/pkg/keyring"
+	"xyz-multifinance/pkg/urlsign"
+)
+
+// Encrypted objects start with this magic followed by a format version.
+var envelopeMagic = []byte("XYZENC")
+
+const envelopeVersion = 1
+
+var ErrCorruptEnvelope = errors.New("storage: corrupt encrypted object")
+
+// EncryptedStore encrypts objects before they reach the underlying store
+// using envelope encryption: every object gets a fresh AES-256-GCM data key,
+// and only that data key, wrapped by the active master key of the keyring,
+// is stored alongside the ciphertext. Rotating the master key therefore only
+// rewrites the small wrapped key of each object (see Rewrap).
+//
+// Objects written before encryption was enabled are returned as they are.
+// Since the underlying store only ever holds ciphertext, signed URLs point
+// at the application, which decrypts while serving them.
+type EncryptedStore struct {
+	inner   BlobStore
+	ring    *keyring.Keyring
+	baseURL string
+	signer  *urlsign.Signer
+	now     func() time.Time
+}
+
+func NewEncryptedStore(inner BlobStore, ring *keyring.Keyring, baseURL string, signer *urlsign.Signer) *EncryptedStore {
+	return &EncryptedStore{
+		inner:   inner,
+		ring:    ring,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		signer:  signer,
+		now:     time.Now,
+	}
+}
+
+func (s *EncryptedStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	sealed, err := s.seal(key, plaintext)
+	if err != nil {
+		return err
+	}
+	return s.inner.Put(ctx, key, bytes.NewReader(sealed), int64(len(sealed)), contentType)
+}
+
+func (s *EncryptedStore) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	body, info, err := s.inner.Get(ctx, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if bytes.HasPrefix(data, envelopeMagic) {
+		if data, err = s.open(key, data); err != nil {
+			return nil, nil, err
+		}
+	}
+	return io.NopCloser(bytes.NewReader(data)), &ObjectInfo{Size: int64(len(data)), ContentType: info.ContentType}, nil
+}
+
+func (s *EncryptedStore) Delete(ctx context.Context, key string) error {
+	return s.inner.Delete(ctx, key)
+}
+
+// List lists the objects of the underlying store. Sizes are those of the
+// stored ciphertext.
+func (s *EncryptedStore) List(ctx context.Context, prefix string) ([]ObjectEntry, error) {
+	return s.inner.List(ctx, prefix)
+}
+
+func (s *EncryptedStore) SignedURL(key string, expiry time.Duration) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	query := s.signer.Sign(key, s.now().Add(expiry))
+	return s.baseURL + "/" + escapeKey(key) + "?" + query.Encode(), nil
+}
+
+// Verify checks the query parameters of a request made with a URL from
+// SignedURL.
+func (s *EncryptedStore) Verify(key string, query url.Values) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	return s.signer.Verify(key, query, s.now())
+}
+
+// Rewrap makes sure an object is encrypted under the active master key. An
+// object sealed with an older key only has its data key rewrapped; a
+// plaintext object is encrypted. It reports whether the object changed.
+func (s *EncryptedStore) Rewrap(ctx context.Context, key string) (bool, error) {
+	body, info, err := s.inner.Get(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return false, err
+	}
+
+	var rewritten []byte
+	if bytes.HasPrefix(data, envelopeMagic) {
+		env, err := parseEnvelope(data)
+		if err != nil {
+			return false, err
+		}
+		if env.keyID == s.ring.Active() {
+			return false, nil
+		}
+		dataKey, err := s.ring.Open(env.keyID, env.wrappedKey, []byte(key))
+		if err != nil {
+			return false, err
+		}
+		keyID, wrapped, err := s.ring.Seal(dataKey, []byte(key))
+		if err != nil {
+			return false, err
+		}
+		env.keyID, env.wrappedKey = keyID, wrapped
+		rewritten = env.encode()
+	} else if rewritten, err = s.seal(key, data); err != nil {
+		return false, err
+	}
+
+	if err := s.inner.Put(ctx, key, bytes.NewReader(rewritten), int64(len(rewritten)), info.ContentType); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// seal encrypts plaintext under a new data key. The object key is bound to
+// both the wrapped data key and the ciphertext, so an object copied to
+// another key no longer decrypts.
+func (s *EncryptedStore) seal(key string, plaintext []byte) ([]byte, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	keyID, wrapped, err := s.ring.Seal(dataKey, []byte(key))
+	if err != nil {
+		return nil, err
+	}
+	env := envelope{
+		keyID:      keyID,
+		wrappedKey: wrapped,
+		nonce:      nonce,
+		ciphertext: aead.Seal(nil, nonce, plaintext, []byte(key)),
+	}
+	return env.encode(), nil
+}
+
+func (s *EncryptedStore) open(key string, data []byte) ([]byte, error) {
+	env, err := parseEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := s.ring.Open(env.keyID, env.wrappedKey, []byte(key))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, env.nonce, env.ciphertext, []byte(key))
+	if err != nil {
+		return nil, keyring.ErrDecrypt
+	}
+	return plaintext, nil
+}
+
+// envelope is the stored layout of an encrypted object:
+//
+//	magic | version | key id length (1) | key id | wrapped key length (2) |
+//	wrapped key | nonce (12) | ciphertext
+type envelope struct {
+	keyID      string
+	wrappedKey []byte
+	nonce      []byte
+	ciphertext []byte
+}
+
+func (e envelope) encode() []byte {
+	out := make([]byte, 0, len(envelopeMagic)+4+len(e.keyID)+len(e.wrappedKey)+len(e.nonce)+len(e.ciphertext))
+	out = append(out, envelopeMagic...)
+	out = append(out, envelopeVersion, byte(len(e.keyID)))
+	out = append(out, e.keyID...)
+	out = binary.BigEndian.AppendUint16(out, uint16(len(e.wrappedKey)))
+	out = append(out, e.wrappedKey...)
+	out = append(out, e.nonce...)
+	return append(out, e.ciphertext...)
+}
+
+func parseEnvelope(data []byte) (envelope, error) {
+	var env envelope
+	rest := data[len(envelopeMagic):]
+	if len(rest) < 2 || rest[0] != envelopeVersion {
+		return env, ErrCorruptEnvelope
+	}
+	idLen := int(rest[1])
+	rest = rest[2:]
+	if len(rest) < idLen+2 {
+		return env, ErrCorruptEnvelope
+	}
+	env.keyID, rest = string(rest[:idLen]), rest[idLen:]
+
+	wrappedLen := int(binary.BigEndian.Uint16(rest))
+	rest = rest[2:]
+	if len(rest) < wrappedLen+12 {
+		return env, ErrCorruptEnvelope
+	}
+	env.wrappedKey, rest = rest[:wrappedLen], rest[wrappedLen:]
+	env.nonce, env.ciphertext = rest[:12], rest[12:]
+	return env, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
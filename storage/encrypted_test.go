@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"xyz-multifinance/pkg/keyring"
+	"xyz-multifinance/pkg/urlsign"
+)
+
+const (
+	testKey1 = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	testKey2 = "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="
+)
+
+func mustRing(t *testing.T, spec, active string) *keyring.Keyring {
+	t.Helper()
+	ring, err := keyring.Parse(spec, active)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ring
+}
+
+func readAll(t *testing.T, store BlobStore, key string) []byte {
+	t.Helper()
+	body, _, err := store.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get(%s): %v", key, err)
+	}
+	defer body.Close()
+	data, _ := io.ReadAll(body)
+	return data
+}
+
+func TestEncryptedStore(t *testing.T) {
+	dir := t.TempDir()
+	inner := NewFileSystemStore(dir, "http://localhost/files", urlsign.New("secret"))
+	signer := urlsign.New("secret")
+	store := NewEncryptedStore(inner, mustRing(t, "k1:"+testKey1, "k1"), "http://localhost/files", signer)
+	ctx := context.Background()
+	photo := []byte("ktp photo of 3201010101010001")
+
+	if err := store.Put(ctx, "kyc/a/ktp.jpg", bytes.NewReader(photo), int64(len(photo)), "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	onDisk, _ := os.ReadFile(filepath.Join(dir, "kyc/a/ktp.jpg"))
+	if bytes.Contains(onDisk, []byte("3201010101010001")) {
+		t.Error("plaintext found on disk")
+	}
+	if got := readAll(t, store, "kyc/a/ktp.jpg"); !bytes.Equal(got, photo) {
+		t.Errorf("Get = %q", got)
+	}
+
+	// An object copied under another key must not decrypt.
+	os.MkdirAll(filepath.Join(dir, "kyc/b"), 0o750)
+	os.WriteFile(filepath.Join(dir, "kyc/b/ktp.jpg"), onDisk, 0o640)
+	if _, _, err := store.Get(ctx, "kyc/b/ktp.jpg"); err != keyring.ErrDecrypt {
+		t.Errorf("Get of a copied object: err = %v, want ErrDecrypt", err)
+	}
+
+	// Objects stored before encryption was enabled are still readable.
+	inner.Put(ctx, "images/legacy.jpg", strings.NewReader("legacy"), 6, "image/jpeg")
+	if got := readAll(t, store, "images/legacy.jpg"); string(got) != "legacy" {
+		t.Errorf("Get of a plaintext object = %q", got)
+	}
+}
+
+func TestEncryptedStoreRewrap(t *testing.T) {
+	dir := t.TempDir()
+	inner := NewFileSystemStore(dir, "http://localhost/files", urlsign.New("secret"))
+	signer := urlsign.New("secret")
+	ctx := context.Background()
+
+	old := NewEncryptedStore(inner, mustRing(t, "k1:"+testKey1, "k1"), "", signer)
+	old.Put(ctx, "kyc/a/ktp.jpg", strings.NewReader("photo"), 5, "image/jpeg")
+	inner.Put(ctx, "images/legacy.jpg", strings.NewReader("legacy"), 6, "image/jpeg")
+	before, _ := os.ReadFile(filepath.Join(dir, "kyc/a/ktp.jpg"))
+
+	rotated := NewEncryptedStore(inner, mustRing(t, "k1:"+testKey1+",k2:"+testKey2, "k2"), "", signer)
+	for _, key := range []string{"kyc/a/ktp.jpg", "images/legacy.jpg"} {
+		if changed, err := rotated.Rewrap(ctx, key); err != nil || !changed {
+			t.Fatalf("Rewrap(%s) = %v, %v", key, changed, err)
+		}
+	}
+	if changed, err := rotated.Rewrap(ctx, "kyc/a/ktp.jpg"); err != nil || changed {
+		t.Errorf("second Rewrap = %v, %v, want no change", changed, err)
+	}
+
+	after, _ := os.ReadFile(filepath.Join(dir, "kyc/a/ktp.jpg"))
+	if !bytes.Equal(after[len(after)-21:], before[len(before)-21:]) {
+		t.Error("Rewrap re-encrypted the data instead of only the data key")
+	}
+
+	// Once everything is rewrapped the old key can be retired.
+	retired := NewEncryptedStore(inner, mustRing(t, "k2:"+testKey2, "k2"), "", signer)
+	if got := readAll(t, retired, "kyc/a/ktp.jpg"); string(got) != "photo" {
+		t.Errorf("Get after rotation = %q", got)
+	}
+	if got := readAll(t, retired, "images/legacy.jpg"); string(got) != "legacy" {
+		t.Errorf("Get of encrypted legacy object = %q", got)
+	}
+	raw, _ := os.ReadFile(filepath.Join(dir, "images/legacy.jpg"))
+	if !bytes.HasPrefix(raw, envelopeMagic) {
+		t.Error("legacy object was not encrypted by Rewrap")
+	}
+}
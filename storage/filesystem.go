@@ -0,0 +1,167 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"xyz-multifinance/pkg/urlsign"
+)
+
+// FileSystemStore keeps objects as files below a root directory. Its signed
+// URLs point at baseURL, where the application serves them after checking
+// the signature with Verify.
+type FileSystemStore struct {
+	root    string
+	baseURL string
+	signer  *urlsign.Signer
+	now     func() time.Time
+}
+
+func NewFileSystemStore(root, baseURL string, signer *urlsign.Signer) *FileSystemStore {
+	return &FileSystemStore{
+		root:    root,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		signer:  signer,
+		now:     time.Now,
+	}
+}
+
+func (s *FileSystemStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o640)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	if _, err := io.Copy(dst, r); err != nil {
+		dst.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return dst.Close()
+}
+
+func (s *FileSystemStore) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	if stat.IsDir() {
+		file.Close()
+		return nil, nil, ErrNotFound
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(path))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return file, &ObjectInfo{Size: stat.Size(), ContentType: contentType}, nil
+}
+
+func (s *FileSystemStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileSystemStore) List(ctx context.Context, prefix string) ([]ObjectEntry, error) {
+	var entries []ObjectEntry
+	err := filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == s.root && os.IsNotExist(err) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, ObjectEntry{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+	return entries, nil
+}
+
+func (s *FileSystemStore) SignedURL(key string, expiry time.Duration) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	query := s.signer.Sign(key, s.now().Add(expiry))
+	return s.baseURL + "/" + escapeKey(key) + "?" + query.Encode(), nil
+}
+
+// Verify checks the query parameters of a request made with a URL from
+// SignedURL.
+func (s *FileSystemStore) Verify(key string, query url.Values) error {
+	if err := ValidateKey(key); err != nil {
+		return err
+	}
+	return s.signer.Verify(key, query, s.now())
+}
+
+func (s *FileSystemStore) path(key string) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"xyz-multifinance/pkg/urlsign"
+)
+
+func TestFileSystemStore(t *testing.T) {
+	store := NewFileSystemStore(t.TempDir(), "http://localhost:8080/api/v1/files/", urlsign.New("secret"))
+	ctx := context.Background()
+	key := "kyc/3201010101010001/selfie_1.png"
+
+	if err := store.Put(ctx, key, strings.NewReader("png bytes"), 9, "image/png"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	body, info, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "png bytes" || info.Size != 9 || info.ContentType != "image/png" {
+		t.Errorf("Get = %q %+v", data, info)
+	}
+
+	signed, err := store.SignedURL(key, time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL: %v", err)
+	}
+	u, _ := url.Parse(signed)
+	if u.Path != "/api/v1/files/"+key {
+		t.Errorf("signed URL path = %s", u.Path)
+	}
+	if err := store.Verify(key, u.Query()); err != nil {
+		t.Errorf("Verify: %v", err)
+	}
+	if err := store.Verify("kyc/3201010101010001/ktp_1.png", u.Query()); err == nil {
+		t.Error("Verify accepted a signature for another key")
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing key: %v", err)
+	}
+	if _, _, err := store.Get(ctx, key); err != ErrNotFound {
+		t.Errorf("Get after delete: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestFileSystemStoreList(t *testing.T) {
+	ctx := context.Background()
+	store := NewFileSystemStore(t.TempDir()+"/missing", "http://localhost:8080/api/v1/files", urlsign.New("secret"))
+	if entries, err := store.List(ctx, ""); err != nil || len(entries) != 0 {
+		t.Fatalf("List of an empty store = %v, %v", entries, err)
+	}
+
+	for _, key := range []string{"kyc/2/ktp.jpg", "kyc/1/selfie.jpg", "kyc/1/ktp.jpg", "images/legacy.jpg"} {
+		if err := store.Put(ctx, key, strings.NewReader(key), int64(len(key)), "image/jpeg"); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+	}
+	entries, err := store.List(ctx, "kyc/1/")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Key != "kyc/1/ktp.jpg" || entries[1].Key != "kyc/1/selfie.jpg" {
+		t.Errorf("List = %+v", entries)
+	}
+	if entries[0].Size != int64(len("kyc/1/ktp.jpg")) || entries[0].LastModified.IsZero() {
+		t.Errorf("entry = %+v", entries[0])
+	}
+	if all, _ := store.List(ctx, ""); len(all) != 4 {
+		t.Errorf("List of all objects returned %d entries", len(all))
+	}
+}
+
+func TestValidateKey(t *testing.T) {
+	for _, key := range []string{"", "/etc/passwd", "kyc/../../etc/passwd", "kyc//a.jpg", "kyc/./a.jpg", `kyc\a.jpg`} {
+		if err := ValidateKey(key); err != ErrInvalidKey {
+			t.Errorf("ValidateKey(%q) = %v, want ErrInvalidKey", key, err)
+		}
+	}
+	if err := ValidateKey("images/ktp_1.jpg"); err != nil {
+		t.Errorf("ValidateKey rejected a valid key: %v", err)
+	}
+}
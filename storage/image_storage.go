@@ -1,34 +0,0 @@
-package storage
-
-import (
-	"fmt"
-	"io"
-	"mime/multipart"
-	"os"
-	"path/filepath"
-	"time"
-)
-
-func SaveImage(file multipart.File, header *multipart.FileHeader, prefix string) (string, error) {
-	defer file.Close()
-
-	dir := "assets/images"
-	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
-		return "", fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	filename := fmt.Sprintf("%s_%d%s", prefix, time.Now().UnixNano(), filepath.Ext(header.Filename))
-	fullPath := filepath.Join(dir, filename)
-
-	dst, err := os.Create(fullPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to create file: %w", err)
-	}
-	defer dst.Close()
-
-	if _, err := io.Copy(dst, file); err != nil {
-		return "", fmt.Errorf("failed to copy file: %w", err)
-	}
-
-	return fullPath, nil
-}
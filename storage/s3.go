@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// S3Config points an S3Store at a bucket on Amazon S3 or an S3-compatible
+// server such as MinIO. PathStyle addresses the bucket as the first path
+// segment (http://host/bucket/key), which most self-hosted servers need,
+// instead of as a subdomain (http://bucket.host/key).
+type S3Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool
+}
+
+// S3Store keeps objects in an S3 bucket, signing requests with AWS
+// Signature Version 4. Uploads are sent with an unsigned payload so bodies
+// can be streamed; use an https endpoint outside of local development.
+type S3Store struct {
+	endpoint  *url.URL
+	bucket    string
+	pathStyle bool
+	creds     sigV4Credentials
+	client    *http.Client
+	now       func() time.Time
+}
+
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+	if cfg.Endpoint == "" || cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, errors.New("storage: s3 endpoint, bucket, access key and secret key are required")
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "http" && endpoint.Scheme != "https") {
+		return nil, fmt.Errorf("storage: invalid s3 endpoint %q", cfg.Endpoint)
+	}
+	region := cfg.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	return &S3Store{
+		endpoint:  endpoint,
+		bucket:    cfg.Bucket,
+		pathStyle: cfg.PathStyle,
+		creds:     sigV4Credentials{AccessKey: cfg.AccessKey, SecretKey: cfg.SecretKey, Region: region},
+		client:    &http.Client{Timeout: time.Minute},
+		now:       time.Now,
+	}, nil
+}
+
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	req, err := s.newRequest(ctx, http.MethodPut, key, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	s.creds.signRequest(req, unsignedPayload, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError(resp)
+	}
+	return nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.creds.signRequest(req, emptyPayloadHash, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, responseError(resp)
+	}
+	return resp.Body, &ObjectInfo{Size: resp.ContentLength, ContentType: resp.Header.Get("Content-Type")}, nil
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	s.creds.signRequest(req, emptyPayloadHash, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return responseError(resp)
+	}
+	return nil
+}
+
+// List pages through the bucket with ListObjectsV2.
+func (s *S3Store) List(ctx context.Context, prefix string) ([]ObjectEntry, error) {
+	var entries []ObjectEntry
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		u := s.bucketURL()
+		u.RawQuery = query.Encode()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		s.creds.signRequest(req, emptyPayloadHash, s.now())
+
+		page, err := s.listPage(req)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			entries = append(entries, ObjectEntry{Key: object.Key, Size: object.Size, LastModified: object.LastModified})
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return entries, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+type listBucketResult struct {
+	Contents []struct {
+		Key          string
+		Size         int64
+		LastModified time.Time
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+func (s *S3Store) listPage(req *http.Request) (*listBucketResult, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp)
+	}
+	var page listBucketResult
+	if err := xml.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("storage: invalid s3 list response: %w", err)
+	}
+	return &page, nil
+}
+
+// SignedURL returns a presigned GET URL. S3 caps the expiry at seven days.
+func (s *S3Store) SignedURL(key string, expiry time.Duration) (string, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+	return s.creds.presign(http.MethodGet, u, expiry, s.now()).String(), nil
+}
+
+func (s *S3Store) newRequest(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	return http.NewRequestWithContext(ctx, method, u.String(), body)
+}
+
+func (s *S3Store) objectURL(key string) (*url.URL, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	u := s.bucketURL()
+	u.Path += key
+	u.RawPath += escapeKey(key)
+	return u, nil
+}
+
+// bucketURL returns the URL of the bucket itself, ending in a slash.
+func (s *S3Store) bucketURL() *url.URL {
+	base := strings.TrimSuffix(s.endpoint.Path, "/")
+	u := &url.URL{Scheme: s.endpoint.Scheme, Host: s.endpoint.Host}
+	if s.pathStyle {
+		u.Path = base + "/" + s.bucket + "/"
+		u.RawPath = base + "/" + escapeKey(s.bucket) + "/"
+	} else {
+		u.Host = s.bucket + "." + s.endpoint.Host
+		u.Path = base + "/"
+		u.RawPath = base + "/"
+	}
+	return u
+}
+
+// responseError turns an S3 error response into an error carrying the
+// status and the start of the XML error document.
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("storage: s3 responded %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"xyz-multifinance/pkg/imageupload"
+)
+
+// SaveImage stores a processed upload under keyPrefix followed by a unique
+// suffix and the image's extension, and returns the new object key.
+func SaveImage(ctx context.Context, store BlobStore, keyPrefix string, img *imageupload.Image) (string, error) {
+	key := fmt.Sprintf("%s_%d%s", keyPrefix, time.Now().UnixNano(), img.Extension)
+	if err := store.Put(ctx, key, bytes.NewReader(img.Data), int64(len(img.Data)), img.ContentType); err != nil {
+		return "", fmt.Errorf("failed to store image: %w", err)
+	}
+	return key, nil
+}
+
+// NewKYCDir returns a fresh key directory for KYC documents. It is random
+// rather than derived from the customer, so object keys never reveal a NIK
+// and can be chosen before the customer row exists.
+func NewKYCDir() string {
+	id := make([]byte, 16)
+	rand.Read(id)
+	return "kyc/" + hex.EncodeToString(id)
+}
+
+// KYCKeyPrefix is the key prefix for a KYC document of the given kind, such
+// as "ktp" or "selfie", in a directory from NewKYCDir.
+func KYCKeyPrefix(dir, kind string) string {
+	return dir + "/" + kind
+}
+
+// CopyObject copies the object at from to the key to. The source is left in
+// place.
+func CopyObject(ctx context.Context, store BlobStore, from, to string) error {
+	body, info, err := store.Get(ctx, from)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	return store.Put(ctx, to, body, info.Size, info.ContentType)
+}